@@ -0,0 +1,59 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+
+	nemgen "github.com/nuzur/nem/idl/gen"
+	"github.com/nuzur/sql-gen/tosql"
+)
+
+func runDiff(ctx context.Context, args []string, stdout, stderr io.Writer) error {
+	fs := newFlagSet("diff", "from.json to.json", stderr)
+	dialect := fs.String("dialect", "", "mysql, postgres or sqlite")
+	fromOptionsFile := fs.String("from-options", "", "schema options JSON file of the from side")
+	toOptionsFile := fs.String("to-options", "", "schema options JSON file of the to side")
+	rollback := fs.Bool("rollback", false, "write the rollback, from to back to from, instead")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() != 2 {
+		fs.Usage()
+		return fmt.Errorf("diff takes two project version files, got %d; an empty from is an empty database", fs.NArg())
+	}
+
+	dbType, err := parseDialect(*dialect)
+	if err != nil {
+		return err
+	}
+	sides := [2]*nemgen.ProjectVersion{}
+	for n, file := range fs.Args() {
+		// an empty name is no project version: the empty database, or none at all
+		if file == "" {
+			continue
+		}
+		if sides[n], err = readProjectVersion(file); err != nil {
+			return err
+		}
+	}
+	fromOptions, err := readOptions(*fromOptionsFile)
+	if err != nil {
+		return err
+	}
+	toOptions, err := readOptions(*toOptionsFile)
+	if err != nil {
+		return err
+	}
+
+	m, err := tosql.GenerateMigrationWithOptions(sides[0], sides[1], dbType, fromOptions, toOptions)
+	if err != nil {
+		return err
+	}
+	if *rollback {
+		_, err = io.WriteString(stdout, m.RollbackSQL())
+		return err
+	}
+	_, err = io.WriteString(stdout, m.SQL())
+	return err
+}
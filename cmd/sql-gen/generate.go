@@ -0,0 +1,88 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"slices"
+
+	"github.com/gofrs/uuid"
+	nemgen "github.com/nuzur/nem/idl/gen"
+	"github.com/nuzur/sql-gen/tosql"
+)
+
+var actions = []tosql.Action{
+	tosql.CreateAction,
+	tosql.DropAction,
+	tosql.InsertAction,
+	tosql.UpdateAction,
+	tosql.DeleteAction,
+	tosql.UpsertAction,
+	tosql.SelectSimpleAction,
+	tosql.SelectForIndexedSimpleAction,
+	tosql.SelectForIndexedCombinedAction,
+}
+
+func runGenerate(ctx context.Context, args []string, stdout, stderr io.Writer) error {
+	fs := newFlagSet("generate", "project_version.json", stderr)
+	dialect := fs.String("dialect", "", "mysql, postgres or sqlite")
+	actionList := fs.String("actions", string(tosql.CreateAction), fmt.Sprintf("comma-separated actions, of %v", actions))
+	entities := fs.String("entities", "", "comma-separated uuids of the entities to generate, every standalone entity if empty")
+	optionsFile := fs.String("options", "", "schema options JSON file")
+	out := fs.String("out", "", "directory to write one <action>.sql file per action into, stdout if empty")
+	zip := fs.Bool("zip", false, "also zip the --out directory into <out>.zip")
+	forGolang := fs.Bool("for-golang", false, "render statements for sqlc-style Go code generation")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() != 1 {
+		fs.Usage()
+		return fmt.Errorf("generate takes one project version file, got %d", fs.NArg())
+	}
+
+	dbType, err := parseDialect(*dialect)
+	if err != nil {
+		return err
+	}
+	requested := []tosql.Action{}
+	for _, a := range list(*actionList) {
+		if !slices.Contains(actions, tosql.Action(a)) {
+			return fmt.Errorf("unknown action %q: want one of %v", a, actions)
+		}
+		requested = append(requested, tosql.Action(a))
+	}
+	pv, err := readProjectVersion(fs.Arg(0))
+	if err != nil {
+		return err
+	}
+	options, err := readOptions(*optionsFile)
+	if err != nil {
+		return err
+	}
+	entityUuids := list(*entities)
+	if len(entityUuids) == 0 {
+		for _, e := range pv.Entities {
+			if e.Type == nemgen.EntityType_ENTITY_TYPE_STANDALONE {
+				entityUuids = append(entityUuids, e.Uuid)
+			}
+		}
+	}
+
+	var sink tosql.Sink = &tosql.WriterSink{W: stdout}
+	if *out != "" {
+		sink = &tosql.DirSink{Dir: *out, Zip: *zip}
+	}
+	_, err = tosql.GenerateSQL(ctx, tosql.GenerateRequest{
+		ExecutionUUID:  uuid.Must(uuid.NewV4()).String(),
+		ProjectVersion: pv,
+		Configvalues: &tosql.ConfigValues{
+			DBType:        dbType,
+			Entities:      entityUuids,
+			Actions:       requested,
+			SchemaOptions: options,
+		},
+		ForGolang: *forGolang,
+		Sink:      sink,
+	})
+	return err
+}
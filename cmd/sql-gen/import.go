@@ -0,0 +1,137 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+
+	"github.com/jmoiron/sqlx"
+	nemgen "github.com/nuzur/nem/idl/gen"
+	"github.com/nuzur/sql-gen/db"
+	"github.com/nuzur/sql-gen/fromsql"
+
+	_ "github.com/go-sql-driver/mysql"
+	_ "github.com/jackc/pgx/v5/stdlib"
+	_ "modernc.org/sqlite"
+)
+
+// drivers are the database/sql drivers import opens each dialect's DSN with.
+var drivers = map[db.DBType]string{
+	db.MYSQLDBType:  "mysql",
+	db.PGDBType:     "pgx",
+	db.SQLiteDBType: "sqlite",
+}
+
+func runImport(ctx context.Context, args []string, stdout, stderr io.Writer) error {
+	fs := newFlagSet("import", "", stderr)
+	dialect := fs.String("dialect", "", "mysql, postgres or sqlite")
+	dsn := fs.String("dsn", "", "the database to import: a go-sql-driver/mysql DSN, a postgres URL or a sqlite file")
+	ddl := fs.String("ddl", "", "a mysqldump --no-data or pg_dump -s file to read instead of a database")
+	schema := fs.String("schema", "", "the mysql database or postgres schema to import, the connection's own if empty")
+	schemas := fs.String("schemas", "", "comma-separated postgres schemas to import together, qualifying every name")
+	allSchemas := fs.Bool("all-schemas", false, "import every postgres schema, qualifying every name")
+	bulk := fs.Bool("bulk", false, "read each kind of detail of every mysql or postgres table at once, for a slow connection")
+	concurrency := fs.Int("concurrency", fromsql.DefaultConcurrency, "the most tables introspected at once")
+	noSample := fs.Bool("no-sample", false, "read no rows, leaving every column the type its SQL type gives it")
+	sampleSize := fs.Int("sample-size", fromsql.DefaultSampleSize, "how many rows to sample from each table")
+	sampleMethod := fs.String("sample-method", "", "how to pick the rows sampled: first (the default), random or tablesample")
+	sampleColumns := fs.String("sample-columns", "", "comma-separated columns, as column or table.column, to sample alone")
+	out := fs.String("out", "", "file to write the project version JSON to, stdout if empty")
+	optionsOut := fs.String("options-out", "", "file to write the schema options JSON to, which generate and diff read back")
+	promotionsOut := fs.String("promotions-out", "", "file to write the JSON list of the columns sampling typed to")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() != 0 {
+		fs.Usage()
+		return fmt.Errorf("import takes no arguments, got %d", fs.NArg())
+	}
+
+	dbType, err := parseDialect(*dialect)
+	if err != nil {
+		return err
+	}
+	method := fromsql.SampleMethod(*sampleMethod)
+	if method == "first" {
+		method = fromsql.SampleFirst
+	}
+	if !slices.Contains([]fromsql.SampleMethod{fromsql.SampleFirst, fromsql.SampleRandom, fromsql.SampleTableSample}, method) {
+		return fmt.Errorf("unknown sample method %q", *sampleMethod)
+	}
+	req := fromsql.GenerateRequest{
+		UserConnection: &nemgen.UserConnection{DbSchema: *schema},
+		DBType:         dbType,
+		Schemas:        list(*schemas),
+		AllSchemas:     *allSchemas,
+		Sampling: fromsql.Sampling{
+			Disabled: *noSample,
+			Size:     *sampleSize,
+			Method:   method,
+			Columns:  list(*sampleColumns),
+		},
+		Bulk:        *bulk,
+		Concurrency: *concurrency,
+	}
+	switch {
+	case *ddl != "" && *dsn != "":
+		return fmt.Errorf("--ddl and --dsn are exclusive")
+	case *ddl != "":
+		src, err := os.ReadFile(*ddl)
+		if err != nil {
+			return err
+		}
+		req.DDL = string(src)
+	case *dsn != "":
+		conn, err := sqlx.Open(drivers[dbType], *dsn)
+		if err != nil {
+			return err
+		}
+		defer conn.Close()
+		if err := conn.PingContext(ctx); err != nil {
+			return fmt.Errorf("connecting to %s: %w", dbType, err)
+		}
+		req.DB = fromsql.FromSqlx(conn)
+	default:
+		return fmt.Errorf("--dsn or --ddl is required")
+	}
+
+	res, err := fromsql.Import(ctx, req)
+	if err != nil {
+		return err
+	}
+	if err := writeJSON(stdout, *out, res.ProjectVersion); err != nil {
+		return err
+	}
+	if *optionsOut != "" {
+		if err := writeJSON(stdout, *optionsOut, res.Options); err != nil {
+			return err
+		}
+	}
+	if *promotionsOut != "" {
+		promotions := []promotion{}
+		for _, p := range res.Promotions {
+			promotions = append(promotions, promotion{
+				Table:  p.Table,
+				Column: p.Column,
+				From:   p.From.String(),
+				To:     p.To.String(),
+				Rows:   p.Rows,
+				Values: p.Values,
+			})
+		}
+		return writeJSON(stdout, *promotionsOut, promotions)
+	}
+	return nil
+}
+
+// promotion is a fromsql.Promotion with its field types spelled out.
+type promotion struct {
+	Table  string `json:"table"`
+	Column string `json:"column"`
+	From   string `json:"from"`
+	To     string `json:"to"`
+	Rows   int    `json:"rows"`
+	Values int    `json:"values"`
+}
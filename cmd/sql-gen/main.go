@@ -0,0 +1,157 @@
+// Command sql-gen generates SQL from a nem project version, imports one from a
+// live database or a schema dump, diffs two into a migration, and renders single statements.
+//
+//	sql-gen generate      --dialect postgres --actions create,drop project_version.json
+//	sql-gen import        --dialect mysql --dsn 'user:pass@tcp(host)/db' --out project_version.json
+//	sql-gen import        --dialect postgres --ddl schema.sql --out project_version.json
+//	sql-gen diff          --dialect postgres from.json to.json
+//	sql-gen render-entity --dialect sqlite --entity user --statement insert --set email=a@b.c project_version.json
+//
+// A project version is read and written as JSON, the way
+// tosql/testdata/project_version.json is. What nem has no field for — CHECK
+// constraints, views, generated columns and so on — travels beside it as a
+// tosql.SchemaOptions JSON file, which import writes with --options-out and the
+// other commands read with --options.
+//
+// The command is a module of its own, so the database drivers import uses are
+// its requirements and not the library's: build it from this directory.
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	nemgen "github.com/nuzur/nem/idl/gen"
+	"github.com/nuzur/sql-gen/db"
+	"github.com/nuzur/sql-gen/tosql"
+)
+
+const usage = `usage: sql-gen <command> [flags] [args]
+
+commands:
+  generate       render a project version's actions as SQL files
+  import         introspect a database or schema dump into a project version
+  diff           the migration from one project version to another
+  render-entity  a single insert, update, delete or select for one entity
+
+Run sql-gen <command> -h for the flags of each.
+`
+
+func main() {
+	if err := run(context.Background(), os.Args[1:], os.Stdout, os.Stderr); err != nil {
+		if !errors.Is(err, flag.ErrHelp) {
+			fmt.Fprintln(os.Stderr, "sql-gen:", err)
+		}
+		os.Exit(1)
+	}
+}
+
+// run runs the command the arguments name, writing its output to stdout and
+// its usage to stderr.
+func run(ctx context.Context, args []string, stdout, stderr io.Writer) error {
+	if len(args) == 0 {
+		fmt.Fprint(stderr, usage)
+		return flag.ErrHelp
+	}
+	switch args[0] {
+	case "generate":
+		return runGenerate(ctx, args[1:], stdout, stderr)
+	case "import":
+		return runImport(ctx, args[1:], stdout, stderr)
+	case "diff":
+		return runDiff(ctx, args[1:], stdout, stderr)
+	case "render-entity":
+		return runRenderEntity(ctx, args[1:], stdout, stderr)
+	case "help", "-h", "-help", "--help":
+		fmt.Fprint(stdout, usage)
+		return nil
+	}
+	return fmt.Errorf("unknown command %q\n\n%s", args[0], usage)
+}
+
+// parseDialect resolves a --dialect, accepting the usual spellings of each.
+func parseDialect(dialect string) (db.DBType, error) {
+	switch strings.ToLower(dialect) {
+	case "mysql":
+		return db.MYSQLDBType, nil
+	case "postgres", "postgresql", "pg":
+		return db.PGDBType, nil
+	case "sqlite", "sqlite3":
+		return db.SQLiteDBType, nil
+	case "":
+		return "", errors.New("--dialect is required: mysql, postgres or sqlite")
+	}
+	return "", fmt.Errorf("unknown dialect %q: want mysql, postgres or sqlite", dialect)
+}
+
+func readProjectVersion(file string) (*nemgen.ProjectVersion, error) {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+	pv := &nemgen.ProjectVersion{}
+	if err := json.Unmarshal(data, pv); err != nil {
+		return nil, fmt.Errorf("reading project version %s: %w", file, err)
+	}
+	return pv, nil
+}
+
+// readOptions reads a SchemaOptions file, or returns the defaults if file is
+// empty.
+func readOptions(file string) (tosql.SchemaOptions, error) {
+	options := tosql.SchemaOptions{}
+	if file == "" {
+		return options, nil
+	}
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return options, err
+	}
+	if err := json.Unmarshal(data, &options); err != nil {
+		return options, fmt.Errorf("reading options %s: %w", file, err)
+	}
+	return options, nil
+}
+
+// writeJSON writes v as indented JSON to file, or to stdout if file is empty.
+func writeJSON(stdout io.Writer, file string, v any) error {
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return err
+	}
+	data = append(data, '\n')
+	if file == "" {
+		_, err = stdout.Write(data)
+		return err
+	}
+	return os.WriteFile(file, data, 0o644)
+}
+
+// newFlagSet is a subcommand's flag set, which returns its errors rather than
+// exiting and prints its usage to stderr.
+func newFlagSet(name string, args string, stderr io.Writer) *flag.FlagSet {
+	fs := flag.NewFlagSet(name, flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "usage: sql-gen %s [flags] %s\n\nflags:\n", name, args)
+		fs.PrintDefaults()
+	}
+	return fs
+}
+
+// list is a comma-separated flag value.
+func list(value string) []string {
+	res := []string{}
+	for _, v := range strings.Split(value, ",") {
+		if v = strings.TrimSpace(v); v != "" {
+			res = append(res, v)
+		}
+	}
+	return res
+}
@@ -0,0 +1,187 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"flag"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const projectVersion = "../../tosql/testdata/project_version.json"
+
+func runArgs(t *testing.T, args ...string) (string, error) {
+	t.Helper()
+	var out bytes.Buffer
+	err := run(context.Background(), args, &out, io.Discard)
+	return out.String(), err
+}
+
+func TestUsageWritesToStderr(t *testing.T) {
+	var stdout, stderr bytes.Buffer
+	err := run(context.Background(), nil, &stdout, &stderr)
+	assert.ErrorIs(t, err, flag.ErrHelp)
+	assert.Contains(t, stderr.String(), "usage: sql-gen <command>")
+	assert.Empty(t, stdout.String())
+
+	stderr.Reset()
+	err = run(context.Background(), []string{"diff", "--nope"}, &stdout, &stderr)
+	assert.Error(t, err)
+	assert.Contains(t, stderr.String(), "usage: sql-gen diff [flags] from.json to.json")
+	assert.Empty(t, stdout.String())
+}
+
+func TestGenerateWritesToStdout(t *testing.T) {
+	out, err := runArgs(t, "generate", "--dialect", "postgres", "--actions", "drop,create", projectVersion)
+	require.NoError(t, err)
+	assert.Contains(t, out, `DROP TABLE IF EXISTS "user"`)
+	assert.Contains(t, out, `CREATE TABLE IF NOT EXISTS "user"`)
+	assert.Less(t, bytes.Index([]byte(out), []byte("DROP")), bytes.Index([]byte(out), []byte("CREATE")))
+}
+
+func TestGenerateWritesToDir(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "sql")
+	_, err := runArgs(t, "generate", "--dialect", "mysql", "--out", dir, "--zip", projectVersion)
+	require.NoError(t, err)
+	create, err := os.ReadFile(filepath.Join(dir, "create.sql"))
+	require.NoError(t, err)
+	assert.Contains(t, string(create), "CREATE TABLE IF NOT EXISTS `user`")
+	assert.FileExists(t, dir+".zip")
+}
+
+func TestGenerateRejectsBadFlags(t *testing.T) {
+	_, err := runArgs(t, "generate", "--dialect", "oracle", projectVersion)
+	assert.ErrorContains(t, err, `unknown dialect "oracle"`)
+	_, err = runArgs(t, "generate", "--dialect", "sqlite", "--actions", "create,truncate", projectVersion)
+	assert.ErrorContains(t, err, `unknown action "truncate"`)
+	_, err = runArgs(t, "frobnicate")
+	assert.ErrorContains(t, err, `unknown command "frobnicate"`)
+}
+
+func TestDiffFromEmpty(t *testing.T) {
+	out, err := runArgs(t, "diff", "--dialect", "sqlite", "", projectVersion)
+	require.NoError(t, err)
+	assert.Contains(t, out, `CREATE TABLE IF NOT EXISTS "user"`)
+
+	out, err = runArgs(t, "diff", "--dialect", "sqlite", "--rollback", "", projectVersion)
+	require.NoError(t, err)
+	assert.Contains(t, out, `DROP TABLE "user";`)
+
+	out, err = runArgs(t, "diff", "--dialect", "sqlite", projectVersion, projectVersion)
+	require.NoError(t, err)
+	assert.Empty(t, out)
+}
+
+func TestRenderEntity(t *testing.T) {
+	out, err := runArgs(t, "render-entity", "--dialect", "pg", "--entity", "user", "--statement", "update",
+		"--set", "email=a@b.c", "--key", "uuid=1", "--key", "version=2", projectVersion)
+	require.NoError(t, err)
+	assert.Contains(t, out, `"parametrized_sql": "UPDATE \"user\"\nSET\n\"email\" = $1\nWHERE\n\"uuid\" = $2 AND \"version\" = $3;\n"`)
+
+	_, err = runArgs(t, "render-entity", "--dialect", "pg", "--entity", "user", "--statement", "delete",
+		"--key", "nope=1", projectVersion)
+	assert.ErrorContains(t, err, `entity user has no field "nope"`)
+
+	out, err = runArgs(t, "render-entity", "--dialect", "pg", "--entity", "user", "--statement", "delete", "--typed",
+		"--key", "uuid=9b2c1c2e-0000-4000-8000-000000000002", "--key", "version=2", projectVersion)
+	require.NoError(t, err)
+	assert.Contains(t, out, `"params": [
+    "9b2c1c2e-0000-4000-8000-000000000002",
+    2
+  ]`)
+
+	_, err = runArgs(t, "render-entity", "--dialect", "pg", "--entity", "user", "--statement", "delete", "--typed",
+		"--key", "uuid=1", "--key", "version=2", projectVersion)
+	assert.ErrorContains(t, err, "not a uuid")
+}
+
+func TestRenderEntityBulkInsert(t *testing.T) {
+	rows := filepath.Join(t.TempDir(), "rows.json")
+	require.NoError(t, os.WriteFile(rows, []byte(`[
+		{"uuid": "9b2c1c2e-0000-4000-8000-000000000001", "version": "1", "email": "a@b.c"},
+		{"uuid": "9b2c1c2e-0000-4000-8000-000000000002", "version": "1", "email": "d@e.f"}
+	]`), 0o644))
+
+	out, err := runArgs(t, "render-entity", "--dialect", "pg", "--entity", "user", "--statement", "bulk-insert",
+		"--rows", rows, "--upsert", "update", "--conflict-index", "unique_email", "--typed", projectVersion)
+	require.NoError(t, err)
+	assert.Contains(t, out, `ON CONFLICT (\"email\") DO UPDATE SET`)
+	assert.Contains(t, out, `"d@e.f"`)
+
+	_, err = runArgs(t, "render-entity", "--dialect", "pg", "--entity", "user", "--statement", "bulk-insert",
+		"--rows", rows, "--upsert", "replace", projectVersion)
+	assert.ErrorContains(t, err, `unknown upsert "replace"`)
+}
+
+func TestImportSQLite(t *testing.T) {
+	dir := t.TempDir()
+	file := filepath.Join(dir, "lot.db")
+	conn, err := sql.Open("sqlite", file)
+	require.NoError(t, err)
+	_, err = conn.Exec(`CREATE TABLE lot (id TEXT PRIMARY KEY, qty INTEGER NOT NULL CHECK (qty > 0))`)
+	require.NoError(t, err)
+	require.NoError(t, conn.Close())
+
+	pvFile := filepath.Join(dir, "pv.json")
+	optionsFile := filepath.Join(dir, "options.json")
+	_, err = runArgs(t, "import", "--dialect", "sqlite", "--dsn", file, "--out", pvFile, "--options-out", optionsFile)
+	require.NoError(t, err)
+	options, err := readOptions(optionsFile)
+	require.NoError(t, err)
+	assert.Len(t, options.Checks, 1)
+
+	// what import wrote, generate reads back
+	out, err := runArgs(t, "generate", "--dialect", "sqlite", "--options", optionsFile, pvFile)
+	require.NoError(t, err)
+	assert.Contains(t, out, `CREATE TABLE IF NOT EXISTS "lot"`)
+	assert.Contains(t, out, "CHECK (qty > 0)")
+}
+
+func TestImportDDL(t *testing.T) {
+	dir := t.TempDir()
+	optionsFile := filepath.Join(dir, "options.json")
+	out, err := runArgs(t, "import", "--dialect", "pg", "--ddl", "../../fromsql/testdata/pg_dump.sql", "--options-out", optionsFile)
+	require.NoError(t, err)
+	assert.Contains(t, out, `"identifier": "purchase"`)
+	options, err := readOptions(optionsFile)
+	require.NoError(t, err)
+	assert.Len(t, options.Views, 1)
+
+	_, err = runArgs(t, "import", "--dialect", "pg")
+	assert.ErrorContains(t, err, "--dsn or --ddl is required")
+	_, err = runArgs(t, "import", "--dialect", "pg", "--ddl", "schema.sql", "--dsn", "postgres://localhost")
+	assert.ErrorContains(t, err, "exclusive")
+}
+
+func TestImportPromotions(t *testing.T) {
+	dir := t.TempDir()
+	file := filepath.Join(dir, "lot.db")
+	conn, err := sql.Open("sqlite", file)
+	require.NoError(t, err)
+	_, err = conn.Exec(`CREATE TABLE lot (id CHAR(36) PRIMARY KEY);
+		INSERT INTO lot VALUES ('6f1c3a52-2a8e-4f7e-9a53-0f0e4b9b8a10')`)
+	require.NoError(t, err)
+	require.NoError(t, conn.Close())
+
+	promotionsFile := filepath.Join(dir, "promotions.json")
+	_, err = runArgs(t, "import", "--dialect", "sqlite", "--dsn", file, "--promotions-out", promotionsFile)
+	require.NoError(t, err)
+	data, err := os.ReadFile(promotionsFile)
+	require.NoError(t, err)
+	assert.JSONEq(t, `[{"table": "lot", "column": "id", "from": "FIELD_TYPE_CHAR", "to": "FIELD_TYPE_UUID", "rows": 1, "values": 1}]`, string(data))
+
+	_, err = runArgs(t, "import", "--dialect", "sqlite", "--dsn", file, "--no-sample", "--promotions-out", promotionsFile)
+	require.NoError(t, err)
+	data, err = os.ReadFile(promotionsFile)
+	require.NoError(t, err)
+	assert.JSONEq(t, `[]`, string(data))
+
+	_, err = runArgs(t, "import", "--dialect", "sqlite", "--dsn", file, "--sample-method", "sometimes")
+	assert.ErrorContains(t, err, `unknown sample method "sometimes"`)
+}
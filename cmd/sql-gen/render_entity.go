@@ -0,0 +1,234 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	nemgen "github.com/nuzur/nem/idl/gen"
+	"github.com/nuzur/sql-gen/tosql"
+)
+
+// assignments is a repeatable field=value flag. The field is named by its
+// identifier or its uuid.
+type assignments []string
+
+func (a *assignments) String() string { return strings.Join(*a, ",") }
+
+func (a *assignments) Set(value string) error {
+	if !strings.Contains(value, "=") {
+		return fmt.Errorf("%q is not field=value", value)
+	}
+	*a = append(*a, value)
+	return nil
+}
+
+// byFieldUuid resolves the assignments onto the entity's field uuids.
+func (a assignments) byFieldUuid(e *nemgen.Entity) (map[string]string, error) {
+	res := make(map[string]string)
+	for _, assignment := range a {
+		name, value, _ := strings.Cut(assignment, "=")
+		f, err := entityField(e, name)
+		if err != nil {
+			return nil, err
+		}
+		res[f.Uuid] = value
+	}
+	return res, nil
+}
+
+func entityField(e *nemgen.Entity, name string) (*nemgen.Field, error) {
+	for _, f := range e.Fields {
+		if f.Uuid == name || f.Identifier == name {
+			return f, nil
+		}
+	}
+	return nil, fmt.Errorf("entity %s has no field %q", e.Identifier, name)
+}
+
+// readRows reads the rows of a bulk insert, resolving their fields onto the
+// entity's field uuids.
+func readRows(file string, e *nemgen.Entity) ([]map[string]string, error) {
+	if file == "" {
+		return nil, fmt.Errorf("--rows is required for bulk-insert")
+	}
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+	rows := []map[string]string{}
+	if err := json.Unmarshal(data, &rows); err != nil {
+		return nil, fmt.Errorf("reading rows %s: %w", file, err)
+	}
+	res := []map[string]string{}
+	for _, row := range rows {
+		byUuid := make(map[string]string)
+		for name, value := range row {
+			f, err := entityField(e, name)
+			if err != nil {
+				return nil, err
+			}
+			byUuid[f.Uuid] = value
+		}
+		res = append(res, byUuid)
+	}
+	return res, nil
+}
+
+func runRenderEntity(ctx context.Context, args []string, stdout, stderr io.Writer) error {
+	fs := newFlagSet("render-entity", "project_version.json", stderr)
+	dialect := fs.String("dialect", "", "mysql, postgres or sqlite")
+	entityName := fs.String("entity", "", "identifier or uuid of the entity")
+	statement := fs.String("statement", "", "insert, update, delete, select or bulk-insert")
+	optionsFile := fs.String("options", "", "schema options JSON file")
+	forGolang := fs.Bool("for-golang", false, "render the statement for sqlc-style Go code generation")
+	columns := fs.String("columns", "", "comma-separated fields a select projects besides the primary keys")
+	rowsFile := fs.String("rows", "", "JSON file of the rows a bulk-insert writes, an array of objects of field to value")
+	upsert := fs.String("upsert", "", "what a bulk-insert does with a conflicting row: update or nothing; fails the statement if empty")
+	conflictIndex := fs.String("conflict-index", "", "identifier or uuid of the unique index a bulk-insert upsert conflicts on; the primary key if empty")
+	typed := fs.Bool("typed", false, "output the params as their field types rather than strings, failing on a value that does not parse")
+	var values, keys assignments
+	fs.Var(&values, "set", "field=value an insert or update writes; repeatable")
+	fs.Var(&keys, "key", "field=value of the primary key an update, delete or select matches; repeatable")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() != 1 {
+		fs.Usage()
+		return fmt.Errorf("render-entity takes one project version file, got %d", fs.NArg())
+	}
+
+	dbType, err := parseDialect(*dialect)
+	if err != nil {
+		return err
+	}
+	pv, err := readProjectVersion(fs.Arg(0))
+	if err != nil {
+		return err
+	}
+	options, err := readOptions(*optionsFile)
+	if err != nil {
+		return err
+	}
+	var entity *nemgen.Entity
+	for _, e := range pv.Entities {
+		if e.Uuid == *entityName || e.Identifier == *entityName {
+			entity = e
+			break
+		}
+	}
+	if entity == nil {
+		return fmt.Errorf("the project version has no entity %q", *entityName)
+	}
+	valuesByField, err := values.byFieldUuid(entity)
+	if err != nil {
+		return err
+	}
+	keysByField, err := keys.byFieldUuid(entity)
+	if err != nil {
+		return err
+	}
+
+	var res any
+	switch *statement {
+	case "insert":
+		p := tosql.GenerateInsertForEntityWithValuesParams{
+			Entity:         entity,
+			ProjectVersion: pv,
+			DBType:         dbType,
+			ForGolang:      *forGolang,
+			SchemaOptions:  options,
+			Values:         valuesByField,
+		}
+		if *typed {
+			res, err = tosql.GenerateInsertForEntityWithTypedValues(ctx, p)
+		} else {
+			res, err = tosql.GenerateInsertForEntityWithValues(ctx, p)
+		}
+	case "update":
+		p := tosql.GenerateUpdateForEntityWithValuesParams{
+			Entity:         entity,
+			ProjectVersion: pv,
+			DBType:         dbType,
+			ForGolang:      *forGolang,
+			SchemaOptions:  options,
+			Values:         valuesByField,
+			Keys:           keysByField,
+		}
+		if *typed {
+			res, err = tosql.GenerateUpdateForEntityWithTypedValues(ctx, p)
+		} else {
+			res, err = tosql.GenerateUpdateForEntityWithValues(ctx, p)
+		}
+	case "delete":
+		p := tosql.GenerateDeleteForEntityWithValuesParams{
+			Entity:         entity,
+			ProjectVersion: pv,
+			DBType:         dbType,
+			ForGolang:      *forGolang,
+			SchemaOptions:  options,
+			Keys:           keysByField,
+		}
+		if *typed {
+			res, err = tosql.GenerateDeleteForEntityWithTypedValues(ctx, p)
+		} else {
+			res, err = tosql.GenerateDeleteForEntityWithValues(ctx, p)
+		}
+	case "select":
+		projected := []string{}
+		for _, name := range list(*columns) {
+			f, err := entityField(entity, name)
+			if err != nil {
+				return err
+			}
+			projected = append(projected, f.Uuid)
+		}
+		p := tosql.GenerateSelectForEntityWithValuesParams{
+			Entity:         entity,
+			ProjectVersion: pv,
+			DBType:         dbType,
+			ForGolang:      *forGolang,
+			SchemaOptions:  options,
+			Keys:           keysByField,
+			Columns:        projected,
+		}
+		if *typed {
+			res, err = tosql.GenerateSelectForEntityWithTypedValues(ctx, p)
+		} else {
+			res, err = tosql.GenerateSelectForEntityWithValues(ctx, p)
+		}
+	case "bulk-insert":
+		var rows []map[string]string
+		if rows, err = readRows(*rowsFile, entity); err != nil {
+			return err
+		}
+		mode := tosql.Upsert(*upsert)
+		if mode != tosql.UpsertNone && mode != tosql.UpsertUpdate && mode != tosql.UpsertNothing {
+			return fmt.Errorf("unknown upsert %q: want update or nothing", *upsert)
+		}
+		p := tosql.GenerateBulkInsertForEntityParams{
+			Entity:         entity,
+			ProjectVersion: pv,
+			DBType:         dbType,
+			ForGolang:      *forGolang,
+			SchemaOptions:  options,
+			Rows:           rows,
+			Upsert:         mode,
+			ConflictIndex:  *conflictIndex,
+		}
+		if *typed {
+			res, err = tosql.GenerateBulkInsertForEntityWithTypedValues(ctx, p)
+		} else {
+			res, err = tosql.GenerateBulkInsertForEntity(ctx, p)
+		}
+	default:
+		return fmt.Errorf("unknown statement %q: want insert, update, delete, select or bulk-insert", *statement)
+	}
+	if err != nil {
+		return err
+	}
+	return writeJSON(stdout, "", res)
+}
@@ -3,6 +3,7 @@ package db
 type DBType string
 
 const (
-	MYSQLDBType DBType = "mysql"
-	PGDBType    DBType = "postgres"
+	MYSQLDBType  DBType = "mysql"
+	PGDBType     DBType = "postgres"
+	SQLiteDBType DBType = "sqlite"
 )
@@ -0,0 +1,56 @@
+package fromsql
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// introspectedAutoIncrement collects the AUTO_INCREMENT option of each mysql
+// table, by the uuid of the entity introspected from it. nem has no field for
+// it, so it is handed back as tosql.SchemaOptions.AutoIncrement. The tables are
+// introspected concurrently, so it locks.
+type introspectedAutoIncrement struct {
+	mu       sync.Mutex
+	byEntity map[string]int64
+}
+
+func (ia *introspectedAutoIncrement) add(entityUuid string, start int64) {
+	if start <= 0 {
+		return
+	}
+	ia.mu.Lock()
+	defer ia.mu.Unlock()
+
+	if ia.byEntity == nil {
+		ia.byEntity = make(map[string]int64)
+	}
+	ia.byEntity[entityUuid] = start
+}
+
+func (ia *introspectedAutoIncrement) list() map[string]int64 {
+	ia.mu.Lock()
+	defer ia.mu.Unlock()
+
+	return ia.byEntity
+}
+
+var mysqlAutoIncrementOption = regexp.MustCompile(`(?i)\bAUTO_INCREMENT\s*=\s*(\d+)`)
+
+// mysqlAutoIncrementStart reads the AUTO_INCREMENT table option out of a SHOW
+// CREATE TABLE statement, 0 if there is none. It is looked for only after the
+// column list, where the table options are, since a column's own
+// AUTO_INCREMENT carries no value.
+func mysqlAutoIncrementStart(createTable string) int64 {
+	options := createTable[strings.LastIndex(createTable, ")")+1:]
+	match := mysqlAutoIncrementOption.FindStringSubmatch(options)
+	if match == nil {
+		return 0
+	}
+	start, err := strconv.ParseInt(match[1], 10, 64)
+	if err != nil {
+		return 0
+	}
+	return start
+}
@@ -0,0 +1,234 @@
+package fromsql
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/nuzur/sql-gen/tosql"
+)
+
+// A bulk read asks for each kind of detail — columns, indexes, checks and so
+// on — once for every table, and parts the rows by table, rather than asking
+// table by table. That is a handful of round trips rather than several per
+// table, which is what an import over a slow connection spends its time on.
+// The sample rows are still read table by table.
+//
+// If a bulk query fails, the tables are read one by one as they are without
+// it, so a server that chokes on the larger queries still imports. The
+// failure is reported as BulkFailed progress.
+
+// withBulkError is the error of the table by table reads, with the failure of
+// the bulk queries they stood in for, if any.
+func withBulkError(err error, bulkErr error) error {
+	if bulkErr == nil {
+		return err
+	}
+	return fmt.Errorf("%w (after the bulk queries failed: %v)", err, bulkErr)
+}
+
+// mysqlTableFilter restricts a query on the schema to the one table, or to
+// none for a bulk read, which names no table.
+func mysqlTableFilter(column string, tableName string) string {
+	if tableName == "" {
+		return ""
+	}
+	return fmt.Sprintf("AND %s = '%s'", column, tableName)
+}
+
+// pgTableFilter restricts a query to the one table, or for a bulk read, which
+// names no table, to the schemas being introspected.
+func (rt *sqlremote) pgTableFilter(schemaColumn string, nameColumn string, table *pgName) string {
+	if table == nil {
+		return rt.pgSchemaFilter(schemaColumn)
+	}
+	return fmt.Sprintf("%s = '%s' AND %s = '%s'", schemaColumn, table.Schema, nameColumn, table.Name)
+}
+
+type mysqlTableDetails struct {
+	TableName string `db:"TABLE_NAME"`
+	Comment   string `db:"TABLE_COMMENT"`
+	// AutoIncrement is the next value of the table's auto-increment key, NULL
+	// if it has none.
+	AutoIncrement sql.NullInt64 `db:"AUTO_INCREMENT"`
+}
+
+// fetchMysqlTablesInBulk reads the tables, bar their sample rows, by table
+// name.
+func (rt *sqlremote) fetchMysqlTablesInBulk(ctx context.Context, tableNames []string) (map[string]*mysqlTable, error) {
+	tables := make(map[string]*mysqlTable)
+	for _, name := range tableNames {
+		tables[name] = &mysqlTable{Name: name, Checks: []tosql.Check{}}
+	}
+
+	// TABLES has the AUTO_INCREMENT SHOW CREATE TABLE reads it from table by
+	// table. mysql 8 caches it for information_schema_stats_expiry, which
+	// can only make the counter start of the DDL generated from it lag.
+	query := fmt.Sprintf(`
+		SELECT TABLE_NAME,
+			TABLE_COMMENT,
+			AUTO_INCREMENT
+		FROM INFORMATION_SCHEMA.TABLES
+		WHERE TABLE_SCHEMA = '%s'
+			AND TABLE_TYPE = 'BASE TABLE'`,
+		rt.dbSchema)
+	details := []*mysqlTableDetails{}
+	if err := rt.db.SelectContext(ctx, &details, query); err != nil {
+		return nil, fmt.Errorf("error getting tables: %v", err)
+	}
+	for _, d := range details {
+		if t := tables[d.TableName]; t != nil {
+			t.Comment = d.Comment
+			// 1 is where a table with no rows starts, which SHOW CREATE
+			// TABLE does not spell out either
+			if d.AutoIncrement.Valid && d.AutoIncrement.Int64 > 1 {
+				t.AutoIncrement = d.AutoIncrement.Int64
+			}
+		}
+	}
+
+	columns, err := rt.fetchMysqlColumnDetails(ctx, "")
+	if err != nil {
+		return nil, err
+	}
+	for _, c := range columns {
+		if t := tables[c.TableName]; t != nil {
+			t.Columns = append(t.Columns, c)
+		}
+	}
+
+	indexes, err := rt.fetchMysqlIndexDetails(ctx, "")
+	if err != nil {
+		return nil, err
+	}
+	for _, i := range indexes {
+		if t := tables[i.TableName]; t != nil {
+			t.Indexes = append(t.Indexes, i)
+		}
+	}
+
+	expressions, err := rt.fetchMysqlIndexExpressions(ctx, "")
+	if err != nil {
+		return nil, err
+	}
+	for _, e := range expressions {
+		if t := tables[e.TableName]; t != nil {
+			if t.IndexExpressions == nil {
+				t.IndexExpressions = make(map[string][]*mysqlIndexExpressionDetails)
+			}
+			t.IndexExpressions[e.Name] = append(t.IndexExpressions[e.Name], e)
+		}
+	}
+
+	checks, err := rt.fetchMysqlChecks(ctx, "")
+	if err != nil {
+		return nil, err
+	}
+	for _, c := range checks {
+		if t := tables[c.TableName]; t != nil {
+			t.Checks = append(t.Checks, mysqlChecks([]*mysqlCheckDetails{c})...)
+		}
+	}
+
+	foreignKeys, err := rt.fetchMysqlForeignKeys(ctx, "")
+	if err != nil {
+		return nil, err
+	}
+	for _, fk := range foreignKeys {
+		if t := tables[fk.TableName]; t != nil {
+			t.ForeignKeys = append(t.ForeignKeys, fk)
+		}
+	}
+
+	// a table the listing found but the bulk read did not, such as one
+	// created in between, is left to read on its own
+	for name, t := range tables {
+		if len(t.Columns) == 0 {
+			delete(tables, name)
+		}
+	}
+	return tables, nil
+}
+
+type pgTableComment struct {
+	pgName
+	Comment string `db:"comment"`
+}
+
+// fetchPgTablesInBulk reads the tables, bar their sample rows, by name.
+func (rt *sqlremote) fetchPgTablesInBulk(ctx context.Context, names []pgName) (map[pgName]*pgTable, error) {
+	tables := make(map[pgName]*pgTable)
+	for _, name := range names {
+		tables[name] = &pgTable{Name: name, Checks: []tosql.Check{}}
+	}
+	table := func(schema string, name string) *pgTable {
+		return tables[pgName{Schema: schema, Name: name}]
+	}
+
+	query := fmt.Sprintf(`
+		SELECT n.nspname AS schema_name,
+			c.relname AS name,
+			coalesce(obj_description(c.oid, 'pg_class'), '') AS comment
+		FROM pg_class c
+		JOIN pg_namespace n ON n.oid = c.relnamespace
+		WHERE c.relkind IN ('r', 'p')
+			AND %s;`,
+		rt.pgSchemaFilter("n.nspname"))
+	comments := []*pgTableComment{}
+	if err := rt.db.SelectContext(ctx, &comments, query); err != nil {
+		return nil, fmt.Errorf("error getting table comments: %v", err)
+	}
+	for _, c := range comments {
+		if t := tables[c.pgName]; t != nil {
+			t.Comment = c.Comment
+		}
+	}
+
+	columns, err := rt.fetchPgColumnDetails(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	for _, c := range columns {
+		if t := table(c.TableSchema, c.TableName); t != nil {
+			t.Columns = append(t.Columns, c)
+		}
+	}
+
+	indexes, err := rt.fetchPgIndexDetails(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	for _, i := range indexes {
+		if t := table(i.TableSchema, i.TableName); t != nil {
+			t.Indexes = append(t.Indexes, i)
+		}
+	}
+
+	checks, err := rt.fetchPgChecks(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	for _, c := range checks {
+		if t := table(c.TableSchema, c.TableName); t != nil {
+			t.Checks = append(t.Checks, pgChecks([]*pgCheckDetails{c})...)
+		}
+	}
+
+	foreignKeys, err := rt.fetchPgForeignKeys(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	for _, fk := range foreignKeys {
+		if t := table(fk.TableSchema, fk.TableName); t != nil {
+			t.ForeignKeys = append(t.ForeignKeys, fk)
+		}
+	}
+
+	// as for mysql, a table the bulk read missed is read on its own
+	for name, t := range tables {
+		if len(t.Columns) == 0 {
+			delete(tables, name)
+		}
+	}
+	return tables, nil
+}
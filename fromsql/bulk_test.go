@@ -0,0 +1,246 @@
+package fromsql
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"regexp"
+	"strings"
+	"sync"
+	"testing"
+
+	nemgen "github.com/nuzur/nem/idl/gen"
+	"github.com/nuzur/sql-gen/db"
+)
+
+// scriptedDB answers each query with the rows of the first response whose key
+// it contains, once its whitespace is collapsed. Rows carrying a TableName are
+// left out of the answer to a query for another table.
+type scriptedDB struct {
+	responses []scriptedResponse
+	// fail fails the queries containing it
+	fail string
+
+	mu      sync.Mutex
+	queries []string
+}
+
+type scriptedResponse struct {
+	key  string
+	rows interface{}
+}
+
+var scriptedTableFilter = regexp.MustCompile(`(?i)(?:TABLE_NAME|relname) = '([^']+)'`)
+
+func (s *scriptedDB) answer(query string) (reflect.Value, error) {
+	query = strings.Join(strings.Fields(query), " ")
+	s.mu.Lock()
+	s.queries = append(s.queries, query)
+	s.mu.Unlock()
+	if s.fail != "" && strings.Contains(query, s.fail) {
+		return reflect.Value{}, errors.New("query too large")
+	}
+	for _, r := range s.responses {
+		if !strings.Contains(query, r.key) {
+			continue
+		}
+		rows := reflect.ValueOf(r.rows)
+		match := scriptedTableFilter.FindStringSubmatch(query)
+		if match == nil {
+			return rows, nil
+		}
+		filtered := reflect.MakeSlice(rows.Type(), 0, rows.Len())
+		for n := 0; n < rows.Len(); n++ {
+			row := rows.Index(n)
+			if row.Kind() == reflect.Pointer {
+				if table := row.Elem().FieldByName("TableName"); table.IsValid() && table.String() != match[1] {
+					continue
+				}
+			}
+			filtered = reflect.Append(filtered, row)
+		}
+		return filtered, nil
+	}
+	return reflect.Value{}, errors.New("unexpected query: " + query)
+}
+
+func (s *scriptedDB) SelectContext(ctx context.Context, dest interface{}, query string, args ...interface{}) error {
+	rows, err := s.answer(query)
+	if err != nil {
+		return err
+	}
+	reflect.ValueOf(dest).Elem().Set(rows)
+	return nil
+}
+
+func (s *scriptedDB) QueryMapsContext(ctx context.Context, query string, args ...interface{}) ([]map[string]interface{}, error) {
+	rows, err := s.answer(query)
+	if err != nil {
+		return nil, err
+	}
+	return rows.Interface().([]map[string]interface{}), nil
+}
+
+func (s *scriptedDB) Select(dest interface{}, query string, args ...interface{}) error {
+	return s.SelectContext(context.Background(), dest, query, args...)
+}
+
+func (s *scriptedDB) QueryMaps(query string, args ...interface{}) ([]map[string]interface{}, error) {
+	return s.QueryMapsContext(context.Background(), query, args...)
+}
+
+// importScripted imports from the scripted database, in bulk or not,
+// returning the DDL rendered from it and how many queries it took.
+func importScripted(t *testing.T, dbType db.DBType, schema string, responses []scriptedResponse, bulk bool, fail string) (string, int) {
+	t.Helper()
+	scripted := &scriptedDB{responses: responses, fail: fail}
+	pv, options, err := GenerateProjectVersionWithOptions(context.Background(), GenerateRequest{
+		UserConnection: &nemgen.UserConnection{DbSchema: schema},
+		DB:             scripted,
+		DBType:         dbType,
+		Bulk:           bulk,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return renderCreateSQLWithOptions(t, pv, dbType, options), len(scripted.queries)
+}
+
+func TestMysqlBulkIntrospection(t *testing.T) {
+	responses := []scriptedResponse{
+		{"SELECT TABLE_NAME FROM information_schema.TABLES", []string{"lot", "pick"}},
+		{"information_schema.VIEWS", []*viewDetails{}},
+		{"SELECT TABLE_NAME, TABLE_COMMENT, AUTO_INCREMENT", []*mysqlTableDetails{
+			{TableName: "lot", Comment: "stock", AutoIncrement: nullInt64(42)},
+			{TableName: "pick"},
+		}},
+		{"SELECT TABLE_COMMENT FROM", []string{}},
+		{"SHOW CREATE TABLE `shop`.`lot`", []map[string]interface{}{{"Create Table": "CREATE TABLE `lot` (...) ENGINE=InnoDB AUTO_INCREMENT=42"}}},
+		{"SHOW CREATE TABLE", []map[string]interface{}{{"Create Table": "CREATE TABLE `pick` (...) ENGINE=InnoDB"}}},
+		{"LIMIT 10", []map[string]interface{}{}},
+		{"INFORMATION_SCHEMA.columns", []*mysqlColumnDetails{
+			{TableName: "lot", Name: "id", DataType: "int", ColumnType: "int", ColumnKey: "PRI", IsNullable: "NO", NumericPrecision: ptrInt64(10), Extra: "auto_increment"},
+			{TableName: "lot", Name: "qty", DataType: "int", ColumnType: "int", IsNullable: "NO", NumericPrecision: ptrInt64(10)},
+			{TableName: "pick", Name: "id", DataType: "int", ColumnType: "int", ColumnKey: "PRI", IsNullable: "NO", NumericPrecision: ptrInt64(10)},
+			{TableName: "pick", Name: "lot_id", DataType: "int", ColumnType: "int", IsNullable: "NO", NumericPrecision: ptrInt64(10)},
+		}},
+		{"EXPRESSION IS NOT NULL", []*mysqlIndexExpressionDetails{}},
+		{"INFORMATION_SCHEMA.STATISTICS s", []*mysqlIndexDetails{
+			{TableName: "lot", Name: "PRIMARY", Seq: 1, ColumnName: "id", ConstraintType: "PRIMARY KEY", IndexType: "BTREE"},
+			{TableName: "pick", Name: "PRIMARY", Seq: 1, ColumnName: "id", ConstraintType: "PRIMARY KEY", IndexType: "BTREE"},
+		}},
+		{"CHECK_CONSTRAINTS", []*mysqlCheckDetails{{TableName: "lot", Name: "qty_positive", Clause: "(`qty` > 0)"}}},
+		{"REFERENTIAL_CONSTRAINTS", []*mysqlForeignKeyDetails{
+			{TableName: "pick", ConstraintName: "pick_lot", ColumnName: "lot_id", ReferencedTableName: "lot", ReferencedColumnName: "id", DeleteRule: "CASCADE", UpdateRule: "NO ACTION"},
+		}},
+	}
+
+	perTable, perTableQueries := importScripted(t, db.MYSQLDBType, "shop", responses, false, "")
+	bulk, bulkQueries := importScripted(t, db.MYSQLDBType, "shop", responses, true, "")
+	for _, want := range []string{"AUTO_INCREMENT = 42", "CONSTRAINT `qty_positive` CHECK (`qty` > 0)", "CONSTRAINT `pick_lot`"} {
+		if !strings.Contains(bulk, want) {
+			t.Errorf("bulk import is missing %q:\n%s", want, bulk)
+		}
+	}
+	// the per-table comment query answers none, and bulk mode has it
+	if want := strings.Replace(perTable, ") ENGINE = InnoDB AUTO_INCREMENT = 42;", ") ENGINE = InnoDB AUTO_INCREMENT = 42 COMMENT = 'stock';", 1); bulk != want {
+		t.Errorf("bulk import renders\n%s\nwant\n%s", bulk, want)
+	}
+	// listing, views, six bulk queries and a sample per table
+	if bulkQueries != 10 || perTableQueries <= bulkQueries {
+		t.Errorf("bulk import took %d queries, per table %d", bulkQueries, perTableQueries)
+	}
+
+	fallback, _ := importScripted(t, db.MYSQLDBType, "shop", responses, true, "s.TABLE_SCHEMA = 'shop' ORDER BY")
+	if fallback != perTable {
+		t.Errorf("a failed bulk read renders\n%s\nwant\n%s", fallback, perTable)
+	}
+}
+
+// pgBulkResponses answer the queries of a postgres import of two tables, in
+// bulk or not.
+func pgBulkResponses() []scriptedResponse {
+	return []scriptedResponse{
+		{"pg_catalog.pg_tables", []pgName{{Schema: "public", Name: "lot"}, {Schema: "public", Name: "pick"}}},
+		{"pg_enum", []*pgEnumLabel{}},
+		{"pg_catalog.pg_views", []*viewDetails{}},
+		{"obj_description(c.oid", []*pgTableComment{{pgName: pgName{Schema: "public", Name: "lot"}, Comment: "stock"}}},
+		{"SELECT coalesce(obj_description(", []string{}},
+		{"LIMIT 10", []map[string]interface{}{}},
+		{"information_schema.columns", []*pgColumnDetails{
+			{TableSchema: "public", TableName: "lot", Name: "id", DataType: "integer", IsNullable: "NO", NumericPrecision: ptrInt64(32), IsIdentity: "YES"},
+			{TableSchema: "public", TableName: "lot", Name: "qty", DataType: "integer", IsNullable: "NO", NumericPrecision: ptrInt64(32)},
+			{TableSchema: "public", TableName: "pick", Name: "id", DataType: "integer", IsNullable: "NO", NumericPrecision: ptrInt64(32)},
+			{TableSchema: "public", TableName: "pick", Name: "lot", DataType: "integer", IsNullable: "NO", NumericPrecision: ptrInt64(32)},
+		}},
+		{"FROM pg_index i", []*pgIndexDetails{
+			{TableSchema: "public", TableName: "lot", Name: "lot_pkey", Seq: 1, ColumnName: "id", IsKey: true, IsUnique: true, Ascending: true, Method: "btree"},
+			{TableSchema: "public", TableName: "pick", Name: "pick_pkey", Seq: 1, ColumnName: "id", IsKey: true, IsUnique: true, Ascending: true, Method: "btree"},
+		}},
+		{"pg_get_constraintdef", []*pgCheckDetails{{TableSchema: "public", TableName: "lot", Name: "qty_positive", Definition: "CHECK ((qty > 0))"}}},
+		{"information_schema.referential_constraints", []*pgForeignKeyDetails{
+			{TableSchema: "public", TableName: "pick", ConstraintName: "pick_lot_fkey", ColumnName: "lot", ReferencedTableSchema: "public", ReferencedTableName: "lot", ReferencedColumnName: "id", DeleteRule: "CASCADE", UpdateRule: "NO ACTION"},
+		}},
+	}
+}
+
+func TestPgBulkIntrospection(t *testing.T) {
+	responses := pgBulkResponses()
+
+	perTable, perTableQueries := importScripted(t, db.PGDBType, "public", responses, false, "")
+	bulk, bulkQueries := importScripted(t, db.PGDBType, "public", responses, true, "")
+	for _, want := range []string{`CONSTRAINT "qty_positive" CHECK (qty > 0)`, `CONSTRAINT "pick_lot_fkey"`, `COMMENT ON TABLE "lot" IS 'stock';`} {
+		if !strings.Contains(bulk, want) {
+			t.Errorf("bulk import is missing %q:\n%s", want, bulk)
+		}
+	}
+	// the per-table comment query answers none, and bulk mode has it
+	if got := strings.Replace(bulk, "\nCOMMENT ON TABLE \"lot\" IS 'stock';", "", 1); got != perTable {
+		t.Errorf("bulk import renders\n%s\nwant\n%s", bulk, perTable)
+	}
+	// listing, enums, views, five bulk queries and a sample per table
+	if bulkQueries != 10 || perTableQueries <= bulkQueries {
+		t.Errorf("bulk import took %d queries, per table %d", bulkQueries, perTableQueries)
+	}
+
+	fallback, _ := importScripted(t, db.PGDBType, "public", responses, true, "tc.table_schema IN ('public')")
+	if fallback != perTable {
+		t.Errorf("a failed bulk read renders\n%s\nwant\n%s", fallback, perTable)
+	}
+}
+
+// A failed bulk read is reported, and is part of the error should the table
+// by table reads fail too.
+func TestBulkFailureIsReported(t *testing.T) {
+	imported := func(fail string) ([]Progress, error) {
+		failed := []Progress{}
+		_, err := GenerateProjectVersion(context.Background(), GenerateRequest{
+			UserConnection: &nemgen.UserConnection{DbSchema: "public"},
+			DB:             &scriptedDB{responses: pgBulkResponses(), fail: fail},
+			DBType:         db.PGDBType,
+			Bulk:           true,
+			Progress: func(p Progress) {
+				if p.Stage == BulkFailed || p.Stage == TableFailed {
+					failed = append(failed, p)
+				}
+			},
+		})
+		return failed, err
+	}
+
+	failed, err := imported("tc.table_schema IN ('public')")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(failed) != 1 || failed[0].Stage != BulkFailed || failed[0].Err == nil {
+		t.Errorf("failures reported = %+v, want the bulk queries'", failed)
+	}
+
+	failed, err = imported("information_schema.referential_constraints")
+	if err == nil || !strings.Contains(err.Error(), "after the bulk queries failed: error getting constraint details") {
+		t.Errorf("GenerateProjectVersion() error = %v, want the bulk failure in it", err)
+	}
+	if len(failed) != 2 || failed[0].Stage != BulkFailed || failed[1].Stage != TableFailed {
+		t.Errorf("failures reported = %+v, want the bulk queries' and then a table's", failed)
+	}
+}
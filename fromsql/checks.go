@@ -0,0 +1,139 @@
+package fromsql
+
+import (
+	"regexp"
+	"strings"
+	"sync"
+
+	"github.com/nuzur/sql-gen/tosql"
+)
+
+// introspectedChecks collects the CHECK constraints of each table, by the uuid
+// of the entity introspected from it. nem has no field for a check, so they
+// are handed back as tosql.SchemaOptions beside the project version. The
+// tables are introspected concurrently, so it locks.
+type introspectedChecks struct {
+	mu       sync.Mutex
+	byEntity map[string][]tosql.Check
+}
+
+func (ic *introspectedChecks) add(entityUuid string, checks []tosql.Check) {
+	if len(checks) == 0 {
+		return
+	}
+	ic.mu.Lock()
+	defer ic.mu.Unlock()
+
+	if ic.byEntity == nil {
+		ic.byEntity = make(map[string][]tosql.Check)
+	}
+	ic.byEntity[entityUuid] = append(ic.byEntity[entityUuid], checks...)
+}
+
+func (ic *introspectedChecks) list() map[string][]tosql.Check {
+	ic.mu.Lock()
+	defer ic.mu.Unlock()
+
+	return ic.byEntity
+}
+
+// checkExpression reduces a check definition as an engine reports it —
+// CHECK ((qty > 0)) from postgres, (`qty` > 0) from mysql — to the bare
+// condition tosql.Check holds. Without it every round trip would wrap the
+// condition in another pair of parentheses.
+func checkExpression(definition string) string {
+	expression := strings.TrimSpace(definition)
+	// postgres reports a constraint that skipped validating the existing rows
+	// as such; the check itself is the same
+	expression = strings.TrimSpace(strings.TrimSuffix(expression, "NOT VALID"))
+	if len(expression) >= 5 && strings.EqualFold(expression[:5], "CHECK") {
+		expression = strings.TrimSpace(expression[5:])
+	}
+	return unwrapParens(expression)
+}
+
+// unwrapParens takes off the parentheses around the whole of an expression,
+// however many pairs of them there are.
+func unwrapParens(expression string) string {
+	expression = strings.TrimSpace(expression)
+	for len(expression) > 0 && expression[0] == '(' && matchingParen(expression, 0) == len(expression)-1 {
+		expression = strings.TrimSpace(expression[1 : len(expression)-1])
+	}
+	return expression
+}
+
+// matchingParen is the index of the parenthesis closing the one at open, or
+// -1. Parentheses inside string literals and quoted identifiers do not count.
+func matchingParen(s string, open int) int {
+	depth := 0
+	for i := open; i < len(s); i++ {
+		switch c := s[i]; c {
+		case '\'', '"', '`':
+			end := strings.IndexByte(s[i+1:], c)
+			if end < 0 {
+				return -1
+			}
+			// a doubled quote is an escaped one, and is skipped as two
+			// quoted sections in a row
+			i += end + 1
+		case '(':
+			depth++
+		case ')':
+			depth--
+			if depth == 0 {
+				return i
+			}
+		}
+	}
+	return -1
+}
+
+// sqliteCheckName matches the CONSTRAINT <name> a CHECK follows, if any.
+var sqliteCheckName = regexp.MustCompile("(?is)CONSTRAINT\\s+(\"(?:[^\"]|\"\")+\"|`[^`]+`|\\[[^\\]]+\\]|\\w+)\\s*$")
+
+// sqliteChecks reads the CHECK constraints out of a CREATE TABLE statement,
+// the only place sqlite keeps them, whether they were declared on the table or
+// on one of its columns.
+func sqliteChecks(statement string) []tosql.Check {
+	res := []tosql.Check{}
+	for i := 0; i < len(statement); i++ {
+		switch c := statement[i]; {
+		case c == '\'' || c == '"' || c == '`':
+			end := strings.IndexByte(statement[i+1:], c)
+			if end < 0 {
+				return res
+			}
+			i += end + 1
+		case c == '[':
+			end := strings.IndexByte(statement[i+1:], ']')
+			if end < 0 {
+				return res
+			}
+			i += end + 1
+		case (c == 'c' || c == 'C') && strings.EqualFold(statement[i:min(i+5, len(statement))], "CHECK") &&
+			(i == 0 || !isWordByte(statement[i-1])) && (i+5 == len(statement) || !isWordByte(statement[i+5])):
+			open := i + 5
+			for open < len(statement) && strings.ContainsRune(" \t\r\n", rune(statement[open])) {
+				open++
+			}
+			if open == len(statement) || statement[open] != '(' {
+				continue
+			}
+			end := matchingParen(statement, open)
+			if end < 0 {
+				return res
+			}
+			check := tosql.Check{Expression: checkExpression(statement[open : end+1])}
+			if match := sqliteCheckName.FindStringSubmatch(statement[:i]); match != nil {
+				check.Name = sqliteUnquoteIdentifier(match[1])
+			}
+			res = append(res, check)
+			i = end
+		}
+	}
+	return res
+}
+
+func isWordByte(c byte) bool {
+	return c == '_' || c >= '0' && c <= '9' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z'
+}
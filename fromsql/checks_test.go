@@ -0,0 +1,50 @@
+package fromsql
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/nuzur/sql-gen/tosql"
+)
+
+func TestCheckExpression(t *testing.T) {
+	cases := map[string]string{
+		// postgres: pg_get_constraintdef
+		"CHECK ((qty > 0))":                    "qty > 0",
+		"CHECK ((qty > 0)) NOT VALID":          "qty > 0",
+		"CHECK (((qty > 0) AND (qty < 1000)))": "(qty > 0) AND (qty < 1000)",
+		"CHECK ((note <> ')'::text))":          "note <> ')'::text",
+		"CHECK ((length(note) < 20))":          "length(note) < 20",
+		// mysql: CHECK_CLAUSE
+		"(`qty` > 0)":                          "`qty` > 0",
+		"((`qty` > 0) and (`qty` < 1000))":     "(`qty` > 0) and (`qty` < 1000)",
+		"(`a` > 0) or (`b` > 0)":               "(`a` > 0) or (`b` > 0)",
+		"(`note` <> _utf8mb4'it''s (a) test')": "`note` <> _utf8mb4'it''s (a) test'",
+	}
+	for definition, want := range cases {
+		if got := checkExpression(definition); got != want {
+			t.Errorf("checkExpression(%q) = %q, want %q", definition, got, want)
+		}
+	}
+}
+
+func TestSQLiteChecksFromTableSQL(t *testing.T) {
+	statement := `CREATE TABLE "lot" (
+		"id" INTEGER PRIMARY KEY,
+		"qty" INTEGER NOT NULL CHECK (qty > 0),
+		"checked" BOOLEAN,
+		"note" TEXT DEFAULT 'CHECK (x)' CHECK(note <> 'a)b'),
+		CONSTRAINT "qty_small" CHECK ((qty < 1000)),
+		constraint [has note] check (length(note) < 20)
+	)`
+
+	want := []tosql.Check{
+		{Expression: "qty > 0"},
+		{Expression: "note <> 'a)b'"},
+		{Name: "qty_small", Expression: "qty < 1000"},
+		{Name: "has note", Expression: "length(note) < 20"},
+	}
+	if got := sqliteChecks(statement); !reflect.DeepEqual(got, want) {
+		t.Errorf("sqliteChecks() = %+v, want %+v", got, want)
+	}
+}
@@ -0,0 +1,50 @@
+package fromsql
+
+import (
+	"strings"
+	"testing"
+
+	nemgen "github.com/nuzur/nem/idl/gen"
+	"github.com/nuzur/sql-gen/db"
+	"github.com/nuzur/sql-gen/tosql"
+)
+
+// The comments read back become the descriptions, which render as the same
+// comments.
+func TestCommentsAreAFixedPoint(t *testing.T) {
+	entity := func(fields []*nemgen.Field) *nemgen.Entity {
+		return &nemgen.Entity{
+			Uuid:        "lot",
+			Identifier:  "lot",
+			Description: "Stock we can't sell yet",
+			Fields:      fields,
+			Type:        nemgen.EntityType_ENTITY_TYPE_STANDALONE,
+			Status:      nemgen.EntityStatus_ENTITY_STATUS_ACTIVE,
+			TypeConfig:  &nemgen.EntityTypeConfig{Standalone: &nemgen.EntityTypeStandaloneConfig{}},
+		}
+	}
+
+	pg := mapPgColumnDetailsToField(&pgColumnDetails{Name: "qty", DataType: "integer", IsNullable: "NO", IsIdentity: "NO",
+		Comment: "Units"}, remoteRows{}, nil)
+	got := renderCreateSQLWithOptions(t, &nemgen.ProjectVersion{Entities: []*nemgen.Entity{entity([]*nemgen.Field{pg})}}, db.PGDBType, tosql.SchemaOptions{})
+	for _, want := range []string{
+		`COMMENT ON TABLE "lot" IS 'Stock we can''t sell yet';`,
+		`COMMENT ON COLUMN "lot"."qty" IS 'Units';`,
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("rendered DDL is missing %s\n%s", want, got)
+		}
+	}
+
+	mysql := mapMysqlColumnDetailsToField(&mysqlColumnDetails{Name: "qty", DataType: "int", ColumnType: "int", IsNullable: "NO",
+		Comment: "Units"}, remoteRows{})
+	got = renderCreateSQLWithOptions(t, &nemgen.ProjectVersion{Entities: []*nemgen.Entity{entity([]*nemgen.Field{mysql})}}, db.MYSQLDBType, tosql.SchemaOptions{})
+	for _, want := range []string{
+		"`qty` INT NOT NULL COMMENT 'Units'",
+		") ENGINE = InnoDB COMMENT = 'Stock we can\\'t sell yet';",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("rendered DDL is missing %s\n%s", want, got)
+		}
+	}
+}
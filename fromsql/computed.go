@@ -0,0 +1,58 @@
+package fromsql
+
+import (
+	"strings"
+	"sync"
+
+	"github.com/nuzur/sql-gen/tosql"
+)
+
+// introspectedComputed collects the generated columns, by the uuid of the
+// field introspected from each. nem has no field for one, so they are handed
+// back as tosql.SchemaOptions.Computed. The tables are introspected
+// concurrently, so it locks.
+type introspectedComputed struct {
+	mu      sync.Mutex
+	byField map[string]tosql.Computed
+}
+
+func (ic *introspectedComputed) add(fieldUuid string, computed *tosql.Computed) {
+	if computed == nil {
+		return
+	}
+	ic.mu.Lock()
+	defer ic.mu.Unlock()
+
+	if ic.byField == nil {
+		ic.byField = make(map[string]tosql.Computed)
+	}
+	ic.byField[fieldUuid] = *computed
+}
+
+func (ic *introspectedComputed) list() map[string]tosql.Computed {
+	ic.mu.Lock()
+	defer ic.mu.Unlock()
+
+	return ic.byField
+}
+
+// pgComputed is the column's generation, or nil if it is not a generated
+// column. Postgres reports the expression wrapped in parentheses of its own.
+func pgComputed(in *pgColumnDetails) *tosql.Computed {
+	if !strings.EqualFold(in.IsGenerated, "ALWAYS") || in.GenerationExpression == nil {
+		return nil
+	}
+	return &tosql.Computed{Expression: unwrapParens(*in.GenerationExpression), Stored: true}
+}
+
+// mysqlComputed is the column's generation, or nil if it is not a generated
+// column. DEFAULT_GENERATED in EXTRA marks an expression default, not a
+// generated column.
+func mysqlComputed(in *mysqlColumnDetails) *tosql.Computed {
+	extra := strings.ToUpper(in.Extra)
+	stored := strings.Contains(extra, "STORED GENERATED")
+	if !stored && !strings.Contains(extra, "VIRTUAL GENERATED") || in.GenerationExpression == nil || *in.GenerationExpression == "" {
+		return nil
+	}
+	return &tosql.Computed{Expression: unwrapParens(*in.GenerationExpression), Stored: stored}
+}
@@ -0,0 +1,61 @@
+package fromsql
+
+import (
+	"strings"
+	"testing"
+
+	nemgen "github.com/nuzur/nem/idl/gen"
+	"github.com/nuzur/sql-gen/db"
+	"github.com/nuzur/sql-gen/tosql"
+)
+
+func TestGeneratedColumnsAreComputed(t *testing.T) {
+	cases := []struct {
+		name string
+		got  *tosql.Computed
+		want *tosql.Computed
+	}{
+		{"pg", pgComputed(&pgColumnDetails{IsGenerated: "ALWAYS", GenerationExpression: ptrString("(qty * 2)")}),
+			&tosql.Computed{Expression: "qty * 2", Stored: true}},
+		{"pg plain", pgComputed(&pgColumnDetails{IsGenerated: "NEVER"}), nil},
+		{"mysql virtual", mysqlComputed(&mysqlColumnDetails{Extra: "VIRTUAL GENERATED", GenerationExpression: ptrString("(`qty` * 2)")}),
+			&tosql.Computed{Expression: "`qty` * 2"}},
+		{"mysql stored", mysqlComputed(&mysqlColumnDetails{Extra: "STORED GENERATED", GenerationExpression: ptrString("(`qty` * 2)")}),
+			&tosql.Computed{Expression: "`qty` * 2", Stored: true}},
+		// an expression default is not a generated column
+		{"mysql default", mysqlComputed(&mysqlColumnDetails{Extra: "DEFAULT_GENERATED", GenerationExpression: ptrString("")}), nil},
+	}
+	for _, tc := range cases {
+		if (tc.got == nil) != (tc.want == nil) || tc.got != nil && *tc.got != *tc.want {
+			t.Errorf("%s: computed = %+v, want %+v", tc.name, tc.got, tc.want)
+		}
+	}
+}
+
+// A generated column renders as the one it was read from.
+func TestGeneratedColumnsAreAFixedPoint(t *testing.T) {
+	rt := &sqlremote{}
+	fields := []*nemgen.Field{}
+	for _, c := range []*mysqlColumnDetails{
+		{Name: "id", DataType: "int", ColumnType: "int", ColumnKey: "PRI", IsNullable: "NO"},
+		{Name: "qty", DataType: "int", ColumnType: "int", IsNullable: "NO"},
+		{Name: "total", DataType: "int", ColumnType: "int", IsNullable: "YES", Extra: "STORED GENERATED", GenerationExpression: ptrString("(`qty` * 2)")},
+	} {
+		f := mapMysqlColumnDetailsToField(c, remoteRows{})
+		rt.computed.add(f.Uuid, mysqlComputed(c))
+		fields = append(fields, f)
+	}
+	e := &nemgen.Entity{
+		Uuid:       "lot",
+		Identifier: "lot",
+		Fields:     fields,
+		Type:       nemgen.EntityType_ENTITY_TYPE_STANDALONE,
+		Status:     nemgen.EntityStatus_ENTITY_STATUS_ACTIVE,
+		TypeConfig: &nemgen.EntityTypeConfig{Standalone: &nemgen.EntityTypeStandaloneConfig{}},
+	}
+	got := renderCreateSQLWithOptions(t, &nemgen.ProjectVersion{Entities: []*nemgen.Entity{e}}, db.MYSQLDBType,
+		tosql.SchemaOptions{Computed: rt.computed.list()})
+	if want := "`total` INT GENERATED ALWAYS AS (`qty` * 2) STORED,"; !strings.Contains(got, want) {
+		t.Errorf("rendered DDL is missing %s\n%s", want, got)
+	}
+}
@@ -0,0 +1,472 @@
+package fromsql
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	nemgen "github.com/nuzur/nem/idl/gen"
+	"github.com/nuzur/sql-gen/db"
+)
+
+// buildProjectVersionFromDDL reads the schema out of the request's DDL rather
+// than a live database: the CREATE TABLE, CREATE INDEX and ALTER TABLE ADD
+// CONSTRAINT statements of a mysqldump --no-data or pg_dump -s, mapped through
+// the same column details and type mapping introspection uses. Everything else
+// in the dump is skipped. There are no rows to sample, so no column is promoted
+// to a semantic type such as an email or a url.
+func (rt *sqlremote) buildProjectVersionFromDDL() (*nemgen.ProjectVersion, error) {
+	switch rt.dbType {
+	case db.MYSQLDBType:
+		schema, err := parseMysqlDDL(rt.ddl)
+		if err != nil {
+			return nil, err
+		}
+		rt.views = rt.mapViews(schema.views)
+		return rt.buildProjectVersionFromMysqlTables(schema.tables), nil
+	case db.PGDBType:
+		// what a dump leaves unqualified is in the schema it is restored into
+		if rt.dbSchema == "" {
+			rt.dbSchema = "public"
+		}
+		schema, err := parsePgDDL(rt.ddl, rt.dbSchema)
+		if err != nil {
+			return nil, err
+		}
+		tables := []*pgTable{}
+		for _, t := range schema.tables {
+			if rt.pgSchemaIncluded(t.Name.Schema) {
+				tables = append(tables, t)
+			}
+		}
+		views := []*viewDetails{}
+		for _, v := range schema.views {
+			if rt.pgSchemaIncluded(v.Schema) {
+				views = append(views, v)
+			}
+		}
+		rt.pgEnumLabels = schema.enumLabels
+		rt.views = rt.mapViews(views)
+		return rt.buildProjectVersionFromPgTables(tables)
+	}
+	return nil, fmt.Errorf("reading a schema from DDL is not supported for %s", rt.dbType)
+}
+
+type ddlTokenKind int
+
+const (
+	ddlEOF ddlTokenKind = iota
+	// ddlWord is a keyword or an unquoted identifier
+	ddlWord
+	// ddlIdentifier is a quoted identifier, unquoted
+	ddlIdentifier
+	// ddlString is a string literal, unquoted and unescaped
+	ddlString
+	ddlNumber
+	ddlPunct
+)
+
+type ddlToken struct {
+	kind ddlTokenKind
+	text string
+	// start and end are the token's span in the source, quotes included
+	start int
+	end   int
+}
+
+// ddlTokenize splits a DDL script into statements of tokens. Comments are
+// dropped, except a mysql /*!50100 ... */ version comment, whose contents are
+// statements like any other.
+func ddlTokenize(src string, dbType db.DBType) ([][]ddlToken, error) {
+	statements := [][]ddlToken{}
+	statement := []ddlToken{}
+	inVersionComment := false
+	for i := 0; i < len(src); {
+		c := src[i]
+		switch {
+		case c == ' ' || c == '\t' || c == '\r' || c == '\n':
+			i++
+		case strings.HasPrefix(src[i:], "--"), c == '#' && dbType == db.MYSQLDBType:
+			end := strings.IndexByte(src[i:], '\n')
+			if end < 0 {
+				end = len(src) - i
+			}
+			i += end
+		case strings.HasPrefix(src[i:], "/*!") && dbType == db.MYSQLDBType:
+			i += 3
+			for i < len(src) && src[i] >= '0' && src[i] <= '9' {
+				i++
+			}
+			inVersionComment = true
+		case strings.HasPrefix(src[i:], "*/") && inVersionComment:
+			i += 2
+			inVersionComment = false
+		case strings.HasPrefix(src[i:], "/*"):
+			end := strings.Index(src[i+2:], "*/")
+			if end < 0 {
+				return nil, fmt.Errorf("unterminated comment at offset %d", i)
+			}
+			i += end + 4
+		case c == ';':
+			if len(statement) > 0 {
+				statements = append(statements, statement)
+			}
+			statement = []ddlToken{}
+			i++
+		case c == '\'' || c == '"' && dbType == db.MYSQLDBType:
+			text, end, err := ddlQuoted(src, i, dbType == db.MYSQLDBType)
+			if err != nil {
+				return nil, err
+			}
+			statement = append(statement, ddlToken{kind: ddlString, text: text, start: i, end: end})
+			i = end
+		case (c == 'E' || c == 'e') && dbType == db.PGDBType && strings.HasPrefix(src[i+1:], "'"):
+			// an escape string, where backslashes escape as on mysql
+			text, end, err := ddlQuoted(src, i+1, true)
+			if err != nil {
+				return nil, err
+			}
+			statement = append(statement, ddlToken{kind: ddlString, text: text, start: i, end: end})
+			i = end
+		case c == '`' || c == '"':
+			text, end, err := ddlQuoted(src, i, false)
+			if err != nil {
+				return nil, err
+			}
+			statement = append(statement, ddlToken{kind: ddlIdentifier, text: text, start: i, end: end})
+			i = end
+		case c == '$' && dbType == db.PGDBType:
+			// a dollar-quoted string, which a function body in pg_dump is
+			tag := src[i : i+1+strings.IndexByte(src[i+1:], '$')+1]
+			end := strings.Index(src[i+len(tag):], tag)
+			if len(tag) < 2 || end < 0 {
+				return nil, fmt.Errorf("unterminated dollar-quoted string at offset %d", i)
+			}
+			end += i + 2*len(tag)
+			statement = append(statement, ddlToken{kind: ddlString, text: src[i+len(tag) : end-len(tag)], start: i, end: end})
+			i = end
+		case isWordByte(c) && !(c >= '0' && c <= '9'):
+			end := i
+			for end < len(src) && (isWordByte(src[end]) || src[end] == '$') {
+				end++
+			}
+			statement = append(statement, ddlToken{kind: ddlWord, text: src[i:end], start: i, end: end})
+			i = end
+		case c >= '0' && c <= '9':
+			end := i
+			for end < len(src) && (src[end] >= '0' && src[end] <= '9' || src[end] == '.') {
+				end++
+			}
+			statement = append(statement, ddlToken{kind: ddlNumber, text: src[i:end], start: i, end: end})
+			i = end
+		case strings.HasPrefix(src[i:], "::"):
+			statement = append(statement, ddlToken{kind: ddlPunct, text: "::", start: i, end: i + 2})
+			i += 2
+		default:
+			statement = append(statement, ddlToken{kind: ddlPunct, text: string(c), start: i, end: i + 1})
+			i++
+		}
+	}
+	if len(statement) > 0 {
+		statements = append(statements, statement)
+	}
+	return statements, nil
+}
+
+// ddlQuoted reads the string literal or quoted identifier starting at open,
+// returning it unquoted and the offset past its closing quote. A doubled quote
+// is an escaped one, and so, where backslashes escape, is \'.
+func ddlQuoted(src string, open int, backslashes bool) (string, int, error) {
+	quote := src[open]
+	var sb strings.Builder
+	for i := open + 1; i < len(src); i++ {
+		switch c := src[i]; {
+		case backslashes && c == '\\' && i+1 < len(src):
+			i++
+			switch src[i] {
+			case 'n':
+				sb.WriteByte('\n')
+			case 't':
+				sb.WriteByte('\t')
+			case 'r':
+				sb.WriteByte('\r')
+			case '0':
+				sb.WriteByte(0)
+			default:
+				sb.WriteByte(src[i])
+			}
+		case c == quote && i+1 < len(src) && src[i+1] == quote:
+			sb.WriteByte(quote)
+			i++
+		case c == quote:
+			return sb.String(), i + 1, nil
+		default:
+			sb.WriteByte(c)
+		}
+	}
+	return "", 0, fmt.Errorf("unterminated %c at offset %d", quote, open)
+}
+
+// ddlParser reads one statement, or a parenthesized part of one, token by
+// token.
+type ddlParser struct {
+	src  string
+	toks []ddlToken
+	pos  int
+}
+
+func (p *ddlParser) done() bool {
+	return p.pos >= len(p.toks)
+}
+
+func (p *ddlParser) peek() ddlToken {
+	if p.done() {
+		return ddlToken{kind: ddlEOF}
+	}
+	return p.toks[p.pos]
+}
+
+func (p *ddlParser) next() ddlToken {
+	t := p.peek()
+	if !p.done() {
+		p.pos++
+	}
+	return t
+}
+
+// keyword reports whether the statement goes on with the given keywords, in
+// any case.
+func (p *ddlParser) keyword(words ...string) bool {
+	for n, w := range words {
+		if p.pos+n >= len(p.toks) {
+			return false
+		}
+		t := p.toks[p.pos+n]
+		if t.kind != ddlWord || !strings.EqualFold(t.text, w) {
+			return false
+		}
+	}
+	return true
+}
+
+// accept consumes the given keywords if the statement goes on with them.
+func (p *ddlParser) accept(words ...string) bool {
+	if !p.keyword(words...) {
+		return false
+	}
+	p.pos += len(words)
+	return true
+}
+
+// acceptAny consumes the one of the keywords the statement goes on with,
+// returning it in upper case, or empty if it goes on with none.
+func (p *ddlParser) acceptAny(words ...string) string {
+	for _, w := range words {
+		if p.accept(w) {
+			return strings.ToUpper(w)
+		}
+	}
+	return ""
+}
+
+func (p *ddlParser) isPunct(c string) bool {
+	t := p.peek()
+	return t.kind == ddlPunct && t.text == c
+}
+
+func (p *ddlParser) acceptPunct(c string) bool {
+	if !p.isPunct(c) {
+		return false
+	}
+	p.pos++
+	return true
+}
+
+// name reads an identifier, quoted or not.
+func (p *ddlParser) name() (string, error) {
+	t := p.peek()
+	if t.kind != ddlWord && t.kind != ddlIdentifier {
+		return "", p.errorf("expected a name")
+	}
+	p.pos++
+	return t.text, nil
+}
+
+// qualifiedName reads a name and whatever it is qualified with: schema.table,
+// or schema.table.column.
+func (p *ddlParser) qualifiedName() ([]string, error) {
+	name, err := p.name()
+	if err != nil {
+		return nil, err
+	}
+	parts := []string{name}
+	for p.acceptPunct(".") {
+		if name, err = p.name(); err != nil {
+			return nil, err
+		}
+		parts = append(parts, name)
+	}
+	return parts, nil
+}
+
+// stringLiteral reads a string literal.
+func (p *ddlParser) stringLiteral() (string, error) {
+	t := p.peek()
+	if t.kind != ddlString {
+		return "", p.errorf("expected a string")
+	}
+	p.pos++
+	return t.text, nil
+}
+
+// integer reads a number, which has to be a whole one.
+func (p *ddlParser) integer() (int64, error) {
+	t := p.peek()
+	if t.kind != ddlNumber {
+		return 0, p.errorf("expected a number")
+	}
+	n, err := strconv.ParseInt(t.text, 10, 64)
+	if err != nil {
+		return 0, p.errorf("expected a whole number")
+	}
+	p.pos++
+	return n, nil
+}
+
+// parens reads a parenthesized part of the statement, returning a parser over
+// what is inside the parentheses.
+func (p *ddlParser) parens() (*ddlParser, error) {
+	if !p.isPunct("(") {
+		return nil, p.errorf("expected (")
+	}
+	depth := 0
+	for end := p.pos; end < len(p.toks); end++ {
+		switch t := p.toks[end]; {
+		case t.kind == ddlPunct && t.text == "(":
+			depth++
+		case t.kind == ddlPunct && t.text == ")":
+			depth--
+			if depth == 0 {
+				inner := &ddlParser{src: p.src, toks: p.toks[p.pos+1 : end]}
+				p.pos = end + 1
+				return inner, nil
+			}
+		}
+	}
+	return nil, p.errorf("unbalanced (")
+}
+
+// skip consumes a token, or a whole parenthesized part of the statement.
+func (p *ddlParser) skip() {
+	if p.isPunct("(") {
+		if _, err := p.parens(); err == nil {
+			return
+		}
+	}
+	p.next()
+}
+
+// split divides what is left of the parser at its top-level commas.
+func (p *ddlParser) split() []*ddlParser {
+	res := []*ddlParser{}
+	depth := 0
+	start := p.pos
+	for i := p.pos; i < len(p.toks); i++ {
+		t := p.toks[i]
+		if t.kind != ddlPunct {
+			continue
+		}
+		switch t.text {
+		case "(", "[":
+			depth++
+		case ")", "]":
+			depth--
+		case ",":
+			if depth == 0 {
+				res = append(res, &ddlParser{src: p.src, toks: p.toks[start:i]})
+				start = i + 1
+			}
+		}
+	}
+	if start < len(p.toks) {
+		res = append(res, &ddlParser{src: p.src, toks: p.toks[start:]})
+	}
+	p.pos = len(p.toks)
+	return res
+}
+
+// rawUntil consumes tokens up to the first top-level one that starts any of
+// the keyword sequences, or to the end, and returns their source text as
+// written.
+func (p *ddlParser) rawUntil(stops ...[]string) string {
+	start := p.pos
+	depth := 0
+	for !p.done() {
+		if depth == 0 && p.pos > start {
+			for _, stop := range stops {
+				if p.keyword(stop...) {
+					return p.raw(start, p.pos)
+				}
+			}
+		}
+		switch t := p.peek(); {
+		case t.kind == ddlPunct && (t.text == "(" || t.text == "["):
+			depth++
+		case t.kind == ddlPunct && (t.text == ")" || t.text == "]"):
+			depth--
+		}
+		p.pos++
+	}
+	return p.raw(start, p.pos)
+}
+
+// rest is the source text of whatever is left of the parser.
+func (p *ddlParser) rest() string {
+	start := p.pos
+	p.pos = len(p.toks)
+	return p.raw(start, p.pos)
+}
+
+// raw is the source text of the tokens from start up to end.
+func (p *ddlParser) raw(start int, end int) string {
+	if start >= end || start >= len(p.toks) {
+		return ""
+	}
+	return strings.TrimSpace(p.src[p.toks[start].start:p.toks[end-1].end])
+}
+
+func (p *ddlParser) errorf(format string, args ...any) error {
+	near := "the end of the statement"
+	if !p.done() {
+		t := p.peek()
+		near = fmt.Sprintf("%q", p.src[t.start:min(t.end+20, len(p.src))])
+	}
+	return fmt.Errorf("%s near %s", fmt.Sprintf(format, args...), near)
+}
+
+// referentialAction reads an ON DELETE / ON UPDATE action, spelled as
+// information_schema reports it.
+func (p *ddlParser) referentialAction() string {
+	switch {
+	case p.accept("SET", "NULL"):
+		return "SET NULL"
+	case p.accept("SET", "DEFAULT"):
+		return "SET DEFAULT"
+	case p.accept("NO", "ACTION"):
+		return "NO ACTION"
+	}
+	return p.acceptAny("CASCADE", "RESTRICT")
+}
+
+// ddlStatements tokenizes a script into a parser per statement.
+func ddlStatements(src string, dbType db.DBType) ([]*ddlParser, error) {
+	statements, err := ddlTokenize(src, dbType)
+	if err != nil {
+		return nil, fmt.Errorf("error reading DDL: %v", err)
+	}
+	res := []*ddlParser{}
+	for _, s := range statements {
+		res = append(res, &ddlParser{src: src, toks: s})
+	}
+	return res, nil
+}
@@ -0,0 +1,772 @@
+package fromsql
+
+import (
+	"database/sql"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/nuzur/sql-gen/db"
+	"github.com/nuzur/sql-gen/tosql"
+)
+
+// mysqlDDL is what a mysql DDL script declares.
+type mysqlDDL struct {
+	tables []*mysqlTable
+	views  []*viewDetails
+}
+
+// mysqlTypeAliases are the type names mysql accepts for another type, which
+// information_schema then reports the other type as.
+var mysqlTypeAliases = map[string]string{
+	"integer": "int",
+	"int4":    "int",
+	"int8":    "bigint",
+	"dec":     "decimal",
+	"numeric": "decimal",
+	"fixed":   "decimal",
+	"real":    "double",
+	"float8":  "double",
+}
+
+// parseMysqlDDL reads the tables and views a mysql DDL script creates, as
+// introspection would find them once it ran.
+func parseMysqlDDL(src string) (*mysqlDDL, error) {
+	statements, err := ddlStatements(src, db.MYSQLDBType)
+	if err != nil {
+		return nil, err
+	}
+	res := &mysqlDDL{}
+	byName := make(map[string]*mysqlTable)
+	for _, p := range statements {
+		var err error
+		switch {
+		case p.accept("CREATE"):
+			p.accept("OR", "REPLACE")
+			p.accept("TEMPORARY")
+			switch {
+			case p.accept("TABLE"):
+				var t *mysqlTable
+				if t, err = parseMysqlCreateTable(p); err == nil && t != nil {
+					res.tables = append(res.tables, t)
+					byName[t.Name] = t
+				}
+			case p.keyword("INDEX"), p.keyword("UNIQUE"), p.keyword("FULLTEXT"), p.keyword("SPATIAL"):
+				err = parseMysqlCreateIndex(p, byName)
+			default:
+				// CREATE ALGORITHM=... DEFINER=... SQL SECURITY ... VIEW
+				for !p.done() && !p.keyword("VIEW") && !p.keyword("TABLE") && !p.keyword("PROCEDURE") {
+					p.next()
+				}
+				if p.accept("VIEW") {
+					var v *viewDetails
+					if v, err = parseMysqlCreateView(p); err == nil {
+						res.views = replaceView(res.views, v)
+					}
+				}
+			}
+		case p.accept("ALTER", "TABLE"):
+			err = parseMysqlAlterTable(p, byName)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("error reading DDL: %v", err)
+		}
+	}
+	for _, t := range res.tables {
+		mysqlColumnKeys(t)
+	}
+	return res, nil
+}
+
+func parseMysqlCreateTable(p *ddlParser) (*mysqlTable, error) {
+	p.accept("IF", "NOT", "EXISTS")
+	name, err := p.qualifiedName()
+	if err != nil {
+		return nil, err
+	}
+	// CREATE TABLE ... LIKE and ... AS SELECT have no columns to read
+	if !p.isPunct("(") {
+		return nil, nil
+	}
+	t := &mysqlTable{Name: name[len(name)-1], IndexExpressions: make(map[string][]*mysqlIndexExpressionDetails)}
+	defs, err := p.parens()
+	if err != nil {
+		return nil, err
+	}
+	for _, def := range defs.split() {
+		if err := parseMysqlDefinition(def, t); err != nil {
+			return nil, fmt.Errorf("table %s: %v", t.Name, err)
+		}
+	}
+	parseMysqlTableOptions(p, t)
+	return t, nil
+}
+
+// parseMysqlTableOptions reads the AUTO_INCREMENT and COMMENT table options,
+// skipping any other.
+func parseMysqlTableOptions(p *ddlParser, t *mysqlTable) {
+	for !p.done() {
+		switch {
+		case p.accept("AUTO_INCREMENT"):
+			p.acceptPunct("=")
+			if n, err := p.integer(); err == nil {
+				t.AutoIncrement = n
+			}
+		case p.accept("COMMENT"):
+			p.acceptPunct("=")
+			if comment, err := p.stringLiteral(); err == nil {
+				t.Comment = comment
+			}
+		default:
+			p.skip()
+		}
+	}
+}
+
+// parseMysqlDefinition reads one of the definitions of a CREATE TABLE, or what
+// an ALTER TABLE ADDs: a column, an index or a constraint.
+func parseMysqlDefinition(p *ddlParser, t *mysqlTable) error {
+	constraint := ""
+	if p.accept("CONSTRAINT") {
+		if !p.keyword("PRIMARY") && !p.keyword("UNIQUE") && !p.keyword("FOREIGN") && !p.keyword("CHECK") {
+			name, err := p.name()
+			if err != nil {
+				return err
+			}
+			constraint = name
+		}
+	}
+	switch {
+	case p.accept("PRIMARY", "KEY"):
+		optionalIndexName(p)
+		return parseMysqlIndex(p, t, "PRIMARY", "PRIMARY KEY", "BTREE")
+	case p.accept("UNIQUE"):
+		p.acceptAny("KEY", "INDEX")
+		if name := optionalIndexName(p); name != "" {
+			constraint = name
+		}
+		return parseMysqlIndex(p, t, constraint, "UNIQUE", "BTREE")
+	case p.keyword("FULLTEXT"), p.keyword("SPATIAL"):
+		kind := p.acceptAny("FULLTEXT", "SPATIAL")
+		p.acceptAny("KEY", "INDEX")
+		return parseMysqlIndex(p, t, optionalIndexName(p), "INDEX", kind)
+	case p.acceptAny("KEY", "INDEX") != "":
+		return parseMysqlIndex(p, t, optionalIndexName(p), "INDEX", "BTREE")
+	case p.accept("FOREIGN", "KEY"):
+		return parseMysqlForeignKey(p, t, constraint)
+	case p.accept("CHECK"):
+		check, err := parseCheck(p, constraint)
+		if err != nil {
+			return err
+		}
+		t.Checks = append(t.Checks, check)
+		return nil
+	}
+	if constraint != "" {
+		return p.errorf("expected a constraint")
+	}
+	column, err := parseMysqlColumn(p, t)
+	if err != nil {
+		return err
+	}
+	t.Columns = append(t.Columns, column)
+	return nil
+}
+
+// parseCheck reads the condition of a CHECK. Whether it is enforced does not
+// change what it checks.
+func parseCheck(p *ddlParser, name string) (tosql.Check, error) {
+	start := p.pos
+	if _, err := p.parens(); err != nil {
+		return tosql.Check{}, err
+	}
+	return tosql.Check{Name: name, Expression: checkExpression(p.raw(start, p.pos))}, nil
+}
+
+// optionalIndexName reads the name an index is declared with, if it has one.
+func optionalIndexName(p *ddlParser) string {
+	if p.isPunct("(") || p.keyword("USING") {
+		return ""
+	}
+	name, _ := p.name()
+	return name
+}
+
+// parseMysqlIndex reads an index's parts and USING. An index declared without
+// a name takes its first column's, as mysql names them.
+func parseMysqlIndex(p *ddlParser, t *mysqlTable, name string, constraintType string, indexType string) error {
+	if p.accept("USING") {
+		indexType = strings.ToUpper(p.next().text)
+	}
+	parts, err := p.parens()
+	if err != nil {
+		return err
+	}
+	if p.accept("USING") {
+		indexType = strings.ToUpper(p.next().text)
+	}
+
+	details := []*mysqlIndexDetails{}
+	expressions := []*mysqlIndexExpressionDetails{}
+	for n, part := range parts.split() {
+		d := &mysqlIndexDetails{
+			Seq:            int64(n + 1),
+			NonUnique:      constraintType == "INDEX",
+			ConstraintType: constraintType,
+			IndexType:      indexType,
+			Collation:      sql.NullString{String: "A", Valid: indexType != "FULLTEXT"},
+		}
+		expression := ""
+		if part.isPunct("(") {
+			start := part.pos
+			if _, err := part.parens(); err != nil {
+				return err
+			}
+			expression = unwrapParens(part.raw(start, part.pos))
+		} else {
+			column, err := part.name()
+			if err != nil {
+				return err
+			}
+			d.ColumnName = column
+			if part.isPunct("(") {
+				length, err := part.parens()
+				if err != nil {
+					return err
+				}
+				n, err := length.integer()
+				if err != nil {
+					return err
+				}
+				d.SubPart = sql.NullInt64{Int64: n, Valid: true}
+			}
+		}
+		if part.accept("DESC") {
+			d.Collation = sql.NullString{String: "D", Valid: true}
+		}
+		if expression != "" {
+			expressions = append(expressions, &mysqlIndexExpressionDetails{
+				Seq:        d.Seq,
+				Expression: expression,
+				Collation:  d.Collation,
+			})
+		}
+		details = append(details, d)
+	}
+	if name == "" {
+		name = mysqlIndexName(t, details)
+	}
+	for _, d := range details {
+		d.Name = name
+	}
+	for _, e := range expressions {
+		e.Name = name
+	}
+	t.Indexes = append(t.Indexes, details...)
+	if len(expressions) > 0 {
+		t.IndexExpressions[name] = expressions
+	}
+	return nil
+}
+
+// mysqlIndexName is the name mysql gives an index declared without one: its
+// first column's, suffixed _2, _3, ... if an index already has it.
+func mysqlIndexName(t *mysqlTable, details []*mysqlIndexDetails) string {
+	base := "functional_index"
+	if len(details) > 0 && details[0].ColumnName != "" {
+		base = details[0].ColumnName
+	}
+	taken := make(map[string]bool)
+	for _, d := range t.Indexes {
+		taken[d.Name] = true
+	}
+	name := base
+	for n := 2; taken[name]; n++ {
+		name = fmt.Sprintf("%s_%d", base, n)
+	}
+	return name
+}
+
+// parseMysqlForeignKey reads a FOREIGN KEY, one detail per column as
+// KEY_COLUMN_USAGE reports them. A foreign key declared without a name is
+// named as mysql names it, <table>_ibfk_<n>.
+func parseMysqlForeignKey(p *ddlParser, t *mysqlTable, name string) error {
+	// the index name is only the supporting index's, not the constraint's
+	optionalIndexName(p)
+	columns, err := parseNameList(p)
+	if err != nil {
+		return err
+	}
+	if !p.accept("REFERENCES") {
+		return p.errorf("expected REFERENCES")
+	}
+	table, err := p.qualifiedName()
+	if err != nil {
+		return err
+	}
+	referenced, err := parseNameList(p)
+	if err != nil {
+		return err
+	}
+	if len(referenced) != len(columns) {
+		return p.errorf("foreign key has %d columns but references %d", len(columns), len(referenced))
+	}
+	deleteRule, updateRule := "NO ACTION", "NO ACTION"
+	for !p.done() {
+		switch {
+		case p.accept("ON", "DELETE"):
+			deleteRule = p.referentialAction()
+		case p.accept("ON", "UPDATE"):
+			updateRule = p.referentialAction()
+		default:
+			p.skip()
+		}
+	}
+	if name == "" {
+		n := 1
+		for _, fk := range t.ForeignKeys {
+			if strings.HasPrefix(fk.ConstraintName, t.Name+"_ibfk_") {
+				n++
+			}
+		}
+		name = fmt.Sprintf("%s_ibfk_%d", t.Name, n)
+	}
+	for n, column := range columns {
+		t.ForeignKeys = append(t.ForeignKeys, &mysqlForeignKeyDetails{
+			ConstraintName:       name,
+			ColumnName:           column,
+			ReferencedColumnName: referenced[n],
+			ReferencedTableName:  table[len(table)-1],
+			DeleteRule:           deleteRule,
+			UpdateRule:           updateRule,
+		})
+	}
+	return nil
+}
+
+// parseNameList reads a parenthesized list of names.
+func parseNameList(p *ddlParser) ([]string, error) {
+	list, err := p.parens()
+	if err != nil {
+		return nil, err
+	}
+	names := []string{}
+	for _, item := range list.split() {
+		name, err := item.name()
+		if err != nil {
+			return nil, err
+		}
+		names = append(names, name)
+	}
+	return names, nil
+}
+
+// parseMysqlColumn reads a column definition into the details
+// INFORMATION_SCHEMA.COLUMNS reports for it. A PRIMARY KEY or UNIQUE declared
+// on the column itself becomes an index of the table.
+func parseMysqlColumn(p *ddlParser, t *mysqlTable) (*mysqlColumnDetails, error) {
+	name, err := p.name()
+	if err != nil {
+		return nil, err
+	}
+	c := &mysqlColumnDetails{Name: name, IsNullable: "YES"}
+	if err := parseMysqlColumnType(p, c); err != nil {
+		return nil, fmt.Errorf("column %s: %v", name, err)
+	}
+
+	extra := []string{}
+	for !p.done() {
+		switch {
+		case p.accept("NOT", "NULL"):
+			c.IsNullable = "NO"
+		case p.accept("NULL"):
+			c.IsNullable = "YES"
+		case p.accept("DEFAULT"):
+			c.DefaultValue = nil
+			value, expression, err := parseMysqlDefault(p)
+			if err != nil {
+				return nil, fmt.Errorf("column %s: %v", name, err)
+			}
+			if value != nil {
+				c.DefaultValue = value
+			}
+			if expression {
+				extra = append(extra, "DEFAULT_GENERATED")
+			}
+		case p.accept("AUTO_INCREMENT"):
+			extra = append(extra, "auto_increment")
+		case p.accept("ON", "UPDATE"):
+			// CURRENT_TIMESTAMP, or NOW(), at whatever precision
+			p.next()
+			if p.isPunct("(") {
+				p.skip()
+			}
+			extra = append(extra, "on update CURRENT_TIMESTAMP")
+		case p.accept("COMMENT"):
+			if c.Comment, err = p.stringLiteral(); err != nil {
+				return nil, err
+			}
+		case p.keyword("PRIMARY", "KEY"), p.keyword("KEY"):
+			p.accept("PRIMARY")
+			p.accept("KEY")
+			c.IsNullable = "NO"
+			t.Indexes = append(t.Indexes, &mysqlIndexDetails{
+				Name:           "PRIMARY",
+				Seq:            1,
+				ColumnName:     name,
+				ConstraintType: "PRIMARY KEY",
+				IndexType:      "BTREE",
+				Collation:      sql.NullString{String: "A", Valid: true},
+			})
+		case p.accept("UNIQUE"):
+			p.accept("KEY")
+			d := &mysqlIndexDetails{
+				Seq:            1,
+				ColumnName:     name,
+				ConstraintType: "UNIQUE",
+				IndexType:      "BTREE",
+				Collation:      sql.NullString{String: "A", Valid: true},
+			}
+			d.Name = mysqlIndexName(t, []*mysqlIndexDetails{d})
+			t.Indexes = append(t.Indexes, d)
+		case p.keyword("GENERATED"), p.keyword("AS"):
+			p.accept("GENERATED", "ALWAYS")
+			p.accept("AS")
+			start := p.pos
+			if _, err := p.parens(); err != nil {
+				return nil, err
+			}
+			expression := p.raw(start, p.pos)
+			c.GenerationExpression = &expression
+			if p.accept("STORED") {
+				extra = append(extra, "STORED GENERATED")
+			} else {
+				p.accept("VIRTUAL")
+				extra = append(extra, "VIRTUAL GENERATED")
+			}
+		case p.keyword("CONSTRAINT"), p.keyword("CHECK"):
+			constraint := ""
+			if p.accept("CONSTRAINT") && !p.keyword("CHECK") {
+				constraint = p.next().text
+			}
+			if !p.accept("CHECK") {
+				return nil, p.errorf("expected CHECK")
+			}
+			check, err := parseCheck(p, constraint)
+			if err != nil {
+				return nil, err
+			}
+			t.Checks = append(t.Checks, check)
+		case p.acceptAny("COLLATE", "CHARSET", "COLUMN_FORMAT", "STORAGE", "SRID") != "":
+			p.acceptPunct("=")
+			p.next()
+		case p.accept("CHARACTER", "SET"):
+			p.next()
+		case p.accept("REFERENCES"):
+			// mysql parses a REFERENCES on the column and ignores it
+			p.rest()
+		default:
+			p.skip()
+		}
+	}
+	if len(extra) > 0 {
+		c.Extra = strings.Join(extra, " ")
+	}
+	return c, nil
+}
+
+// parseMysqlColumnType reads a column's type into DATA_TYPE, COLUMN_TYPE and
+// the length, precision and scale columns.
+func parseMysqlColumnType(p *ddlParser, c *mysqlColumnDetails) error {
+	t := p.next()
+	if t.kind != ddlWord {
+		return p.errorf("expected a type")
+	}
+	dataType := strings.ToLower(t.text)
+	if alias, ok := mysqlTypeAliases[dataType]; ok {
+		dataType = alias
+	}
+	if dataType == "double" {
+		p.accept("PRECISION")
+	}
+	if dataType == "bool" || dataType == "boolean" {
+		c.DataType, c.ColumnType = "tinyint", "tinyint(1)"
+		return nil
+	}
+	if (dataType == "character" || dataType == "char") && p.accept("VARYING") {
+		dataType = "varchar"
+	}
+	if dataType == "character" {
+		dataType = "char"
+	}
+	c.DataType = dataType
+	c.ColumnType = dataType
+
+	args := []int64{}
+	if p.isPunct("(") {
+		list, err := p.parens()
+		if err != nil {
+			return err
+		}
+		if dataType == "enum" || dataType == "set" {
+			// spelled the way information_schema reports it, whichever
+			// way the dump escapes its quotes
+			labels := []string{}
+			for _, item := range list.split() {
+				label, err := item.stringLiteral()
+				if err != nil {
+					return err
+				}
+				labels = append(labels, "'"+strings.ReplaceAll(label, "'", "''")+"'")
+			}
+			c.ColumnType = dataType + "(" + strings.Join(labels, ",") + ")"
+		} else {
+			for _, item := range list.split() {
+				n, err := item.integer()
+				if err != nil {
+					return err
+				}
+				args = append(args, n)
+			}
+			c.ColumnType = dataType + "(" + joinInts(args) + ")"
+		}
+	}
+	for {
+		if modifier := p.acceptAny("UNSIGNED", "ZEROFILL", "SIGNED"); modifier != "" {
+			if modifier != "SIGNED" {
+				c.ColumnType += " " + strings.ToLower(modifier)
+			}
+			continue
+		}
+		break
+	}
+
+	switch dataType {
+	case "char", "binary":
+		length := int64(1)
+		if len(args) > 0 {
+			length = args[0]
+		}
+		c.CharMax = &length
+	case "varchar", "varbinary":
+		if len(args) > 0 {
+			c.CharMax = &args[0]
+		}
+	case "decimal":
+		precision, scale := int64(10), int64(0)
+		if len(args) > 0 {
+			precision = args[0]
+		}
+		if len(args) > 1 {
+			scale = args[1]
+		}
+		c.NumericPrecision, c.NumericScale = &precision, &scale
+	case "datetime", "timestamp", "time":
+		precision := int64(0)
+		if len(args) > 0 {
+			precision = args[0]
+		}
+		c.DatetimePrecision = &precision
+	}
+	return nil
+}
+
+// parseMysqlDefault reads a column's DEFAULT as COLUMN_DEFAULT reports it: a
+// literal unquoted, an expression without its parentheses, and no value at
+// all for DEFAULT NULL. It also reports whether the default is an
+// expression, which EXTRA marks DEFAULT_GENERATED.
+func parseMysqlDefault(p *ddlParser) (*string, bool, error) {
+	t := p.peek()
+	switch {
+	case t.kind == ddlString:
+		p.next()
+		return &t.text, false, nil
+	case p.accept("NULL"):
+		return nil, false, nil
+	case p.accept("TRUE"):
+		value := "1"
+		return &value, false, nil
+	case p.accept("FALSE"):
+		value := "0"
+		return &value, false, nil
+	case p.isPunct("("):
+		start := p.pos
+		if _, err := p.parens(); err != nil {
+			return nil, false, err
+		}
+		value := unwrapParens(p.raw(start, p.pos))
+		return &value, true, nil
+	case p.isPunct("-") || p.isPunct("+") || t.kind == ddlNumber:
+		start := p.pos
+		p.acceptPunct("-")
+		p.acceptPunct("+")
+		p.next()
+		value := p.raw(start, p.pos)
+		return &value, false, nil
+	case t.kind == ddlWord:
+		// CURRENT_TIMESTAMP, NOW() and the like, or a b'0' / x'ff' literal
+		start := p.pos
+		p.next()
+		if p.peek().kind == ddlString && p.peek().start == t.end {
+			p.next()
+			value := p.raw(start, p.pos)
+			return &value, false, nil
+		}
+		if p.isPunct("(") {
+			p.skip()
+		}
+		value := p.raw(start, p.pos)
+		if strings.EqualFold(value, "now()") {
+			value = "CURRENT_TIMESTAMP"
+		}
+		return &value, true, nil
+	}
+	return nil, false, p.errorf("expected a default value")
+}
+
+func parseMysqlCreateIndex(p *ddlParser, byName map[string]*mysqlTable) error {
+	constraintType, indexType := "INDEX", "BTREE"
+	switch p.acceptAny("UNIQUE", "FULLTEXT", "SPATIAL") {
+	case "UNIQUE":
+		constraintType = "UNIQUE"
+	case "FULLTEXT":
+		indexType = "FULLTEXT"
+	case "SPATIAL":
+		indexType = "SPATIAL"
+	}
+	if !p.accept("INDEX") {
+		return p.errorf("expected INDEX")
+	}
+	name, err := p.name()
+	if err != nil {
+		return err
+	}
+	if p.accept("USING") {
+		indexType = strings.ToUpper(p.next().text)
+	}
+	if !p.accept("ON") {
+		return p.errorf("expected ON")
+	}
+	table, err := p.qualifiedName()
+	if err != nil {
+		return err
+	}
+	t, ok := byName[table[len(table)-1]]
+	if !ok {
+		return nil
+	}
+	return parseMysqlIndex(p, t, name, constraintType, indexType)
+}
+
+func parseMysqlAlterTable(p *ddlParser, byName map[string]*mysqlTable) error {
+	table, err := p.qualifiedName()
+	if err != nil {
+		return err
+	}
+	t, ok := byName[table[len(table)-1]]
+	if !ok {
+		return nil
+	}
+	for _, spec := range p.split() {
+		switch {
+		case spec.accept("ADD"):
+			spec.accept("COLUMN")
+			if spec.isPunct("(") {
+				continue
+			}
+			err = parseMysqlDefinition(spec, t)
+		case spec.accept("MODIFY"):
+			spec.accept("COLUMN")
+			err = replaceMysqlColumn(spec, t, "")
+		case spec.accept("CHANGE"):
+			spec.accept("COLUMN")
+			var old string
+			if old, err = spec.name(); err == nil {
+				err = replaceMysqlColumn(spec, t, old)
+			}
+		default:
+			parseMysqlTableOptions(spec, t)
+		}
+		if err != nil {
+			return fmt.Errorf("table %s: %v", t.Name, err)
+		}
+	}
+	return nil
+}
+
+// replaceMysqlColumn reads a column definition over the column of the given
+// name, or of the definition's own name if that is empty.
+func replaceMysqlColumn(p *ddlParser, t *mysqlTable, old string) error {
+	c, err := parseMysqlColumn(p, t)
+	if err != nil {
+		return err
+	}
+	if old == "" {
+		old = c.Name
+	}
+	for n, existing := range t.Columns {
+		if existing.Name == old {
+			t.Columns[n] = c
+			return nil
+		}
+	}
+	return p.errorf("table %s has no column %s", t.Name, old)
+}
+
+func parseMysqlCreateView(p *ddlParser) (*viewDetails, error) {
+	name, err := p.qualifiedName()
+	if err != nil {
+		return nil, err
+	}
+	return &viewDetails{Name: name[len(name)-1], Definition: sqliteViewDefinition(p.rest())}, nil
+}
+
+// replaceView adds a view to the views read so far, in place of any of the
+// same name: mysqldump creates a placeholder for every view before the view
+// itself, so that views can select from each other.
+func replaceView(views []*viewDetails, v *viewDetails) []*viewDetails {
+	for n, existing := range views {
+		if existing.Schema == v.Schema && existing.Name == v.Name {
+			views[n] = v
+			return views
+		}
+	}
+	return append(views, v)
+}
+
+// mysqlColumnKeys fills in each column's COLUMN_KEY from the table's indexes:
+// PRI for a primary key column, UNI for a column unique on its own.
+func mysqlColumnKeys(t *mysqlTable) {
+	byIndex := make(map[string][]*mysqlIndexDetails)
+	for _, d := range t.Indexes {
+		byIndex[d.Name] = append(byIndex[d.Name], d)
+	}
+	for _, c := range t.Columns {
+		for _, index := range byIndex {
+			switch {
+			case index[0].ConstraintType == "PRIMARY KEY" && mysqlIndexHasColumn(index, c.Name):
+				c.ColumnKey = "PRI"
+				c.IsNullable = "NO"
+			case index[0].ConstraintType == "UNIQUE" && len(index) == 1 && index[0].ColumnName == c.Name && c.ColumnKey == "":
+				c.ColumnKey = "UNI"
+			}
+		}
+	}
+}
+
+func mysqlIndexHasColumn(index []*mysqlIndexDetails, column string) bool {
+	for _, d := range index {
+		if d.ColumnName == column {
+			return true
+		}
+	}
+	return false
+}
+
+func joinInts(ns []int64) string {
+	s := []string{}
+	for _, n := range ns {
+		s = append(s, strconv.FormatInt(n, 10))
+	}
+	return strings.Join(s, ",")
+}
@@ -0,0 +1,827 @@
+package fromsql
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/nuzur/sql-gen/db"
+)
+
+// pgDDL is what a postgres DDL script declares.
+type pgDDL struct {
+	tables     []*pgTable
+	views      []*viewDetails
+	enumLabels map[pgName][]string
+}
+
+// pgTypeNames are the built-in type names, by each spelling postgres accepts
+// them in, mapped to the one information_schema.columns reports. The serial
+// types are integers defaulting to a sequence.
+var pgTypeNames = map[string]string{
+	"smallint":                    "smallint",
+	"int2":                        "smallint",
+	"smallserial":                 "smallint",
+	"serial2":                     "smallint",
+	"integer":                     "integer",
+	"int":                         "integer",
+	"int4":                        "integer",
+	"serial":                      "integer",
+	"serial4":                     "integer",
+	"bigint":                      "bigint",
+	"int8":                        "bigint",
+	"bigserial":                   "bigint",
+	"serial8":                     "bigint",
+	"boolean":                     "boolean",
+	"bool":                        "boolean",
+	"double precision":            "double precision",
+	"float":                       "double precision",
+	"float8":                      "double precision",
+	"real":                        "real",
+	"float4":                      "real",
+	"numeric":                     "numeric",
+	"decimal":                     "numeric",
+	"character varying":           "character varying",
+	"varchar":                     "character varying",
+	"character":                   "character",
+	"char":                        "character",
+	"bpchar":                      "character",
+	"text":                        "text",
+	"uuid":                        "uuid",
+	"bytea":                       "bytea",
+	"json":                        "json",
+	"jsonb":                       "jsonb",
+	"date":                        "date",
+	"timestamp":                   "timestamp without time zone",
+	"timestamp without time zone": "timestamp without time zone",
+	"timestamptz":                 "timestamp with time zone",
+	"timestamp with time zone":    "timestamp with time zone",
+	"time":                        "time without time zone",
+	"time without time zone":      "time without time zone",
+	"timetz":                      "time with time zone",
+	"time with time zone":         "time with time zone",
+}
+
+// pgColumnConstraints are the keywords a column's type ends at.
+var pgColumnConstraints = [][]string{
+	{"NOT", "NULL"}, {"NULL"}, {"CONSTRAINT"}, {"CHECK"}, {"DEFAULT"}, {"GENERATED"},
+	{"UNIQUE"}, {"PRIMARY", "KEY"}, {"REFERENCES"}, {"COLLATE"},
+}
+
+// pgDDLParser collects what a postgres DDL script declares as it reads it.
+// Names left unqualified are in schema.
+type pgDDLParser struct {
+	pgDDL
+	schema  string
+	byName  map[pgName]*pgTable
+	primary map[pgName][]string
+	// unresolved are the foreign keys that reference a primary key, by the
+	// position in it of the column each references
+	unresolved map[*pgForeignKeyDetails]int
+}
+
+// parsePgDDL reads the tables, views and enum types a postgres DDL script
+// creates, as introspection would find them once it ran. Names left
+// unqualified are in the given schema.
+func parsePgDDL(src string, schema string) (*pgDDL, error) {
+	statements, err := ddlStatements(src, db.PGDBType)
+	if err != nil {
+		return nil, err
+	}
+	d := &pgDDLParser{
+		pgDDL:      pgDDL{enumLabels: make(map[pgName][]string)},
+		schema:     schema,
+		byName:     make(map[pgName]*pgTable),
+		primary:    make(map[pgName][]string),
+		unresolved: make(map[*pgForeignKeyDetails]int),
+	}
+	if err := d.statements(statements); err != nil {
+		return nil, fmt.Errorf("error reading DDL: %v", err)
+	}
+	d.resolveReferences()
+	return &d.pgDDL, nil
+}
+
+func (d *pgDDLParser) statements(statements []*ddlParser) error {
+	for _, p := range statements {
+		var err error
+		switch {
+		case p.accept("CREATE"):
+			p.accept("OR", "REPLACE")
+			p.acceptAny("GLOBAL", "LOCAL")
+			p.acceptAny("TEMPORARY", "TEMP", "UNLOGGED")
+			switch {
+			case p.accept("TABLE"):
+				err = d.createTable(p)
+			case p.accept("UNIQUE", "INDEX"):
+				err = d.createIndex(p, true)
+			case p.accept("INDEX"):
+				err = d.createIndex(p, false)
+			case p.accept("TYPE"):
+				err = d.createType(p)
+			case p.accept("VIEW"):
+				err = d.createView(p, false)
+			case p.accept("MATERIALIZED", "VIEW"):
+				err = d.createView(p, true)
+			}
+		case p.accept("ALTER", "TABLE"):
+			err = d.alterTable(p)
+		case p.accept("COMMENT", "ON"):
+			err = d.comment(p)
+		case p.accept("DO"):
+			err = d.do(p)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// do reads the statements of an anonymous code block, which is how tosql
+// creates an enum type only if it does not exist yet.
+func (d *pgDDLParser) do(p *ddlParser) error {
+	p.accept("LANGUAGE")
+	body, err := p.stringLiteral()
+	if err != nil {
+		return err
+	}
+	statements, err := ddlStatements(body, db.PGDBType)
+	if err != nil {
+		return err
+	}
+	for _, s := range statements {
+		s.accept("BEGIN")
+	}
+	return d.statements(statements)
+}
+
+// name reads a table or type name, in the default schema if it is not
+// qualified with one.
+func (d *pgDDLParser) name(p *ddlParser) (pgName, error) {
+	parts, err := p.qualifiedName()
+	if err != nil {
+		return pgName{}, err
+	}
+	if len(parts) == 1 {
+		return pgName{Schema: d.schema, Name: parts[0]}, nil
+	}
+	return pgName{Schema: parts[len(parts)-2], Name: parts[len(parts)-1]}, nil
+}
+
+// table reads the name of a table an ALTER TABLE, CREATE INDEX or COMMENT ON
+// is about, which is nil if the script does not create it.
+func (d *pgDDLParser) table(p *ddlParser) (*pgTable, error) {
+	p.accept("IF", "EXISTS")
+	p.accept("ONLY")
+	name, err := d.name(p)
+	if err != nil {
+		return nil, err
+	}
+	p.acceptPunct("*")
+	return d.byName[name], nil
+}
+
+func (d *pgDDLParser) createTable(p *ddlParser) error {
+	p.accept("IF", "NOT", "EXISTS")
+	name, err := d.name(p)
+	if err != nil {
+		return err
+	}
+	// CREATE TABLE ... AS, ... OF and ... PARTITION OF have no columns to read
+	if !p.isPunct("(") {
+		return nil
+	}
+	t := &pgTable{Name: name}
+	defs, err := p.parens()
+	if err != nil {
+		return err
+	}
+	for _, def := range defs.split() {
+		if def.keyword("LIKE") || def.keyword("EXCLUDE") {
+			continue
+		}
+		if err := d.definition(def, t); err != nil {
+			return fmt.Errorf("table %s: %v", name.Name, err)
+		}
+	}
+	d.tables = append(d.tables, t)
+	d.byName[name] = t
+	return nil
+}
+
+// definition reads one of the definitions of a CREATE TABLE, or what an ALTER
+// TABLE ADDs: a column or a constraint.
+func (d *pgDDLParser) definition(p *ddlParser, t *pgTable) error {
+	constraint := ""
+	if p.accept("CONSTRAINT") {
+		name, err := p.name()
+		if err != nil {
+			return err
+		}
+		constraint = name
+	}
+	switch {
+	case p.accept("PRIMARY", "KEY"):
+		columns, err := parseNameList(p)
+		if err != nil {
+			return err
+		}
+		d.primaryKey(t, constraint, columns)
+		return nil
+	case p.accept("UNIQUE"):
+		p.accept("NULLS", "NOT", "DISTINCT")
+		p.accept("NULLS", "DISTINCT")
+		columns, err := parseNameList(p)
+		if err != nil {
+			return err
+		}
+		d.unique(t, constraint, columns)
+		return nil
+	case p.accept("FOREIGN", "KEY"):
+		columns, err := parseNameList(p)
+		if err != nil {
+			return err
+		}
+		if !p.accept("REFERENCES") {
+			return p.errorf("expected REFERENCES")
+		}
+		return d.references(p, t, constraint, columns)
+	case p.accept("CHECK"):
+		check, err := parseCheck(p, constraint)
+		if err != nil {
+			return err
+		}
+		t.Checks = append(t.Checks, check)
+		return nil
+	}
+	if constraint != "" {
+		return p.errorf("expected a constraint")
+	}
+	return d.column(p, t)
+}
+
+// column reads a column definition into the details
+// information_schema.columns reports for it. A PRIMARY KEY, UNIQUE or
+// REFERENCES on the column itself is a constraint of the table.
+func (d *pgDDLParser) column(p *ddlParser, t *pgTable) error {
+	name, err := p.name()
+	if err != nil {
+		return err
+	}
+	c := &pgColumnDetails{Name: name, IsNullable: "YES", IsIdentity: "NO", IsGenerated: "NEVER"}
+	serial, err := d.columnType(p, c)
+	if err != nil {
+		return fmt.Errorf("column %s: %v", name, err)
+	}
+	if serial {
+		// a serial column is an integer defaulting to a sequence it owns
+		value := fmt.Sprintf("nextval('%s_%s_seq'::regclass)", t.Name.Name, name)
+		c.DefaultValue = &value
+		c.IsNullable = "NO"
+	}
+	t.Columns = append(t.Columns, c)
+
+	for !p.done() {
+		constraint := ""
+		if p.accept("CONSTRAINT") {
+			if constraint, err = p.name(); err != nil {
+				return err
+			}
+		}
+		switch {
+		case p.accept("NOT", "NULL"):
+			c.IsNullable = "NO"
+		case p.accept("NULL"):
+			c.IsNullable = "YES"
+		case p.accept("DEFAULT"):
+			d.columnDefault(c, p.rawUntil(pgColumnConstraints...))
+		case p.accept("GENERATED"):
+			if err := d.generated(p, c); err != nil {
+				return err
+			}
+		case p.accept("PRIMARY", "KEY"):
+			d.primaryKey(t, constraint, []string{name})
+		case p.accept("UNIQUE"):
+			p.accept("NULLS", "NOT", "DISTINCT")
+			p.accept("NULLS", "DISTINCT")
+			d.unique(t, constraint, []string{name})
+		case p.accept("REFERENCES"):
+			if err := d.references(p, t, constraint, []string{name}); err != nil {
+				return err
+			}
+		case p.accept("CHECK"):
+			if constraint == "" {
+				constraint = fmt.Sprintf("%s_%s_check", t.Name.Name, name)
+			}
+			check, err := parseCheck(p, constraint)
+			if err != nil {
+				return err
+			}
+			t.Checks = append(t.Checks, check)
+		case p.accept("COLLATE"):
+			if _, err := p.qualifiedName(); err != nil {
+				return err
+			}
+		default:
+			p.skip()
+		}
+	}
+	return nil
+}
+
+// columnType reads a column's type into data_type, udt_name and the length,
+// precision and scale columns, reporting whether it is a serial type.
+func (d *pgDDLParser) columnType(p *ddlParser, c *pgColumnDetails) (bool, error) {
+	words := []string{}
+	args := []int64{}
+	schema := ""
+	array := false
+	for !p.done() {
+		stop := false
+		for _, constraint := range pgColumnConstraints {
+			if len(words) > 0 && p.keyword(constraint...) {
+				stop = true
+			}
+		}
+		if stop {
+			break
+		}
+		switch t := p.peek(); {
+		case p.isPunct("("):
+			list, err := p.parens()
+			if err != nil {
+				return false, err
+			}
+			for _, item := range list.split() {
+				n, err := item.integer()
+				if err != nil {
+					return false, err
+				}
+				args = append(args, n)
+			}
+		case p.isPunct("["):
+			for !p.done() && !p.acceptPunct("]") {
+				p.next()
+			}
+			array = true
+		case p.isPunct("."):
+			p.next()
+			schema = words[len(words)-1]
+			words = words[:len(words)-1]
+		case p.accept("ARRAY"):
+			array = true
+		case t.kind == ddlIdentifier:
+			p.next()
+			words = append(words, t.text)
+		case t.kind == ddlWord:
+			p.next()
+			words = append(words, strings.ToLower(t.text))
+		default:
+			return false, p.errorf("expected a type")
+		}
+	}
+	if len(words) == 0 {
+		return false, p.errorf("expected a type")
+	}
+
+	typeName := strings.Join(words, " ")
+	if schema == "pg_catalog" {
+		schema = ""
+	}
+	serial := schema == "" && strings.Contains(typeName, "serial")
+	dataType, builtin := pgTypeNames[typeName]
+	if !builtin || schema != "" {
+		// a type of the schema's own, which only an enum maps to
+		if schema == "" {
+			schema = d.schema
+		}
+		c.UdtSchema, c.UdtName, c.DataType = schema, typeName, typeName
+		if _, ok := d.enumLabels[pgName{Schema: schema, Name: typeName}]; ok {
+			c.DataType = "USER-DEFINED"
+		}
+	} else {
+		c.UdtSchema, c.UdtName, c.DataType = "pg_catalog", typeName, dataType
+	}
+	if array {
+		c.UdtName, c.DataType = "_"+c.UdtName, "ARRAY"
+		return serial, nil
+	}
+
+	switch dataType {
+	case "character", "character varying":
+		if len(args) > 0 {
+			c.CharMax = &args[0]
+		} else if dataType == "character" {
+			length := int64(1)
+			c.CharMax = &length
+		}
+	case "numeric":
+		if len(args) > 0 {
+			scale := int64(0)
+			if len(args) > 1 {
+				scale = args[1]
+			}
+			c.NumericPrecision, c.NumericScale = &args[0], &scale
+		}
+	case "timestamp without time zone", "timestamp with time zone", "time without time zone", "time with time zone":
+		precision := int64(pgDefaultDatetimePrecision)
+		if len(args) > 0 {
+			precision = args[0]
+		}
+		c.DatetimePrecision = &precision
+	}
+	return serial, nil
+}
+
+// columnDefault sets a column's default as column_default reports it, which
+// is NULL for DEFAULT NULL.
+func (d *pgDDLParser) columnDefault(c *pgColumnDetails, value string) {
+	if value == "" || strings.EqualFold(value, "NULL") {
+		c.DefaultValue = nil
+		return
+	}
+	c.DefaultValue = &value
+}
+
+// generated reads what follows GENERATED: a generated column's expression, or
+// an identity.
+func (d *pgDDLParser) generated(p *ddlParser, c *pgColumnDetails) error {
+	generation := "ALWAYS"
+	if p.accept("BY", "DEFAULT") {
+		generation = "BY DEFAULT"
+	} else if !p.accept("ALWAYS") {
+		return p.errorf("expected ALWAYS or BY DEFAULT")
+	}
+	if !p.accept("AS") {
+		return p.errorf("expected AS")
+	}
+	if p.accept("IDENTITY") {
+		c.IsIdentity, c.IdentityGeneration, c.IsNullable = "YES", &generation, "NO"
+		if p.isPunct("(") {
+			p.skip()
+		}
+		return nil
+	}
+	start := p.pos
+	if _, err := p.parens(); err != nil {
+		return err
+	}
+	expression := p.raw(start, p.pos)
+	c.IsGenerated, c.GenerationExpression = "ALWAYS", &expression
+	p.accept("STORED")
+	return nil
+}
+
+// primaryKey adds a PRIMARY KEY's index, named <table>_pkey if the
+// constraint has no name, as postgres names it.
+func (d *pgDDLParser) primaryKey(t *pgTable, name string, columns []string) {
+	if name == "" {
+		name = t.Name.Name + "_pkey"
+	}
+	for n, column := range columns {
+		t.Indexes = append(t.Indexes, &pgIndexDetails{
+			Name:       name,
+			Seq:        int64(n + 1),
+			ColumnName: column,
+			IsKey:      true,
+			IsUnique:   true,
+			Ascending:  true,
+			Method:     "btree",
+		})
+		for _, c := range t.Columns {
+			if c.Name == column {
+				c.IsNullable = "NO"
+			}
+		}
+	}
+	d.primary[t.Name] = columns
+}
+
+// unique adds a UNIQUE constraint's index, named <table>_<columns>_key if the
+// constraint has no name, as postgres names it.
+func (d *pgDDLParser) unique(t *pgTable, name string, columns []string) {
+	if name == "" {
+		name = fmt.Sprintf("%s_%s_key", t.Name.Name, strings.Join(columns, "_"))
+	}
+	for n, column := range columns {
+		t.Indexes = append(t.Indexes, &pgIndexDetails{
+			Name:       name,
+			Seq:        int64(n + 1),
+			ColumnName: column,
+			IsUnique:   true,
+			Ascending:  true,
+			Method:     "btree",
+		})
+	}
+}
+
+// references reads what follows REFERENCES. A foreign key declared without a
+// name is named <table>_<column>_fkey, as postgres names it, and one that
+// names no referenced columns references the primary key, which is resolved
+// once the whole script is read.
+func (d *pgDDLParser) references(p *ddlParser, t *pgTable, name string, columns []string) error {
+	referenced, err := d.name(p)
+	if err != nil {
+		return err
+	}
+	referencedColumns := []string{}
+	if p.isPunct("(") {
+		if referencedColumns, err = parseNameList(p); err != nil {
+			return err
+		}
+		if len(referencedColumns) != len(columns) {
+			return p.errorf("foreign key has %d columns but references %d", len(columns), len(referencedColumns))
+		}
+	}
+	deleteRule, updateRule := "NO ACTION", "NO ACTION"
+actions:
+	for !p.done() {
+		switch {
+		case p.accept("ON", "DELETE"):
+			deleteRule = p.referentialAction()
+		case p.accept("ON", "UPDATE"):
+			updateRule = p.referentialAction()
+		case p.keyword("NOT", "NULL"), p.keyword("NULL"), p.keyword("DEFAULT"), p.keyword("CONSTRAINT"),
+			p.keyword("CHECK"), p.keyword("UNIQUE"), p.keyword("PRIMARY"), p.keyword("GENERATED"):
+			// the column's next constraint
+			break actions
+		default:
+			p.skip()
+		}
+	}
+
+	if name == "" {
+		name = fmt.Sprintf("%s_%s_fkey", t.Name.Name, strings.Join(columns, "_"))
+	}
+	for n, column := range columns {
+		fk := &pgForeignKeyDetails{
+			ConstraintName:        name,
+			ColumnName:            column,
+			ReferencedTableName:   referenced.Name,
+			ReferencedTableSchema: referenced.Schema,
+			DeleteRule:            deleteRule,
+			UpdateRule:            updateRule,
+		}
+		if len(referencedColumns) > 0 {
+			fk.ReferencedColumnName = referencedColumns[n]
+		} else {
+			d.unresolved[fk] = n
+		}
+		t.ForeignKeys = append(t.ForeignKeys, fk)
+	}
+	return nil
+}
+
+// resolveReferences points the foreign keys that name no referenced columns
+// at the primary key of the table they reference.
+func (d *pgDDLParser) resolveReferences() {
+	for fk, n := range d.unresolved {
+		primary := d.primary[pgName{Schema: fk.ReferencedTableSchema, Name: fk.ReferencedTableName}]
+		if n < len(primary) {
+			fk.ReferencedColumnName = primary[n]
+		}
+	}
+}
+
+func (d *pgDDLParser) createIndex(p *ddlParser, unique bool) error {
+	p.accept("CONCURRENTLY")
+	p.accept("IF", "NOT", "EXISTS")
+	name := ""
+	if !p.keyword("ON") {
+		var err error
+		if name, err = p.name(); err != nil {
+			return err
+		}
+	}
+	if !p.accept("ON") {
+		return p.errorf("expected ON")
+	}
+	t, err := d.table(p)
+	if err != nil || t == nil {
+		return err
+	}
+	method := "btree"
+	if p.accept("USING") {
+		method = strings.ToLower(p.next().text)
+	}
+	parts, err := p.parens()
+	if err != nil {
+		return err
+	}
+	predicate := ""
+	for !p.done() {
+		if p.accept("WHERE") {
+			predicate = unwrapParens(p.rest())
+			break
+		}
+		p.skip()
+	}
+
+	details := []*pgIndexDetails{}
+	for n, part := range parts.split() {
+		id := &pgIndexDetails{
+			Seq:       int64(n + 1),
+			IsUnique:  unique,
+			Ascending: true,
+			Predicate: predicate,
+			Method:    method,
+		}
+		start := part.pos
+		if part.isPunct("(") {
+			part.skip()
+			id.Expression = unwrapParens(part.raw(start, part.pos))
+		} else {
+			column, err := part.name()
+			if err != nil {
+				return err
+			}
+			if part.isPunct("(") {
+				// a function call, which is an expression without parentheses
+				part.skip()
+				id.Expression = part.raw(start, part.pos)
+			} else {
+				id.ColumnName = column
+			}
+		}
+		if name == "" {
+			name = pgIndexName(t, id)
+		}
+		for !part.done() {
+			switch {
+			case part.accept("COLLATE"):
+				if _, err := part.qualifiedName(); err != nil {
+					return err
+				}
+			case part.accept("ASC"):
+			case part.accept("DESC"):
+				id.Ascending = false
+			case part.accept("NULLS"):
+				part.next()
+			default:
+				opClass, err := part.qualifiedName()
+				if err != nil {
+					return err
+				}
+				id.OpClass = opClass[len(opClass)-1]
+				if part.isPunct("(") {
+					part.skip()
+				}
+			}
+		}
+		details = append(details, id)
+	}
+	for _, id := range details {
+		id.Name = name
+	}
+	t.Indexes = append(t.Indexes, details...)
+	return nil
+}
+
+// pgIndexName is the name postgres gives an index created without one:
+// <table>_<first column>_idx, or <table>_expr_idx over an expression.
+func pgIndexName(t *pgTable, first *pgIndexDetails) string {
+	column := first.ColumnName
+	if column == "" {
+		column = "expr"
+	}
+	return fmt.Sprintf("%s_%s_idx", t.Name.Name, column)
+}
+
+func (d *pgDDLParser) alterTable(p *ddlParser) error {
+	t, err := d.table(p)
+	if err != nil || t == nil {
+		return err
+	}
+	for _, action := range p.split() {
+		switch {
+		case action.accept("ADD"):
+			action.accept("COLUMN")
+			action.accept("IF", "NOT", "EXISTS")
+			err = d.definition(action, t)
+		case action.accept("ALTER"):
+			action.accept("COLUMN")
+			err = d.alterColumn(action, t)
+		}
+		if err != nil {
+			return fmt.Errorf("table %s: %v", t.Name.Name, err)
+		}
+	}
+	return nil
+}
+
+// alterColumn reads the ALTER COLUMN actions pg_dump writes on their own:
+// the default of a serial column, and an identity.
+func (d *pgDDLParser) alterColumn(p *ddlParser, t *pgTable) error {
+	name, err := p.name()
+	if err != nil {
+		return err
+	}
+	var c *pgColumnDetails
+	for _, column := range t.Columns {
+		if column.Name == name {
+			c = column
+		}
+	}
+	if c == nil {
+		return p.errorf("no column %s", name)
+	}
+	switch {
+	case p.accept("SET", "DEFAULT"):
+		d.columnDefault(c, p.rest())
+	case p.accept("DROP", "DEFAULT"):
+		c.DefaultValue = nil
+	case p.accept("SET", "NOT", "NULL"):
+		c.IsNullable = "NO"
+	case p.accept("DROP", "NOT", "NULL"):
+		c.IsNullable = "YES"
+	case p.accept("ADD", "GENERATED"):
+		return d.generated(p, c)
+	}
+	return nil
+}
+
+func (d *pgDDLParser) comment(p *ddlParser) error {
+	kind := p.acceptAny("TABLE", "COLUMN")
+	if kind == "" {
+		return nil
+	}
+	parts, err := p.qualifiedName()
+	if err != nil {
+		return err
+	}
+	column := ""
+	if kind == "COLUMN" {
+		column, parts = parts[len(parts)-1], parts[:len(parts)-1]
+	}
+	name := pgName{Schema: d.schema, Name: parts[len(parts)-1]}
+	if len(parts) > 1 {
+		name.Schema = parts[len(parts)-2]
+	}
+	t := d.byName[name]
+	if t == nil || !p.accept("IS") {
+		return nil
+	}
+	comment := ""
+	if !p.accept("NULL") {
+		if comment, err = p.stringLiteral(); err != nil {
+			return err
+		}
+	}
+	if kind == "TABLE" {
+		t.Comment = comment
+		return nil
+	}
+	for _, c := range t.Columns {
+		if c.Name == column {
+			c.Comment = comment
+		}
+	}
+	return nil
+}
+
+// createType reads the labels of an enum type. Any other type has no field
+// type to map to.
+func (d *pgDDLParser) createType(p *ddlParser) error {
+	name, err := d.name(p)
+	if err != nil {
+		return err
+	}
+	if !p.accept("AS", "ENUM") {
+		return nil
+	}
+	list, err := p.parens()
+	if err != nil {
+		return err
+	}
+	labels := []string{}
+	for _, item := range list.split() {
+		label, err := item.stringLiteral()
+		if err != nil {
+			return err
+		}
+		labels = append(labels, label)
+	}
+	d.enumLabels[name] = labels
+	return nil
+}
+
+func (d *pgDDLParser) createView(p *ddlParser, materialized bool) error {
+	p.accept("IF", "NOT", "EXISTS")
+	name, err := d.name(p)
+	if err != nil {
+		return err
+	}
+	definition := sqliteViewDefinition(p.rest())
+	for _, suffix := range []string{"WITH NO DATA", "WITH DATA"} {
+		if len(definition) > len(suffix) && strings.EqualFold(definition[len(definition)-len(suffix):], suffix) {
+			definition = strings.TrimSpace(definition[:len(definition)-len(suffix)])
+		}
+	}
+	d.views = replaceView(d.views, &viewDetails{
+		Schema:       name.Schema,
+		Name:         name.Name,
+		Definition:   pgViewDefinition(definition),
+		Materialized: materialized,
+	})
+	return nil
+}
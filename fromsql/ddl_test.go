@@ -0,0 +1,169 @@
+package fromsql
+
+import (
+	"context"
+	"os"
+	"strings"
+	"testing"
+
+	nemgen "github.com/nuzur/nem/idl/gen"
+	"github.com/nuzur/sql-gen/db"
+	"github.com/nuzur/sql-gen/tosql"
+)
+
+func projectVersionFromDDL(t *testing.T, dbType db.DBType, ddl string) (*nemgen.ProjectVersion, tosql.SchemaOptions) {
+	t.Helper()
+	pv, options, err := GenerateProjectVersionWithOptions(context.Background(), GenerateRequest{
+		DBType: dbType,
+		DDL:    ddl,
+	})
+	if err != nil {
+		t.Fatalf("reading DDL: %v", err)
+	}
+	return pv, options
+}
+
+// A dump reads back into the schema it was taken from, and the schema
+// rendered from it reads back into itself.
+func TestDDLDumps(t *testing.T) {
+	tests := []struct {
+		file   string
+		dbType db.DBType
+		want   []string
+		absent []string
+	}{
+		{
+			file:   "testdata/mysqldump.sql",
+			dbType: db.MYSQLDBType,
+			want: []string{
+				"`id` INT NOT NULL AUTO_INCREMENT,",
+				"`email` VARCHAR(320) NOT NULL COMMENT 'where the receipts go',",
+				"`tier` ENUM('free', 'pro', 'it\\'s') NOT NULL DEFAULT 'free',",
+				"`active` TINYINT(1) NOT NULL DEFAULT 1,",
+				"`created_at` DATETIME(3) NOT NULL DEFAULT CURRENT_TIMESTAMP(3),",
+				"`updated_at` DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,",
+				"`token` CHAR(36) NOT NULL DEFAULT (uuid()),",
+				"`display` VARCHAR(512) GENERATED ALWAYS AS (concat(`name`,_utf8mb4' <',`email`,_utf8mb4'>')) VIRTUAL,",
+				"INDEX `lower_name` ((lower(`name`))),",
+				"INDEX `name_prefix` (`name`(20) DESC),",
+				"UNIQUE INDEX `email` (`email`),",
+				"CONSTRAINT `balance_positive` CHECK (`balance` >= 0)",
+				") ENGINE = InnoDB AUTO_INCREMENT = 1042 COMMENT = 'people who buy things';",
+				"FULLTEXT INDEX `note` (`note`),",
+				"CONSTRAINT `purchase_customer`\n        FOREIGN KEY (`customer_id`)\n        REFERENCES `customer` (`id`)\n        ON DELETE CASCADE",
+				"CREATE OR REPLACE VIEW `pro_customer` AS\nselect `customer`.`id` AS `id` from `customer` where (`customer`.`tier` = 'pro');",
+			},
+			// the index backing the foreign key, and the placeholder
+			// mysqldump creates before the view itself
+			absent: []string{"INDEX `purchase_customer`", "SELECT 1 AS"},
+		},
+		{
+			file:   "testdata/pg_dump.sql",
+			dbType: db.PGDBType,
+			want: []string{
+				`CREATE TYPE "tier" AS ENUM ('free', 'pro');`,
+				`"id" INTEGER NOT NULL GENERATED BY DEFAULT AS IDENTITY,`,
+				`"tier" "tier" NOT NULL DEFAULT 'free'`,
+				`UNIQUE ("email")`,
+				`CONSTRAINT "balance_positive" CHECK (balance >= (0)::numeric)`,
+				`CREATE INDEX "customer_lower_name" ON "customer" ((lower(name))) WHERE name IS NOT NULL;`,
+				`COMMENT ON TABLE "customer" IS 'people who buy things';`,
+				`COMMENT ON COLUMN "customer"."email" IS 'where the receipts go';`,
+				"CONSTRAINT \"purchase_customer_id_fkey\"\n        FOREIGN KEY (\"customer_id\")\n        REFERENCES \"customer\" (\"id\")\n        ON DELETE CASCADE",
+				`CREATE INDEX "purchase_note_trgm" ON "purchase" USING gin ("note" gin_trgm_ops);`,
+				"CREATE OR REPLACE VIEW \"pro_customer\" AS\nSELECT id\n   FROM public.customer\n  WHERE (tier = 'pro'::public.tier);",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.file, func(t *testing.T) {
+			ddl, err := os.ReadFile(tt.file)
+			if err != nil {
+				t.Fatal(err)
+			}
+			pv, options := projectVersionFromDDL(t, tt.dbType, string(ddl))
+			got := renderCreateSQLWithOptions(t, pv, tt.dbType, options)
+			for _, want := range tt.want {
+				if !strings.Contains(got, want) {
+					t.Errorf("rendered DDL is missing %q:\n%s", want, got)
+				}
+			}
+			for _, absent := range tt.absent {
+				if strings.Contains(got, absent) {
+					t.Errorf("rendered DDL should not contain %q:\n%s", absent, got)
+				}
+			}
+
+			pv, options = projectVersionFromDDL(t, tt.dbType, got)
+			if again := renderCreateSQLWithOptions(t, pv, tt.dbType, options); again != got {
+				t.Errorf("rendered DDL does not read back into itself:\n%s\nwant:\n%s", again, got)
+			}
+		})
+	}
+}
+
+func TestPgDDLDefaults(t *testing.T) {
+	pv, options := projectVersionFromDDL(t, db.PGDBType, `
+CREATE TABLE lot (id serial PRIMARY KEY, code text NOT NULL UNIQUE);
+CREATE TABLE pick (
+    id bigint NOT NULL,
+    lot integer REFERENCES lot ON DELETE SET NULL,
+    qty numeric(10,3) DEFAULT 0 CHECK (qty >= 0)
+);
+ALTER TABLE ONLY pick ADD CONSTRAINT pick_pkey PRIMARY KEY (id);
+CREATE INDEX ON pick (lot);
+`)
+	got := renderCreateSQLWithOptions(t, pv, db.PGDBType, options)
+	for _, want := range []string{
+		`"id" INTEGER NOT NULL GENERATED BY DEFAULT AS IDENTITY,`,
+		`PRIMARY KEY ("id")`,
+		`UNIQUE ("code")`,
+		"CONSTRAINT \"pick_lot_fkey\"\n        FOREIGN KEY (\"lot\")\n        REFERENCES \"lot\" (\"id\")\n        ON DELETE SET NULL",
+		`CONSTRAINT "pick_qty_check" CHECK (qty >= 0)`,
+		`CREATE INDEX "pick_lot_idx" ON "pick" ("lot");`,
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("rendered DDL is missing %q:\n%s", want, got)
+		}
+	}
+}
+
+// An escape string reads to its end past a \', and is kept as written.
+func TestPgDDLEscapeStrings(t *testing.T) {
+	pv, options := projectVersionFromDDL(t, db.PGDBType, `
+CREATE TABLE note (
+    id integer PRIMARY KEY,
+    body text NOT NULL DEFAULT E'it\'s',
+    tag text NOT NULL DEFAULT e'a\\b'
+);
+`)
+	got := renderCreateSQLWithOptions(t, pv, db.PGDBType, options)
+	for _, want := range []string{
+		`"body" TEXT NOT NULL DEFAULT E'it\'s'`,
+		`"tag" TEXT NOT NULL DEFAULT e'a\\b'`,
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("rendered DDL is missing %q:\n%s", want, got)
+		}
+	}
+}
+
+func TestDDLErrors(t *testing.T) {
+	tests := []struct {
+		dbType db.DBType
+		ddl    string
+	}{
+		{db.MYSQLDBType, "CREATE TABLE t (id INT"},
+		{db.MYSQLDBType, "CREATE TABLE t (id INT, note TEXT DEFAULT 'open);"},
+		{db.PGDBType, "CREATE TABLE t (id integer, shape public.polygon);"},
+		{db.PGDBType, "CREATE TABLE t (note text DEFAULT $$open);"},
+		{db.PGDBType, `CREATE TABLE t (note text DEFAULT E'open\');`},
+		{db.PGDBType, "CREATE TABLE t (id);"},
+	}
+	for _, tt := range tests {
+		_, _, err := GenerateProjectVersionWithOptions(context.Background(), GenerateRequest{DBType: tt.dbType, DDL: tt.ddl})
+		if err == nil {
+			t.Errorf("reading %q should fail", tt.ddl)
+		}
+	}
+}
@@ -38,6 +38,12 @@ func mysqlColumnDefault(raw *string, extra string) columnDefault {
 	return columnDefault{Value: value, IsExpression: isExpression, Present: true}
 }
 
+// mysqlAutoIncrement reports whether the column is AUTO_INCREMENT, which EXTRA
+// is the only place to find.
+func mysqlAutoIncrement(extra string) bool {
+	return strings.Contains(strings.ToUpper(extra), "AUTO_INCREMENT")
+}
+
 // mysqlOnUpdateCurrentTimestamp reports whether the column carries ON UPDATE
 // CURRENT_TIMESTAMP, which EXTRA is the only place to find it.
 func mysqlOnUpdateCurrentTimestamp(extra string) bool {
@@ -113,3 +119,32 @@ func pgDatetimePrecision(precision *int64) int64 {
 	}
 	return *precision
 }
+
+// sqliteColumnDefault reads a sqlite column's default.
+//
+// PRAGMA table_info reports the DEFAULT clause exactly as the CREATE TABLE
+// wrote it: a literal keeps its quotes, and an expression keeps the parentheses
+// sqlite requires around it. tosql adds those parentheses itself (see
+// defaultExpression), so they come off here or a re-render would double them.
+// The bare CURRENT_* keywords are the only expressions sqlite allows without
+// them. An explicit DEFAULT NULL is the same column as no default at all.
+func sqliteColumnDefault(raw *string) columnDefault {
+	if raw == nil {
+		return columnDefault{}
+	}
+	value := strings.TrimSpace(*raw)
+	if value == "" || strings.EqualFold(value, "null") {
+		return columnDefault{}
+	}
+
+	if literal, ok := stripPgQuotedLiteral(value); ok {
+		return columnDefault{Value: literal, Present: true}
+	}
+	if numericLiteral.MatchString(value) || strings.EqualFold(value, "true") || strings.EqualFold(value, "false") {
+		return columnDefault{Value: value, Present: true}
+	}
+	if strings.HasPrefix(value, "(") && strings.HasSuffix(value, ")") {
+		value = strings.TrimSpace(value[1 : len(value)-1])
+	}
+	return columnDefault{Value: value, IsExpression: true, Present: true}
+}
@@ -0,0 +1,121 @@
+package fromsql
+
+import (
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gofrs/uuid"
+	nemgen "github.com/nuzur/nem/idl/gen"
+)
+
+// introspectedEnums collects the enums behind the native enum columns of the
+// database, one per enum type: a named type on postgres, and on mysql, where an
+// ENUM or SET is declared inline, one per column. The tables are introspected
+// concurrently, so it locks.
+type introspectedEnums struct {
+	mu     sync.Mutex
+	byName map[pgName]*nemgen.Enum
+}
+
+// enum returns the enum of the given name, creating it with the given labels
+// the first time it is asked for. A label's numeric value is its 1-based
+// position, which is also what mysql stores for an ENUM. Postgres types of the
+// same name in different schemas are different enums; mysql has no schema.
+func (ie *introspectedEnums) enum(schema string, name string, labels []string) *nemgen.Enum {
+	ie.mu.Lock()
+	defer ie.mu.Unlock()
+
+	key := pgName{Schema: schema, Name: name}
+	if e, ok := ie.byName[key]; ok {
+		return e
+	}
+	if ie.byName == nil {
+		ie.byName = make(map[pgName]*nemgen.Enum)
+	}
+	values := []*nemgen.EnumValue{}
+	for n, l := range labels {
+		values = append(values, &nemgen.EnumValue{
+			Identifier:   l,
+			Display:      l,
+			NumericValue: int64(n + 1),
+		})
+	}
+	e := &nemgen.Enum{
+		Uuid:         uuid.Must(uuid.NewV4()).String(),
+		Version:      time.Now().Unix(),
+		Identifier:   name,
+		StaticValues: values,
+		Status:       nemgen.EnumStatus_ENUM_STATUS_ACTIVE,
+	}
+	ie.byName[key] = e
+	return e
+}
+
+// list is every enum handed out so far, by name, then schema.
+func (ie *introspectedEnums) list() []*nemgen.Enum {
+	ie.mu.Lock()
+	defer ie.mu.Unlock()
+
+	keys := []pgName{}
+	for k := range ie.byName {
+		keys = append(keys, k)
+	}
+	sort.Slice(keys, func(a, b int) bool {
+		if keys[a].Name != keys[b].Name {
+			return keys[a].Name < keys[b].Name
+		}
+		return keys[a].Schema < keys[b].Schema
+	})
+	res := []*nemgen.Enum{}
+	for _, k := range keys {
+		res = append(res, ie.byName[k])
+	}
+	return res
+}
+
+// resolveEnum points an enum field at the enum of the given name, in the given
+// postgres schema.
+func (rt *sqlremote) resolveEnum(f *nemgen.Field, schema string, name string, labels []string) {
+	if f.GetType() != nemgen.FieldType_FIELD_TYPE_ENUM || f.GetTypeConfig().GetEnum() == nil {
+		return
+	}
+	enum := rt.enums.enum(schema, name, labels)
+	f.TypeConfig.Enum.EnumUuid = enum.Uuid
+	rt.placeInSchema(enum.Uuid, schema)
+}
+
+// mysqlEnumLabels reads the labels out of an ENUM or SET COLUMN_TYPE. mysql
+// reports a quote inside a label doubled:
+//
+//	enum('draft','it''s')
+func mysqlEnumLabels(columnType string) []string {
+	open := strings.Index(columnType, "(")
+	end := strings.LastIndex(columnType, ")")
+	if open < 0 || end < open {
+		return nil
+	}
+	body := columnType[open+1 : end]
+
+	labels := []string{}
+	var label strings.Builder
+	quoted := false
+	for i := 0; i < len(body); i++ {
+		c := body[i]
+		switch {
+		case !quoted && c == '\'':
+			quoted = true
+			label.Reset()
+		case quoted && c == '\'' && i+1 < len(body) && body[i+1] == '\'':
+			label.WriteByte('\'')
+			i++
+		case quoted && c == '\'':
+			quoted = false
+			labels = append(labels, label.String())
+		case quoted:
+			label.WriteByte(c)
+		}
+	}
+	return labels
+}
@@ -0,0 +1,133 @@
+package fromsql
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/gofrs/uuid"
+	nemgen "github.com/nuzur/nem/idl/gen"
+	"github.com/nuzur/sql-gen/db"
+	"github.com/nuzur/sql-gen/tosql"
+)
+
+func TestMysqlEnumLabels(t *testing.T) {
+	cases := map[string][]string{
+		"enum('draft','published')": {"draft", "published"},
+		"set('a,b','it''s','')":     {"a,b", "it's", ""},
+		"enum('(x)')":               {"(x)"},
+		"int":                       nil,
+	}
+	for columnType, want := range cases {
+		if got := mysqlEnumLabels(columnType); !reflect.DeepEqual(got, want) {
+			t.Errorf("mysqlEnumLabels(%q) = %q, want %q", columnType, got, want)
+		}
+	}
+}
+
+// introspectedEnumEntity assembles a table the way the introspection would,
+// resolving each enum field against the named enum its labels came from.
+func introspectedEnumEntity(rt *sqlremote, identifier string, fields []*nemgen.Field, enumNames []string, labels [][]string) *nemgen.ProjectVersion {
+	for n, f := range fields {
+		rt.resolveEnum(f, "", enumNames[n], labels[n])
+	}
+	return &nemgen.ProjectVersion{
+		Entities: []*nemgen.Entity{{
+			Uuid:       uuid.Must(uuid.NewV4()).String(),
+			Identifier: identifier,
+			Fields:     fields,
+			Type:       nemgen.EntityType_ENTITY_TYPE_STANDALONE,
+			Status:     nemgen.EntityStatus_ENTITY_STATUS_ACTIVE,
+			TypeConfig: &nemgen.EntityTypeConfig{
+				Standalone: &nemgen.EntityTypeStandaloneConfig{},
+			},
+		}},
+		Enums: rt.enums.list(),
+	}
+}
+
+func TestMysqlEnumColumnsAreAFixedPoint(t *testing.T) {
+	columns := []*mysqlColumnDetails{
+		{Name: "id", DataType: "char", ColumnType: "char(36)", ColumnKey: "PRI", IsNullable: "NO", CharMax: ptrInt64(36)},
+		{Name: "state", DataType: "enum", ColumnType: "enum('draft','published')", IsNullable: "NO", DefaultValue: ptrString("draft")},
+		{Name: "labels", DataType: "set", ColumnType: "set('news','sport')", IsNullable: "YES"},
+	}
+	fields := []*nemgen.Field{}
+	names := []string{}
+	labels := [][]string{}
+	for _, c := range columns {
+		fields = append(fields, mapMysqlColumnDetailsToField(c, nil))
+		names = append(names, "post_"+c.Name)
+		labels = append(labels, mysqlEnumLabels(c.ColumnType))
+	}
+	if fields[1].Type != nemgen.FieldType_FIELD_TYPE_ENUM || fields[1].TypeConfig.Enum.AllowMultiple {
+		t.Errorf("enum column mapped to %v %v, want a single-select enum", fields[1].Type, fields[1].TypeConfig)
+	}
+	if fields[2].Type != nemgen.FieldType_FIELD_TYPE_ENUM || !fields[2].TypeConfig.Enum.AllowMultiple {
+		t.Errorf("set column mapped to %v %v, want a multi-select enum", fields[2].Type, fields[2].TypeConfig)
+	}
+
+	pv := introspectedEnumEntity(&sqlremote{}, "post", fields, names, labels)
+	if len(pv.Enums) != 2 {
+		t.Fatalf("got %d enums, want one per enum column", len(pv.Enums))
+	}
+
+	const want = "CREATE TABLE IF NOT EXISTS `post` (\n" +
+		"    `id` CHAR(36) NOT NULL,\n" +
+		"    `state` ENUM('draft', 'published') NOT NULL DEFAULT 'draft',\n" +
+		"    `labels` SET('news', 'sport'),\n" +
+		"    PRIMARY KEY (`id`)\n" +
+		") ENGINE = InnoDB;\n\n"
+	if got := renderCreateSQLWithOptions(t, pv, db.MYSQLDBType, tosql.SchemaOptions{EnumMode: tosql.EnumModeNative}); got != want {
+		t.Errorf("re-rendered DDL differs from the DDL that created the database\n got:\n%s\nwant:\n%s", got, want)
+	}
+}
+
+// Columns of the same postgres type share one enum, whether they hold one
+// label or an array of them.
+func TestPgEnumColumnsShareTheirType(t *testing.T) {
+	states := []string{"draft", "published"}
+	columns := []*pgColumnDetails{
+		{Name: "id", DataType: "uuid", IsNullable: "NO", UdtName: "uuid"},
+		{Name: "state", DataType: "USER-DEFINED", IsNullable: "NO", UdtName: "post_state", EnumLabels: states, DefaultValue: ptrString("'draft'::post_state")},
+		{Name: "history", DataType: "ARRAY", IsNullable: "YES", UdtName: "_post_state", EnumLabels: states},
+		{Name: "shape", DataType: "USER-DEFINED", IsNullable: "YES", UdtName: "geometry"},
+	}
+	fields := []*nemgen.Field{}
+	names := []string{}
+	labels := [][]string{}
+	for _, c := range columns {
+		fields = append(fields, mapPgColumnDetailsToField(c, nil, nil))
+		names = append(names, pgEnumTypeName(c))
+		labels = append(labels, c.EnumLabels)
+	}
+	if fields[3].Type != nemgen.FieldType_FIELD_TYPE_INVALID {
+		t.Errorf("a user-defined type that is no enum mapped to %v", fields[3].Type)
+	}
+	fields[0].Key = true
+
+	pv := introspectedEnumEntity(&sqlremote{}, "post", fields[:3], names, labels)
+	if len(pv.Enums) != 1 || pv.Enums[0].Identifier != "post_state" {
+		t.Fatalf("got enums %v, want the one post_state", pv.Enums)
+	}
+	if fields[1].TypeConfig.Enum.EnumUuid != fields[2].TypeConfig.Enum.EnumUuid {
+		t.Errorf("the columns of one type resolved to different enums")
+	}
+	if !fields[2].TypeConfig.Enum.AllowMultiple {
+		t.Errorf("an array of an enum type mapped to a single-select")
+	}
+
+	const want = "DO $$ BEGIN\n" +
+		"    CREATE TYPE \"post_state\" AS ENUM ('draft', 'published');\n" +
+		"EXCEPTION\n" +
+		"    WHEN duplicate_object THEN NULL;\n" +
+		"END $$;\n\n" +
+		"CREATE TABLE IF NOT EXISTS \"post\" (\n" +
+		"    \"id\" UUID NOT NULL,\n" +
+		"    \"state\" \"post_state\" NOT NULL DEFAULT 'draft',\n" +
+		"    \"history\" \"post_state\"[],\n" +
+		"    PRIMARY KEY (\"id\")\n" +
+		");\n\n"
+	if got := renderCreateSQLWithOptions(t, pv, db.PGDBType, tosql.SchemaOptions{EnumMode: tosql.EnumModeNative}); got != want {
+		t.Errorf("re-rendered DDL differs from the DDL that created the database\n got:\n%s\nwant:\n%s", got, want)
+	}
+}
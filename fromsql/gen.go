@@ -11,19 +11,78 @@ import (
 )
 
 func GenerateProjectVersion(ctx context.Context, params GenerateRequest) (*nemgen.ProjectVersion, error) {
+	pv, _, err := GenerateProjectVersionWithOptions(ctx, params)
+	return pv, err
+}
+
+// GenerateProjectVersionWithOptions is GenerateProjectVersion, also returning
+// the tosql.SchemaOptions that render the project version back into the
+// schema it was read from: native enums, and the CHECK constraints, views and
+// postgres schemas nem has no field for.
+func GenerateProjectVersionWithOptions(ctx context.Context, params GenerateRequest) (*nemgen.ProjectVersion, tosql.SchemaOptions, error) {
+	res, err := Import(ctx, params)
+	if err != nil {
+		return nil, tosql.SchemaOptions{}, err
+	}
+	return res.ProjectVersion, res.Options, nil
+}
+
+// ImportResult is everything an import reads out of a schema.
+type ImportResult struct {
+	ProjectVersion *nemgen.ProjectVersion
+	// Options render ProjectVersion back into the schema, as
+	// GenerateProjectVersionWithOptions returns them.
+	Options tosql.SchemaOptions
+	// Promotions are the columns the sampled rows typed, by table and
+	// column.
+	Promotions []Promotion
+}
+
+// Import is GenerateProjectVersionWithOptions, also reporting how the sampled
+// rows refined the column types.
+func Import(ctx context.Context, params GenerateRequest) (*ImportResult, error) {
 	rt := New(params)
 
-	if params.DBType == db.MYSQLDBType {
-		return rt.buildProjectVersionFromMysql()
+	var pv *nemgen.ProjectVersion
+	var err error
+	if params.DDL != "" {
+		pv, err = rt.buildProjectVersionFromDDL()
+	} else if params.DBType == db.MYSQLDBType {
+		pv, err = rt.buildProjectVersionFromMysql(ctx)
 	} else if params.DBType == db.PGDBType {
-		return rt.buildProjectVersionFromPg()
+		pv, err = rt.buildProjectVersionFromPg(ctx)
+	} else if params.DBType == db.SQLiteDBType {
+		pv, err = rt.buildProjectVersionFromSQLite(ctx)
+	} else {
+		err = errors.New("unsupported database type")
+	}
+	if err != nil {
+		// a query cut short by cancellation fails with whatever the driver
+		// makes of it; the cancellation is what the caller asked about
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return nil, ctxErr
+		}
+		return nil, err
 	}
 
-	return nil, errors.New("unsupported database type")
+	return &ImportResult{
+		ProjectVersion: pv,
+		Options: tosql.SchemaOptions{
+			// the enums introspection finds are native enum columns
+			EnumMode:      tosql.EnumModeNative,
+			Checks:        rt.checks.list(),
+			Schemas:       rt.pgSchemas.list(),
+			Views:         rt.views,
+			AutoIncrement: rt.autoIncrement.list(),
+			Computed:      rt.computed.list(),
+			Indexes:       rt.indexes.list(),
+		},
+		Promotions: rt.promotions.list(),
+	}, nil
 }
 
 func GenerateSQL(ctx context.Context, params GenerateRequest) (*tosql.GenerateResponse, error) {
-	pv, err := GenerateProjectVersion(ctx, params)
+	pv, options, err := GenerateProjectVersionWithOptions(ctx, params)
 	if err != nil {
 		return nil, err
 	}
@@ -44,6 +103,9 @@ func GenerateSQL(ctx context.Context, params GenerateRequest) (*tosql.GenerateRe
 			Actions: []tosql.Action{
 				tosql.CreateAction,
 			},
+			SchemaOptions: options,
 		},
+		Sink:          params.Sink,
+		ExecutionsDir: params.ExecutionsDir,
 	})
 }
@@ -0,0 +1,100 @@
+package fromsql
+
+import (
+	"strings"
+	"sync"
+
+	nemgen "github.com/nuzur/nem/idl/gen"
+	"github.com/nuzur/sql-gen/tosql"
+)
+
+// introspectedIndexes collects the partial and expression indexes, and the
+// ones with an access method or operator classes, by the uuid of the index
+// introspected from each. nem has no place for any of them, so they are
+// handed back as
+// tosql.SchemaOptions.Indexes. The tables are introspected concurrently, so
+// it locks.
+type introspectedIndexes struct {
+	mu      sync.Mutex
+	byIndex map[string]tosql.IndexOptions
+}
+
+func (ii *introspectedIndexes) add(indexUuid string, options *tosql.IndexOptions) {
+	if options == nil {
+		return
+	}
+	ii.mu.Lock()
+	defer ii.mu.Unlock()
+
+	if ii.byIndex == nil {
+		ii.byIndex = make(map[string]tosql.IndexOptions)
+	}
+	ii.byIndex[indexUuid] = *options
+}
+
+func (ii *introspectedIndexes) list() map[string]tosql.IndexOptions {
+	ii.mu.Lock()
+	defer ii.mu.Unlock()
+
+	return ii.byIndex
+}
+
+// pgIndexOptions is the predicate, the expression members, the access method
+// and the operator classes of one index's details, or nil for a btree over
+// plain columns and every row.
+func pgIndexOptions(in []*pgIndexDetails, fields []*nemgen.Field) *tosql.IndexOptions {
+	if len(in) == 0 {
+		return nil
+	}
+	options := tosql.IndexOptions{Where: in[0].Predicate}
+	if !strings.EqualFold(in[0].Method, "btree") {
+		options.Method = in[0].Method
+	}
+	for _, id := range in {
+		if id.Expression == "" {
+			if id.OpClass == "" {
+				continue
+			}
+			for _, f := range fields {
+				if f.Identifier != id.ColumnName {
+					continue
+				}
+				if options.OpClasses == nil {
+					options.OpClasses = make(map[string]string)
+				}
+				options.OpClasses[f.Uuid] = id.OpClass
+			}
+			continue
+		}
+		options.Expressions = append(options.Expressions, tosql.IndexExpression{
+			Expression: unwrapParens(id.Expression),
+			Priority:   id.Seq,
+			Descending: !id.Ascending,
+			OpClass:    id.OpClass,
+		})
+	}
+	if options.Where == "" && len(options.Expressions) == 0 && options.Method == "" && len(options.OpClasses) == 0 {
+		return nil
+	}
+	return &options
+}
+
+// mysqlIndexOptions is the functional key parts and the SPATIAL or HASH kind
+// of one index, or nil if it has neither. mysql has no partial indexes.
+func mysqlIndexOptions(in []*mysqlIndexDetails, expressions []*mysqlIndexExpressionDetails) *tosql.IndexOptions {
+	options := tosql.IndexOptions{}
+	if len(in) > 0 && (strings.EqualFold(in[0].IndexType, "SPATIAL") || strings.EqualFold(in[0].IndexType, "HASH")) {
+		options.Method = strings.ToLower(in[0].IndexType)
+	}
+	for _, id := range expressions {
+		options.Expressions = append(options.Expressions, tosql.IndexExpression{
+			Expression: unwrapParens(id.Expression),
+			Priority:   id.Seq,
+			Descending: id.Collation.Valid && id.Collation.String == "D",
+		})
+	}
+	if options.Method == "" && len(options.Expressions) == 0 {
+		return nil
+	}
+	return &options
+}
@@ -0,0 +1,97 @@
+package fromsql
+
+import (
+	"database/sql"
+	"reflect"
+	"strings"
+	"testing"
+
+	nemgen "github.com/nuzur/nem/idl/gen"
+	"github.com/nuzur/sql-gen/db"
+	"github.com/nuzur/sql-gen/tosql"
+)
+
+func TestIndexOptionsFromDetails(t *testing.T) {
+	fields := []*nemgen.Field{{Uuid: "email-uuid", Identifier: "email"}}
+	cases := []struct {
+		name string
+		got  *tosql.IndexOptions
+		want *tosql.IndexOptions
+	}{
+		{"pg plain", pgIndexOptions([]*pgIndexDetails{{Name: "account_email_key", Seq: 1, ColumnName: "email", Ascending: true, Method: "btree"}}, fields), nil},
+		{"pg partial", pgIndexOptions([]*pgIndexDetails{
+			{Name: "account_email_live", Seq: 1, ColumnName: "email", Ascending: true, Predicate: "deleted_at IS NULL", Method: "btree"},
+		}, fields), &tosql.IndexOptions{Where: "deleted_at IS NULL"}},
+		{"pg expression", pgIndexOptions([]*pgIndexDetails{
+			{Name: "account_name_lower", Seq: 1, ColumnName: "deleted_at", Ascending: true, Method: "btree"},
+			{Name: "account_name_lower", Seq: 2, Expression: "lower(name)", Method: "btree"},
+		}, fields), &tosql.IndexOptions{Expressions: []tosql.IndexExpression{{Expression: "lower(name)", Priority: 2, Descending: true}}}},
+		{"pg gin", pgIndexOptions([]*pgIndexDetails{
+			{Name: "account_email_trgm", Seq: 1, ColumnName: "email", Ascending: true, Method: "gin", OpClass: "gin_trgm_ops"},
+			{Name: "account_email_trgm", Seq: 2, Expression: "lower(name)", Ascending: true, Method: "gin", OpClass: "gin_trgm_ops"},
+		}, fields), &tosql.IndexOptions{Method: "gin", OpClasses: map[string]string{"email-uuid": "gin_trgm_ops"},
+			Expressions: []tosql.IndexExpression{{Expression: "lower(name)", Priority: 2, OpClass: "gin_trgm_ops"}}}},
+		{"mysql none", mysqlIndexOptions([]*mysqlIndexDetails{{Name: "account_name", IndexType: "BTREE"}}, nil), nil},
+		{"mysql functional", mysqlIndexOptions([]*mysqlIndexDetails{{Name: "account_name_lower", IndexType: "BTREE"}}, []*mysqlIndexExpressionDetails{
+			{Name: "account_name_lower", Seq: 1, Expression: "lower(`name`)", Collation: sql.NullString{String: "A", Valid: true}},
+		}), &tosql.IndexOptions{Expressions: []tosql.IndexExpression{{Expression: "lower(`name`)", Priority: 1}}}},
+		{"mysql spatial", mysqlIndexOptions([]*mysqlIndexDetails{{Name: "place_location", IndexType: "SPATIAL"}}, nil),
+			&tosql.IndexOptions{Method: "spatial"}},
+	}
+	for _, tc := range cases {
+		if !reflect.DeepEqual(tc.got, tc.want) {
+			t.Errorf("%s: options = %+v, want %+v", tc.name, tc.got, tc.want)
+		}
+	}
+}
+
+// A partial unique index, an expression index and a gin index render as the
+// ones they were read from, and the partial one does not make its column
+// unique.
+func TestPgIndexOptionsAreAFixedPoint(t *testing.T) {
+	rt := &sqlremote{}
+	details := []*pgIndexDetails{
+		{Name: "account_pkey", Seq: 1, ColumnName: "id", IsKey: true, IsUnique: true, Ascending: true, Method: "btree"},
+		{Name: "account_email_live", Seq: 1, ColumnName: "email", IsUnique: true, Ascending: true, Predicate: "deleted_at IS NULL", Method: "btree"},
+		{Name: "account_name_lower", Seq: 1, Expression: "lower(name)", Ascending: true, Method: "btree"},
+		{Name: "account_name_trgm", Seq: 1, ColumnName: "name", Ascending: true, Method: "gin", OpClass: "gin_trgm_ops"},
+	}
+	fields := []*nemgen.Field{}
+	for _, c := range []*pgColumnDetails{
+		{Name: "id", DataType: "uuid", IsNullable: "NO", IsIdentity: "NO"},
+		{Name: "email", DataType: "text", IsNullable: "NO", IsIdentity: "NO"},
+		{Name: "name", DataType: "text", IsNullable: "NO", IsIdentity: "NO"},
+		{Name: "deleted_at", DataType: "date", IsNullable: "YES", IsIdentity: "NO"},
+	} {
+		fields = append(fields, mapPgColumnDetailsToField(c, remoteRows{}, details))
+	}
+	if fields[1].GetUnique() {
+		t.Errorf("email is unique, but only among the live rows")
+	}
+	indexes, err := rt.buildIndexesFromPg(details, fields)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(indexes) != 4 {
+		t.Fatalf("got %d indexes, want 4", len(indexes))
+	}
+	e := &nemgen.Entity{
+		Uuid:       "account",
+		Identifier: "account",
+		Fields:     fields,
+		Type:       nemgen.EntityType_ENTITY_TYPE_STANDALONE,
+		Status:     nemgen.EntityStatus_ENTITY_STATUS_ACTIVE,
+		TypeConfig: &nemgen.EntityTypeConfig{Standalone: &nemgen.EntityTypeStandaloneConfig{Indexes: indexes}},
+	}
+	got := renderCreateSQLWithOptions(t, &nemgen.ProjectVersion{Entities: []*nemgen.Entity{e}}, db.PGDBType,
+		tosql.SchemaOptions{Indexes: rt.indexes.list()})
+	for _, want := range []string{
+		`CREATE UNIQUE INDEX "account_email_live" ON "account" ("email") WHERE deleted_at IS NULL;`,
+		`CREATE INDEX "account_name_lower" ON "account" ((lower(name)));`,
+		`CREATE INDEX "account_name_trgm" ON "account" USING gin ("name" gin_trgm_ops);`,
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("rendered DDL is missing %s\n%s", want, got)
+		}
+	}
+}
@@ -1,24 +1,26 @@
 package fromsql
 
 import (
+	"context"
 	"database/sql"
 	"fmt"
 	"slices"
 	"sort"
 	"strings"
-	"sync"
 	"time"
 
 	nemgen "github.com/nuzur/nem/idl/gen"
 	"github.com/nuzur/sql-gen/db"
 	"github.com/nuzur/sql-gen/tosql"
-	"golang.org/x/sync/errgroup"
 	"google.golang.org/protobuf/types/known/timestamppb"
 
 	"github.com/gofrs/uuid"
 )
 
 type mysqlColumnDetails struct {
+	// TableName is the table the row is about, which a bulk read parts the
+	// rows of every table by. The other details carry it for the same reason.
+	TableName        string  `db:"TABLE_NAME"`
 	Name             string  `db:"COLUMN_NAME"`
 	DataType         string  `db:"DATA_TYPE"`   // just the type
 	ColumnType       string  `db:"COLUMN_TYPE"` // full type with size
@@ -33,14 +35,22 @@ type mysqlColumnDetails struct {
 	// fsp is 0, so a bare DATETIME reports 0 — which is exactly the "unset"
 	// precision the model renders bare.
 	DatetimePrecision *int64 `db:"DATETIME_PRECISION"`
-	// Extra carries "DEFAULT_GENERATED" for an expression default and
-	// "on update CURRENT_TIMESTAMP" for the auto-refresh clause. Neither is
-	// visible anywhere else in information_schema, so without it an expression
-	// default reconstructs as a string literal and ON UPDATE is lost entirely.
+	// Extra carries "DEFAULT_GENERATED" for an expression default,
+	// "on update CURRENT_TIMESTAMP" for the auto-refresh clause and
+	// "auto_increment" for a key that counts on its own. None is visible
+	// anywhere else in information_schema, so without it an expression default
+	// reconstructs as a string literal and the others are lost entirely.
 	Extra string `db:"EXTRA"`
+	// GenerationExpression is a generated column's expression, which EXTRA
+	// marks VIRTUAL GENERATED or STORED GENERATED. It is empty for any other
+	// column.
+	GenerationExpression *string `db:"GENERATION_EXPRESSION"`
+	// Comment is the column's COMMENT, empty if it has none.
+	Comment string `db:"COLUMN_COMMENT"`
 }
 
 type mysqlIndexDetails struct {
+	TableName      string `db:"TABLE_NAME"`
 	Name           string `db:"INDEX_NAME"`
 	Seq            int64  `db:"SEQ_IN_INDEX"`
 	NonUnique      bool   `db:"NON_UNIQUE"`
@@ -58,7 +68,24 @@ type mysqlIndexDetails struct {
 	SubPart sql.NullInt64 `db:"SUB_PART"`
 }
 
+// mysqlIndexExpressionDetails is a functional key part, which STATISTICS
+// reports with a NULL COLUMN_NAME and the expression beside it.
+type mysqlIndexExpressionDetails struct {
+	TableName  string         `db:"TABLE_NAME"`
+	Name       string         `db:"INDEX_NAME"`
+	Seq        int64          `db:"SEQ_IN_INDEX"`
+	Expression string         `db:"EXPRESSION"`
+	Collation  sql.NullString `db:"COLLATION"`
+}
+
+type mysqlCheckDetails struct {
+	TableName string `db:"TABLE_NAME"`
+	Name      string `db:"CONSTRAINT_NAME"`
+	Clause    string `db:"CHECK_CLAUSE"`
+}
+
 type mysqlForeignKeyDetails struct {
+	TableName            string `db:"TABLE_NAME"`
 	ConstraintName       string `db:"CONSTRAINT_NAME"`
 	ColumnName           string `db:"COLUMN_NAME"`
 	ReferencedColumnName string `db:"REFERENCED_COLUMN_NAME"`
@@ -70,70 +97,99 @@ type mysqlForeignKeyDetails struct {
 	UpdateRule string `db:"UPDATE_RULE"`
 }
 
-func (rt *sqlremote) buildProjectVersionFromMysql() (*nemgen.ProjectVersion, error) {
-	if rt.userConnection.DbSchema == "" {
-		res, err := rt.db.QueryMaps("SELECT DATABASE()")
+// mysqlTable is everything introspection reads about one mysql table, from
+// the live database or out of its DDL.
+type mysqlTable struct {
+	Name          string
+	Comment       string
+	AutoIncrement int64
+	Columns       []*mysqlColumnDetails
+	Indexes       []*mysqlIndexDetails
+	// IndexExpressions are the functional key parts of the indexes, by index
+	// name.
+	IndexExpressions map[string][]*mysqlIndexExpressionDetails
+	Checks           []tosql.Check
+	ForeignKeys      []*mysqlForeignKeyDetails
+	// SampleData are a few of the table's rows, which refine the column types.
+	// A table read from DDL has none.
+	SampleData remoteRows
+}
+
+func (rt *sqlremote) buildProjectVersionFromMysql(ctx context.Context) (*nemgen.ProjectVersion, error) {
+	if rt.dbSchema == "" {
+		res, err := rt.db.QueryMapsContext(ctx, "SELECT DATABASE()")
 		if err == nil && len(res) > 0 {
 			for _, v := range res[0] {
 				if str, ok := v.(string); ok {
-					rt.userConnection.DbSchema = str
+					rt.dbSchema = str
 					break
 				}
 			}
 		}
 	}
 
-	tableNames, err := rt.getTableNames()
+	tableNames, err := rt.getTableNames(ctx)
 	if err != nil {
 		return nil, err
 	}
 
-	eg := errgroup.Group{}
-	mu := &sync.Mutex{}
-	entities := []*nemgen.Entity{}
-	for _, tableName := range tableNames {
-		eg.Go(func() error {
-			e, err := rt.buildEntityFromMysql(tableName)
-			if err != nil {
-				return err
+	rt.views, err = rt.fetchMysqlViews(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var bulk map[string]*mysqlTable
+	var bulkErr error
+	if rt.bulk {
+		// failing, it leaves every table to read on its own
+		bulk, bulkErr = rt.fetchMysqlTablesInBulk(ctx, tableNames)
+		if bulkErr != nil {
+			if ctx.Err() != nil {
+				return nil, bulkErr
 			}
-			mu.Lock()
-			entities = append(entities, e)
-			mu.Unlock()
-			return nil
-		})
+			rt.progress.add(BulkFailed, "", bulkErr)
+		}
 	}
-	err = eg.Wait()
+
+	tables := make([]*mysqlTable, len(tableNames))
+	err = rt.introspectTables(ctx, tableNames, func(ctx context.Context, n int) error {
+		t, err := rt.fetchMysqlTable(ctx, tableNames[n], bulk)
+		if err != nil {
+			return err
+		}
+		tables[n] = t
+		return nil
+	})
 	if err != nil {
-		return nil, err
+		return nil, withBulkError(err, bulkErr)
+	}
+
+	return rt.buildProjectVersionFromMysqlTables(tables), nil
+}
+
+// buildProjectVersionFromMysqlTables maps the tables read, however they were
+// read, onto a project version.
+func (rt *sqlremote) buildProjectVersionFromMysqlTables(tables []*mysqlTable) *nemgen.ProjectVersion {
+	entities := []*nemgen.Entity{}
+	for _, t := range tables {
+		entities = append(entities, rt.buildEntityFromMysql(t))
 	}
 
-	// The tables are introspected concurrently, so they land in the slice in
-	// whatever order they finished. tosql's topological sort uses input order as
-	// its tie-break, which would make the emitted DDL differ run to run — and the
-	// MySQL diff, which compares re-rendered DDL against the model's, would read
-	// that as a change on every plan.
+	// tosql's topological sort uses input order as its tie-break, so the
+	// entities have to come in an order that does not depend on how the tables
+	// were read — or the MySQL diff, which compares re-rendered DDL against the
+	// model's, would read a different order as a change on every plan.
 	sort.Slice(entities, func(a, b int) bool {
 		return entities[a].Identifier < entities[b].Identifier
 	})
 
-	eg = errgroup.Group{}
 	relationships := []*nemgen.Relationship{}
-	for _, e := range entities {
-		eg.Go(func() error {
-			rels, err := rt.buildRelationshipsFromMysql(e.Identifier, entities)
-			if err != nil {
-				return err
+	for _, t := range tables {
+		for _, fkd := range t.ForeignKeys {
+			if rel := mapMysqlFKDetailsToRelationship(fkd, t.Name, entities); rel != nil {
+				relationships = append(relationships, rel)
 			}
-			mu.Lock()
-			relationships = append(relationships, rels...)
-			mu.Unlock()
-			return nil
-		})
-	}
-	err = eg.Wait()
-	if err != nil {
-		return nil, err
+		}
 	}
 	sort.Slice(relationships, func(a, b int) bool {
 		return relationships[a].Identifier < relationships[b].Identifier
@@ -145,12 +201,77 @@ func (rt *sqlremote) buildProjectVersionFromMysql() (*nemgen.ProjectVersion, err
 		Entities:      entities,
 		Status:        nemgen.ProjectVersionStatus_PROJECT_VERSION_STATUS_ACTIVE,
 		Relationships: relationships,
-	}, nil
+		Enums:         rt.enums.list(),
+	}
+}
+
+func (rt *sqlremote) fetchMysqlViews(ctx context.Context) ([]tosql.View, error) {
+	query := fmt.Sprintf(`
+		SELECT TABLE_NAME AS name,
+			VIEW_DEFINITION AS definition
+		FROM information_schema.VIEWS
+		WHERE TABLE_SCHEMA = '%s'
+		ORDER BY TABLE_NAME;`,
+		rt.dbSchema,
+	)
+
+	details := []*viewDetails{}
+	if err := rt.db.SelectContext(ctx, &details, query); err != nil {
+		return nil, fmt.Errorf("error getting views: %v", err)
+	}
+	for _, d := range details {
+		d.Definition = mysqlViewDefinition(d.Definition, rt.dbSchema)
+	}
+	return rt.mapViews(details), nil
+}
+
+// fetchMysqlTable reads everything about a table from the live database,
+// bar what a bulk read already has.
+func (rt *sqlremote) fetchMysqlTable(ctx context.Context, tableName string, bulk map[string]*mysqlTable) (*mysqlTable, error) {
+	var err error
+	if t := bulk[tableName]; t != nil {
+		if t.SampleData, err = rt.sampleRows(ctx, "", tableName, mysqlColumnNames(t.Columns)); err != nil {
+			return nil, err
+		}
+		return t, nil
+	}
+
+	t := &mysqlTable{Name: tableName}
+	if t.Columns, err = rt.fetchMysqlColumnDetails(ctx, tableName); err != nil {
+		return nil, err
+	}
+	if t.SampleData, err = rt.sampleRows(ctx, "", tableName, mysqlColumnNames(t.Columns)); err != nil {
+		return nil, err
+	}
+	if t.Indexes, err = rt.fetchMysqlIndexDetails(ctx, tableName); err != nil {
+		return nil, err
+	}
+	expressions, err := rt.fetchMysqlIndexExpressions(ctx, tableName)
+	if err != nil {
+		return nil, err
+	}
+	t.IndexExpressions = mysqlIndexExpressionsByIndex(expressions)
+	checks, err := rt.fetchMysqlChecks(ctx, tableName)
+	if err != nil {
+		return nil, err
+	}
+	t.Checks = mysqlChecks(checks)
+	if t.AutoIncrement, err = rt.fetchMysqlAutoIncrement(ctx, tableName); err != nil {
+		return nil, err
+	}
+	if t.Comment, err = rt.fetchMysqlTableComment(ctx, tableName); err != nil {
+		return nil, err
+	}
+	if t.ForeignKeys, err = rt.fetchMysqlForeignKeys(ctx, tableName); err != nil {
+		return nil, err
+	}
+	return t, nil
 }
 
-func (rt *sqlremote) buildRelationshipsFromMysql(tableName string, entities []*nemgen.Entity) ([]*nemgen.Relationship, error) {
+func (rt *sqlremote) fetchMysqlForeignKeys(ctx context.Context, tableName string) ([]*mysqlForeignKeyDetails, error) {
 	foreignKeysQuery := fmt.Sprintf(`
 		SELECT 
+			tc.TABLE_NAME,
 			tc.CONSTRAINT_NAME, 
 			kcu.COLUMN_NAME, 
 			kcu.REFERENCED_COLUMN_NAME, 
@@ -168,58 +289,90 @@ func (rt *sqlremote) buildRelationshipsFromMysql(tableName string, entities []*n
 			rc.CONSTRAINT_SCHEMA = tc.TABLE_SCHEMA)
 		WHERE 
 			tc.CONSTRAINT_TYPE='FOREIGN KEY' AND
-			tc.TABLE_SCHEMA = '%s' AND
-			tc.TABLE_NAME = '%s' 
+			tc.TABLE_SCHEMA = '%s'
+			%s
 		ORDER BY ORDINAL_POSITION`,
-		rt.userConnection.DbSchema,
-		tableName,
+		rt.dbSchema,
+		mysqlTableFilter("tc.TABLE_NAME", tableName),
 	)
 
 	var fkDetails []*mysqlForeignKeyDetails = []*mysqlForeignKeyDetails{}
-	err := rt.db.Select(&fkDetails, foreignKeysQuery)
+	err := rt.db.SelectContext(ctx, &fkDetails, foreignKeysQuery)
 	if err != nil {
 		return nil, fmt.Errorf("error getting constraint details: %v", err)
 	}
-
-	rels := []*nemgen.Relationship{}
-	for _, fkd := range fkDetails {
-		rels = append(rels, mapMysqlFKDetailsToRelationship(fkd, tableName, entities))
-	}
-
-	return rels, nil
+	return fkDetails, nil
 }
 
-func (rt *sqlremote) buildEntityFromMysql(tableName string) (*nemgen.Entity, error) {
-
-	fields, err := rt.buildFieldsFromMysql(tableName)
-	if err != nil {
-		return nil, err
-	}
+func (rt *sqlremote) buildEntityFromMysql(t *mysqlTable) *nemgen.Entity {
+	fields := rt.buildFieldsFromMysql(t)
+	indexes := rt.buildIndexesFromMysql(t, fields)
 
-	indexes, err := rt.buildIndexesFromMysql(tableName, fields)
-	if err != nil {
-		return nil, err
-	}
-
-	return &nemgen.Entity{
-		Uuid:       uuid.Must(uuid.NewV4()).String(),
-		Version:    time.Now().Unix(),
-		Identifier: tableName,
-		Fields:     fields,
-		Type:       nemgen.EntityType_ENTITY_TYPE_STANDALONE,
+	e := &nemgen.Entity{
+		Uuid:        uuid.Must(uuid.NewV4()).String(),
+		Version:     time.Now().Unix(),
+		Identifier:  t.Name,
+		Description: t.Comment,
+		Fields:      fields,
+		Type:        nemgen.EntityType_ENTITY_TYPE_STANDALONE,
 		TypeConfig: &nemgen.EntityTypeConfig{
 			Standalone: &nemgen.EntityTypeStandaloneConfig{
 				Indexes: indexes,
 			},
 		},
 		Status: nemgen.EntityStatus_ENTITY_STATUS_ACTIVE,
-	}, nil
+	}
+	rt.checks.add(e.Uuid, t.Checks)
+	rt.autoIncrement.add(e.Uuid, t.AutoIncrement)
+	return e
+
+}
 
+// fetchMysqlAutoIncrement is the value the table's auto-increment key counts
+// on from. information_schema.TABLES has it too, but mysql 8 serves that from
+// statistics cached for up to a day; SHOW CREATE TABLE reads the table itself.
+func (rt *sqlremote) fetchMysqlAutoIncrement(ctx context.Context, tableName string) (int64, error) {
+	query := fmt.Sprintf("SHOW CREATE TABLE `%s`.`%s`", rt.dbSchema, tableName)
+	res, err := rt.db.QueryMapsContext(ctx, query)
+	if err != nil {
+		return 0, fmt.Errorf("error getting table definition: %v", err)
+	}
+	if len(res) == 0 {
+		return 0, nil
+	}
+	switch createTable := res[0]["Create Table"].(type) {
+	case string:
+		return mysqlAutoIncrementStart(createTable), nil
+	case []byte:
+		return mysqlAutoIncrementStart(string(createTable)), nil
+	}
+	return 0, nil
 }
 
-func (rt *sqlremote) buildFieldsFromMysql(tableName string) ([]*nemgen.Field, error) {
+// fetchMysqlTableComment is the table's COMMENT, empty if it has none.
+func (rt *sqlremote) fetchMysqlTableComment(ctx context.Context, tableName string) (string, error) {
+	query := fmt.Sprintf(`
+		SELECT TABLE_COMMENT
+		FROM INFORMATION_SCHEMA.TABLES
+		WHERE TABLE_SCHEMA = '%s'
+			AND TABLE_NAME = '%s'`,
+		rt.dbSchema,
+		tableName)
+
+	comments := []string{}
+	if err := rt.db.SelectContext(ctx, &comments, query); err != nil {
+		return "", fmt.Errorf("error getting table comment: %v", err)
+	}
+	if len(comments) == 0 {
+		return "", nil
+	}
+	return comments[0], nil
+}
+
+func (rt *sqlremote) fetchMysqlColumnDetails(ctx context.Context, tableName string) ([]*mysqlColumnDetails, error) {
 	columnsQuery := fmt.Sprintf(`
-		SELECT COLUMN_NAME,
+		SELECT TABLE_NAME,
+				COLUMN_NAME,
 			   	DATA_TYPE,
 				COLUMN_TYPE,
 				COLUMN_KEY,
@@ -229,44 +382,58 @@ func (rt *sqlremote) buildFieldsFromMysql(tableName string) ([]*nemgen.Field, er
 				NUMERIC_PRECISION,
 				NUMERIC_SCALE,
 				DATETIME_PRECISION,
-				EXTRA
+				EXTRA,
+				GENERATION_EXPRESSION,
+				COLUMN_COMMENT
 		FROM INFORMATION_SCHEMA.columns
 		WHERE 
 			TABLE_SCHEMA = '%s'
-			AND TABLE_NAME = '%s'
+			%s
 		ORDER BY ORDINAL_POSITION`,
-		rt.userConnection.DbSchema,
-		tableName,
+		rt.dbSchema,
+		mysqlTableFilter("TABLE_NAME", tableName),
 	)
 
 	var columnsDetails []*mysqlColumnDetails = []*mysqlColumnDetails{}
-	err := rt.db.Select(&columnsDetails, columnsQuery)
+	err := rt.db.SelectContext(ctx, &columnsDetails, columnsQuery)
 	if err != nil {
 		return nil, fmt.Errorf("error getting columns: %v", err)
 	}
+	return columnsDetails, nil
+}
 
-	sampleData, err := rt.sampleTableValues(tableName)
-	if err != nil {
-		return nil, err
+func mysqlColumnNames(columns []*mysqlColumnDetails) []string {
+	names := []string{}
+	for _, c := range columns {
+		names = append(names, c.Name)
 	}
+	return names
+}
 
+func (rt *sqlremote) buildFieldsFromMysql(t *mysqlTable) []*nemgen.Field {
 	fields := []*nemgen.Field{}
-	for _, columnDetails := range columnsDetails {
-		f := mapMysqlColumnDetailsToField(columnDetails, sampleData)
+	for _, columnDetails := range t.Columns {
+		f := mapMysqlColumnDetailsToField(columnDetails, t.SampleData)
+		unsampled, _ := mapMysqlColumnDataTypeToFieldType(columnDetails, nil)
+		rt.promotions.check(t.Name, columnDetails.Name, unsampled, f, t.SampleData)
 		if f != nil {
+			// an ENUM or SET is declared inline, so its enum is the column's own
+			rt.resolveEnum(f, "", fmt.Sprintf("%s_%s", t.Name, columnDetails.Name), mysqlEnumLabels(columnDetails.ColumnType))
+			rt.computed.add(f.Uuid, mysqlComputed(columnDetails))
 			fields = append(fields, f)
 		}
 	}
-	return fields, nil
+	return fields
 }
 
-func (rt *sqlremote) buildIndexesFromMysql(tableName string, fields []*nemgen.Field) ([]*nemgen.Index, error) {
+func (rt *sqlremote) fetchMysqlIndexDetails(ctx context.Context, tableName string) ([]*mysqlIndexDetails, error) {
 	indexesQuery := fmt.Sprintf(`
 		SELECT DISTINCT
+			s.TABLE_NAME,
 			s.INDEX_NAME,
 			s.SEQ_IN_INDEX,
 			s.NON_UNIQUE,
-			s.COLUMN_NAME,
+			IFNULL(s.COLUMN_NAME, '') AS COLUMN_NAME,
 			s.COLLATION,
 			s.INDEX_TYPE,
 			s.SUB_PART,
@@ -283,17 +450,20 @@ func (rt *sqlremote) buildIndexesFromMysql(tableName string, fields []*nemgen.Fi
 				ON kcu.constraint_name = s.index_name
 		WHERE
 			0 = 0 AND s.TABLE_SCHEMA = '%s'
-				AND s.table_name = '%s'
+				%s
 		ORDER BY s.INDEX_NAME, s.SEQ_IN_INDEX`,
-		rt.userConnection.DbSchema,
-		tableName)
+		rt.dbSchema,
+		mysqlTableFilter("s.TABLE_NAME", tableName))
 
 	var indexesDetails []*mysqlIndexDetails = []*mysqlIndexDetails{}
-	err := rt.db.Select(&indexesDetails, indexesQuery)
+	err := rt.db.SelectContext(ctx, &indexesDetails, indexesQuery)
 	if err != nil {
 		return nil, fmt.Errorf("error getting indexes: %v", err)
 	}
+	return indexesDetails, nil
+}
 
+func (rt *sqlremote) buildIndexesFromMysql(t *mysqlTable, fields []*nemgen.Field) []*nemgen.Index {
 	// mysql creates a supporting index for every foreign key that has none, and
 	// names it after the constraint. It is not in the DDL that created the table
 	// and the relationship already implies it, so reconstructing it as an index
@@ -301,14 +471,14 @@ func (rt *sqlremote) buildIndexesFromMysql(tableName string, fields []*nemgen.Fi
 	// generates — a DROP KEY / ADD KEY the diff proposes on every plan. An index
 	// the schema really declares survives, because mysql reuses an existing index
 	// instead of creating one and the declared name is what STATISTICS reports.
-	implicit, err := rt.foreignKeyConstraintNames(tableName)
-	if err != nil {
-		return nil, err
+	implicit := make(map[string]bool)
+	for _, fkd := range t.ForeignKeys {
+		implicit[fkd.ConstraintName] = true
 	}
 
 	// group indexes by name
 	groupedIndexesDetails := make(map[string][]*mysqlIndexDetails)
-	for _, indexDetails := range indexesDetails {
+	for _, indexDetails := range t.Indexes {
 		if implicit[indexDetails.Name] {
 			continue
 		}
@@ -325,6 +495,7 @@ func (rt *sqlremote) buildIndexesFromMysql(tableName string, fields []*nemgen.Fi
 		i := mapMysqlIndexDetailsToIndex(groupedDetails, fields)
 		if i != nil {
 			indexes = append(indexes, i)
+			rt.indexes.add(i.Uuid, mysqlIndexOptions(groupedDetails, t.IndexExpressions[i.Identifier]))
 		}
 	}
 
@@ -335,31 +506,82 @@ func (rt *sqlremote) buildIndexesFromMysql(tableName string, fields []*nemgen.Fi
 		return indexes[a].Identifier < indexes[b].Identifier
 	})
 
-	return indexes, nil
+	return indexes
 }
 
-// foreignKeyConstraintNames is the set of foreign key constraint names on a
-// table, which is also the set of names mysql gives the indexes it creates to
-// support them.
-func (rt *sqlremote) foreignKeyConstraintNames(tableName string) (map[string]bool, error) {
+// fetchMysqlIndexExpressions reads the functional key parts of a table's
+// indexes. mysql has them from 8.0.13, and STATISTICS has no EXPRESSION
+// column before that, so an older server has none to report.
+func (rt *sqlremote) fetchMysqlIndexExpressions(ctx context.Context, tableName string) ([]*mysqlIndexExpressionDetails, error) {
 	query := fmt.Sprintf(`
-		SELECT CONSTRAINT_NAME
-		FROM INFORMATION_SCHEMA.TABLE_CONSTRAINTS
-		WHERE CONSTRAINT_TYPE = 'FOREIGN KEY'
-			AND TABLE_SCHEMA = '%s'
-			AND TABLE_NAME = '%s'`,
-		rt.userConnection.DbSchema,
-		tableName)
+		SELECT TABLE_NAME,
+			INDEX_NAME,
+			SEQ_IN_INDEX,
+			EXPRESSION,
+			COLLATION
+		FROM INFORMATION_SCHEMA.STATISTICS
+		WHERE TABLE_SCHEMA = '%s'
+			%s
+			AND EXPRESSION IS NOT NULL
+		ORDER BY INDEX_NAME, SEQ_IN_INDEX`,
+		rt.dbSchema,
+		mysqlTableFilter("TABLE_NAME", tableName))
 
-	names := []string{}
-	if err := rt.db.Select(&names, query); err != nil {
-		return nil, fmt.Errorf("error getting foreign key constraint names: %v", err)
+	details := []*mysqlIndexExpressionDetails{}
+	if err := rt.db.SelectContext(ctx, &details, query); err != nil {
+		if strings.Contains(err.Error(), "EXPRESSION") {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("error getting index expressions: %v", err)
 	}
-	res := make(map[string]bool, len(names))
-	for _, n := range names {
-		res[n] = true
+	return details, nil
+}
+
+// mysqlIndexExpressionsByIndex parts a table's functional key parts by the
+// index they are in.
+func mysqlIndexExpressionsByIndex(details []*mysqlIndexExpressionDetails) map[string][]*mysqlIndexExpressionDetails {
+	byIndex := make(map[string][]*mysqlIndexExpressionDetails)
+	for _, d := range details {
+		byIndex[d.Name] = append(byIndex[d.Name], d)
 	}
-	return res, nil
+	return byIndex
+}
+
+// fetchMysqlChecks reads a table's CHECK constraints. mysql only has them
+// from 8.0.16: before that it parsed a CHECK and threw it away, and
+// information_schema has no CHECK_CONSTRAINTS table to ask.
+func (rt *sqlremote) fetchMysqlChecks(ctx context.Context, tableName string) ([]*mysqlCheckDetails, error) {
+	query := fmt.Sprintf(`
+		SELECT tc.TABLE_NAME,
+			tc.CONSTRAINT_NAME,
+			cc.CHECK_CLAUSE
+		FROM INFORMATION_SCHEMA.TABLE_CONSTRAINTS tc
+		JOIN INFORMATION_SCHEMA.CHECK_CONSTRAINTS cc ON (
+			cc.CONSTRAINT_SCHEMA = tc.TABLE_SCHEMA AND
+			cc.CONSTRAINT_NAME = tc.CONSTRAINT_NAME)
+		WHERE tc.CONSTRAINT_TYPE = 'CHECK'
+			AND tc.TABLE_SCHEMA = '%s'
+			%s
+		ORDER BY tc.CONSTRAINT_NAME`,
+		rt.dbSchema,
+		mysqlTableFilter("tc.TABLE_NAME", tableName))
+
+	details := []*mysqlCheckDetails{}
+	if err := rt.db.SelectContext(ctx, &details, query); err != nil {
+		if strings.Contains(err.Error(), "CHECK_CONSTRAINTS") {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("error getting check constraints: %v", err)
+	}
+	return details, nil
+}
+
+func mysqlChecks(details []*mysqlCheckDetails) []tosql.Check {
+	checks := []tosql.Check{}
+	for _, d := range details {
+		checks = append(checks, tosql.Check{Name: d.Name, Expression: checkExpression(d.Clause)})
+	}
+	return checks
 }
 
 func mapMysqlColumnDetailsToField(in *mysqlColumnDetails, sampleData remoteRows) *nemgen.Field {
@@ -374,6 +596,8 @@ func mapMysqlColumnDetailsToField(in *mysqlColumnDetails, sampleData remoteRows)
 	// implicit DEFAULT CURRENT_TIMESTAMP, so a column defaulted to something else
 	// survives the round trip.
 	def := mysqlColumnDefault(in.DefaultValue, in.Extra)
+	isKey := in.ColumnKey == "PRI"
+	autoIncrement := isKey && fieldType == nemgen.FieldType_FIELD_TYPE_INTEGER && mysqlAutoIncrement(in.Extra)
 	return &nemgen.Field{
 		Uuid:                     uuid.Must(uuid.NewV4()).String(),
 		Version:                  time.Now().Unix(),
@@ -382,10 +606,12 @@ func mapMysqlColumnDetailsToField(in *mysqlColumnDetails, sampleData remoteRows)
 		Type:                     fieldType,
 		TypeConfig:               fieldTypeConfig,
 		Status:                   nemgen.FieldStatus_FIELD_STATUS_ACTIVE,
-		Key:                      in.ColumnKey == "PRI",
+		Key:                      isKey,
+		KeyAutoIncrement:         autoIncrement,
 		Unique:                   in.ColumnKey == "UNI",
 		DefaultValue:             def.Value,
 		DefaultValueIsExpression: def.IsExpression,
+		Description:              in.Comment,
 	}
 }
 
@@ -445,6 +671,13 @@ func mapMysqlColumnDataTypeToFieldType(in *mysqlColumnDetails, sampleData remote
 		}
 	case "double":
 		return nemgen.FieldType_FIELD_TYPE_FLOAT, nil
+	case "enum", "set":
+		// the enum itself is resolved by the caller, which knows the table
+		return nemgen.FieldType_FIELD_TYPE_ENUM, &nemgen.FieldTypeConfig{
+			Enum: &nemgen.FieldTypeEnumConfig{
+				AllowMultiple: dataType == "set",
+			},
+		}
 	case "decimal":
 		// The scale has to come back out or the round trip loses it, and a
 		// decimal field with no number_of_decimals renders at the default
@@ -734,6 +967,10 @@ func mapMysqlFKDetailsToRelationship(in *mysqlForeignKeyDetails, tableName strin
 			toEntity = e
 		}
 	}
+	// a foreign key into a table that was not read has no entity to point at
+	if fromEntity == nil || toEntity == nil {
+		return nil
+	}
 
 	var fromField *nemgen.Field
 	for _, f := range fromEntity.Fields {
@@ -750,6 +987,9 @@ func mapMysqlFKDetailsToRelationship(in *mysqlForeignKeyDetails, tableName strin
 			break
 		}
 	}
+	if fromField == nil || toField == nil {
+		return nil
+	}
 
 	return &nemgen.Relationship{
 		Uuid:       uuid.Must(uuid.NewV4()).String(),
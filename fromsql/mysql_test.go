@@ -5,6 +5,7 @@ import (
 	"database/sql"
 	"os"
 	"strconv"
+	"strings"
 	"testing"
 
 	"github.com/gofrs/uuid"
@@ -276,3 +277,78 @@ func renderCreateSQL(t *testing.T, e *nemgen.Entity, dbType db.DBType) string {
 
 func nullString(v string) sql.NullString { return sql.NullString{String: v, Valid: true} }
 func nullInt64(v int64) sql.NullInt64    { return sql.NullInt64{Int64: v, Valid: true} }
+
+// An AUTO_INCREMENT key comes back auto-increment, and renders as one again
+// with the table's counter: what GenerateInsertForEntityWithValues needs to
+// leave the key to the database.
+func TestMysqlAutoIncrementIsAFixedPoint(t *testing.T) {
+	id := mapMysqlColumnDetailsToField(&mysqlColumnDetails{
+		Name: "id", DataType: "bigint", ColumnType: "bigint", ColumnKey: "PRI", IsNullable: "NO", Extra: "auto_increment",
+	}, remoteRows{})
+	if !id.GetKeyAutoIncrement() {
+		t.Fatal("the auto_increment key did not come back auto-increment")
+	}
+	counter := mapMysqlColumnDetailsToField(&mysqlColumnDetails{
+		Name: "counter", DataType: "bigint", ColumnType: "bigint", IsNullable: "NO",
+	}, remoteRows{})
+	if counter.GetKeyAutoIncrement() {
+		t.Error("a column that is neither key nor auto_increment came back auto-increment")
+	}
+
+	start := mysqlAutoIncrementStart("CREATE TABLE `ticket` (\n" +
+		"  `id` bigint NOT NULL AUTO_INCREMENT,\n" +
+		"  PRIMARY KEY (`id`)\n" +
+		") ENGINE=InnoDB AUTO_INCREMENT=1000 DEFAULT CHARSET=utf8mb4")
+	if start != 1000 {
+		t.Fatalf("AUTO_INCREMENT start = %d, want 1000", start)
+	}
+
+	e := &nemgen.Entity{
+		Uuid:       uuid.Must(uuid.NewV4()).String(),
+		Identifier: "ticket",
+		Fields:     []*nemgen.Field{id},
+		Type:       nemgen.EntityType_ENTITY_TYPE_STANDALONE,
+		Status:     nemgen.EntityStatus_ENTITY_STATUS_ACTIVE,
+		TypeConfig: &nemgen.EntityTypeConfig{Standalone: &nemgen.EntityTypeStandaloneConfig{}},
+	}
+	got := renderCreateSQLWithOptions(t, &nemgen.ProjectVersion{Entities: []*nemgen.Entity{e}}, db.MYSQLDBType,
+		tosql.SchemaOptions{AutoIncrement: map[string]int64{e.Uuid: start}})
+	for _, want := range []string{
+		"`id` BIGINT NOT NULL AUTO_INCREMENT",
+		") ENGINE = InnoDB AUTO_INCREMENT = 1000;",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("rendered DDL is missing %s\n%s", want, got)
+		}
+	}
+}
+
+func TestMysqlAutoIncrementStartIgnoresTheColumns(t *testing.T) {
+	if got := mysqlAutoIncrementStart("CREATE TABLE `ticket` (\n  `id` int NOT NULL AUTO_INCREMENT,\n  PRIMARY KEY (`id`)\n) ENGINE=InnoDB"); got != 0 {
+		t.Errorf("AUTO_INCREMENT start = %d, want 0 for a table with no counter option", got)
+	}
+}
+
+// A connection naming no schema is read from the database's current one,
+// which is the import's own: the caller's connection is left as it was.
+func TestMysqlCurrentSchemaLeavesTheConnection(t *testing.T) {
+	scripted := &scriptedDB{responses: []scriptedResponse{
+		{"SELECT DATABASE()", []map[string]interface{}{{"DATABASE()": "shop"}}},
+		{"SELECT TABLE_NAME FROM information_schema.TABLES", []string{}},
+		{"information_schema.VIEWS", []*viewDetails{}},
+	}}
+	conn := &nemgen.UserConnection{}
+	if _, err := GenerateProjectVersion(context.Background(), GenerateRequest{
+		UserConnection: conn,
+		DB:             scripted,
+		DBType:         db.MYSQLDBType,
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if conn.DbSchema != "" {
+		t.Errorf("the connection's schema was set to %q", conn.DbSchema)
+	}
+	if !strings.Contains(strings.Join(scripted.queries, "\n"), "TABLE_SCHEMA = 'shop'") {
+		t.Errorf("the tables were not listed in the current schema: %q", scripted.queries)
+	}
+}
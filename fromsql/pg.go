@@ -1,22 +1,26 @@
 package fromsql
 
 import (
+	"context"
 	"fmt"
 	"slices"
 	"sort"
 	"strings"
-	"sync"
 	"time"
 
 	"github.com/gofrs/uuid"
 	nemgen "github.com/nuzur/nem/idl/gen"
 	"github.com/nuzur/sql-gen/db"
 	"github.com/nuzur/sql-gen/tosql"
-	"golang.org/x/sync/errgroup"
 	"google.golang.org/protobuf/types/known/timestamppb"
 )
 
 type pgColumnDetails struct {
+	// TableSchema and TableName are the table the row is about, which a bulk
+	// read parts the rows of every table by. The other details carry them for
+	// the same reason.
+	TableSchema      string  `db:"table_schema"`
+	TableName        string  `db:"table_name"`
 	Name             string  `db:"column_name"`
 	DataType         string  `db:"data_type"`
 	DefaultValue     *string `db:"column_default"`
@@ -28,91 +32,190 @@ type pgColumnDetails struct {
 	// timestamp/time column, NULL for every other type. Postgres reports 6 for a
 	// bare TIMESTAMP — see pgDatetimePrecision for why that folds back to unset.
 	DatetimePrecision *int64 `db:"datetime_precision"`
+	// UdtName is the column's underlying type, which names the type of a
+	// USER-DEFINED column and, prefixed with an underscore, the element type of
+	// an ARRAY one.
+	UdtName string `db:"udt_name"`
+	// UdtSchema is the schema UdtName is in.
+	UdtSchema string `db:"udt_schema"`
+	// IsIdentity is YES for a GENERATED ... AS IDENTITY column, and
+	// IdentityGeneration then says whether ALWAYS or BY DEFAULT. Both come back
+	// as a BY DEFAULT identity (see pgAutoIncrement).
+	IsIdentity         string  `db:"is_identity"`
+	IdentityGeneration *string `db:"identity_generation"`
+	// IsGenerated is ALWAYS for a generated column, whose expression is
+	// GenerationExpression.
+	IsGenerated          string  `db:"is_generated"`
+	GenerationExpression *string `db:"generation_expression"`
+	// Comment is the column's COMMENT ON, empty if it has none.
+	Comment string `db:"column_comment"`
+	// EnumLabels are the labels of the column's enum type when it is one (or an
+	// array of one), filled in from pgEnumLabels.
+	EnumLabels []string `db:"-"`
+}
+
+type pgCheckDetails struct {
+	TableSchema string `db:"table_schema"`
+	TableName   string `db:"table_name"`
+	Name        string `db:"name"`
+	Definition  string `db:"definition"`
 }
 
 type pgIndexDetails struct {
-	Name       string `db:"index_name"`
-	Seq        int64  `db:"index_order"`
+	TableSchema string `db:"table_schema"`
+	TableName   string `db:"table_name"`
+	Name        string `db:"index_name"`
+	Seq         int64  `db:"index_order"`
+	// ColumnName is empty for a member that is an expression, which
+	// Expression holds instead.
 	ColumnName string `db:"index_column"`
+	Expression string `db:"index_expression"`
 	IsKey      bool   `db:"is_key"`
 	IsUnique   bool   `db:"is_unique"`
 	Ascending  bool   `db:"ascending"`
+	// Predicate is a partial index's WHERE condition, empty for an index over
+	// every row.
+	Predicate string `db:"predicate"`
+	// Method is the index's access method, such as btree or gin.
+	Method string `db:"method"`
+	// OpClass is the member's operator class, empty for its type's default.
+	OpClass string `db:"op_class"`
 }
 
 type pgForeignKeyDetails struct {
+	TableSchema          string `db:"table_schema"`
+	TableName            string `db:"table_name"`
 	ConstraintName       string `db:"constraint_name"`
 	ColumnName           string `db:"column_name"`
 	ReferencedColumnName string `db:"referenced_column_name"`
 	ReferencedTableName  string `db:"referenced_table_name"`
+	// ReferencedTableSchema is the schema of the referenced table, which need
+	// not be the one the constraint's own table is in.
+	ReferencedTableSchema string `db:"referenced_table_schema"`
 	// DeleteRule / UpdateRule are the referential actions, reported as
 	// "NO ACTION" for a constraint created without an explicit clause.
 	DeleteRule string `db:"delete_rule"`
 	UpdateRule string `db:"update_rule"`
 }
 
-func (rt *sqlremote) buildProjectVersionFromPg() (*nemgen.ProjectVersion, error) {
-	if rt.userConnection.DbSchema == "" {
-		res, err := rt.db.QueryMaps("SELECT current_schema()")
+// pgTable is everything introspection reads about one postgres table, from
+// the live database or out of its DDL.
+type pgTable struct {
+	Name        pgName
+	Comment     string
+	Columns     []*pgColumnDetails
+	Indexes     []*pgIndexDetails
+	Checks      []tosql.Check
+	ForeignKeys []*pgForeignKeyDetails
+	// SampleData are a few of the table's rows, which refine the column types.
+	// A table read from DDL has none.
+	SampleData remoteRows
+}
+
+func (rt *sqlremote) buildProjectVersionFromPg(ctx context.Context) (*nemgen.ProjectVersion, error) {
+	if rt.dbSchema == "" {
+		res, err := rt.db.QueryMapsContext(ctx, "SELECT current_schema()")
 		if err == nil && len(res) > 0 {
 			for _, v := range res[0] {
 				if str, ok := v.(string); ok {
-					rt.userConnection.DbSchema = str
+					rt.dbSchema = str
 					break
 				}
 			}
 		}
 	}
 
-	tableNames, err := rt.getTableNames()
+	tables, err := rt.getPgTables(ctx)
 	if err != nil {
 		return nil, err
 	}
 
-	eg := errgroup.Group{}
-	mu := &sync.Mutex{}
-	entities := []*nemgen.Entity{}
-	for _, tableName := range tableNames {
-		eg.Go(func() error {
-			e, err := rt.buildEntityFromPg(tableName)
-			if err != nil {
-				return err
-			}
-			mu.Lock()
-			entities = append(entities, e)
-			mu.Unlock()
-			return nil
-		})
+	rt.pgEnumLabels, err = rt.fetchPgEnumLabels(ctx)
+	if err != nil {
+		return nil, err
 	}
-	err = eg.Wait()
+
+	rt.views, err = rt.fetchPgViews(ctx)
 	if err != nil {
 		return nil, err
 	}
 
-	// The tables are introspected concurrently, so they land in the slice in
-	// completion order. tosql's topological sort uses input order as its
-	// tie-break, so an unsorted slice emits the same schema's DDL in a different
-	// order run to run.
+	names := []string{}
+	for _, table := range tables {
+		names = append(names, table.Schema+"."+table.Name)
+	}
+	var bulk map[pgName]*pgTable
+	var bulkErr error
+	if rt.bulk {
+		// failing, it leaves every table to read on its own
+		bulk, bulkErr = rt.fetchPgTablesInBulk(ctx, tables)
+		if bulkErr != nil {
+			if ctx.Err() != nil {
+				return nil, bulkErr
+			}
+			rt.progress.add(BulkFailed, "", bulkErr)
+		}
+	}
+
+	pgTables := make([]*pgTable, len(tables))
+	err = rt.introspectTables(ctx, names, func(ctx context.Context, n int) error {
+		t, err := rt.fetchPgTable(ctx, tables[n], bulk)
+		if err != nil {
+			return err
+		}
+		pgTables[n] = t
+		return nil
+	})
+	if err != nil {
+		return nil, withBulkError(err, bulkErr)
+	}
+
+	return rt.buildProjectVersionFromPgTables(pgTables)
+}
+
+// buildProjectVersionFromPgTables maps the tables read, however they were
+// read, onto a project version. rt.pgEnumLabels has to hold the enum types
+// their columns use.
+func (rt *sqlremote) buildProjectVersionFromPgTables(tables []*pgTable) (*nemgen.ProjectVersion, error) {
+	entities := []*nemgen.Entity{}
+	byTable := make(map[pgName]*nemgen.Entity)
+	for _, t := range tables {
+		e, err := rt.buildEntityFromPg(t)
+		if err != nil {
+			return nil, err
+		}
+		entities = append(entities, e)
+		byTable[t.Name] = e
+	}
+
+	// tosql's topological sort uses input order as its tie-break, so the
+	// entities have to come in an order that does not depend on how the tables
+	// were read, or the same schema's DDL is emitted in a different order run
+	// to run. Tables of the same name in different schemas go in schema order.
+	schemas := make(map[*nemgen.Entity]string)
+	for table, e := range byTable {
+		schemas[e] = table.Schema
+	}
 	sort.Slice(entities, func(a, b int) bool {
-		return entities[a].Identifier < entities[b].Identifier
+		if entities[a].Identifier != entities[b].Identifier {
+			return entities[a].Identifier < entities[b].Identifier
+		}
+		return schemas[entities[a]] < schemas[entities[b]]
 	})
 
-	eg = errgroup.Group{}
 	relationships := []*nemgen.Relationship{}
-	for _, e := range entities {
-		eg.Go(func() error {
-			rels, err := rt.buildRelationshipsFromPg(e.Identifier, entities)
-			if err != nil {
-				return err
+	for _, t := range tables {
+		for _, fkd := range t.ForeignKeys {
+			// a foreign key into a schema that is not introspected has no
+			// entity to point at
+			to, ok := byTable[pgName{Schema: fkd.ReferencedTableSchema, Name: fkd.ReferencedTableName}]
+			if !ok {
+				continue
 			}
-			mu.Lock()
-			relationships = append(relationships, rels...)
-			mu.Unlock()
-			return nil
-		})
-	}
-	err = eg.Wait()
-	if err != nil {
-		return nil, err
+			if rel := mapPgFKDetailsToRelationship(fkd, byTable[t.Name], to); rel != nil {
+				relationships = append(relationships, rel)
+			}
+		}
 	}
 	sort.Slice(relationships, func(a, b int) bool {
 		return relationships[a].Identifier < relationships[b].Identifier
@@ -124,14 +227,86 @@ func (rt *sqlremote) buildProjectVersionFromPg() (*nemgen.ProjectVersion, error)
 		Entities:      entities,
 		Status:        nemgen.ProjectVersionStatus_PROJECT_VERSION_STATUS_ACTIVE,
 		Relationships: relationships,
+		Enums:         rt.enums.list(),
 	}, nil
 }
 
-func (rt *sqlremote) buildRelationshipsFromPg(tableName string, entities []*nemgen.Entity) ([]*nemgen.Relationship, error) {
+// fetchPgViews reads the views and materialized views of the introspected
+// schemas. pg_views leaves materialized views out, so they come from
+// pg_matviews.
+func (rt *sqlremote) fetchPgViews(ctx context.Context) ([]tosql.View, error) {
+	query := fmt.Sprintf(`
+		SELECT schemaname AS schema_name,
+			viewname AS name,
+			definition,
+			false AS materialized
+		FROM pg_catalog.pg_views
+		WHERE %s
+		UNION ALL
+		SELECT schemaname AS schema_name,
+			matviewname AS name,
+			definition,
+			true AS materialized
+		FROM pg_catalog.pg_matviews
+		WHERE %s
+		ORDER BY schema_name, name;`,
+		rt.pgSchemaFilter("schemaname"),
+		rt.pgSchemaFilter("schemaname"),
+	)
+
+	details := []*viewDetails{}
+	if err := rt.db.SelectContext(ctx, &details, query); err != nil {
+		return nil, fmt.Errorf("error getting views: %v", err)
+	}
+	for _, d := range details {
+		d.Definition = pgViewDefinition(d.Definition)
+	}
+	return rt.mapViews(details), nil
+}
+
+// fetchPgTable reads everything about a table from the live database,
+// bar what a bulk read already has.
+func (rt *sqlremote) fetchPgTable(ctx context.Context, table pgName, bulk map[pgName]*pgTable) (*pgTable, error) {
+	var err error
+	if t := bulk[table]; t != nil {
+		if t.SampleData, err = rt.sampleRows(ctx, table.Schema, table.Name, pgColumnNames(t.Columns)); err != nil {
+			return nil, err
+		}
+		return t, nil
+	}
+
+	t := &pgTable{Name: table}
+	if t.Indexes, err = rt.fetchPgIndexDetails(ctx, &table); err != nil {
+		return nil, err
+	}
+	if t.Columns, err = rt.fetchPgColumnDetails(ctx, &table); err != nil {
+		return nil, err
+	}
+	if t.SampleData, err = rt.sampleRows(ctx, table.Schema, table.Name, pgColumnNames(t.Columns)); err != nil {
+		return nil, err
+	}
+	checks, err := rt.fetchPgChecks(ctx, &table)
+	if err != nil {
+		return nil, err
+	}
+	t.Checks = pgChecks(checks)
+	if t.Comment, err = rt.fetchPgTableComment(ctx, table); err != nil {
+		return nil, err
+	}
+	if t.ForeignKeys, err = rt.fetchPgForeignKeys(ctx, &table); err != nil {
+		return nil, err
+	}
+	return t, nil
+}
+
+func (rt *sqlremote) fetchPgForeignKeys(ctx context.Context, table *pgName) ([]*pgForeignKeyDetails, error) {
 	foreignKeysQuery := fmt.Sprintf(`
 		SELECT
+			tc.table_schema,
+			tc.table_name,
 			tc.constraint_name, 
 			kcu.column_name, 
+			ccu.table_schema AS referenced_table_schema,
 			ccu.table_name AS referenced_table_name,
 			ccu.column_name AS referenced_column_name,
 			rc.delete_rule,
@@ -142,136 +317,239 @@ func (rt *sqlremote) buildRelationshipsFromPg(tableName string, entities []*nemg
 			AND tc.table_schema = kcu.table_schema
 		JOIN information_schema.constraint_column_usage AS ccu
 			ON ccu.constraint_name = tc.constraint_name
+			AND ccu.constraint_schema = tc.table_schema
 		JOIN information_schema.referential_constraints AS rc
 			ON rc.constraint_name = tc.constraint_name
 			AND rc.constraint_schema = tc.table_schema
 		WHERE tc.constraint_type = 'FOREIGN KEY'
-			AND tc.table_schema='%s'
-			AND tc.table_name='%s'
+			AND %s
 		ORDER BY ORDINAL_POSITION;`,
-		rt.userConnection.DbSchema,
-		tableName,
+		rt.pgTableFilter("tc.table_schema", "tc.table_name", table),
 	)
 
 	var fkDetails []*pgForeignKeyDetails = []*pgForeignKeyDetails{}
-	err := rt.db.Select(&fkDetails, foreignKeysQuery)
+	err := rt.db.SelectContext(ctx, &fkDetails, foreignKeysQuery)
 	if err != nil {
 		return nil, fmt.Errorf("error getting constraint details: %v", err)
 	}
-
-	rels := []*nemgen.Relationship{}
-	for _, fkd := range fkDetails {
-		rels = append(rels, mapPgFKDetailsToRelationship(fkd, tableName, entities))
-	}
-
-	return rels, nil
+	return fkDetails, nil
 }
 
-func (rt *sqlremote) buildEntityFromPg(tableName string) (*nemgen.Entity, error) {
-
-	indexDetails, err := rt.fetchPgIndexDetails(tableName)
-	if err != nil {
-		return nil, err
-	}
+func (rt *sqlremote) buildEntityFromPg(t *pgTable) (*nemgen.Entity, error) {
 
-	fields, err := rt.buildFieldsFromPg(tableName, indexDetails)
+	fields, err := rt.buildFieldsFromPg(t)
 	if err != nil {
 		return nil, err
 	}
 
-	indexes, err := rt.buildIndexesFromPg(indexDetails, fields)
+	indexes, err := rt.buildIndexesFromPg(t.Indexes, fields)
 	if err != nil {
 		return nil, err
 	}
 
-	return &nemgen.Entity{
-		Uuid:       uuid.Must(uuid.NewV4()).String(),
-		Version:    time.Now().Unix(),
-		Identifier: tableName,
-		Fields:     fields,
-		Type:       nemgen.EntityType_ENTITY_TYPE_STANDALONE,
+	e := &nemgen.Entity{
+		Uuid:        uuid.Must(uuid.NewV4()).String(),
+		Version:     time.Now().Unix(),
+		Identifier:  t.Name.Name,
+		Description: t.Comment,
+		Fields:      fields,
+		Type:        nemgen.EntityType_ENTITY_TYPE_STANDALONE,
 		TypeConfig: &nemgen.EntityTypeConfig{
 			Standalone: &nemgen.EntityTypeStandaloneConfig{
 				Indexes: indexes,
 			},
 		},
 		Status: nemgen.EntityStatus_ENTITY_STATUS_ACTIVE,
-	}, nil
+	}
+	rt.checks.add(e.Uuid, t.Checks)
+	rt.placeInSchema(e.Uuid, t.Name.Schema)
+	return e, nil
 
 }
 
-func (rt *sqlremote) buildFieldsFromPg(tableName string, indexDetails []*pgIndexDetails) ([]*nemgen.Field, error) {
+func (rt *sqlremote) fetchPgColumnDetails(ctx context.Context, table *pgName) ([]*pgColumnDetails, error) {
 	columnsQuery := fmt.Sprintf(
-		`SELECT column_name,
+		`SELECT table_schema,
+				table_name,
+				column_name,
 				data_type,
 				column_default,
 				is_nullable,
 				character_maximum_length,
 				numeric_precision,
 				numeric_scale,
-				datetime_precision
+				datetime_precision,
+				udt_name,
+				udt_schema,
+				is_identity,
+				identity_generation,
+				is_generated,
+				generation_expression,
+				coalesce(col_description(format('%%I.%%I', table_schema, table_name)::regclass, ordinal_position::int), '') AS column_comment
 				FROM information_schema.columns
-				WHERE table_schema = '%s' 
-				AND table_name = '%s'
+				WHERE %s
 				ORDER BY ordinal_position;`,
-		rt.userConnection.DbSchema,
-		tableName,
+		rt.pgTableFilter("table_schema", "table_name", table),
 	)
 
-	sampleData, err := rt.sampleTableValues(tableName)
-	if err != nil {
-		return nil, err
-	}
-
 	var columnsDetails []*pgColumnDetails = []*pgColumnDetails{}
-	err = rt.db.Select(&columnsDetails, columnsQuery)
+	err := rt.db.SelectContext(ctx, &columnsDetails, columnsQuery)
 	if err != nil {
 		return nil, fmt.Errorf("error getting columns: %v", err)
 	}
+	return columnsDetails, nil
+}
+
+func pgColumnNames(columns []*pgColumnDetails) []string {
+	names := []string{}
+	for _, c := range columns {
+		names = append(names, c.Name)
+	}
+	return names
+}
 
+func (rt *sqlremote) buildFieldsFromPg(t *pgTable) ([]*nemgen.Field, error) {
 	fields := []*nemgen.Field{}
-	for _, columnDetails := range columnsDetails {
-		f := mapPgColumnDetailsToField(columnDetails, sampleData, indexDetails)
+	for _, columnDetails := range t.Columns {
+		enumType := pgName{Schema: columnDetails.UdtSchema, Name: pgEnumTypeName(columnDetails)}
+		columnDetails.EnumLabels = rt.pgEnumLabels[enumType]
+		f := mapPgColumnDetailsToField(columnDetails, t.SampleData, t.Indexes)
 		if f == nil {
 			continue
 		}
+		unsampled, _ := mapPgColumnDataTypeToFieldType(columnDetails, nil)
+		rt.promotions.check(t.Name.Schema+"."+t.Name.Name, columnDetails.Name, unsampled, f, t.SampleData)
+		rt.resolveEnum(f, enumType.Schema, enumType.Name, columnDetails.EnumLabels)
 		// Fail loudly on an unmapped column type rather than silently dropping the
 		// column: a missing column corrupts the introspected schema and makes the
 		// diff try to DROP a live column. Better to surface the unsupported type.
 		if f.Type == nemgen.FieldType_FIELD_TYPE_INVALID {
-			return nil, fmt.Errorf("unsupported postgres column type %q for %s.%s", columnDetails.DataType, tableName, columnDetails.Name)
+			return nil, fmt.Errorf("unsupported postgres column type %q for %s.%s", columnDetails.DataType, t.Name.Name, columnDetails.Name)
 		}
+		rt.computed.add(f.Uuid, pgComputed(columnDetails))
 		fields = append(fields, f)
 	}
 	return fields, nil
 }
 
-func (rt *sqlremote) fetchPgIndexDetails(tableName string) ([]*pgIndexDetails, error) {
+type pgEnumLabel struct {
+	SchemaName string `db:"schema_name"`
+	TypeName   string `db:"type_name"`
+	Label      string `db:"label"`
+}
+
+// fetchPgEnumLabels reads the labels of every enum type, in their declared
+// order. A table can use a type from any schema, introspected or not, so it
+// reads them all.
+func (rt *sqlremote) fetchPgEnumLabels(ctx context.Context) (map[pgName][]string, error) {
+	query := `
+		SELECT n.nspname AS schema_name,
+			t.typname AS type_name,
+			e.enumlabel AS label
+		FROM pg_catalog.pg_type t
+		JOIN pg_catalog.pg_enum e ON e.enumtypid = t.oid
+		JOIN pg_catalog.pg_namespace n ON n.oid = t.typnamespace
+		ORDER BY n.nspname, t.typname, e.enumsortorder;`
+
+	labels := []*pgEnumLabel{}
+	if err := rt.db.SelectContext(ctx, &labels, query); err != nil {
+		return nil, fmt.Errorf("error getting enum types: %v", err)
+	}
+	res := make(map[pgName][]string)
+	for _, l := range labels {
+		key := pgName{Schema: l.SchemaName, Name: l.TypeName}
+		res[key] = append(res[key], l.Label)
+	}
+	return res, nil
+}
+
+// pgEnumTypeName is the type a column would be an enum of: its own type, or
+// its element type if it is an array.
+func pgEnumTypeName(in *pgColumnDetails) string {
+	if strings.EqualFold(in.DataType, "array") {
+		return strings.TrimPrefix(in.UdtName, "_")
+	}
+	return in.UdtName
+}
+
+// fetchPgChecks reads a table's CHECK constraints. They come from
+// pg_constraint rather than information_schema.check_constraints, which also
+// lists every NOT NULL as a check.
+func (rt *sqlremote) fetchPgChecks(ctx context.Context, table *pgName) ([]*pgCheckDetails, error) {
+	query := fmt.Sprintf(`
+		SELECT nsp.nspname AS table_schema,
+			rel.relname AS table_name,
+			con.conname AS name,
+			pg_get_constraintdef(con.oid) AS definition
+		FROM pg_constraint con
+		JOIN pg_class rel ON rel.oid = con.conrelid
+		JOIN pg_namespace nsp ON nsp.oid = rel.relnamespace
+		WHERE con.contype = 'c'
+			AND %s
+		ORDER BY con.conname;`,
+		rt.pgTableFilter("nsp.nspname", "rel.relname", table),
+	)
+
+	details := []*pgCheckDetails{}
+	if err := rt.db.SelectContext(ctx, &details, query); err != nil {
+		return nil, fmt.Errorf("error getting check constraints: %v", err)
+	}
+	return details, nil
+}
+
+func pgChecks(details []*pgCheckDetails) []tosql.Check {
+	checks := []tosql.Check{}
+	for _, d := range details {
+		checks = append(checks, tosql.Check{Name: d.Name, Expression: checkExpression(d.Definition)})
+	}
+	return checks
+}
+
+// fetchPgTableComment is the table's COMMENT ON, empty if it has none.
+func (rt *sqlremote) fetchPgTableComment(ctx context.Context, table pgName) (string, error) {
+	query := fmt.Sprintf(`SELECT coalesce(obj_description(%s, 'pg_class'), '');`, table.regclass())
+	comments := []string{}
+	if err := rt.db.SelectContext(ctx, &comments, query); err != nil {
+		return "", fmt.Errorf("error getting table comment: %v", err)
+	}
+	if len(comments) == 0 {
+		return "", nil
+	}
+	return comments[0], nil
+}
+
+func (rt *sqlremote) fetchPgIndexDetails(ctx context.Context, table *pgName) ([]*pgIndexDetails, error) {
 	indexesQuery := fmt.Sprintf(`
-			SELECT distinct i.indexrelid::regclass AS index_name,                                    
+			SELECT distinct tn.nspname AS table_schema,
+				tc.relname AS table_name,
+				i.indexrelid::regclass AS index_name,                                    
 				k.i AS index_order,                                                                                                             
-				coalesce(a.attname,                                                     
-							(('{' || pg_get_expr(                                          
-										i.indexprs,                                        
-										i.indrelid                                         
-									)                                                     
-								|| '}')::text[]                                          
-							)[k.i]                                                         
-						) AS index_column,                                              
+				coalesce(a.attname, '') AS index_column,
+				CASE WHEN k.attnum = 0
+					THEN pg_get_indexdef(i.indexrelid, k.i::int, true)
+					ELSE ''
+				END AS index_expression,
 				i.indoption[k.i - 1] = 0 AS ascending,                                  
 				i.indisprimary AS is_key, 
-				i.indisunique as is_unique
+				i.indisunique as is_unique,
+				coalesce(pg_get_expr(i.indpred, i.indrelid, true), '') AS predicate,
+				am.amname AS method,
+				CASE WHEN opc.opcdefault THEN '' ELSE coalesce(opc.opcname, '') END AS op_class
 			FROM pg_index i                                                                
+			JOIN pg_class AS tc ON tc.oid = i.indrelid
+			JOIN pg_namespace AS tn ON tn.oid = tc.relnamespace
 			CROSS JOIN LATERAL unnest(i.indkey) WITH ORDINALITY AS k(attnum, i)         
 			LEFT JOIN pg_attribute AS a                                                 
 				ON i.indrelid = a.attrelid AND k.attnum = a.attnum                       
-			WHERE i.indrelid = '%s'::regclass;
+			JOIN pg_class AS ic ON ic.oid = i.indexrelid
+			JOIN pg_am AS am ON am.oid = ic.relam
+			LEFT JOIN pg_opclass AS opc ON opc.oid = i.indclass[k.i - 1]
+			WHERE %s;
 			`,
-		//rt.userConnection.DbSchema,
-		tableName)
+		rt.pgTableFilter("tn.nspname", "tc.relname", table))
 
 	var indexesDetails []*pgIndexDetails = []*pgIndexDetails{}
-	err := rt.db.Select(&indexesDetails, indexesQuery)
+	err := rt.db.SelectContext(ctx, &indexesDetails, indexesQuery)
 	if err != nil {
 		return nil, fmt.Errorf("error getting indexes: %v", err)
 	}
@@ -296,6 +574,7 @@ func (rt *sqlremote) buildIndexesFromPg(indexesDetails []*pgIndexDetails, fields
 		i := mapPgIndexDetailsToIndex(groupedDetails, fields)
 		if i != nil {
 			indexes = append(indexes, i)
+			rt.indexes.add(i.Uuid, pgIndexOptions(groupedDetails, fields))
 		}
 	}
 
@@ -320,7 +599,9 @@ func mapPgColumnDetailsToField(in *pgColumnDetails, sampleData remoteRows, index
 			if id.IsKey {
 				isKey = true
 			}
-			if id.IsUnique {
+			// a partial unique index leaves the column free to repeat outside
+			// the rows it covers
+			if id.IsUnique && id.Predicate == "" {
 				isUnique = true
 			}
 		}
@@ -330,6 +611,11 @@ func mapPgColumnDetailsToField(in *pgColumnDetails, sampleData remoteRows, index
 	// Same reason as the mysql side: a default the model does not carry is a
 	// column change the plan proposes forever.
 	def := pgColumnDefault(in.DefaultValue)
+	autoIncrement := isKey && fieldType == nemgen.FieldType_FIELD_TYPE_INTEGER && pgAutoIncrement(in)
+	if autoIncrement {
+		// the sequence is the key's, not a default the model should carry
+		def = columnDefault{}
+	}
 	return &nemgen.Field{
 		Uuid:                     uuid.Must(uuid.NewV4()).String(),
 		Version:                  time.Now().Unix(),
@@ -339,10 +625,24 @@ func mapPgColumnDetailsToField(in *pgColumnDetails, sampleData remoteRows, index
 		TypeConfig:               fieldTypeConfig,
 		Status:                   nemgen.FieldStatus_FIELD_STATUS_ACTIVE,
 		Key:                      isKey,
+		KeyAutoIncrement:         autoIncrement,
 		Unique:                   isUnique,
 		DefaultValue:             def.Value,
 		DefaultValueIsExpression: def.IsExpression,
+		Description:              in.Comment,
+	}
+}
+
+// pgAutoIncrement reports whether postgres fills the column in from a
+// sequence: an identity column, or a SERIAL one, which is an integer column
+// defaulting to the next value of a sequence it owns. An identity declared
+// ALWAYS is taken as BY DEFAULT, the one identity the model renders, which
+// only differs in letting an insert supply the key itself.
+func pgAutoIncrement(in *pgColumnDetails) bool {
+	if strings.EqualFold(in.IsIdentity, "YES") {
+		return true
 	}
+	return in.DefaultValue != nil && strings.HasPrefix(strings.TrimSpace(*in.DefaultValue), "nextval(")
 }
 
 func mapPgColumnDataTypeToFieldType(in *pgColumnDetails, sampleData remoteRows) (nemgen.FieldType, *nemgen.FieldTypeConfig) {
@@ -469,6 +769,15 @@ func mapPgColumnDataTypeToFieldType(in *pgColumnDetails, sampleData remoteRows)
 		}
 	case "time", "time without time zone", "time with time zone":
 		return nemgen.FieldType_FIELD_TYPE_TIME, nil
+	case "user-defined", "array":
+		// only enum types, and arrays of them, have a field type to map to
+		if len(in.EnumLabels) > 0 {
+			return nemgen.FieldType_FIELD_TYPE_ENUM, &nemgen.FieldTypeConfig{
+				Enum: &nemgen.FieldTypeEnumConfig{
+					AllowMultiple: dataType == "array",
+				},
+			}
+		}
 	}
 	return nemgen.FieldType_FIELD_TYPE_INVALID, nil
 
@@ -491,25 +800,25 @@ func mapPgIndexDetailsToIndex(in []*pgIndexDetails, fields []*nemgen.Field) *nem
 	first := in[0]
 
 	columns := []string{}
-	isColumnKey := make(map[string]bool)
-	isColumnUnique := make(map[string]bool)
+	hasExpressions := false
 	for _, id := range in {
 		columns = append(columns, id.ColumnName)
-		isColumnKey[id.ColumnName] = id.IsKey
-		isColumnUnique[id.ColumnName] = id.IsUnique
+		if id.Expression != "" {
+			hasExpressions = true
+		}
 	}
 
-	indexFields := make(map[string]*nemgen.Field)
 	indexType := nemgen.IndexType_INDEX_TYPE_INDEX
+	if first.IsKey {
+		indexType = nemgen.IndexType_INDEX_TYPE_PRIMARY
+	} else if first.IsUnique {
+		indexType = nemgen.IndexType_INDEX_TYPE_UNIQUE
+	}
+
+	indexFields := make(map[string]*nemgen.Field)
 	for _, f := range fields {
 		if slices.Contains(columns, f.Identifier) {
 			indexFields[f.Identifier] = f
-			if isColumnUnique[f.Identifier] {
-				indexType = nemgen.IndexType_INDEX_TYPE_UNIQUE
-			}
-			if isColumnKey[f.Identifier] {
-				indexType = nemgen.IndexType_INDEX_TYPE_PRIMARY
-			}
 		}
 	}
 
@@ -533,7 +842,8 @@ func mapPgIndexDetailsToIndex(in []*pgIndexDetails, fields []*nemgen.Field) *nem
 			Order:     order,
 		})
 	}
-	if len(finalIndexFields) == 0 {
+	// an index over expressions alone has its members in the index options
+	if len(finalIndexFields) == 0 && !hasExpressions {
 		return nil
 	}
 
@@ -546,22 +856,13 @@ func mapPgIndexDetailsToIndex(in []*pgIndexDetails, fields []*nemgen.Field) *nem
 	}
 }
 
-func mapPgFKDetailsToRelationship(in *pgForeignKeyDetails, tableName string, entities []*nemgen.Entity) *nemgen.Relationship {
-	if in == nil {
+// mapPgFKDetailsToRelationship maps a foreign key from a column of fromEntity
+// to one of toEntity. It returns nil if either column is not a field.
+func mapPgFKDetailsToRelationship(in *pgForeignKeyDetails, fromEntity *nemgen.Entity, toEntity *nemgen.Entity) *nemgen.Relationship {
+	if in == nil || fromEntity == nil || toEntity == nil {
 		return nil
 	}
 
-	var fromEntity *nemgen.Entity
-	var toEntity *nemgen.Entity
-	for _, e := range entities {
-		if e.Identifier == tableName {
-			fromEntity = e
-		}
-		if e.Identifier == in.ReferencedTableName {
-			toEntity = e
-		}
-	}
-
 	var fromField *nemgen.Field
 	for _, f := range fromEntity.Fields {
 		if f.Identifier == in.ColumnName {
@@ -577,6 +878,9 @@ func mapPgFKDetailsToRelationship(in *pgForeignKeyDetails, tableName string, ent
 			break
 		}
 	}
+	if fromField == nil || toField == nil {
+		return nil
+	}
 
 	return &nemgen.Relationship{
 		Uuid:       uuid.Must(uuid.NewV4()).String(),
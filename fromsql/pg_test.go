@@ -1,9 +1,12 @@
 package fromsql
 
 import (
+	"strings"
 	"testing"
 
 	nemgen "github.com/nuzur/nem/idl/gen"
+	"github.com/nuzur/sql-gen/db"
+	"github.com/nuzur/sql-gen/tosql"
 )
 
 // ptrInt64 is a small helper for the CharMax field.
@@ -100,3 +103,65 @@ func TestMapPgVarcharSamplingKeepsWidth(t *testing.T) {
 		})
 	}
 }
+
+// A SERIAL key and an identity key both come back auto-increment, without the
+// sequence as a default; a sequence default on any other column stays one.
+func TestPgSequenceKeysAreAutoIncrement(t *testing.T) {
+	pkey := []*pgIndexDetails{{Name: "counter_pkey", Seq: 1, ColumnName: "id", IsKey: true, IsUnique: true, Ascending: true}}
+	cases := []struct {
+		name string
+		in   *pgColumnDetails
+		want bool
+	}{
+		{"serial", &pgColumnDetails{Name: "id", DataType: "integer", IsNullable: "NO", IsIdentity: "NO",
+			DefaultValue: ptrString("nextval('counter_id_seq'::regclass)")}, true},
+		{"identity", &pgColumnDetails{Name: "id", DataType: "bigint", IsNullable: "NO", IsIdentity: "YES",
+			IdentityGeneration: ptrString("BY DEFAULT")}, true},
+		{"identity always", &pgColumnDetails{Name: "id", DataType: "bigint", IsNullable: "NO", IsIdentity: "YES",
+			IdentityGeneration: ptrString("ALWAYS")}, true},
+		{"plain", &pgColumnDetails{Name: "id", DataType: "bigint", IsNullable: "NO", IsIdentity: "NO"}, false},
+		{"not the key", &pgColumnDetails{Name: "seq", DataType: "bigint", IsNullable: "NO", IsIdentity: "NO",
+			DefaultValue: ptrString("nextval('counter_seq_seq'::regclass)")}, false},
+	}
+	for _, tc := range cases {
+		f := mapPgColumnDetailsToField(tc.in, remoteRows{}, pkey)
+		if f.GetKeyAutoIncrement() != tc.want {
+			t.Errorf("%s: KeyAutoIncrement = %v, want %v", tc.name, f.GetKeyAutoIncrement(), tc.want)
+		}
+		if tc.want && f.GetDefaultValue() != "" {
+			t.Errorf("%s: default = %q, want none", tc.name, f.GetDefaultValue())
+		}
+		if !tc.want && tc.in.DefaultValue != nil && f.GetDefaultValue() != *tc.in.DefaultValue {
+			t.Errorf("%s: default = %q, want the sequence kept", tc.name, f.GetDefaultValue())
+		}
+	}
+}
+
+// A serial key is rendered as an identity column, which postgres reports back
+// as one: the second introspection is the same as the first.
+func TestPgSequenceKeysAreAFixedPoint(t *testing.T) {
+	pkey := []*pgIndexDetails{{Name: "counter_pkey", Seq: 1, ColumnName: "id", IsKey: true, IsUnique: true, Ascending: true}}
+	serial := mapPgColumnDetailsToField(&pgColumnDetails{Name: "id", DataType: "integer", IsNullable: "NO", IsIdentity: "NO",
+		DefaultValue: ptrString("nextval('counter_id_seq'::regclass)")}, remoteRows{}, pkey)
+	entity := func(f *nemgen.Field) *nemgen.Entity {
+		return &nemgen.Entity{
+			Uuid:       "counter",
+			Identifier: "counter",
+			Fields:     []*nemgen.Field{f},
+			Type:       nemgen.EntityType_ENTITY_TYPE_STANDALONE,
+			Status:     nemgen.EntityStatus_ENTITY_STATUS_ACTIVE,
+			TypeConfig: &nemgen.EntityTypeConfig{Standalone: &nemgen.EntityTypeStandaloneConfig{}},
+		}
+	}
+	first := renderCreateSQLWithOptions(t, &nemgen.ProjectVersion{Entities: []*nemgen.Entity{entity(serial)}}, db.PGDBType, tosql.SchemaOptions{})
+	if !strings.Contains(first, `"id" INTEGER NOT NULL GENERATED BY DEFAULT AS IDENTITY`) {
+		t.Fatalf("the serial key did not render as an identity column:\n%s", first)
+	}
+
+	identity := mapPgColumnDetailsToField(&pgColumnDetails{Name: "id", DataType: "integer", IsNullable: "NO", IsIdentity: "YES",
+		IdentityGeneration: ptrString("BY DEFAULT")}, remoteRows{}, pkey)
+	second := renderCreateSQLWithOptions(t, &nemgen.ProjectVersion{Entities: []*nemgen.Entity{entity(identity)}}, db.PGDBType, tosql.SchemaOptions{})
+	if first != second {
+		t.Errorf("re-introspected identity key renders differently:\n%s\nvs\n%s", first, second)
+	}
+}
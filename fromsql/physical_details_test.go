@@ -377,7 +377,7 @@ func introspectedPgSchema(t *testing.T) *nemgen.ProjectVersion {
 		ConstraintName: "fk_child_parent", ColumnName: "parent_uuid",
 		ReferencedColumnName: "id", ReferencedTableName: "parent",
 		DeleteRule: "SET NULL", UpdateRule: "CASCADE",
-	}, "child", entities)
+	}, entities[0], entities[1])
 
 	return &nemgen.ProjectVersion{Entities: entities, Relationships: []*nemgen.Relationship{rel}}
 }
@@ -387,6 +387,11 @@ func introspectedPgSchema(t *testing.T) *nemgen.ProjectVersion {
 // renders when both of its endpoints are present.
 func renderCreateSQLForPV(t *testing.T, pv *nemgen.ProjectVersion, dbType db.DBType) string {
 	t.Helper()
+	return renderCreateSQLWithOptions(t, pv, dbType, tosql.SchemaOptions{})
+}
+
+func renderCreateSQLWithOptions(t *testing.T, pv *nemgen.ProjectVersion, dbType db.DBType, options tosql.SchemaOptions) string {
+	t.Helper()
 
 	entities := []string{}
 	for _, e := range pv.Entities {
@@ -396,9 +401,10 @@ func renderCreateSQLForPV(t *testing.T, pv *nemgen.ProjectVersion, dbType db.DBT
 		ExecutionUUID:  uuid.Must(uuid.NewV4()).String(),
 		ProjectVersion: pv,
 		Configvalues: &tosql.ConfigValues{
-			DBType:   dbType,
-			Entities: entities,
-			Actions:  []tosql.Action{tosql.CreateAction},
+			DBType:        dbType,
+			Entities:      entities,
+			Actions:       []tosql.Action{tosql.CreateAction},
+			SchemaOptions: options,
 		},
 	})
 	if err != nil {
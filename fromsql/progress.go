@@ -0,0 +1,125 @@
+package fromsql
+
+import (
+	"context"
+	"sync"
+
+	"golang.org/x/sync/errgroup"
+)
+
+// DefaultConcurrency is how many tables are introspected at once when
+// GenerateRequest.Concurrency is not set. Each one holds a connection while
+// its queries run.
+const DefaultConcurrency = 8
+
+type ProgressStage int
+
+const (
+	// TableDiscovered is reported for every table listed, before any is
+	// introspected.
+	TableDiscovered ProgressStage = iota
+	// TableIntrospected is reported once all of a table's queries returned.
+	TableIntrospected
+	// TableFailed is reported for the table whose query failed the import.
+	// The tables cancelled because of it are not reported.
+	TableFailed
+	// BulkFailed is reported, with no table, when the queries of a
+	// GenerateRequest.Bulk import failed and every table is read on its own
+	// instead.
+	BulkFailed
+)
+
+func (s ProgressStage) String() string {
+	switch s {
+	case TableDiscovered:
+		return "discovered"
+	case TableIntrospected:
+		return "introspected"
+	case TableFailed:
+		return "failed"
+	case BulkFailed:
+		return "bulk failed"
+	}
+	return "unknown"
+}
+
+// Progress is one step of an import, with the running totals after it.
+type Progress struct {
+	Stage ProgressStage
+	// Table is the table the step is about, qualified with its schema on
+	// postgres.
+	Table string
+	// Err is why the table, or the bulk queries, failed.
+	Err error
+
+	Discovered   int
+	Introspected int
+	Failed       int
+}
+
+// progressReporter keeps the totals and calls the request's Progress with
+// them, one call at a time.
+type progressReporter struct {
+	mu       sync.Mutex
+	report   func(Progress)
+	progress Progress
+}
+
+func (pr *progressReporter) add(stage ProgressStage, table string, err error) {
+	if pr.report == nil {
+		return
+	}
+	pr.mu.Lock()
+	defer pr.mu.Unlock()
+	switch stage {
+	case TableDiscovered:
+		pr.progress.Discovered++
+	case TableIntrospected:
+		pr.progress.Introspected++
+	case TableFailed:
+		pr.progress.Failed++
+	}
+	pr.progress.Stage = stage
+	pr.progress.Table = table
+	pr.progress.Err = err
+	pr.report(pr.progress)
+}
+
+// group is an errgroup running at most rt.concurrency functions at once.
+func (rt *sqlremote) group(ctx context.Context) (*errgroup.Group, context.Context) {
+	eg, egCtx := errgroup.WithContext(ctx)
+	if rt.concurrency > 0 {
+		eg.SetLimit(rt.concurrency)
+	} else {
+		eg.SetLimit(DefaultConcurrency)
+	}
+	return eg, egCtx
+}
+
+// introspectTables runs introspect for each of the tables, reporting their
+// progress. It starts none once one has failed.
+func (rt *sqlremote) introspectTables(ctx context.Context, tables []string, introspect func(ctx context.Context, n int) error) error {
+	for _, table := range tables {
+		rt.progress.add(TableDiscovered, table, nil)
+	}
+
+	eg, egCtx := rt.group(ctx)
+	for n, table := range tables {
+		if egCtx.Err() != nil {
+			break
+		}
+		eg.Go(func() error {
+			if err := introspect(egCtx, n); err != nil {
+				// the group cancels the others once this returns, so the
+				// first failure is the one the context is still live for
+				if egCtx.Err() == nil {
+					rt.progress.add(TableFailed, table, err)
+				}
+				return err
+			}
+			rt.progress.add(TableIntrospected, table, nil)
+			return nil
+		})
+	}
+	return eg.Wait()
+}
@@ -31,6 +31,8 @@ func promotionPreservesWidth(t nemgen.FieldType, dbType db.DBType, width int64)
 		rendered = tosql.FieldTypeToMYSQL(f)
 	case db.PGDBType:
 		rendered = tosql.FieldTypeToPG(f)
+	case db.SQLiteDBType:
+		rendered = tosql.FieldTypeToSQLite(f)
 	default:
 		return false
 	}
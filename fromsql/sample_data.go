@@ -1,23 +1,196 @@
 package fromsql
 
 import (
+	"context"
 	"fmt"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
 
+	nemgen "github.com/nuzur/nem/idl/gen"
 	"github.com/nuzur/sql-gen/db"
 )
 
-func (rt *sqlremote) sampleTableValues(name string) (remoteRows, error) {
-	// Get Data
-	query := ""
-	if rt.dbType == db.MYSQLDBType {
-		query = fmt.Sprintf("SELECT * FROM `%s` LIMIT 10", name)
-	} else if rt.dbType == db.PGDBType {
-		query = fmt.Sprintf(`SELECT * FROM "%s" LIMIT 10`, name)
+// DefaultSampleSize is how many rows are read from each table when
+// Sampling.Size is not set.
+const DefaultSampleSize = 10
+
+type SampleMethod string
+
+const (
+	// SampleFirst reads the first rows the database returns, which is cheap
+	// but always the same rows, often the oldest.
+	SampleFirst SampleMethod = ""
+	// SampleRandom orders the table at random, which reads the whole of it.
+	SampleRandom SampleMethod = "random"
+	// SampleTableSample reads a random Percent of the table's pages with
+	// postgres' TABLESAMPLE SYSTEM, which skips the rest of the table but may
+	// find no row at all in a small one. It is SampleRandom elsewhere.
+	SampleTableSample SampleMethod = "tablesample"
+)
+
+// Sampling is which rows introspection reads to refine a column's type past
+// its SQL type: a CHAR(36) whose values are all uuids is a uuid field, a
+// varchar of addresses an email field, and so on. The rows are real data, so
+// an import that must not read any can turn it off, at the cost of every
+// column keeping the type its SQL type alone gives it.
+type Sampling struct {
+	// Disabled reads no rows at all.
+	Disabled bool
+	// Size is how many rows are read from each table; zero is
+	// DefaultSampleSize.
+	Size int
+	// Method is how the rows are picked.
+	Method SampleMethod
+	// Percent is the share of the table SampleTableSample reads; zero is 1.
+	Percent float64
+	// Columns limits sampling to the columns named, each as column, which
+	// matches it in every table, or as table.column. A postgres table may be
+	// qualified with its schema too. A table none of whose columns are named
+	// is not read.
+	Columns []string
+}
+
+// columns is what to select of a table sampled, all of it if nil. ok is false
+// if nothing of it is.
+func (s Sampling) columns(schema string, table string, all []string) (columns []string, ok bool) {
+	if len(s.Columns) == 0 {
+		return nil, true
+	}
+	for _, column := range all {
+		for _, name := range s.Columns {
+			if name == column || name == table+"."+column || (schema != "" && name == schema+"."+table+"."+column) {
+				columns = append(columns, column)
+				break
+			}
+		}
+	}
+	return columns, len(columns) > 0
+}
+
+// sampleRows reads the rows of the table the request's Sampling asks for.
+// schema is empty but for postgres.
+func (rt *sqlremote) sampleRows(ctx context.Context, schema string, table string, columns []string) (remoteRows, error) {
+	s := rt.sampling
+	if s.Disabled {
+		return nil, nil
+	}
+	columns, ok := s.columns(schema, table, columns)
+	if !ok {
+		return nil, nil
+	}
+
+	// a quote in the identifier is doubled; sqlite quotes as postgres does
+	quote := func(identifier string) string {
+		if rt.dbType == db.MYSQLDBType {
+			return "`" + strings.ReplaceAll(identifier, "`", "``") + "`"
+		}
+		return pgQuoteIdentifier(identifier)
+	}
+	selected := "*"
+	if columns != nil {
+		quoted := []string{}
+		for _, c := range columns {
+			quoted = append(quoted, quote(c))
+		}
+		selected = strings.Join(quoted, ", ")
+	}
+	from := quote(table)
+	if schema != "" {
+		from = pgName{Schema: schema, Name: table}.quoted()
+	}
+	size := s.Size
+	if size <= 0 {
+		size = DefaultSampleSize
 	}
 
-	data, err := rt.db.QueryMaps(query)
+	query := fmt.Sprintf("SELECT %s FROM %s LIMIT %d", selected, from, size)
+	switch {
+	case s.Method == SampleTableSample && rt.dbType == db.PGDBType:
+		percent := s.Percent
+		if percent <= 0 {
+			percent = 1
+		}
+		query = fmt.Sprintf("SELECT %s FROM %s TABLESAMPLE SYSTEM (%g) LIMIT %d", selected, from, percent, size)
+	case s.Method == SampleRandom || s.Method == SampleTableSample:
+		random := "random()"
+		if rt.dbType == db.MYSQLDBType {
+			random = "RAND()"
+		}
+		query = fmt.Sprintf("SELECT %s FROM %s ORDER BY %s LIMIT %d", selected, from, random, size)
+	}
+
+	data, err := rt.db.QueryMapsContext(ctx, query)
 	if err != nil {
 		return nil, fmt.Errorf("error getting sample data: %v | query:  %v", err, query)
 	}
 	return data, nil
 }
+
+// Promotion is a column typed by the rows sampled from it rather than by its
+// SQL type alone.
+type Promotion struct {
+	// Table is qualified with its schema on postgres.
+	Table  string
+	Column string
+	// From is the type the SQL type alone maps to, and To the type the column
+	// was given.
+	From nemgen.FieldType
+	To   nemgen.FieldType
+	// Rows is how many rows were sampled, and Values how many of them held a
+	// value in the column, every one of which looked like a To.
+	Rows   int
+	Values int
+}
+
+func (p Promotion) String() string {
+	return fmt.Sprintf("%s.%s promoted from %s to %s: %d values in %d sampled rows",
+		p.Table, p.Column, p.From, p.To, p.Values, p.Rows)
+}
+
+// introspectedPromotions collects the promotions of the tables seen so far.
+// The tables are introspected concurrently, so it locks.
+type introspectedPromotions struct {
+	mu         sync.Mutex
+	promotions []Promotion
+}
+
+// check records a promotion if the field's type is not the one its SQL type
+// alone, unsampled, maps to.
+func (ip *introspectedPromotions) check(table string, column string, unsampled nemgen.FieldType, f *nemgen.Field, sampleData remoteRows) {
+	if f == nil || f.Type == unsampled {
+		return
+	}
+	values := 0
+	for _, r := range sampleData {
+		if r[column] != nil {
+			values++
+		}
+	}
+
+	ip.mu.Lock()
+	defer ip.mu.Unlock()
+	ip.promotions = append(ip.promotions, Promotion{
+		Table:  table,
+		Column: column,
+		From:   unsampled,
+		To:     f.Type,
+		Rows:   len(sampleData),
+		Values: values,
+	})
+}
+
+func (ip *introspectedPromotions) list() []Promotion {
+	ip.mu.Lock()
+	defer ip.mu.Unlock()
+
+	promotions := slices.Clone(ip.promotions)
+	sort.Slice(promotions, func(a, b int) bool {
+		if promotions[a].Table != promotions[b].Table {
+			return promotions[a].Table < promotions[b].Table
+		}
+		return promotions[a].Column < promotions[b].Column
+	})
+	return promotions
+}
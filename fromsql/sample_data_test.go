@@ -0,0 +1,60 @@
+package fromsql
+
+import (
+	"context"
+	"testing"
+
+	"github.com/nuzur/sql-gen/db"
+)
+
+func TestSampleQueries(t *testing.T) {
+	tests := []struct {
+		dbType   db.DBType
+		schema   string
+		sampling Sampling
+		want     string
+	}{
+		{db.MYSQLDBType, "", Sampling{}, "SELECT * FROM `lot` LIMIT 10"},
+		{db.MYSQLDBType, "", Sampling{Method: SampleTableSample, Size: 5}, "SELECT * FROM `lot` ORDER BY RAND() LIMIT 5"},
+		{db.PGDBType, "stock", Sampling{Method: SampleTableSample}, `SELECT * FROM "stock"."lot" TABLESAMPLE SYSTEM (1) LIMIT 10`},
+		{db.PGDBType, "stock", Sampling{Method: SampleTableSample, Percent: 0.5, Columns: []string{"stock.lot.qty"}}, `SELECT "qty" FROM "stock"."lot" TABLESAMPLE SYSTEM (0.5) LIMIT 10`},
+		{db.PGDBType, "stock", Sampling{Method: SampleRandom}, `SELECT * FROM "stock"."lot" ORDER BY random() LIMIT 10`},
+	}
+	for _, tt := range tests {
+		scripted := &scriptedDB{responses: []scriptedResponse{{"SELECT", []map[string]interface{}{}}}}
+		rt := New(GenerateRequest{DB: scripted, DBType: tt.dbType, Sampling: tt.sampling})
+		if _, err := rt.sampleRows(context.Background(), tt.schema, "lot", []string{"id", "qty"}); err != nil {
+			t.Fatal(err)
+		}
+		if len(scripted.queries) != 1 || scripted.queries[0] != tt.want {
+			t.Errorf("%s %+v sampled with %q, want %q", tt.dbType, tt.sampling, scripted.queries, tt.want)
+		}
+	}
+}
+
+func TestSampleQueriesEscapeQuotes(t *testing.T) {
+	tests := []struct {
+		dbType db.DBType
+		schema string
+		column string
+		want   string
+	}{
+		{db.MYSQLDBType, "", "odd`col", "SELECT `odd``col` FROM `lot``s` LIMIT 10"},
+		{db.SQLiteDBType, "", `say "hi"`, "SELECT \"say \"\"hi\"\"\" FROM \"lot`s\" LIMIT 10"},
+		{db.PGDBType, "stock", `say "hi"`, "SELECT \"say \"\"hi\"\"\" FROM \"stock\".\"lot`s\" LIMIT 10"},
+	}
+	for _, tt := range tests {
+		named := "lot`s." + tt.column
+		if tt.schema != "" {
+			named = tt.schema + "." + named
+		}
+		scripted := &scriptedDB{responses: []scriptedResponse{{"SELECT", []map[string]interface{}{}}}}
+		rt := New(GenerateRequest{DB: scripted, DBType: tt.dbType, Sampling: Sampling{Columns: []string{named}}})
+		if _, err := rt.sampleRows(context.Background(), tt.schema, "lot`s", []string{tt.column}); err != nil {
+			t.Fatal(err)
+		}
+		if len(scripted.queries) != 1 || scripted.queries[0] != tt.want {
+			t.Errorf("%s sampled with %q, want %q", tt.dbType, scripted.queries, tt.want)
+		}
+	}
+}
@@ -0,0 +1,125 @@
+package fromsql
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+)
+
+// pgName is a postgres table or type together with the schema it is in.
+type pgName struct {
+	Schema string `db:"schema_name"`
+	Name   string `db:"name"`
+}
+
+// quoted is the name as an identifier: "billing"."invoice".
+func (n pgName) quoted() string {
+	return fmt.Sprintf("%s.%s", pgQuoteIdentifier(n.Schema), pgQuoteIdentifier(n.Name))
+}
+
+// regclass is the table as a regclass literal. A bare table name would be
+// resolved through the connection's search_path rather than the schema the
+// table was found in.
+func (n pgName) regclass() string {
+	return fmt.Sprintf("'%s'::regclass", strings.ReplaceAll(n.quoted(), "'", "''"))
+}
+
+func pgQuoteIdentifier(identifier string) string {
+	return `"` + strings.ReplaceAll(identifier, `"`, `""`) + `"`
+}
+
+// pgSchemaFilter is the condition on a schema name column selecting the
+// schemas to introspect: the ones the request lists, every schema that is not
+// postgres' own, or else the connection's.
+func (rt *sqlremote) pgSchemaFilter(column string) string {
+	if rt.allSchemas {
+		return fmt.Sprintf(`%s NOT IN ('pg_catalog', 'information_schema') AND %s NOT LIKE 'pg\_%%'`, column, column)
+	}
+	schemas := rt.schemas
+	if len(schemas) == 0 {
+		schemas = []string{rt.dbSchema}
+	}
+	literals := []string{}
+	for _, s := range schemas {
+		literals = append(literals, fmt.Sprintf("'%s'", strings.ReplaceAll(s, "'", "''")))
+	}
+	return fmt.Sprintf("%s IN (%s)", column, strings.Join(literals, ", "))
+}
+
+// pgSchemaIncluded reports whether a schema is one pgSchemaFilter selects.
+func (rt *sqlremote) pgSchemaIncluded(schema string) bool {
+	if rt.allSchemas {
+		return schema != "pg_catalog" && schema != "information_schema" && !strings.HasPrefix(schema, "pg_")
+	}
+	if len(rt.schemas) == 0 {
+		return schema == rt.dbSchema
+	}
+	return slices.Contains(rt.schemas, schema)
+}
+
+func (rt *sqlremote) getPgTables(ctx context.Context) ([]pgName, error) {
+	query := fmt.Sprintf(`
+		SELECT schemaname AS schema_name,
+			tablename AS name
+		FROM pg_catalog.pg_tables
+		WHERE %s
+		ORDER BY schemaname, tablename;`,
+		rt.pgSchemaFilter("schemaname"),
+	)
+
+	tables := []pgName{}
+	if err := rt.db.SelectContext(ctx, &tables, query); err != nil {
+		return nil, fmt.Errorf("error getting table names: %v", err)
+	}
+	return tables, nil
+}
+
+// introspectedSchemas collects the postgres schema of each table and enum type
+// that has to be named with it, by the uuid of the entity or enum introspected
+// from it: all of them once the request names the schemas to read, and
+// otherwise only those outside the connection's schema. They are handed back
+// as tosql.SchemaOptions.Schemas.
+type introspectedSchemas struct {
+	mu     sync.Mutex
+	byUuid map[string]string
+}
+
+func (is *introspectedSchemas) add(uuid string, schema string) {
+	is.mu.Lock()
+	defer is.mu.Unlock()
+
+	if is.byUuid == nil {
+		is.byUuid = make(map[string]string)
+	}
+	is.byUuid[uuid] = schema
+}
+
+func (is *introspectedSchemas) list() map[string]string {
+	is.mu.Lock()
+	defer is.mu.Unlock()
+
+	return is.byUuid
+}
+
+// placeInSchema records the schema of an introspected table or type, if it
+// has to be named with it.
+func (rt *sqlremote) placeInSchema(uuid string, schema string) {
+	if schema = rt.qualifiedSchema(schema); schema != "" {
+		rt.pgSchemas.add(uuid, schema)
+	}
+}
+
+// qualifiedSchema is the schema to name something in, or empty if it is to go
+// unqualified.
+func (rt *sqlremote) qualifiedSchema(schema string) string {
+	if !rt.qualifySchemas() && schema == rt.dbSchema {
+		return ""
+	}
+	return schema
+}
+
+func (rt *sqlremote) qualifySchemas() bool {
+	return rt.allSchemas || len(rt.schemas) > 0
+}
@@ -0,0 +1,106 @@
+package fromsql
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/gofrs/uuid"
+	nemgen "github.com/nuzur/nem/idl/gen"
+	"github.com/nuzur/sql-gen/db"
+	"github.com/nuzur/sql-gen/tosql"
+)
+
+func TestPgNameRegclass(t *testing.T) {
+	cases := map[pgName]string{
+		{Schema: "public", Name: "invoice"}:     `'"public"."invoice"'::regclass`,
+		{Schema: "Billing", Name: "line item"}:  `'"Billing"."line item"'::regclass`,
+		{Schema: "o'hara", Name: `say "hi"`}:    `'"o''hara"."say ""hi"""'::regclass`,
+		{Schema: "billing", Name: "invoice.v2"}: `'"billing"."invoice.v2"'::regclass`,
+	}
+	for name, want := range cases {
+		if got := name.regclass(); got != want {
+			t.Errorf("%+v.regclass() = %s, want %s", name, got, want)
+		}
+	}
+}
+
+func TestPgSchemaFilter(t *testing.T) {
+	cases := []struct {
+		rt   *sqlremote
+		want string
+	}{
+		{&sqlremote{dbSchema: "public"}, "schemaname IN ('public')"},
+		{&sqlremote{schemas: []string{"billing", "crm"}}, "schemaname IN ('billing', 'crm')"},
+		{&sqlremote{allSchemas: true}, `schemaname NOT IN ('pg_catalog', 'information_schema') AND schemaname NOT LIKE 'pg\_%'`},
+	}
+	for _, tc := range cases {
+		if got := tc.rt.pgSchemaFilter("schemaname"); got != tc.want {
+			t.Errorf("pgSchemaFilter() = %s, want %s", got, tc.want)
+		}
+	}
+}
+
+// Reading the connection's own schema leaves the names as they were; naming
+// the schemas to read qualifies them all.
+func TestOnlyRequestedOrForeignSchemasAreRecorded(t *testing.T) {
+	rt := &sqlremote{dbSchema: "public"}
+	rt.placeInSchema("invoice", "public")
+	rt.placeInSchema("status", "types")
+	if got := rt.pgSchemas.list(); len(got) != 1 || got["status"] != "types" {
+		t.Errorf("default schema: recorded %v, want only the type outside public", got)
+	}
+
+	rt = &sqlremote{dbSchema: "public", schemas: []string{"public", "billing"}}
+	rt.placeInSchema("invoice", "billing")
+	rt.placeInSchema("customer", "public")
+	if got := rt.pgSchemas.list(); len(got) != 2 || got["customer"] != "public" {
+		t.Errorf("requested schemas: recorded %v, want both tables", got)
+	}
+}
+
+// A foreign key into another schema keeps pointing there once the project
+// version is rendered again.
+func TestPgForeignKeysAcrossSchemas(t *testing.T) {
+	rt := &sqlremote{schemas: []string{"billing", "crm"}}
+	table := func(schema string, name string, columns ...*pgColumnDetails) *nemgen.Entity {
+		fields := []*nemgen.Field{}
+		for _, c := range columns {
+			fields = append(fields, mapPgColumnDetailsToField(c, nil, []*pgIndexDetails{
+				{Name: name + "_pkey", Seq: 1, ColumnName: "id", IsKey: true, IsUnique: true, Ascending: true},
+			}))
+		}
+		e := &nemgen.Entity{
+			Uuid:       uuid.Must(uuid.NewV4()).String(),
+			Identifier: name,
+			Fields:     fields,
+			Type:       nemgen.EntityType_ENTITY_TYPE_STANDALONE,
+			Status:     nemgen.EntityStatus_ENTITY_STATUS_ACTIVE,
+			TypeConfig: &nemgen.EntityTypeConfig{Standalone: &nemgen.EntityTypeStandaloneConfig{}},
+		}
+		rt.placeInSchema(e.Uuid, schema)
+		return e
+	}
+	id := &pgColumnDetails{Name: "id", DataType: "uuid", IsNullable: "NO", UdtName: "uuid"}
+	customer := table("crm", "customer", id)
+	invoice := table("billing", "invoice", id,
+		&pgColumnDetails{Name: "customer_id", DataType: "uuid", IsNullable: "NO", UdtName: "uuid"})
+
+	rel := mapPgFKDetailsToRelationship(&pgForeignKeyDetails{
+		ConstraintName: "invoice_customer_fk", ColumnName: "customer_id",
+		ReferencedTableSchema: "crm", ReferencedTableName: "customer", ReferencedColumnName: "id",
+	}, invoice, customer)
+	if rel == nil {
+		t.Fatal("the foreign key did not map to a relationship")
+	}
+
+	pv := &nemgen.ProjectVersion{Entities: []*nemgen.Entity{invoice, customer}, Relationships: []*nemgen.Relationship{rel}}
+	got := renderCreateSQLWithOptions(t, pv, db.PGDBType, tosql.SchemaOptions{Schemas: rt.pgSchemas.list()})
+	for _, want := range []string{
+		`CREATE TABLE IF NOT EXISTS "billing"."invoice" (`,
+		`REFERENCES "crm"."customer" ("id")`,
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("re-rendered DDL is missing %s\n%s", want, got)
+		}
+	}
+}
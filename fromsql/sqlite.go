@@ -0,0 +1,755 @@
+package fromsql
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gofrs/uuid"
+	nemgen "github.com/nuzur/nem/idl/gen"
+	"github.com/nuzur/sql-gen/db"
+	"github.com/nuzur/sql-gen/tosql"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// sqliteColumnDetails is one row of PRAGMA table_info.
+type sqliteColumnDetails struct {
+	Name string `db:"name"`
+	// DeclaredType is the type exactly as the CREATE TABLE spelled it —
+	// "VARCHAR(255)", "DECIMAL(38,9)", or "" for a column declared without one.
+	// sqlite keeps no other type information.
+	DeclaredType string `db:"type"`
+	NotNull      bool   `db:"notnull"`
+	// DefaultValue is the DEFAULT clause's text as written, quotes and
+	// parentheses included; NULL when there is none.
+	DefaultValue *string `db:"dflt_value"`
+	// PrimaryKey is the column's 1-based position in the primary key, 0 when it
+	// is not part of it.
+	PrimaryKey int64 `db:"pk"`
+}
+
+// sqliteIndexDetails is one key column of one index: PRAGMA index_list joined
+// to PRAGMA index_xinfo.
+type sqliteIndexDetails struct {
+	Name string `db:"index_name"`
+	Seq  int64  `db:"index_order"`
+	// ColumnName is NULL for an expression key part.
+	ColumnName sql.NullString `db:"index_column"`
+	IsUnique   bool           `db:"is_unique"`
+	// Origin is "c" for CREATE INDEX, "u" for a UNIQUE constraint and "pk" for
+	// the primary key.
+	Origin     string `db:"origin"`
+	Descending bool   `db:"is_desc"`
+}
+
+// sqliteForeignKeyDetails is one row of PRAGMA foreign_key_list. A multi-column
+// foreign key is one row per column, sharing ID.
+type sqliteForeignKeyDetails struct {
+	ID                  int64  `db:"id"`
+	Seq                 int64  `db:"seq"`
+	ReferencedTableName string `db:"referenced_table_name"`
+	ColumnName          string `db:"column_name"`
+	// ReferencedColumnName is NULL when the constraint references the parent's
+	// primary key implicitly (`REFERENCES parent` with no column list).
+	ReferencedColumnName sql.NullString `db:"referenced_column_name"`
+	// DeleteRule / UpdateRule are "NO ACTION" for a constraint created without
+	// an explicit clause, like on the other engines.
+	DeleteRule string `db:"delete_rule"`
+	UpdateRule string `db:"update_rule"`
+}
+
+func (rt *sqlremote) buildProjectVersionFromSQLite(ctx context.Context) (*nemgen.ProjectVersion, error) {
+	tableNames, err := rt.getTableNames(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	rt.views, err = rt.fetchSQLiteViews(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	mu := &sync.Mutex{}
+	entities := []*nemgen.Entity{}
+	err = rt.introspectTables(ctx, tableNames, func(ctx context.Context, n int) error {
+		e, err := rt.buildEntityFromSQLite(ctx, tableNames[n])
+		if err != nil {
+			return err
+		}
+		mu.Lock()
+		entities = append(entities, e)
+		mu.Unlock()
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// Same reason as the other engines: completion order is not stable, and
+	// tosql's topological sort breaks ties by input order.
+	sort.Slice(entities, func(a, b int) bool {
+		return entities[a].Identifier < entities[b].Identifier
+	})
+
+	eg, egCtx := rt.group(ctx)
+	relationships := []*nemgen.Relationship{}
+	for _, e := range entities {
+		eg.Go(func() error {
+			rels, err := rt.buildRelationshipsFromSQLite(egCtx, e.Identifier, entities)
+			if err != nil {
+				return err
+			}
+			mu.Lock()
+			relationships = append(relationships, rels...)
+			mu.Unlock()
+			return nil
+		})
+	}
+	err = eg.Wait()
+	if err != nil {
+		return nil, err
+	}
+	sort.Slice(relationships, func(a, b int) bool {
+		return relationships[a].Identifier < relationships[b].Identifier
+	})
+
+	return &nemgen.ProjectVersion{
+		Uuid:          uuid.Must(uuid.NewV4()).String(),
+		Version:       time.Now().Unix(),
+		Entities:      entities,
+		Status:        nemgen.ProjectVersionStatus_PROJECT_VERSION_STATUS_ACTIVE,
+		Relationships: relationships,
+	}, nil
+}
+
+func (rt *sqlremote) buildRelationshipsFromSQLite(ctx context.Context, tableName string, entities []*nemgen.Entity) ([]*nemgen.Relationship, error) {
+	foreignKeysQuery := fmt.Sprintf(`
+		SELECT
+			id,
+			seq,
+			"table" AS referenced_table_name,
+			"from" AS column_name,
+			"to" AS referenced_column_name,
+			on_delete AS delete_rule,
+			on_update AS update_rule
+		FROM pragma_foreign_key_list(%s)
+		ORDER BY id, seq;`,
+		sqliteQuoteLiteral(tableName),
+	)
+
+	var fkDetails []*sqliteForeignKeyDetails = []*sqliteForeignKeyDetails{}
+	err := rt.db.SelectContext(ctx, &fkDetails, foreignKeysQuery)
+	if err != nil {
+		return nil, fmt.Errorf("error getting constraint details: %v", err)
+	}
+	if len(fkDetails) == 0 {
+		return []*nemgen.Relationship{}, nil
+	}
+
+	names, err := rt.sqliteForeignKeyConstraintNames(ctx, tableName)
+	if err != nil {
+		return nil, err
+	}
+
+	// group the columns of each foreign key
+	grouped := make(map[int64][]*sqliteForeignKeyDetails)
+	ids := []int64{}
+	for _, fkd := range fkDetails {
+		if _, found := grouped[fkd.ID]; !found {
+			ids = append(ids, fkd.ID)
+		}
+		grouped[fkd.ID] = append(grouped[fkd.ID], fkd)
+	}
+
+	rels := []*nemgen.Relationship{}
+	for _, id := range ids {
+		group := grouped[id]
+		columns := []string{}
+		for _, fkd := range group {
+			columns = append(columns, fkd.ColumnName)
+		}
+		name, found := names[strings.Join(columns, ",")]
+		if !found {
+			name = fmt.Sprintf("%s_%s_fkey", tableName, strings.Join(columns, "_"))
+		}
+		if rel := mapSQLiteFKDetailsToRelationship(group, name, tableName, entities); rel != nil {
+			rels = append(rels, rel)
+		}
+	}
+
+	return rels, nil
+}
+
+// sqliteForeignKeyConstraint matches a named table-level foreign key in a
+// CREATE TABLE statement, capturing the name and the column list.
+var sqliteForeignKeyConstraint = regexp.MustCompile("(?is)CONSTRAINT\\s+(\"(?:[^\"]|\"\")+\"|`[^`]+`|\\[[^\\]]+\\]|\\w+)\\s+FOREIGN\\s+KEY\\s*\\(([^)]*)\\)")
+
+// sqliteForeignKeyConstraintNames maps the column list of each named foreign key
+// on a table ("a,b") to the constraint's name.
+//
+// PRAGMA foreign_key_list reports everything about a foreign key except its
+// name, and the catalog keeps no other record of it: the only place it survives
+// is the CREATE TABLE text in sqlite_master. Without it every relationship would
+// come back under an invented name, and re-rendering the schema would rename
+// each constraint.
+func (rt *sqlremote) sqliteForeignKeyConstraintNames(ctx context.Context, tableName string) (map[string]string, error) {
+	statement, err := rt.sqliteTableSQL(ctx, tableName)
+	if err != nil {
+		return nil, err
+	}
+
+	res := make(map[string]string)
+	for _, match := range sqliteForeignKeyConstraint.FindAllStringSubmatch(statement, -1) {
+		columns := []string{}
+		for _, c := range strings.Split(match[2], ",") {
+			columns = append(columns, sqliteUnquoteIdentifier(c))
+		}
+		res[strings.Join(columns, ",")] = sqliteUnquoteIdentifier(match[1])
+	}
+	return res, nil
+}
+
+func (rt *sqlremote) fetchSQLiteViews(ctx context.Context) ([]tosql.View, error) {
+	query := "SELECT name, sql AS definition FROM sqlite_master WHERE type = 'view' ORDER BY name;"
+
+	details := []*viewDetails{}
+	if err := rt.db.SelectContext(ctx, &details, query); err != nil {
+		return nil, fmt.Errorf("error getting views: %v", err)
+	}
+	for _, d := range details {
+		d.Definition = sqliteViewDefinition(d.Definition)
+	}
+	return rt.mapViews(details), nil
+}
+
+// sqliteTableSQL is the CREATE TABLE statement sqlite_master keeps for a table.
+func (rt *sqlremote) sqliteTableSQL(ctx context.Context, tableName string) (string, error) {
+	query := fmt.Sprintf(`SELECT sql FROM sqlite_master WHERE type = 'table' AND name = %s;`, sqliteQuoteLiteral(tableName))
+
+	statements := []string{}
+	if err := rt.db.SelectContext(ctx, &statements, query); err != nil {
+		return "", fmt.Errorf("error getting table definition: %v", err)
+	}
+	return strings.Join(statements, "\n"), nil
+}
+
+func (rt *sqlremote) buildEntityFromSQLite(ctx context.Context, tableName string) (*nemgen.Entity, error) {
+
+	columnsDetails, err := rt.fetchSQLiteColumnDetails(ctx, tableName)
+	if err != nil {
+		return nil, err
+	}
+
+	indexDetails, err := rt.fetchSQLiteIndexDetails(ctx, tableName)
+	if err != nil {
+		return nil, err
+	}
+
+	fields, err := rt.buildFieldsFromSQLite(ctx, tableName, columnsDetails, indexDetails)
+	if err != nil {
+		return nil, err
+	}
+
+	indexes, err := rt.buildIndexesFromSQLite(tableName, columnsDetails, indexDetails, fields)
+	if err != nil {
+		return nil, err
+	}
+
+	statement, err := rt.sqliteTableSQL(ctx, tableName)
+	if err != nil {
+		return nil, err
+	}
+
+	e := &nemgen.Entity{
+		Uuid:       uuid.Must(uuid.NewV4()).String(),
+		Version:    time.Now().Unix(),
+		Identifier: tableName,
+		Fields:     fields,
+		Type:       nemgen.EntityType_ENTITY_TYPE_STANDALONE,
+		TypeConfig: &nemgen.EntityTypeConfig{
+			Standalone: &nemgen.EntityTypeStandaloneConfig{
+				Indexes: indexes,
+			},
+		},
+		Status: nemgen.EntityStatus_ENTITY_STATUS_ACTIVE,
+	}
+	rt.checks.add(e.Uuid, sqliteChecks(statement))
+	return e, nil
+
+}
+
+func (rt *sqlremote) fetchSQLiteColumnDetails(ctx context.Context, tableName string) ([]*sqliteColumnDetails, error) {
+	columnsQuery := fmt.Sprintf(
+		`SELECT name, type, "notnull", dflt_value, pk
+				FROM pragma_table_info(%s)
+				ORDER BY cid;`,
+		sqliteQuoteLiteral(tableName),
+	)
+
+	var columnsDetails []*sqliteColumnDetails = []*sqliteColumnDetails{}
+	err := rt.db.SelectContext(ctx, &columnsDetails, columnsQuery)
+	if err != nil {
+		return nil, fmt.Errorf("error getting columns: %v", err)
+	}
+	return columnsDetails, nil
+}
+
+func (rt *sqlremote) buildFieldsFromSQLite(ctx context.Context, tableName string, columnsDetails []*sqliteColumnDetails, indexDetails []*sqliteIndexDetails) ([]*nemgen.Field, error) {
+	names := []string{}
+	for _, c := range columnsDetails {
+		names = append(names, c.Name)
+	}
+	sampleData, err := rt.sampleRows(ctx, "", tableName, names)
+	if err != nil {
+		return nil, err
+	}
+
+	fields := []*nemgen.Field{}
+	for _, columnDetails := range columnsDetails {
+		f := mapSQLiteColumnDetailsToField(columnDetails, sampleData, indexDetails)
+		if f == nil {
+			continue
+		}
+		unsampled, _ := mapSQLiteColumnDataTypeToFieldType(columnDetails, nil)
+		rt.promotions.check(tableName, columnDetails.Name, unsampled, f, sampleData)
+		// Same rule as postgres: an unmapped column fails the introspection
+		// rather than vanishing from it.
+		if f.Type == nemgen.FieldType_FIELD_TYPE_INVALID {
+			return nil, fmt.Errorf("unsupported sqlite column type %q for %s.%s", columnDetails.DeclaredType, tableName, columnDetails.Name)
+		}
+		fields = append(fields, f)
+	}
+	return fields, nil
+}
+
+func (rt *sqlremote) fetchSQLiteIndexDetails(ctx context.Context, tableName string) ([]*sqliteIndexDetails, error) {
+	indexesQuery := fmt.Sprintf(`
+			SELECT il.name AS index_name,
+				ii.seqno AS index_order,
+				ii.name AS index_column,
+				il."unique" AS is_unique,
+				il.origin AS origin,
+				ii."desc" AS is_desc
+			FROM pragma_index_list(%s) AS il
+			JOIN pragma_index_xinfo(il.name) AS ii
+			WHERE ii.key = 1
+			ORDER BY il.name, ii.seqno;
+			`,
+		sqliteQuoteLiteral(tableName))
+
+	var indexesDetails []*sqliteIndexDetails = []*sqliteIndexDetails{}
+	err := rt.db.SelectContext(ctx, &indexesDetails, indexesQuery)
+	if err != nil {
+		return nil, fmt.Errorf("error getting indexes: %v", err)
+	}
+	return indexesDetails, nil
+}
+
+func (rt *sqlremote) buildIndexesFromSQLite(tableName string, columnsDetails []*sqliteColumnDetails, indexesDetails []*sqliteIndexDetails, fields []*nemgen.Field) ([]*nemgen.Index, error) {
+
+	// group indexes by name
+	groupedIndexesDetails := make(map[string][]*sqliteIndexDetails)
+	for _, indexDetails := range indexesDetails {
+		// The primary key is read from table_info instead: an INTEGER PRIMARY
+		// KEY is the rowid itself and has no index at all to find here.
+		if indexDetails.Origin == "pk" {
+			continue
+		}
+		groupedIndexesDetails[indexDetails.Name] = append(groupedIndexesDetails[indexDetails.Name], indexDetails)
+	}
+
+	indexes := []*nemgen.Index{}
+	if i := sqlitePrimaryIndex(columnsDetails, fields); i != nil {
+		indexes = append(indexes, i)
+	}
+	for _, groupedDetails := range groupedIndexesDetails {
+		i := mapSQLiteIndexDetailsToIndex(groupedDetails, tableName, fields)
+		if i != nil {
+			indexes = append(indexes, i)
+		}
+	}
+
+	// Go randomizes map iteration; without this the reconstructed schema lists
+	// the same indexes in a different order on every introspection.
+	sort.Slice(indexes, func(a, b int) bool {
+		return indexes[a].Identifier < indexes[b].Identifier
+	})
+
+	return indexes, nil
+}
+
+// sqlitePrimaryIndex rebuilds the primary key from table_info, which numbers
+// each key column by its position in the key — not necessarily the order the
+// columns are declared in.
+func sqlitePrimaryIndex(columnsDetails []*sqliteColumnDetails, fields []*nemgen.Field) *nemgen.Index {
+	fieldsByName := make(map[string]*nemgen.Field)
+	for _, f := range fields {
+		fieldsByName[f.Identifier] = f
+	}
+
+	indexFields := []*nemgen.IndexField{}
+	for _, c := range columnsDetails {
+		f, ok := fieldsByName[c.Name]
+		if c.PrimaryKey == 0 || !ok {
+			continue
+		}
+		indexFields = append(indexFields, &nemgen.IndexField{
+			FieldUuid: f.Uuid,
+			Priority:  c.PrimaryKey,
+			Order:     nemgen.IndexFieldOrder_INDEX_FIELD_ORDER_ASC,
+		})
+	}
+	sort.Slice(indexFields, func(a, b int) bool {
+		return indexFields[a].Priority < indexFields[b].Priority
+	})
+	if len(indexFields) == 0 {
+		return nil
+	}
+	return &nemgen.Index{
+		Uuid:       uuid.Must(uuid.NewV4()).String(),
+		Identifier: "primary",
+		Status:     nemgen.IndexStatus_INDEX_STATUS_ACTIVE,
+		Type:       nemgen.IndexType_INDEX_TYPE_PRIMARY,
+		Fields:     indexFields,
+	}
+}
+
+func mapSQLiteColumnDetailsToField(in *sqliteColumnDetails, sampleData remoteRows, indexDetails []*sqliteIndexDetails) *nemgen.Field {
+	if in == nil {
+		return &nemgen.Field{}
+	}
+
+	// A field is unique only when a unique index covers it alone; a composite
+	// unique constrains the tuple, not the column.
+	columnsPerIndex := make(map[string]int)
+	for _, id := range indexDetails {
+		columnsPerIndex[id.Name]++
+	}
+	isUnique := false
+	for _, id := range indexDetails {
+		if id.IsUnique && id.Origin != "pk" && id.ColumnName.String == in.Name && columnsPerIndex[id.Name] == 1 {
+			isUnique = true
+		}
+	}
+
+	fieldType, fieldTypeConfig := mapSQLiteColumnDataTypeToFieldType(in, sampleData)
+	def := sqliteColumnDefault(in.DefaultValue)
+	return &nemgen.Field{
+		Uuid:       uuid.Must(uuid.NewV4()).String(),
+		Version:    time.Now().Unix(),
+		Identifier: in.Name,
+		// sqlite lets a non-INTEGER primary key column hold NULL unless it says
+		// NOT NULL, so the flag is read as reported rather than implied by pk.
+		Required:                 in.NotNull,
+		Type:                     fieldType,
+		TypeConfig:               fieldTypeConfig,
+		Status:                   nemgen.FieldStatus_FIELD_STATUS_ACTIVE,
+		Key:                      in.PrimaryKey > 0,
+		Unique:                   isUnique,
+		DefaultValue:             def.Value,
+		DefaultValueIsExpression: def.IsExpression,
+	}
+}
+
+// sqliteDeclaredType splits a declared column type into its upper-cased name and
+// numeric arguments: "varchar(255)" is ("VARCHAR", [255]) and "DECIMAL(38, 9)"
+// is ("DECIMAL", [38 9]).
+func sqliteDeclaredType(declared string) (string, []int64) {
+	declared = strings.ToUpper(strings.TrimSpace(declared))
+	open := strings.IndexByte(declared, '(')
+	if open < 0 || !strings.HasSuffix(declared, ")") {
+		return strings.Join(strings.Fields(declared), " "), nil
+	}
+	args := []int64{}
+	for _, arg := range strings.Split(declared[open+1:len(declared)-1], ",") {
+		n, err := strconv.ParseInt(strings.TrimSpace(arg), 10, 64)
+		if err != nil {
+			continue
+		}
+		args = append(args, n)
+	}
+	return strings.Join(strings.Fields(declared[:open]), " "), args
+}
+
+func mapSQLiteColumnDataTypeToFieldType(in *sqliteColumnDetails, sampleData remoteRows) (nemgen.FieldType, *nemgen.FieldTypeConfig) {
+	if in == nil {
+		return nemgen.FieldType_FIELD_TYPE_INVALID, nil
+	}
+	dataType, args := sqliteDeclaredType(in.DeclaredType)
+	arg := func(n int, def int64) int64 {
+		if len(args) > n {
+			return args[n]
+		}
+		return def
+	}
+	switch dataType {
+	case "UUID":
+		return nemgen.FieldType_FIELD_TYPE_UUID, nil
+	case "CHAR", "CHARACTER", "NCHAR", "NATIVE CHARACTER":
+		max := arg(0, 0)
+		if max == 36 {
+			if sampleData.isUUID(in.Name) {
+				return nemgen.FieldType_FIELD_TYPE_UUID, &nemgen.FieldTypeConfig{}
+			}
+		}
+		return nemgen.FieldType_FIELD_TYPE_CHAR, &nemgen.FieldTypeConfig{
+			Char: &nemgen.FieldTypeCharConfig{
+				MaxSize: max,
+			},
+		}
+	case "BOOLEAN", "BOOL":
+		return nemgen.FieldType_FIELD_TYPE_BOOLEAN, nil
+	case "TINYINT", "SMALLINT", "INT2":
+		return nemgen.FieldType_FIELD_TYPE_INTEGER, &nemgen.FieldTypeConfig{
+			Integer: &nemgen.FieldTypeIntegerConfig{
+				Size: nemgen.FieldTypeIntegerConfigSize_FIELD_TYPE_INTEGER_CONFIG_SIZE_SIXTEEN_BITS,
+			},
+		}
+	case "INTEGER", "INT", "MEDIUMINT":
+		return nemgen.FieldType_FIELD_TYPE_INTEGER, &nemgen.FieldTypeConfig{
+			Integer: &nemgen.FieldTypeIntegerConfig{
+				Size: nemgen.FieldTypeIntegerConfigSize_FIELD_TYPE_INTEGER_CONFIG_SIZE_THIRTY_TWO_BITS,
+			},
+		}
+	case "BIGINT", "INT8", "UNSIGNED BIG INT":
+		return nemgen.FieldType_FIELD_TYPE_INTEGER, &nemgen.FieldTypeConfig{
+			Integer: &nemgen.FieldTypeIntegerConfig{
+				Size: nemgen.FieldTypeIntegerConfigSize_FIELD_TYPE_INTEGER_CONFIG_SIZE_SIXTY_FOUR_BITS,
+			},
+		}
+	case "REAL", "DOUBLE", "DOUBLE PRECISION", "FLOAT":
+		return nemgen.FieldType_FIELD_TYPE_FLOAT, nil
+	case "DECIMAL", "NUMERIC":
+		return nemgen.FieldType_FIELD_TYPE_DECIMAL, &nemgen.FieldTypeConfig{
+			Decimal: &nemgen.FieldTypeDecimalConfig{
+				NumberOfDecimals: arg(1, 0),
+			},
+		}
+	case "VARCHAR", "CHARACTER VARYING", "VARYING CHARACTER", "NVARCHAR":
+		max := arg(0, 255)
+
+		// Same width rule as the other engines. See promotionPreservesWidth.
+		if sampleData.isEmail(in.Name) && promotionPreservesWidth(nemgen.FieldType_FIELD_TYPE_EMAIL, db.SQLiteDBType, max) {
+			return nemgen.FieldType_FIELD_TYPE_EMAIL, nil
+		}
+		if sampleData.isURL(in.Name) && promotionPreservesWidth(nemgen.FieldType_FIELD_TYPE_URL, db.SQLiteDBType, max) {
+			return nemgen.FieldType_FIELD_TYPE_URL, nil
+		}
+		return nemgen.FieldType_FIELD_TYPE_VARCHAR, &nemgen.FieldTypeConfig{
+			Varchar: &nemgen.FieldTypeVarcharConfig{
+				MaxSize: max,
+			},
+		}
+	case "TEXT", "CLOB":
+		return nemgen.FieldType_FIELD_TYPE_TEXT, &nemgen.FieldTypeConfig{
+			Text: &nemgen.FieldTypeTextConfig{
+				MaxSize: 65535,
+			},
+		}
+	case "BLOB":
+		return nemgen.FieldType_FIELD_TYPE_FILE, &nemgen.FieldTypeConfig{
+			File: &nemgen.FieldTypeFileConfig{
+				// The column holds the bytes themselves, so say so: an unset
+				// storage_type now means object store (a url/key column).
+				StorageType: nemgen.FieldTypeFileConfigStorageType_FIELD_TYPE_FILE_CONFIG_STORAGE_TYPE_BINARY,
+			},
+		}
+	case "JSON":
+		if sampleData.isJSONArray(in.Name) {
+			return nemgen.FieldType_FIELD_TYPE_ARRAY, nil
+		}
+		return nemgen.FieldType_FIELD_TYPE_JSON, nil
+	case "DATE":
+		return nemgen.FieldType_FIELD_TYPE_DATE, nil
+	case "DATETIME", "TIMESTAMP":
+		return nemgen.FieldType_FIELD_TYPE_DATETIME, &nemgen.FieldTypeConfig{
+			Datetime: &nemgen.FieldTypeDatetimeConfig{
+				// A datetime column with no default has to say so: an unset
+				// default_value still renders DEFAULT CURRENT_TIMESTAMP.
+				NoDefaultCurrentTimestamp: in.DefaultValue == nil,
+			},
+		}
+	case "TIME":
+		return nemgen.FieldType_FIELD_TYPE_TIME, nil
+	}
+	// Includes a column declared with no type at all, which sqlite allows and
+	// nem has no field type for.
+	return nemgen.FieldType_FIELD_TYPE_INVALID, nil
+}
+
+func mapSQLiteIndexDetailsToIndex(in []*sqliteIndexDetails, tableName string, fields []*nemgen.Field) *nemgen.Index {
+	if len(in) == 0 {
+		return nil
+	}
+
+	first := in[0]
+
+	fieldsByName := make(map[string]*nemgen.Field)
+	for _, f := range fields {
+		fieldsByName[f.Identifier] = f
+	}
+
+	indexType := nemgen.IndexType_INDEX_TYPE_INDEX
+	if first.IsUnique {
+		indexType = nemgen.IndexType_INDEX_TYPE_UNIQUE
+	}
+
+	columns := []string{}
+	finalIndexFields := []*nemgen.IndexField{}
+	for _, id := range in {
+		// An expression key part has no column, and a column that failed to
+		// map has no field; either way there is nothing to point the index at.
+		f, ok := fieldsByName[id.ColumnName.String]
+		if !id.ColumnName.Valid || !ok {
+			continue
+		}
+		order := nemgen.IndexFieldOrder_INDEX_FIELD_ORDER_ASC
+		if id.Descending {
+			order = nemgen.IndexFieldOrder_INDEX_FIELD_ORDER_DESC
+		}
+		columns = append(columns, id.ColumnName.String)
+		finalIndexFields = append(finalIndexFields, &nemgen.IndexField{
+			FieldUuid: f.Uuid,
+			Priority:  id.Seq,
+			Order:     order,
+		})
+	}
+	if len(finalIndexFields) == 0 {
+		return nil
+	}
+
+	// An inline UNIQUE constraint gets an index named sqlite_autoindex_<table>_N,
+	// which says nothing and shifts whenever a constraint is added before it.
+	// The generated DDL never names these indexes anyway, so they take the name
+	// postgres would give the same constraint.
+	name := first.Name
+	if first.Origin == "u" && strings.HasPrefix(name, "sqlite_autoindex_") {
+		name = fmt.Sprintf("%s_%s_key", tableName, strings.Join(columns, "_"))
+	}
+
+	return &nemgen.Index{
+		Uuid:       uuid.Must(uuid.NewV4()).String(),
+		Identifier: name,
+		Status:     nemgen.IndexStatus_INDEX_STATUS_ACTIVE,
+		Type:       indexType,
+		Fields:     finalIndexFields,
+	}
+}
+
+func mapSQLiteFKDetailsToRelationship(in []*sqliteForeignKeyDetails, name string, tableName string, entities []*nemgen.Entity) *nemgen.Relationship {
+	if len(in) == 0 {
+		return nil
+	}
+
+	var fromEntity *nemgen.Entity
+	var toEntity *nemgen.Entity
+	for _, e := range entities {
+		if e.Identifier == tableName {
+			fromEntity = e
+		}
+		if e.Identifier == in[0].ReferencedTableName {
+			toEntity = e
+		}
+	}
+	// sqlite accepts a foreign key to a table that does not exist; there is
+	// nothing to relate it to.
+	if fromEntity == nil || toEntity == nil {
+		return nil
+	}
+
+	fieldUuid := func(e *nemgen.Entity, name string) string {
+		for _, f := range e.Fields {
+			if f.Identifier == name {
+				return f.Uuid
+			}
+		}
+		return ""
+	}
+
+	// A constraint written as `REFERENCES parent` names no columns and means
+	// the parent's primary key, in key order.
+	toKeys := []string{}
+	for _, f := range toEntity.Fields {
+		if f.Key {
+			toKeys = append(toKeys, f.Uuid)
+		}
+	}
+
+	fromFieldUuids := []string{}
+	toFieldUuids := []string{}
+	for n, fkd := range in {
+		from := fieldUuid(fromEntity, fkd.ColumnName)
+		to := ""
+		if fkd.ReferencedColumnName.Valid {
+			to = fieldUuid(toEntity, fkd.ReferencedColumnName.String)
+		} else if n < len(toKeys) {
+			to = toKeys[n]
+		}
+		if from == "" || to == "" {
+			return nil
+		}
+		fromFieldUuids = append(fromFieldUuids, from)
+		toFieldUuids = append(toFieldUuids, to)
+	}
+
+	return &nemgen.Relationship{
+		Uuid:       uuid.Must(uuid.NewV4()).String(),
+		Version:    time.Now().Unix(),
+		Identifier: name,
+		From: &nemgen.RelationshipNode{
+			Uuid: uuid.Must(uuid.NewV4()).String(),
+			Type: nemgen.RelationshipNodeType_RELATIONSHIP_NODE_TYPE_ENTITY,
+			TypeConfig: &nemgen.RelationshipNodeTypeConfig{
+				Entity: &nemgen.RelationshipNodeTypeEntityConfig{
+					EntityUuid: fromEntity.Uuid,
+					FieldUuids: fromFieldUuids,
+				},
+			},
+		},
+		To: &nemgen.RelationshipNode{
+			Uuid: uuid.Must(uuid.NewV4()).String(),
+			Type: nemgen.RelationshipNodeType_RELATIONSHIP_NODE_TYPE_ENTITY,
+			TypeConfig: &nemgen.RelationshipNodeTypeConfig{
+				Entity: &nemgen.RelationshipNodeTypeEntityConfig{
+					EntityUuid: toEntity.Uuid,
+					FieldUuids: toFieldUuids,
+				},
+			},
+		},
+		Status:        nemgen.RelationshipStatus_RELATIONSHIP_STATUS_ACTIVE,
+		UseForeignKey: true,
+		Cardinality:   nemgen.RelationshipCardinality_RELATIONSHIP_CARDINALITY_ONE_TO_ONE,
+		CreatedAt:     timestamppb.Now(),
+		UpdatedAt:     timestamppb.Now(),
+		OnDelete:      tosql.ReferentialActionFromSQL(in[0].DeleteRule),
+		OnUpdate:      tosql.ReferentialActionFromSQL(in[0].UpdateRule),
+	}
+}
+
+// sqliteQuoteLiteral renders a name as a string literal, which is how the
+// pragma table-valued functions take their argument.
+func sqliteQuoteLiteral(value string) string {
+	return "'" + strings.ReplaceAll(value, "'", "''") + "'"
+}
+
+// sqliteUnquoteIdentifier strips any of the identifier quotings sqlite accepts:
+// "name", `name` and [name].
+func sqliteUnquoteIdentifier(identifier string) string {
+	identifier = strings.TrimSpace(identifier)
+	if len(identifier) >= 2 {
+		switch {
+		case identifier[0] == '"' && identifier[len(identifier)-1] == '"':
+			return strings.ReplaceAll(identifier[1:len(identifier)-1], `""`, `"`)
+		case identifier[0] == '`' && identifier[len(identifier)-1] == '`',
+			identifier[0] == '[' && identifier[len(identifier)-1] == ']':
+			return identifier[1 : len(identifier)-1]
+		}
+	}
+	return identifier
+}
@@ -0,0 +1,239 @@
+package fromsql
+
+import (
+	"database/sql"
+	"testing"
+
+	"github.com/gofrs/uuid"
+	nemgen "github.com/nuzur/nem/idl/gen"
+	"github.com/nuzur/sql-gen/db"
+)
+
+// TestMapSQLiteDeclaredTypes guards the declared types FieldTypeToSQLite writes:
+// sqlite reports them back verbatim, so each one has to map back to the field
+// type it was rendered from, widths included.
+func TestMapSQLiteDeclaredTypes(t *testing.T) {
+	cases := []struct {
+		declared string
+		want     nemgen.FieldType
+	}{
+		{"CHAR(36)", nemgen.FieldType_FIELD_TYPE_CHAR},
+		{"VARCHAR(255)", nemgen.FieldType_FIELD_TYPE_VARCHAR},
+		{"varchar(255)", nemgen.FieldType_FIELD_TYPE_VARCHAR},
+		{"TEXT", nemgen.FieldType_FIELD_TYPE_TEXT},
+		{"INTEGER", nemgen.FieldType_FIELD_TYPE_INTEGER},
+		{"BIGINT", nemgen.FieldType_FIELD_TYPE_INTEGER},
+		{"REAL", nemgen.FieldType_FIELD_TYPE_FLOAT},
+		{"DECIMAL(38, 9)", nemgen.FieldType_FIELD_TYPE_DECIMAL},
+		{"BOOLEAN", nemgen.FieldType_FIELD_TYPE_BOOLEAN},
+		{"JSON", nemgen.FieldType_FIELD_TYPE_JSON},
+		{"BLOB", nemgen.FieldType_FIELD_TYPE_FILE},
+		{"DATE", nemgen.FieldType_FIELD_TYPE_DATE},
+		{"DATETIME", nemgen.FieldType_FIELD_TYPE_DATETIME},
+		{"TIME", nemgen.FieldType_FIELD_TYPE_TIME},
+	}
+	for _, tc := range cases {
+		t.Run(tc.declared, func(t *testing.T) {
+			got, _ := mapSQLiteColumnDataTypeToFieldType(&sqliteColumnDetails{Name: "col", DeclaredType: tc.declared}, remoteRows{})
+			if got != tc.want {
+				t.Errorf("declared type %q: got %v, want %v", tc.declared, got, tc.want)
+			}
+		})
+	}
+
+	// A column declared with no type at all carries nothing to map back; it has
+	// to surface as INVALID so buildFields reports it rather than guessing.
+	if got, _ := mapSQLiteColumnDataTypeToFieldType(&sqliteColumnDetails{Name: "col"}, remoteRows{}); got != nemgen.FieldType_FIELD_TYPE_INVALID {
+		t.Errorf("untyped column: got %v, want INVALID", got)
+	}
+}
+
+func TestSQLiteDefaultNormalization(t *testing.T) {
+	for _, tc := range []struct {
+		raw          string
+		wantValue    string
+		wantIsExpr   bool
+		wantHasValue bool
+	}{
+		{"'active'", "active", false, true},
+		{"'it''s'", "it's", false, true},
+		{"5", "5", false, true},
+		{"-1.5", "-1.5", false, true},
+		{"1", "1", false, true},
+		{"CURRENT_TIMESTAMP", "CURRENT_TIMESTAMP", true, true},
+		// the generator parenthesizes expression defaults; they come back bare
+		{"(datetime('now'))", "datetime('now')", true, true},
+		{"NULL", "", false, false},
+	} {
+		got := sqliteColumnDefault(&tc.raw)
+		if got.Value != tc.wantValue || got.IsExpression != tc.wantIsExpr || got.Present != tc.wantHasValue {
+			t.Errorf("sqliteColumnDefault(%q) = %+v, want value=%q expr=%v present=%v",
+				tc.raw, got, tc.wantValue, tc.wantIsExpr, tc.wantHasValue)
+		}
+	}
+	if got := sqliteColumnDefault(nil); got.Present {
+		t.Errorf("a NULL dflt_value must be no default at all, got %+v", got)
+	}
+}
+
+func TestSQLiteForeignKeyConstraintNamesFromTableSQL(t *testing.T) {
+	statement := `CREATE TABLE "child" (
+    "id" CHAR(36) NOT NULL,
+    "a" INTEGER, "b" INTEGER,
+    PRIMARY KEY ("id"),
+    CONSTRAINT "fk ""quoted"""
+        FOREIGN KEY ("a", "b")
+        REFERENCES "parent" ("x", "y"),
+    CONSTRAINT bare_name FOREIGN KEY (id) REFERENCES other (id)
+)`
+	got := map[string]string{}
+	for _, match := range sqliteForeignKeyConstraint.FindAllStringSubmatch(statement, -1) {
+		got[match[2]] = sqliteUnquoteIdentifier(match[1])
+	}
+	if got[`"a", "b"`] != `fk "quoted"` {
+		t.Errorf("quoted constraint name = %q, want %q", got[`"a", "b"`], `fk "quoted"`)
+	}
+	if got["id"] != "bare_name" {
+		t.Errorf("bare constraint name = %q, want \"bare_name\"", got["id"])
+	}
+}
+
+const sqlitePhysicalDetailsDDL = "CREATE TABLE IF NOT EXISTS \"parent\" (\n" +
+	"    \"id\" CHAR(36) NOT NULL,\n" +
+	"    \"name\" VARCHAR(100),\n" +
+	"    PRIMARY KEY (\"id\")\n" +
+	");\n\n" +
+	"CREATE TABLE IF NOT EXISTS \"child\" (\n" +
+	"    \"id\" CHAR(36) NOT NULL,\n" +
+	"    \"parent_uuid\" CHAR(36),\n" +
+	"    \"status\" VARCHAR(20) DEFAULT 'active',\n" +
+	"    \"qty\" INTEGER DEFAULT 5,\n" +
+	"    \"active\" BOOLEAN DEFAULT 1,\n" +
+	"    \"created_at\" DATETIME DEFAULT CURRENT_TIMESTAMP,\n" +
+	"    PRIMARY KEY (\"id\"),\n" +
+	"    CONSTRAINT \"fk_child_parent\"\n" +
+	"        FOREIGN KEY (\"parent_uuid\")\n" +
+	"        REFERENCES \"parent\" (\"id\")\n" +
+	"        ON DELETE SET NULL\n" +
+	"        ON UPDATE CASCADE\n" +
+	");\n" +
+	"CREATE INDEX \"idx_child_status\" ON \"child\" (\"status\" DESC, \"qty\");\n\n"
+
+// The rows below are what PRAGMA table_info / index_list / foreign_key_list
+// report for sqlitePhysicalDetailsDDL.
+func sqliteChildColumns() []*sqliteColumnDetails {
+	return []*sqliteColumnDetails{
+		{Name: "id", DeclaredType: "CHAR(36)", NotNull: true, PrimaryKey: 1},
+		{Name: "parent_uuid", DeclaredType: "CHAR(36)"},
+		{Name: "status", DeclaredType: "VARCHAR(20)", DefaultValue: ptrString("'active'")},
+		{Name: "qty", DeclaredType: "INTEGER", DefaultValue: ptrString("5")},
+		{Name: "active", DeclaredType: "BOOLEAN", DefaultValue: ptrString("1")},
+		{Name: "created_at", DeclaredType: "DATETIME", DefaultValue: ptrString("CURRENT_TIMESTAMP")},
+	}
+}
+
+func sqliteChildIndexes() []*sqliteIndexDetails {
+	return []*sqliteIndexDetails{
+		{Name: "sqlite_autoindex_child_1", Seq: 0, ColumnName: nullString("id"), IsUnique: true, Origin: "pk"},
+		{Name: "idx_child_status", Seq: 0, ColumnName: nullString("status"), Origin: "c", Descending: true},
+		{Name: "idx_child_status", Seq: 1, ColumnName: nullString("qty"), Origin: "c"},
+	}
+}
+
+func sqliteParentColumns() []*sqliteColumnDetails {
+	return []*sqliteColumnDetails{
+		{Name: "id", DeclaredType: "CHAR(36)", NotNull: true, PrimaryKey: 1},
+		{Name: "name", DeclaredType: "VARCHAR(100)"},
+	}
+}
+
+func TestSQLiteIntrospectionKeepsPhysicalDetails(t *testing.T) {
+	pv := introspectedSQLiteSchema(t)
+	child := pv.Entities[0]
+
+	byName := map[string]*nemgen.Field{}
+	for _, f := range child.Fields {
+		byName[f.Identifier] = f
+	}
+	if !byName["id"].Key || !byName["id"].Required {
+		t.Error("id must come back as a required key")
+	}
+	if byName["id"].Unique {
+		t.Error("the primary key's own index must not mark the column unique as well")
+	}
+	if got := byName["status"].GetTypeConfig().GetVarchar().GetMaxSize(); got != 20 {
+		t.Errorf("status width = %d, want 20", got)
+	}
+	// sqlite has no boolean storage and reports the default as written; the
+	// renderer already spells either form as 1/0, same as for mysql.
+	if got := byName["active"].GetDefaultValue(); got != "1" {
+		t.Errorf("active default = %q, want \"1\"", got)
+	}
+
+	var secondary *nemgen.Index
+	for _, i := range child.GetTypeConfig().GetStandalone().GetIndexes() {
+		if i.Identifier == "idx_child_status" {
+			secondary = i
+		}
+	}
+	if secondary == nil || len(secondary.Fields) != 2 {
+		t.Fatalf("idx_child_status missing or incomplete: %+v", secondary)
+	}
+	if secondary.Fields[0].Order != nemgen.IndexFieldOrder_INDEX_FIELD_ORDER_DESC {
+		t.Error("a DESC key part must keep its order")
+	}
+
+	rel := pv.Relationships[0]
+	if rel.GetOnDelete() != nemgen.RelationshipReferentialAction_RELATIONSHIP_REFERENTIAL_ACTION_SET_NULL {
+		t.Errorf("on delete = %v, want SET NULL", rel.GetOnDelete())
+	}
+}
+
+func TestSQLitePhysicalDetailsAreAFixedPoint(t *testing.T) {
+	if got := renderCreateSQLForPV(t, introspectedSQLiteSchema(t), db.SQLiteDBType); got != sqlitePhysicalDetailsDDL {
+		t.Errorf("re-rendered DDL differs from the DDL that created the database\n got:\n%s\nwant:\n%s",
+			got, sqlitePhysicalDetailsDDL)
+	}
+}
+
+func introspectedSQLiteSchema(t *testing.T) *nemgen.ProjectVersion {
+	t.Helper()
+
+	rt := &sqlremote{}
+	build := func(name string, columns []*sqliteColumnDetails, indexes []*sqliteIndexDetails) *nemgen.Entity {
+		fields := []*nemgen.Field{}
+		for _, c := range columns {
+			fields = append(fields, mapSQLiteColumnDetailsToField(c, remoteRows{}, indexes))
+		}
+		idxs, err := rt.buildIndexesFromSQLite(name, columns, indexes, fields)
+		if err != nil {
+			t.Fatalf("building indexes for %s: %v", name, err)
+		}
+		return &nemgen.Entity{
+			Uuid:       uuid.Must(uuid.NewV4()).String(),
+			Identifier: name,
+			Fields:     fields,
+			Type:       nemgen.EntityType_ENTITY_TYPE_STANDALONE,
+			Status:     nemgen.EntityStatus_ENTITY_STATUS_ACTIVE,
+			TypeConfig: &nemgen.EntityTypeConfig{
+				Standalone: &nemgen.EntityTypeStandaloneConfig{Indexes: idxs},
+			},
+		}
+	}
+
+	entities := []*nemgen.Entity{
+		build("child", sqliteChildColumns(), sqliteChildIndexes()),
+		build("parent", sqliteParentColumns(), nil),
+	}
+
+	rel := mapSQLiteFKDetailsToRelationship([]*sqliteForeignKeyDetails{{
+		ID: 0, Seq: 0, ReferencedTableName: "parent", ColumnName: "parent_uuid",
+		ReferencedColumnName: sql.NullString{String: "id", Valid: true},
+		DeleteRule:           "SET NULL", UpdateRule: "CASCADE",
+	}}, "fk_child_parent", "child", entities)
+	if rel == nil {
+		t.Fatal("foreign key did not map to a relationship")
+	}
+
+	return &nemgen.ProjectVersion{Entities: entities, Relationships: []*nemgen.Relationship{rel}}
+}
@@ -0,0 +1,118 @@
+package sqlitetest
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+
+	"github.com/jmoiron/sqlx"
+	nemgen "github.com/nuzur/nem/idl/gen"
+	"github.com/nuzur/sql-gen/db"
+	"github.com/nuzur/sql-gen/fromsql"
+
+	_ "modernc.org/sqlite"
+)
+
+// countingDB is a fromsql.DB with no context of its own, as transports written
+// before fromsql.DBContext are. It cancels the import once it has run
+// cancelAfter queries.
+type countingDB struct {
+	db          fromsql.DB
+	queries     atomic.Int64
+	cancelAfter int64
+	cancel      context.CancelFunc
+}
+
+func (c *countingDB) count() {
+	if c.queries.Add(1) == c.cancelAfter {
+		c.cancel()
+	}
+}
+
+func (c *countingDB) Select(dest interface{}, query string, args ...interface{}) error {
+	c.count()
+	return c.db.Select(dest, query, args...)
+}
+
+func (c *countingDB) QueryMaps(query string, args ...interface{}) ([]map[string]interface{}, error) {
+	c.count()
+	return c.db.QueryMaps(query, args...)
+}
+
+func sqliteWithTables(t *testing.T, tables int) fromsql.DB {
+	t.Helper()
+	conn, err := sqlx.Open("sqlite", filepath.Join(t.TempDir(), "lots.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	for n := 0; n < tables; n++ {
+		if _, err := conn.Exec(fmt.Sprintf(`CREATE TABLE lot_%d (id TEXT PRIMARY KEY, qty INTEGER NOT NULL)`, n)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return fromsql.FromSqlx(conn)
+}
+
+func TestCancelledImportStops(t *testing.T) {
+	const tables = 50
+	conn := sqliteWithTables(t, tables)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	counting := &countingDB{db: conn, cancelAfter: 10, cancel: cancel}
+	_, err := fromsql.GenerateProjectVersion(ctx, fromsql.GenerateRequest{
+		UserConnection: &nemgen.UserConnection{},
+		DB:             counting,
+		DBType:         db.SQLiteDBType,
+	})
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("GenerateProjectVersion() error = %v, want context.Canceled", err)
+	}
+	// each table takes several queries; past the cancellation only those
+	// already let through run
+	if got := counting.queries.Load(); got >= 2*tables {
+		t.Errorf("ran %d queries after cancelling at 10", got)
+	}
+
+	// the same import, left to finish
+	counting = &countingDB{db: conn, cancel: func() {}}
+	pv, err := fromsql.GenerateProjectVersion(context.Background(), fromsql.GenerateRequest{
+		UserConnection: &nemgen.UserConnection{},
+		DB:             counting,
+		DBType:         db.SQLiteDBType,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(pv.Entities) != tables {
+		t.Errorf("got %d entities, want %d", len(pv.Entities), tables)
+	}
+}
+
+func TestContextDB(t *testing.T) {
+	conn := sqliteWithTables(t, 1)
+	if fromsql.ContextDB(conn) != conn.(fromsql.DBContext) {
+		t.Error("ContextDB() should return a fromsql.DBContext as is")
+	}
+	if fromsql.ContextDB(nil) != nil {
+		t.Error("ContextDB(nil) should be nil")
+	}
+
+	counting := &countingDB{db: conn}
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	var names []string
+	if err := fromsql.ContextDB(counting).SelectContext(ctx, &names, "SELECT name FROM sqlite_master"); !errors.Is(err, context.Canceled) {
+		t.Errorf("SelectContext() error = %v, want context.Canceled", err)
+	}
+	if _, err := fromsql.ContextDB(counting).QueryMapsContext(ctx, "SELECT 1"); !errors.Is(err, context.Canceled) {
+		t.Errorf("QueryMapsContext() error = %v, want context.Canceled", err)
+	}
+	if got := counting.queries.Load(); got != 0 {
+		t.Errorf("ran %d queries on a cancelled context", got)
+	}
+}
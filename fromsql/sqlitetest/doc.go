@@ -0,0 +1,4 @@
+// Package sqlitetest runs fromsql against a real sqlite database. It is a
+// module of its own so the sqlite driver stays out of sql-gen's requirements:
+// run its tests from this directory.
+package sqlitetest
@@ -0,0 +1,116 @@
+package sqlitetest
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	nemgen "github.com/nuzur/nem/idl/gen"
+	"github.com/nuzur/sql-gen/db"
+	"github.com/nuzur/sql-gen/fromsql"
+)
+
+// hookDB runs before ahead of every query, which fails with its error.
+type hookDB struct {
+	db     fromsql.DB
+	before func(query string) error
+}
+
+func (h *hookDB) Select(dest interface{}, query string, args ...interface{}) error {
+	if err := h.before(query); err != nil {
+		return err
+	}
+	return h.db.Select(dest, query, args...)
+}
+
+func (h *hookDB) QueryMaps(query string, args ...interface{}) ([]map[string]interface{}, error) {
+	if err := h.before(query); err != nil {
+		return nil, err
+	}
+	return h.db.QueryMaps(query, args...)
+}
+
+func TestIntrospectionProgress(t *testing.T) {
+	const tables = 12
+	conn := sqliteWithTables(t, tables)
+
+	var inFlight, most atomic.Int64
+	limited := &hookDB{db: conn, before: func(string) error {
+		n := inFlight.Add(1)
+		defer inFlight.Add(-1)
+		for m := most.Load(); n > m && !most.CompareAndSwap(m, n); m = most.Load() {
+		}
+		time.Sleep(time.Millisecond)
+		return nil
+	}}
+
+	var mu sync.Mutex
+	events := []fromsql.Progress{}
+	_, err := fromsql.GenerateProjectVersion(context.Background(), fromsql.GenerateRequest{
+		UserConnection: &nemgen.UserConnection{},
+		DB:             limited,
+		DBType:         db.SQLiteDBType,
+		Concurrency:    2,
+		Progress: func(p fromsql.Progress) {
+			mu.Lock()
+			defer mu.Unlock()
+			events = append(events, p)
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := most.Load(); got > 2 {
+		t.Errorf("%d queries ran at once, want at most 2", got)
+	}
+
+	if len(events) != 2*tables {
+		t.Fatalf("got %d progress events, want %d", len(events), 2*tables)
+	}
+	for n, p := range events {
+		want := fromsql.TableDiscovered
+		if n >= tables {
+			want = fromsql.TableIntrospected
+		}
+		if p.Stage != want {
+			t.Errorf("event %d is %v, want %v", n, p.Stage, want)
+		}
+	}
+	if last := events[len(events)-1]; last.Discovered != tables || last.Introspected != tables || last.Failed != 0 {
+		t.Errorf("final totals = %+v, want %d discovered and introspected", last, tables)
+	}
+}
+
+func TestIntrospectionProgressFailure(t *testing.T) {
+	conn := sqliteWithTables(t, 6)
+	broken := errors.New("connection reset")
+	failing := &hookDB{db: conn, before: func(query string) error {
+		if strings.Contains(query, "lot_3") {
+			return broken
+		}
+		return nil
+	}}
+
+	failed := []fromsql.Progress{}
+	_, err := fromsql.GenerateProjectVersion(context.Background(), fromsql.GenerateRequest{
+		UserConnection: &nemgen.UserConnection{},
+		DB:             failing,
+		DBType:         db.SQLiteDBType,
+		Concurrency:    1,
+		Progress: func(p fromsql.Progress) {
+			if p.Stage == fromsql.TableFailed {
+				failed = append(failed, p)
+			}
+		},
+	})
+	if err == nil || !strings.Contains(err.Error(), broken.Error()) {
+		t.Fatalf("GenerateProjectVersion() error = %v, want %v", err, broken)
+	}
+	if len(failed) != 1 || failed[0].Table != "lot_3" || failed[0].Failed != 1 || failed[0].Introspected != 3 {
+		t.Errorf("failures reported = %+v, want lot_3 after 3 introspected", failed)
+	}
+}
@@ -0,0 +1,99 @@
+package sqlitetest
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/jmoiron/sqlx"
+	nemgen "github.com/nuzur/nem/idl/gen"
+	"github.com/nuzur/sql-gen/db"
+	"github.com/nuzur/sql-gen/fromsql"
+)
+
+func sqliteWithPeople(t *testing.T) fromsql.DB {
+	t.Helper()
+	conn, err := sqlx.Open("sqlite", filepath.Join(t.TempDir(), "people.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	statements := []string{
+		`CREATE TABLE person (id CHAR(36) PRIMARY KEY, email VARCHAR(512), name TEXT)`,
+		`INSERT INTO person VALUES
+			('6f1c3a52-2a8e-4f7e-9a53-0f0e4b9b8a10', 'ada@example.com', 'Ada'),
+			('0b6c6f0e-7a4a-4b8e-8d3e-2f7e6a1c9b21', NULL, 'Grace'),
+			('c2a7d0b4-5e1f-4c3a-9b8d-7e6f5a4b3c22', 'alan@example.com', 'Alan')`,
+	}
+	for _, s := range statements {
+		if _, err := conn.Exec(s); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return fromsql.FromSqlx(conn)
+}
+
+func TestSamplingPromotions(t *testing.T) {
+	conn := sqliteWithPeople(t)
+
+	sampled := func(sampling fromsql.Sampling) (*fromsql.ImportResult, []string) {
+		t.Helper()
+		queries := []string{}
+		recording := &hookDB{db: conn, before: func(query string) error {
+			if strings.Contains(query, `FROM "person"`) {
+				queries = append(queries, query)
+			}
+			return nil
+		}}
+		res, err := fromsql.Import(context.Background(), fromsql.GenerateRequest{
+			UserConnection: &nemgen.UserConnection{},
+			DB:             recording,
+			DBType:         db.SQLiteDBType,
+			Sampling:       sampling,
+			Concurrency:    1,
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return res, queries
+	}
+	promoted := func(res *fromsql.ImportResult) string {
+		out := []string{}
+		for _, p := range res.Promotions {
+			out = append(out, p.String())
+		}
+		return strings.Join(out, "\n")
+	}
+
+	res, queries := sampled(fromsql.Sampling{})
+	want := strings.Join([]string{
+		fmt.Sprintf("person.email promoted from %s to %s: 2 values in 3 sampled rows", nemgen.FieldType_FIELD_TYPE_VARCHAR, nemgen.FieldType_FIELD_TYPE_EMAIL),
+		fmt.Sprintf("person.id promoted from %s to %s: 3 values in 3 sampled rows", nemgen.FieldType_FIELD_TYPE_CHAR, nemgen.FieldType_FIELD_TYPE_UUID),
+	}, "\n")
+	if got := promoted(res); got != want {
+		t.Errorf("promotions =\n%s\nwant\n%s", got, want)
+	}
+	if want := []string{`SELECT * FROM "person" LIMIT 10`}; strings.Join(queries, "\n") != strings.Join(want, "\n") {
+		t.Errorf("sampled with %q, want %q", queries, want)
+	}
+
+	res, queries = sampled(fromsql.Sampling{Disabled: true})
+	if len(res.Promotions) != 0 || len(queries) != 0 {
+		t.Errorf("disabled sampling read %q and promoted\n%s", queries, promoted(res))
+	}
+
+	res, queries = sampled(fromsql.Sampling{Columns: []string{"person.email", "missing"}, Size: 2, Method: fromsql.SampleRandom})
+	if want := `SELECT "email" FROM "person" ORDER BY random() LIMIT 2`; len(queries) != 1 || queries[0] != want {
+		t.Errorf("sampled with %q, want %q", queries, want)
+	}
+	if len(res.Promotions) != 1 || res.Promotions[0].Column != "email" {
+		t.Errorf("promotions of the email column alone =\n%s", promoted(res))
+	}
+
+	_, queries = sampled(fromsql.Sampling{Columns: []string{"other.email"}})
+	if len(queries) != 0 {
+		t.Errorf("a table with no column named was sampled with %q", queries)
+	}
+}
@@ -1,21 +1,34 @@
 package fromsql
 
-import "github.com/jmoiron/sqlx"
+import (
+	"context"
 
-// FromSqlx wraps an existing *sqlx.DB so it satisfies the DB interface. Used
-// by callers that already hold a concrete sqlx connection (remote DB paths,
-// in-tree test rigs, etc.). LocalAgentConnection callers should NOT go through
+	"github.com/jmoiron/sqlx"
+)
+
+// FromSqlx wraps an existing *sqlx.DB so it satisfies the DB interface, and
+// DBContext so that cancelling an import cancels its queries. Used by callers
+// that already hold a concrete sqlx connection (remote DB paths, in-tree test
+// rigs, etc.). LocalAgentConnection callers should NOT go through
 // this — they have their own adapter that routes via the agent stream.
 func FromSqlx(db *sqlx.DB) DB { return &sqlxAdapter{db: db} }
 
 type sqlxAdapter struct{ db *sqlx.DB }
 
 func (a *sqlxAdapter) Select(dest interface{}, query string, args ...interface{}) error {
-	return a.db.Select(dest, query, args...)
+	return a.SelectContext(context.Background(), dest, query, args...)
 }
 
 func (a *sqlxAdapter) QueryMaps(query string, args ...interface{}) ([]map[string]interface{}, error) {
-	rows, err := a.db.Queryx(query, args...)
+	return a.QueryMapsContext(context.Background(), query, args...)
+}
+
+func (a *sqlxAdapter) SelectContext(ctx context.Context, dest interface{}, query string, args ...interface{}) error {
+	return a.db.SelectContext(ctx, dest, query, args...)
+}
+
+func (a *sqlxAdapter) QueryMapsContext(ctx context.Context, query string, args ...interface{}) ([]map[string]interface{}, error) {
+	rows, err := a.db.QueryxContext(ctx, query, args...)
 	if err != nil {
 		return nil, err
 	}
@@ -1,6 +1,7 @@
 package fromsql
 
 import (
+	"context"
 	"fmt"
 
 	"github.com/nuzur/sql-gen/db"
@@ -8,24 +9,35 @@ import (
 
 func New(params GenerateRequest) *sqlremote {
 	return &sqlremote{
-		userConnection: params.UserConnection,
-		db:             params.DB,
-		dbType:         params.DBType,
-		version:        params.Version,
+		dbSchema:    params.UserConnection.GetDbSchema(),
+		db:          ContextDB(params.DB),
+		dbType:      params.DBType,
+		version:     params.Version,
+		schemas:     params.Schemas,
+		allSchemas:  params.AllSchemas,
+		ddl:         params.DDL,
+		sampling:    params.Sampling,
+		bulk:        params.Bulk,
+		concurrency: params.Concurrency,
+		progress:    progressReporter{report: params.Progress},
 	}
 }
 
-func (rt *sqlremote) getTableNames() ([]string, error) {
+// getTableNames lists the mysql and sqlite tables. Postgres tables are listed
+// together with their schema by getPgTables.
+func (rt *sqlremote) getTableNames(ctx context.Context) ([]string, error) {
 	// Get table list
 	query := ""
 	if rt.dbType == db.MYSQLDBType {
-		query = "SHOW TABLES"
-	} else if rt.dbType == db.PGDBType {
-		query = fmt.Sprintf("SELECT tablename FROM pg_catalog.pg_tables where schemaname = '%s';", rt.userConnection.DbSchema)
+		// SHOW TABLES lists the views too
+		query = fmt.Sprintf("SELECT TABLE_NAME FROM information_schema.TABLES WHERE TABLE_SCHEMA = '%s' AND TABLE_TYPE = 'BASE TABLE' ORDER BY TABLE_NAME;", rt.dbSchema)
+	} else if rt.dbType == db.SQLiteDBType {
+		// sqlite_% are sqlite's own tables (sqlite_sequence, sqlite_stat1).
+		query = "SELECT name FROM sqlite_master WHERE type = 'table' AND name NOT LIKE 'sqlite_%' ORDER BY name;"
 	}
 
 	data := []string{}
-	err := rt.db.Select(&data, query)
+	err := rt.db.SelectContext(ctx, &data, query)
 
 	if err != nil {
 		return nil, fmt.Errorf("error getting table names: %v", err)
@@ -0,0 +1,61 @@
+-- MySQL dump 10.13  Distrib 8.0.36, for Linux (x86_64)
+--
+-- Host: localhost    Database: shop
+-- ------------------------------------------------------
+/*!40101 SET @OLD_CHARACTER_SET_CLIENT=@@CHARACTER_SET_CLIENT */;
+/*!50503 SET NAMES utf8mb4 */;
+/*!40014 SET @OLD_FOREIGN_KEY_CHECKS=@@FOREIGN_KEY_CHECKS, FOREIGN_KEY_CHECKS=0 */;
+
+--
+-- Table structure for table `customer`
+--
+
+DROP TABLE IF EXISTS `customer`;
+/*!40101 SET @saved_cs_client     = @@character_set_client */;
+/*!50503 SET character_set_client = utf8mb4 */;
+CREATE TABLE `customer` (
+  `id` int NOT NULL AUTO_INCREMENT,
+  `email` varchar(320) NOT NULL COMMENT 'where the receipts go',
+  `name` varchar(255) DEFAULT NULL,
+  `tier` enum('free','pro','it''s') NOT NULL DEFAULT 'free',
+  `active` tinyint(1) NOT NULL DEFAULT '1',
+  `balance` decimal(12,2) NOT NULL DEFAULT '0.00',
+  `created_at` datetime(3) NOT NULL DEFAULT CURRENT_TIMESTAMP(3),
+  `updated_at` datetime NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+  `token` char(36) NOT NULL DEFAULT (uuid()),
+  `display` varchar(512) GENERATED ALWAYS AS (concat(`name`,_utf8mb4' <',`email`,_utf8mb4'>')) VIRTUAL,
+  PRIMARY KEY (`id`),
+  UNIQUE KEY `email` (`email`),
+  KEY `name_prefix` (`name`(20) DESC),
+  KEY `lower_name` ((lower(`name`))),
+  CONSTRAINT `balance_positive` CHECK ((`balance` >= 0))
+) ENGINE=InnoDB AUTO_INCREMENT=1042 DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_0900_ai_ci COMMENT='people who buy things';
+/*!40101 SET character_set_client = @saved_cs_client */;
+
+--
+-- Table structure for table `purchase`
+--
+
+DROP TABLE IF EXISTS `purchase`;
+CREATE TABLE `purchase` (
+  `id` bigint NOT NULL AUTO_INCREMENT,
+  `customer_id` int NOT NULL,
+  `note` text,
+  PRIMARY KEY (`id`),
+  KEY `purchase_customer` (`customer_id`),
+  FULLTEXT KEY `note` (`note`),
+  CONSTRAINT `purchase_customer` FOREIGN KEY (`customer_id`) REFERENCES `customer` (`id`) ON DELETE CASCADE
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_0900_ai_ci;
+
+--
+-- Temporary view structure for view `pro_customer`
+--
+
+DROP TABLE IF EXISTS `pro_customer`;
+/*!50001 DROP VIEW IF EXISTS `pro_customer`*/;
+/*!50001 CREATE VIEW `pro_customer` AS SELECT 1 AS `id`*/;
+
+/*!50001 DROP VIEW IF EXISTS `pro_customer`*/;
+/*!50001 CREATE ALGORITHM=UNDEFINED */
+/*!50013 DEFINER=`root`@`localhost` SQL SECURITY DEFINER */
+/*!50001 VIEW `pro_customer` AS select `customer`.`id` AS `id` from `customer` where (`customer`.`tier` = 'pro') */;
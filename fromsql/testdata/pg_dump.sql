@@ -0,0 +1,93 @@
+--
+-- PostgreSQL database dump
+--
+
+SET statement_timeout = 0;
+SET client_encoding = 'UTF8';
+SELECT pg_catalog.set_config('search_path', '', false);
+
+CREATE TYPE public.tier AS ENUM (
+    'free',
+    'pro'
+);
+
+CREATE FUNCTION public.touch() RETURNS trigger
+    LANGUAGE plpgsql
+    AS $$
+BEGIN
+    NEW.updated_at = now();
+    RETURN NEW;
+END;
+$$;
+
+SET default_tablespace = '';
+
+CREATE TABLE public.customer (
+    id integer NOT NULL,
+    email character varying(320) NOT NULL,
+    name text,
+    tier public.tier DEFAULT 'free'::public.tier NOT NULL,
+    balance numeric(12,2) DEFAULT 0 NOT NULL,
+    created_at timestamp(3) with time zone DEFAULT CURRENT_TIMESTAMP NOT NULL,
+    token uuid DEFAULT gen_random_uuid() NOT NULL,
+    display text GENERATED ALWAYS AS ((name || ' <'::text) || (email)::text) STORED,
+    CONSTRAINT balance_positive CHECK ((balance >= (0)::numeric))
+);
+
+COMMENT ON TABLE public.customer IS 'people who buy things';
+COMMENT ON COLUMN public.customer.email IS 'where the receipts go';
+
+CREATE SEQUENCE public.customer_id_seq
+    AS integer
+    START WITH 1
+    INCREMENT BY 1
+    NO MINVALUE
+    NO MAXVALUE
+    CACHE 1;
+
+ALTER SEQUENCE public.customer_id_seq OWNED BY public.customer.id;
+
+CREATE TABLE public.purchase (
+    id bigint NOT NULL,
+    customer_id integer NOT NULL,
+    tags text,
+    note text
+);
+
+ALTER TABLE public.purchase ALTER COLUMN id ADD GENERATED BY DEFAULT AS IDENTITY (
+    SEQUENCE NAME public.purchase_id_seq
+    START WITH 1
+    INCREMENT BY 1
+    NO MINVALUE
+    NO MAXVALUE
+    CACHE 1
+);
+
+CREATE VIEW public.pro_customer AS
+ SELECT id
+   FROM public.customer
+  WHERE (tier = 'pro'::public.tier);
+
+ALTER TABLE ONLY public.customer ALTER COLUMN id SET DEFAULT nextval('public.customer_id_seq'::regclass);
+
+ALTER TABLE ONLY public.customer
+    ADD CONSTRAINT customer_email_key UNIQUE (email);
+
+ALTER TABLE ONLY public.customer
+    ADD CONSTRAINT customer_pkey PRIMARY KEY (id);
+
+ALTER TABLE ONLY public.purchase
+    ADD CONSTRAINT purchase_pkey PRIMARY KEY (id);
+
+CREATE INDEX customer_lower_name ON public.customer USING btree (lower(name)) WHERE (name IS NOT NULL);
+
+CREATE INDEX purchase_note_trgm ON public.purchase USING gin (note public.gin_trgm_ops);
+
+CREATE INDEX purchase_customer ON public.purchase USING btree (customer_id DESC);
+
+ALTER TABLE ONLY public.purchase
+    ADD CONSTRAINT purchase_customer_id_fkey FOREIGN KEY (customer_id) REFERENCES public.customer(id) ON DELETE CASCADE;
+
+--
+-- PostgreSQL database dump complete
+--
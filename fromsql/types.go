@@ -1,8 +1,11 @@
 package fromsql
 
 import (
+	"context"
+
 	nemgen "github.com/nuzur/nem/idl/gen"
 	"github.com/nuzur/sql-gen/db"
+	"github.com/nuzur/sql-gen/tosql"
 )
 
 // DB is the minimal database surface fromsql needs to introspect a live SQL
@@ -10,8 +13,10 @@ import (
 // underlying transport — a direct *sqlx.DB for remote connections, or a
 // LocalAgentChannel-routed adapter for agent-backed local connections.
 //
-// Caller responsibility: each method may be invoked with no explicit context,
-// so the underlying transport should use a reasonable default deadline.
+// A DB that also implements DBContext is queried with the context
+// introspection runs under. Otherwise its methods are invoked with no explicit
+// context, so the underlying transport should use a reasonable default
+// deadline.
 type DB interface {
 	// Select scans a result set into a slice destination. Accepts both
 	// scalar slices (`*[]string`) and struct slices with `db:"col"` tags,
@@ -23,18 +28,119 @@ type DB interface {
 	QueryMaps(query string, args ...interface{}) ([]map[string]interface{}, error)
 }
 
+// DBContext is DB with every query bound to a context. Cancelling the context
+// GenerateProjectVersion runs under stops an import as soon as the queries in
+// flight return, and a transport that honours the context stops those too.
+type DBContext interface {
+	SelectContext(ctx context.Context, dest interface{}, query string, args ...interface{}) error
+	QueryMapsContext(ctx context.Context, query string, args ...interface{}) ([]map[string]interface{}, error)
+}
+
+// ContextDB adapts a DB to DBContext. A DB that implements it already is
+// returned as is; any other checks the context before each query, so that a
+// cancelled import starts no new one.
+func ContextDB(db DB) DBContext {
+	if db == nil {
+		return nil
+	}
+	if dbc, ok := db.(DBContext); ok {
+		return dbc
+	}
+	return &contextAdapter{db: db}
+}
+
+type contextAdapter struct{ db DB }
+
+func (a *contextAdapter) SelectContext(ctx context.Context, dest interface{}, query string, args ...interface{}) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	return a.db.Select(dest, query, args...)
+}
+
+func (a *contextAdapter) QueryMapsContext(ctx context.Context, query string, args ...interface{}) ([]map[string]interface{}, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return a.db.QueryMaps(query, args...)
+}
+
 type GenerateRequest struct {
 	UserConnection *nemgen.UserConnection
 	DB             DB
 	DBType         db.DBType
 	Version        *int64
+	// Schemas are the postgres schemas to introspect into the one project
+	// version. Empty reads the connection's DbSchema alone, or its
+	// current_schema() if that is unset. Naming them qualifies every table and
+	// type with its schema in the generated DDL.
+	Schemas []string
+	// AllSchemas introspects every schema except postgres' own, and qualifies
+	// the names as Schemas does.
+	AllSchemas bool
+	// DDL is a schema dump, such as mysqldump --no-data or pg_dump -s
+	// writes, to read the schema out of instead of introspecting DB, which is
+	// then not used. With no rows to sample, no column is promoted to a
+	// semantic type such as an email or a url.
+	DDL string
+	// Sampling is which rows are read to refine the column types. The zero
+	// value reads the first DefaultSampleSize rows of every table.
+	Sampling Sampling
+	// Bulk reads each kind of detail of every table at once, then parts it by
+	// table, rather than reading table by table: a few round trips in all
+	// rather than several per table, bar the sample rows. If a bulk query
+	// fails, the tables are read one by one. Mysql and postgres only.
+	Bulk bool
+	// Concurrency is the most tables introspected at once, each holding a
+	// connection. Zero, or less, is DefaultConcurrency.
+	Concurrency int
+	// Progress, if set, is told of every table as it is discovered,
+	// introspected or fails, one call at a time. Tables read from DDL are
+	// not reported.
+	Progress func(Progress)
+	// Sink and ExecutionsDir are where GenerateSQL writes, as
+	// tosql.GenerateRequest's are.
+	Sink          tosql.Sink
+	ExecutionsDir string
 }
 
 type sqlremote struct {
-	userConnection *nemgen.UserConnection
-	db             DB
-	dbType         db.DBType
-	version        *int64
+	// dbSchema is the connection's schema, or the database's current one when
+	// the connection names none
+	dbSchema    string
+	db          DBContext
+	dbType      db.DBType
+	version     *int64
+	schemas     []string
+	allSchemas  bool
+	ddl         string
+	sampling    Sampling
+	bulk        bool
+	concurrency int
+	progress    progressReporter
+
+	// enums are the enums the native enum columns seen so far map to
+	enums introspectedEnums
+	// checks are the CHECK constraints of the tables seen so far
+	checks introspectedChecks
+	// autoIncrement are the AUTO_INCREMENT options of the mysql tables seen so
+	// far
+	autoIncrement introspectedAutoIncrement
+	// promotions are the columns the sampled rows typed, of the tables seen so
+	// far
+	promotions introspectedPromotions
+	// computed are the generated columns seen so far
+	computed introspectedComputed
+	// indexes are the partial and expression indexes seen so far
+	indexes introspectedIndexes
+	// views are the database's views, read once its tables are
+	views []tosql.View
+	// pgEnumLabels are the labels of each postgres enum type, by schema and
+	// type name
+	pgEnumLabels map[pgName][]string
+	// pgSchemas are the schemas of the tables and enum types seen so far that
+	// are named with one
+	pgSchemas introspectedSchemas
 }
 
 type remoteRows []map[string]interface{}
@@ -0,0 +1,80 @@
+package fromsql
+
+import (
+	"strings"
+
+	"github.com/nuzur/sql-gen/tosql"
+)
+
+// viewDetails is a view as the engines report it. nem has no notion of a
+// view, so they are handed back as tosql.SchemaOptions beside the project
+// version rather than as entities.
+type viewDetails struct {
+	Schema       string `db:"schema_name"`
+	Name         string `db:"name"`
+	Definition   string `db:"definition"`
+	Materialized bool   `db:"materialized"`
+}
+
+// mysqlViewDefinition makes a VIEW_DEFINITION portable across databases.
+// mysql reports every table and column in it qualified with the database the
+// view is in, which would tie the view created from it to that database.
+func mysqlViewDefinition(definition string, database string) string {
+	return strings.TrimSpace(strings.ReplaceAll(definition, "`"+database+"`.", ""))
+}
+
+// pgViewDefinition trims the leading space and terminating semicolon postgres
+// reports a view's query with.
+func pgViewDefinition(definition string) string {
+	return strings.TrimSpace(strings.TrimSuffix(strings.TrimSpace(definition), ";"))
+}
+
+// sqliteViewDefinition reads the query out of the CREATE VIEW statement
+// sqlite_master keeps for a view: whatever follows its first AS outside of a
+// quoted name or a column list. The column list itself is not kept, so the
+// view's columns take the names the query gives them.
+func sqliteViewDefinition(statement string) string {
+	depth := 0
+	for i := 0; i < len(statement); i++ {
+		switch c := statement[i]; {
+		case c == '\'' || c == '"' || c == '`':
+			end := strings.IndexByte(statement[i+1:], c)
+			if end < 0 {
+				return ""
+			}
+			i += end + 1
+		case c == '[':
+			end := strings.IndexByte(statement[i+1:], ']')
+			if end < 0 {
+				return ""
+			}
+			i += end + 1
+		case c == '(':
+			depth++
+		case c == ')':
+			depth--
+		case depth == 0 && (c == 'a' || c == 'A') && i+2 <= len(statement) && strings.EqualFold(statement[i:i+2], "AS") &&
+			(i == 0 || !isWordByte(statement[i-1])) && (i+2 == len(statement) || !isWordByte(statement[i+2])):
+			return strings.TrimSuffix(strings.TrimSpace(statement[i+2:]), ";")
+		}
+	}
+	return ""
+}
+
+// mapViews turns the reported views into the views tosql renders, skipping
+// any whose definition the connection is not allowed to read.
+func (rt *sqlremote) mapViews(details []*viewDetails) []tosql.View {
+	res := []tosql.View{}
+	for _, d := range details {
+		if d.Definition == "" {
+			continue
+		}
+		res = append(res, tosql.View{
+			Schema:       rt.qualifiedSchema(d.Schema),
+			Name:         d.Name,
+			Definition:   d.Definition,
+			Materialized: d.Materialized,
+		})
+	}
+	return res
+}
@@ -0,0 +1,50 @@
+package fromsql
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/nuzur/sql-gen/tosql"
+)
+
+func TestViewDefinitions(t *testing.T) {
+	sqlite := map[string]string{
+		`CREATE VIEW big_lots AS SELECT id FROM lot WHERE qty > 100`:               "SELECT id FROM lot WHERE qty > 100",
+		`CREATE VIEW IF NOT EXISTS "as" AS select "as" from lot;`:                  `select "as" from lot`,
+		"CREATE VIEW [lot as of] (id, total) AS\nSELECT id, qty AS total FROM lot": "SELECT id, qty AS total FROM lot",
+		`CREATE TEMP VIEW v AS SELECT 'AS' AS x`:                                   `SELECT 'AS' AS x`,
+	}
+	for statement, want := range sqlite {
+		if got := sqliteViewDefinition(statement); got != want {
+			t.Errorf("sqliteViewDefinition(%q) = %q, want %q", statement, got, want)
+		}
+	}
+
+	mysql := "select `shop`.`lot`.`id` AS `id` from `shop`.`lot` where (`shop`.`lot`.`qty` > 100)"
+	if got, want := mysqlViewDefinition(mysql, "shop"), "select `lot`.`id` AS `id` from `lot` where (`lot`.`qty` > 100)"; got != want {
+		t.Errorf("mysqlViewDefinition() = %q, want %q", got, want)
+	}
+
+	pg := " SELECT lot.id\n   FROM lot\n  WHERE (lot.qty > 100);"
+	if got, want := pgViewDefinition(pg), "SELECT lot.id\n   FROM lot\n  WHERE (lot.qty > 100)"; got != want {
+		t.Errorf("pgViewDefinition() = %q, want %q", got, want)
+	}
+}
+
+// A view whose definition the connection may not read is reported with an
+// empty one, and there is nothing to create it from.
+func TestViewsWithoutADefinitionAreSkipped(t *testing.T) {
+	rt := &sqlremote{dbSchema: "public"}
+	got := rt.mapViews([]*viewDetails{
+		{Schema: "public", Name: "big_lots", Definition: "SELECT id FROM lot"},
+		{Schema: "public", Name: "secret"},
+		{Schema: "reports", Name: "totals", Definition: "SELECT 1", Materialized: true},
+	})
+	want := []tosql.View{
+		{Name: "big_lots", Definition: "SELECT id FROM lot"},
+		{Schema: "reports", Name: "totals", Definition: "SELECT 1", Materialized: true},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("mapViews() = %+v, want %+v", got, want)
+	}
+}
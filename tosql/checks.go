@@ -0,0 +1,45 @@
+package tosql
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+)
+
+// Check is a CHECK constraint on an entity's table.
+type Check struct {
+	// Name is the constraint's name. An unnamed check is named the way
+	// postgres names an unnamed table CHECK: <table>_check, then
+	// <table>_check1 and so on.
+	Name string `json:"name,omitempty"`
+	// Expression is the condition, in the engine's own SQL, without the CHECK
+	// keyword or the parentheses around it.
+	Expression string `json:"expression"`
+}
+
+// addChecks adds the entity's declared checks. They go in before the ones
+// applyEnumMode derives, which give way to a declared check of the same name.
+func (e *SchemaEntity) addChecks(checks []Check) {
+	unnamed := 0
+	for _, c := range checks {
+		name := c.Name
+		for name == "" || e.hasCheck(name) && c.Name == "" {
+			name = fmt.Sprintf("%s_check", e.Name)
+			if unnamed > 0 {
+				name += strconv.Itoa(unnamed)
+			}
+			unnamed++
+		}
+		e.Checks = append(e.Checks, SchemaCheck{
+			DBType:     e.DBType,
+			Name:       name,
+			Expression: c.Expression,
+		})
+	}
+}
+
+func (e SchemaEntity) hasCheck(name string) bool {
+	return slices.ContainsFunc(e.Checks, func(c SchemaCheck) bool {
+		return c.Name == name
+	})
+}
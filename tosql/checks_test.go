@@ -0,0 +1,102 @@
+package tosql
+
+import (
+	"testing"
+
+	nemgen "github.com/nuzur/nem/idl/gen"
+	"github.com/nuzur/sql-gen/db"
+	"github.com/stretchr/testify/assert"
+)
+
+func checkVersion() *nemgen.ProjectVersion {
+	return &nemgen.ProjectVersion{
+		Entities: []*nemgen.Entity{
+			migrationTable("lot", "lot", []*nemgen.Field{
+				migrationKey("lot.id"),
+				migrationField("lot.qty", "qty", nemgen.FieldType_FIELD_TYPE_INTEGER, true),
+			}),
+		},
+	}
+}
+
+func checkOptions(checks ...Check) SchemaOptions {
+	return SchemaOptions{Checks: map[string][]Check{"lot": checks}}
+}
+
+func TestChecksAreRenderedAsNamedConstraints(t *testing.T) {
+	options := checkOptions(
+		Check{Name: "lot_qty_positive", Expression: "qty > 0"},
+		Check{Expression: "qty < 1000"},
+		Check{Expression: "qty <> 13"},
+	)
+
+	assert.Equal(t, "CREATE TABLE IF NOT EXISTS `lot` (\n"+
+		"    `id` CHAR(36) NOT NULL,\n"+
+		"    `qty` INT NOT NULL,\n"+
+		"    PRIMARY KEY (`id`),\n"+
+		"    CONSTRAINT `lot_qty_positive` CHECK (qty > 0),\n"+
+		"    CONSTRAINT `lot_check` CHECK (qty < 1000),\n"+
+		"    CONSTRAINT `lot_check1` CHECK (qty <> 13)\n"+
+		") ENGINE = InnoDB;\n\n",
+		generateWithOptions(t, checkVersion(), db.MYSQLDBType, options, CreateAction))
+
+	assert.Equal(t, "CREATE TABLE IF NOT EXISTS \"lot\" (\n"+
+		"    \"id\" UUID NOT NULL,\n"+
+		"    \"qty\" INTEGER NOT NULL,\n"+
+		"    PRIMARY KEY (\"id\"),\n"+
+		"    CONSTRAINT \"lot_qty_positive\" CHECK (qty > 0),\n"+
+		"    CONSTRAINT \"lot_check\" CHECK (qty < 1000),\n"+
+		"    CONSTRAINT \"lot_check1\" CHECK (qty <> 13)\n"+
+		");\n\n",
+		generateWithOptions(t, checkVersion(), db.PGDBType, options, CreateAction))
+}
+
+// The checks of an entity that is not generated are not an error: options
+// read from a database can cover more tables than are asked for.
+func TestChecksOfOtherEntitiesAreIgnored(t *testing.T) {
+	options := SchemaOptions{Checks: map[string][]Check{"shelf": {{Expression: "1 = 1"}}}}
+	assert.NotContains(t, generateWithOptions(t, checkVersion(), db.SQLiteDBType, options, CreateAction), "CHECK")
+}
+
+// An enum check that a declared check already covers, as it does when the
+// schema is read back from a database it was created in, is not added twice.
+func TestDeclaredChecksTakePrecedenceOverEnumChecks(t *testing.T) {
+	options := checkedEnums
+	options.Checks = map[string][]Check{"post": {{Name: "post_state_check", Expression: "state IN (1, 2)"}}}
+
+	assert.Equal(t, "CREATE TABLE IF NOT EXISTS `post` (\n"+
+		"    `id` CHAR(36) NOT NULL,\n"+
+		"    `state` INT NOT NULL DEFAULT 1,\n"+
+		"    `labels` JSON,\n"+
+		"    PRIMARY KEY (`id`),\n"+
+		"    CONSTRAINT `post_state_check` CHECK (state IN (1, 2))\n"+
+		") ENGINE = InnoDB;\n\n",
+		generateWithOptions(t, enumVersion(), db.MYSQLDBType, options, CreateAction))
+}
+
+func TestMigrationAddsAndReplacesChecks(t *testing.T) {
+	from := checkOptions(Check{Name: "lot_qty_positive", Expression: "qty > 0"})
+	to := checkOptions(
+		Check{Name: "lot_qty_positive", Expression: "qty >= 1"},
+		Check{Name: "lot_qty_small", Expression: "qty < 1000"},
+	)
+
+	m, err := GenerateMigrationWithOptions(checkVersion(), checkVersion(), db.PGDBType, from, to)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{
+		`ALTER TABLE "lot" DROP CONSTRAINT "lot_qty_positive";`,
+		`ALTER TABLE "lot" ADD CONSTRAINT "lot_qty_positive" CHECK (qty >= 1);`,
+		`ALTER TABLE "lot" ADD CONSTRAINT "lot_qty_small" CHECK (qty < 1000);`,
+	}, m.Statements)
+	assert.Equal(t, []string{
+		`ALTER TABLE "lot" DROP CONSTRAINT "lot_qty_positive";`,
+		`ALTER TABLE "lot" DROP CONSTRAINT "lot_qty_small";`,
+		`ALTER TABLE "lot" ADD CONSTRAINT "lot_qty_positive" CHECK (qty > 0);`,
+	}, m.Rollback)
+
+	// sqlite cannot alter a table's constraints, so it rebuilds the table
+	m, err = GenerateMigrationWithOptions(checkVersion(), checkVersion(), db.SQLiteDBType, from, to)
+	assert.NoError(t, err)
+	assert.Contains(t, m.SQL(), `CONSTRAINT "lot_qty_small" CHECK (qty < 1000)`)
+	assert.Contains(t, m.SQL(), `DROP TABLE "lot";`)
+}
@@ -0,0 +1,75 @@
+package tosql
+
+import (
+	"fmt"
+	"strings"
+
+	nemgen "github.com/nuzur/nem/idl/gen"
+	"github.com/nuzur/sql-gen/db"
+)
+
+// The entity and field descriptions are the tables' and columns' comments.
+// mysql declares them in the CREATE TABLE, postgres with a COMMENT ON statement
+// after it, and sqlite has none.
+
+// commentLiteral quotes a comment for the engine. mysql reads a backslash as an
+// escape in a string; postgres, with standard_conforming_strings, does not, and
+// only understands a doubled quote.
+func commentLiteral(comment string, dbType db.DBType) string {
+	if dbType == db.MYSQLDBType {
+		return fmt.Sprintf("'%s'", EscapeValue(comment))
+	}
+	return fmt.Sprintf("'%s'", strings.ReplaceAll(comment, "'", "''"))
+}
+
+// commentClause is the mysql column's COMMENT, empty everywhere else.
+func commentClause(f *nemgen.Field, dbType db.DBType) string {
+	if dbType != db.MYSQLDBType || f.GetDescription() == "" {
+		return ""
+	}
+	return "COMMENT " + commentLiteral(f.GetDescription(), dbType)
+}
+
+// CommentClause is the mysql table's COMMENT option, with the space before it,
+// or empty.
+func (e SchemaEntity) CommentClause() string {
+	if e.DBType != db.MYSQLDBType || e.Comment == "" {
+		return ""
+	}
+	return " COMMENT = " + commentLiteral(e.Comment, e.DBType)
+}
+
+// CommentStatements are the postgres table's COMMENT ON statements, for the
+// table and each of its columns with a description.
+func (e SchemaEntity) CommentStatements() []string {
+	if e.DBType != db.PGDBType {
+		return nil
+	}
+	statements := []string{}
+	if e.Comment != "" {
+		statements = append(statements, e.pgTableComment())
+	}
+	for _, f := range e.Fields {
+		if f.Field.GetDescription() != "" {
+			statements = append(statements, e.pgColumnComment(f))
+		}
+	}
+	return statements
+}
+
+// pgTableComment sets the postgres table's comment, or removes it.
+func (e SchemaEntity) pgTableComment() string {
+	return fmt.Sprintf("COMMENT ON TABLE %s IS %s;", e.QualifiedName(), pgCommentValue(e.Comment))
+}
+
+// pgColumnComment sets the postgres column's comment, or removes it.
+func (e SchemaEntity) pgColumnComment(f SchemaField) string {
+	return fmt.Sprintf(`COMMENT ON COLUMN %s."%s" IS %s;`, e.QualifiedName(), f.Name, pgCommentValue(f.Field.GetDescription()))
+}
+
+func pgCommentValue(comment string) string {
+	if comment == "" {
+		return "NULL"
+	}
+	return commentLiteral(comment, db.PGDBType)
+}
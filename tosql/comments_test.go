@@ -0,0 +1,71 @@
+package tosql
+
+import (
+	"testing"
+
+	nemgen "github.com/nuzur/nem/idl/gen"
+	"github.com/nuzur/sql-gen/db"
+	"github.com/stretchr/testify/assert"
+)
+
+// commentedVersion is a lot table whose description and qty column's
+// description are its comments.
+func commentedVersion(tableComment string, qtyComment string) *nemgen.ProjectVersion {
+	pv := checkVersion()
+	pv.Entities[0].Description = tableComment
+	pv.Entities[0].Fields[1].Description = qtyComment
+	return pv
+}
+
+func TestComments(t *testing.T) {
+	pv := commentedVersion("Stock we can't sell yet", `Units, not C:\boxes`)
+
+	pg := generateWithOptions(t, pv, db.PGDBType, SchemaOptions{}, CreateAction)
+	assert.Contains(t, pg, ");\n"+
+		"COMMENT ON TABLE \"lot\" IS 'Stock we can''t sell yet';\n"+
+		"COMMENT ON COLUMN \"lot\".\"qty\" IS 'Units, not C:\\boxes';\n")
+
+	mysql := generateWithOptions(t, pv, db.MYSQLDBType, SchemaOptions{}, CreateAction)
+	assert.Contains(t, mysql, "    `qty` INT NOT NULL COMMENT 'Units, not C:\\\\boxes',\n")
+	assert.Contains(t, mysql, ") ENGINE = InnoDB COMMENT = 'Stock we can\\'t sell yet';\n")
+
+	assert.NotContains(t, generateWithOptions(t, pv, db.SQLiteDBType, SchemaOptions{}, CreateAction), "COMMENT")
+}
+
+func TestMigrationOfComments(t *testing.T) {
+	from := commentedVersion("Stock", "Units")
+	to := commentedVersion("", "Boxes")
+
+	m, err := GenerateMigration(from, to, db.PGDBType)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{
+		`COMMENT ON TABLE "lot" IS NULL;`,
+		`COMMENT ON COLUMN "lot"."qty" IS 'Boxes';`,
+	}, m.Statements)
+	assert.Equal(t, []string{
+		`COMMENT ON TABLE "lot" IS 'Stock';`,
+		`COMMENT ON COLUMN "lot"."qty" IS 'Units';`,
+	}, m.Rollback)
+
+	m, err = GenerateMigration(from, to, db.MYSQLDBType)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{
+		"ALTER TABLE `lot` MODIFY COLUMN `qty` INT NOT NULL COMMENT 'Boxes';",
+		"ALTER TABLE `lot` COMMENT = '';",
+	}, m.Statements)
+
+	// a new column is commented once it is added
+	added := commentedVersion("Stock", "Units")
+	added.Entities[0].Fields = append(added.Entities[0].Fields, migrationField("lot.note", "note", nemgen.FieldType_FIELD_TYPE_TEXT, false))
+	added.Entities[0].Fields[2].Description = "Free text"
+	m, err = GenerateMigration(from, added, db.PGDBType)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{
+		`ALTER TABLE "lot" ADD COLUMN "note" TEXT;`,
+		`COMMENT ON COLUMN "lot"."note" IS 'Free text';`,
+	}, m.Statements)
+
+	m, err = GenerateMigration(from, to, db.SQLiteDBType)
+	assert.NoError(t, err)
+	assert.Empty(t, m.Statements)
+}
@@ -0,0 +1,59 @@
+package tosql
+
+import (
+	"fmt"
+
+	"github.com/nuzur/sql-gen/db"
+)
+
+// Computed makes a field a generated column, whose value the database works out
+// from the row's other columns. nem has no field for one, so they travel beside
+// the project version in SchemaOptions.
+type Computed struct {
+	// Expression is the column's value in the engine's own SQL, without the
+	// parentheses around it.
+	Expression string `json:"expression"`
+	// Stored keeps the value on disk instead of working it out on every read.
+	// Postgres only has stored generated columns, and makes every one stored.
+	Stored bool `json:"stored,omitempty"`
+}
+
+// clause is the column's GENERATED ALWAYS AS clause for the engine.
+func (c Computed) clause(dbType db.DBType) string {
+	kind := "VIRTUAL"
+	if c.Stored || dbType == db.PGDBType {
+		kind = "STORED"
+	}
+	return fmt.Sprintf("GENERATED ALWAYS AS (%s) %s", c.Expression, kind)
+}
+
+// computeColumns makes the entity's computed fields generated columns. A
+// generated column cannot have a default, an ON UPDATE or an identity of its
+// own: its value is the expression's.
+func (e *SchemaEntity) computeColumns(computed map[string]Computed) {
+	for i, f := range e.Fields {
+		c, ok := computed[f.Field.GetUuid()]
+		if !ok || c.Expression == "" {
+			continue
+		}
+		e.Fields[i].Computed = c.clause(e.DBType)
+		e.Fields[i].Default = ""
+		e.Fields[i].OnUpdate = ""
+		e.Fields[i].Identity = ""
+	}
+}
+
+// InsertFields are the fields an INSERT names: all but the computed ones,
+// which the databases refuse to be written.
+func (e SchemaEntity) InsertFields() []SchemaField {
+	res := []SchemaField{}
+	for _, f := range e.Fields {
+		if f.Computed == "" {
+			res = append(res, f)
+		}
+	}
+	for i := range res {
+		res[i].HasComma = i < len(res)-1
+	}
+	return res
+}
@@ -0,0 +1,101 @@
+package tosql
+
+import (
+	"context"
+	"testing"
+
+	nemgen "github.com/nuzur/nem/idl/gen"
+	"github.com/nuzur/sql-gen/db"
+	"github.com/stretchr/testify/assert"
+)
+
+// computedVersion is a lot whose total is worked out from its qty.
+func computedVersion() *nemgen.ProjectVersion {
+	pv := checkVersion()
+	lot := pv.Entities[0]
+	lot.Fields = append(lot.Fields, migrationField("lot.total", "total", nemgen.FieldType_FIELD_TYPE_INTEGER, false))
+	return pv
+}
+
+var computedOptions = SchemaOptions{Computed: map[string]Computed{
+	"lot.total": {Expression: "qty * 2"},
+}}
+
+func TestComputedColumnsAreGenerated(t *testing.T) {
+	assert.Contains(t, generateWithOptions(t, computedVersion(), db.PGDBType, computedOptions, CreateAction),
+		"\"total\" INTEGER GENERATED ALWAYS AS (qty * 2) STORED,\n")
+	assert.Contains(t, generateWithOptions(t, computedVersion(), db.MYSQLDBType, computedOptions, CreateAction),
+		"`total` INT GENERATED ALWAYS AS (qty * 2) VIRTUAL,\n")
+	assert.Contains(t, generateWithOptions(t, computedVersion(), db.SQLiteDBType, computedOptions, CreateAction),
+		"\"total\" INTEGER GENERATED ALWAYS AS (qty * 2) VIRTUAL,\n")
+
+	stored := SchemaOptions{Computed: map[string]Computed{"lot.total": {Expression: "qty * 2", Stored: true}}}
+	assert.Contains(t, generateWithOptions(t, computedVersion(), db.MYSQLDBType, stored, CreateAction),
+		"`total` INT GENERATED ALWAYS AS (qty * 2) STORED,\n")
+
+	// a generated datetime does not get the DEFAULT every other datetime does
+	pv := computedVersion()
+	pv.Entities[0].Fields[2].Type = nemgen.FieldType_FIELD_TYPE_DATETIME
+	pv.Entities[0].Fields[2].Required = true
+	assert.Contains(t, generateWithOptions(t, pv, db.MYSQLDBType, SchemaOptions{Computed: map[string]Computed{
+		"lot.total": {Expression: "now()"},
+	}}, CreateAction), "`total` DATETIME GENERATED ALWAYS AS (now()) VIRTUAL NOT NULL,\n")
+}
+
+func TestComputedColumnsAreNotWritten(t *testing.T) {
+	assert.Equal(t, "-- name: InsertLot :execresult\n"+
+		"INSERT INTO \"lot\"\n(\"id\",\"qty\")\nVALUES\n(?,?);\n\n",
+		generateWithOptions(t, computedVersion(), db.PGDBType, computedOptions, InsertAction))
+	assert.Equal(t, "-- name: UpdateLot :exec\n"+
+		"UPDATE \"lot\"\nSET\n\"qty\" = ?\nWHERE\n\"id\" = ?;\n\n",
+		generateWithOptions(t, computedVersion(), db.PGDBType, computedOptions, UpdateAction))
+
+	pv := computedVersion()
+	insert, err := GenerateInsertForEntityWithValues(context.Background(), GenerateInsertForEntityWithValuesParams{
+		Entity:         pv.Entities[0],
+		ProjectVersion: pv,
+		DBType:         db.PGDBType,
+		SchemaOptions:  computedOptions,
+		Values:         map[string]string{"lot.id": "a", "lot.qty": "4", "lot.total": "8"},
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, "INSERT INTO \"lot\"\n(\"id\",\"qty\")\nVALUES\n($1,$2);", insert.ParametrizedSQL)
+	assert.Equal(t, []string{"a", "4"}, insert.Params)
+
+	update, err := GenerateUpdateForEntityWithValues(context.Background(), GenerateUpdateForEntityWithValuesParams{
+		Entity:         pv.Entities[0],
+		ProjectVersion: pv,
+		DBType:         db.PGDBType,
+		ForGolang:      true,
+		SchemaOptions:  computedOptions,
+		Values:         map[string]string{"lot.qty": "4", "lot.total": "8"},
+		Keys:           map[string]string{"lot.id": "a"},
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, "UPDATE \"lot\"\nSET\n\"qty\" = $1\nWHERE\n\"id\" = $2;\n", update.ParametrizedSQL)
+	assert.Equal(t, []string{"4", "a"}, update.Params)
+}
+
+func TestMigrationAddsColumnsAgainToChangeTheirGeneration(t *testing.T) {
+	pv := computedVersion()
+	pv.Entities[0].TypeConfig.Standalone.Indexes = []*nemgen.Index{
+		migrationIndex("lot.total_idx", "idx_lot_total", nemgen.IndexType_INDEX_TYPE_INDEX, "lot.total"),
+	}
+	m, err := GenerateMigrationWithOptions(pv, pv, db.MYSQLDBType, SchemaOptions{}, computedOptions)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{
+		"DROP INDEX `idx_lot_total` ON `lot`;",
+		"ALTER TABLE `lot` DROP COLUMN `total`;",
+		"ALTER TABLE `lot` ADD COLUMN `total` INT GENERATED ALWAYS AS (qty * 2) VIRTUAL AFTER `qty`;",
+		"CREATE INDEX `idx_lot_total` ON `lot` (`total`);",
+	}, m.Statements)
+
+	// postgres keeps the values of a column that stops being generated
+	m, err = GenerateMigrationWithOptions(computedVersion(), computedVersion(), db.PGDBType, computedOptions, SchemaOptions{})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{`ALTER TABLE "lot" ALTER COLUMN "total" DROP EXPRESSION;`}, m.Statements)
+	assert.Equal(t, []string{
+		`ALTER TABLE "lot" DROP COLUMN "total";`,
+		`ALTER TABLE "lot" ADD COLUMN "total" INTEGER GENERATED ALWAYS AS (qty * 2) STORED;`,
+	}, m.Rollback)
+}
@@ -16,12 +16,50 @@ const (
 	UpdateAction                   Action = "update"
 	DeleteAction                   Action = "delete"
 	CreateAction                   Action = "create"
+	// DropAction is the rollback of CreateAction: it drops the same tables,
+	// dependents first.
+	DropAction Action = "drop"
+	// UpsertAction inserts a row, or overwrites the one with the same primary
+	// key, or with the same values of a unique index.
+	UpsertAction Action = "upsert"
 )
 
 type ConfigValues struct {
 	DBType   db.DBType `json:"db_type"`
 	Entities []string  `json:"entities"`
 	Actions  []Action  `json:"actions"`
+	SchemaOptions
+}
+
+// SchemaOptions are what rendering a schema takes besides the project
+// version: the choices it does not make itself, and the parts of a database
+// schema nem has no field for.
+type SchemaOptions struct {
+	EnumMode EnumMode `json:"enum_mode,omitempty"`
+	// Checks are the CHECK constraints of each entity's table, by entity uuid.
+	Checks map[string][]Check `json:"checks,omitempty"`
+	// Schemas are the postgres schemas entity tables and enum types are in, by
+	// entity or enum uuid. Anything not listed is left unqualified, in whatever
+	// schema the connection defaults to. Other engines ignore them.
+	Schemas map[string]string `json:"schemas,omitempty"`
+	// Views are the views over the tables, created after them.
+	Views []View `json:"views,omitempty"`
+	// AutoIncrement is the value each mysql table's auto-increment key starts
+	// counting from, by entity uuid. It only sets up a table being created: on
+	// one that exists the counter belongs to the rows, so a migration leaves it
+	// alone. Other engines ignore it.
+	AutoIncrement map[string]int64 `json:"auto_increment,omitempty"`
+	// Computed are the generated columns, by field uuid.
+	Computed map[string]Computed `json:"computed,omitempty"`
+	// Indexes are what the indexes have beyond their fields, such as a WHERE
+	// or an access method, by index uuid.
+	Indexes map[string]IndexOptions `json:"indexes,omitempty"`
+	// SoftDelete is the nullable datetime field each entity's rows are soft
+	// deleted through, by entity uuid: the delete queries and
+	// GenerateDeleteForEntityWithValues set it instead of removing the row,
+	// and the selects leave out the rows it is set on. A field set on update
+	// cannot be one.
+	SoftDelete map[string]string `json:"soft_delete,omitempty"`
 }
 
 func ConfigValuesFromAny(any interface{}) (*ConfigValues, error) {
@@ -58,7 +58,7 @@ func defaultClause(f *nemgen.Field, dbType db.DBType) string {
 			}
 			return "DEFAULT " + value
 		}
-		return fmt.Sprintf("DEFAULT '%s'", EscapeValue(value))
+		return fmt.Sprintf("DEFAULT '%s'", escapeLiteral(value, dbType))
 	}
 
 	if f.GetType() == nemgen.FieldType_FIELD_TYPE_DATETIME {
@@ -81,9 +81,13 @@ func defaultClause(f *nemgen.Field, dbType db.DBType) string {
 // back through information_schema without the parentheses, so they are added
 // here rather than expected from the model. Postgres accepts either form and
 // reports it back unparenthesized too.
+//
+// sqlite has the same rule as mysql — only literals and the CURRENT_* keywords
+// may appear bare — but reports the default back exactly as written, so there
+// it is fromsql that removes the parentheses again.
 func defaultExpression(value string, dbType db.DBType) string {
 	value = strings.TrimSpace(value)
-	if dbType != db.MYSQLDBType {
+	if dbType != db.MYSQLDBType && dbType != db.SQLiteDBType {
 		return value
 	}
 	if currentTimestampExpr.MatchString(value) {
@@ -128,6 +132,10 @@ func booleanLiteral(value string) (isTrue bool, ok bool) {
 // exactly this, but it is gated on the column type being spelled "boolean",
 // which this generator never emits.)
 //
+// sqlite has no boolean storage class either: TRUE and FALSE are accepted as
+// aliases for 1 and 0, but PRAGMA table_info reports the default as written, so
+// it gets the same numeric spelling as mysql to keep one canonical form.
+//
 // Postgres has a real boolean and reports true/false, so it gets the keywords.
 // That also fixes the other direction: a model that stores "1" on a boolean
 // field — every project imported from mysql — rendered BOOLEAN DEFAULT 1, which
@@ -146,7 +154,7 @@ func booleanDefaultLiteral(value string, dbType db.DBType) string {
 		// engine's error names the column.
 		return value
 	}
-	if dbType == db.MYSQLDBType {
+	if dbType == db.MYSQLDBType || dbType == db.SQLiteDBType {
 		if isTrue {
 			return "1"
 		}
@@ -158,6 +166,24 @@ func booleanDefaultLiteral(value string, dbType db.DBType) string {
 	return "false"
 }
 
+// identityClause makes an auto-increment integer key the database fills in
+// itself: a postgres identity column, or a mysql AUTO_INCREMENT one. The
+// identity is BY DEFAULT rather than ALWAYS so a row can still be inserted with
+// an explicit key, as it can into the serial columns it replaces. A key of any
+// other type has nothing to count with.
+func identityClause(f *nemgen.Field, dbType db.DBType) string {
+	if !f.GetKey() || !f.GetKeyAutoIncrement() || f.GetType() != nemgen.FieldType_FIELD_TYPE_INTEGER {
+		return ""
+	}
+	switch dbType {
+	case db.PGDBType:
+		return "GENERATED BY DEFAULT AS IDENTITY"
+	case db.MYSQLDBType:
+		return "AUTO_INCREMENT"
+	}
+	return ""
+}
+
 // onUpdateClause renders ON UPDATE CURRENT_TIMESTAMP for a datetime field that
 // asks for it.
 //
@@ -0,0 +1,136 @@
+package tosql
+
+import (
+	"context"
+	"os"
+	"testing"
+
+	"github.com/gofrs/uuid"
+	nemgen "github.com/nuzur/nem/idl/gen"
+	"github.com/nuzur/sql-gen/db"
+	"github.com/stretchr/testify/assert"
+)
+
+// cyclicVersion has two tables that reference each other, and one that
+// references itself.
+func cyclicVersion() *nemgen.ProjectVersion {
+	return &nemgen.ProjectVersion{
+		Entities: []*nemgen.Entity{
+			migrationTable("team", "team", []*nemgen.Field{
+				migrationKey("team.id"),
+				migrationField("team.captain_id", "captain_id", nemgen.FieldType_FIELD_TYPE_UUID, false),
+			}),
+			migrationTable("player", "player", []*nemgen.Field{
+				migrationKey("player.id"),
+				migrationField("player.team_id", "team_id", nemgen.FieldType_FIELD_TYPE_UUID, false),
+				migrationField("player.mentor_id", "mentor_id", nemgen.FieldType_FIELD_TYPE_UUID, false),
+			}),
+		},
+		Relationships: []*nemgen.Relationship{
+			migrationRelationship("team_captain", "team_captain", "team", "team.captain_id", "player", "player.id"),
+			migrationRelationship("player_team", "player_team", "player", "player.team_id", "team", "team.id"),
+			migrationRelationship("player_mentor", "player_mentor", "player", "player.mentor_id", "player", "player.id"),
+		},
+	}
+}
+
+func generateDrop(t *testing.T, pv *nemgen.ProjectVersion, dbType db.DBType) string {
+	t.Helper()
+
+	entities := []string{}
+	for _, e := range pv.Entities {
+		entities = append(entities, e.Uuid)
+	}
+	res, err := GenerateSQL(context.Background(), GenerateRequest{
+		ExecutionUUID:  uuid.Must(uuid.NewV4()).String(),
+		ProjectVersion: pv,
+		Configvalues: &ConfigValues{
+			DBType:   dbType,
+			Entities: entities,
+			Actions:  []Action{DropAction},
+		},
+	})
+	if !assert.NoError(t, err) {
+		return ""
+	}
+	t.Cleanup(func() {
+		os.RemoveAll(res.WorkingDir)
+		os.RemoveAll(res.ZipFile)
+	})
+	return res.Results[0].Data
+}
+
+// Dependents go first: a table can only be dropped once nothing references it.
+func TestDropOrderIsTheReverseOfCreate(t *testing.T) {
+	assert.Equal(t,
+		"DROP TABLE IF EXISTS `book`;\n"+
+			"DROP TABLE IF EXISTS `shelf`;\n"+
+			"DROP TABLE IF EXISTS `author`;\n",
+		generateDrop(t, migrationBaseVersion(), db.MYSQLDBType))
+}
+
+// No table order satisfies a foreign key cycle, so the constraints closing it
+// go first. The self-reference is not one of them.
+func TestDropBreaksForeignKeyCyclesFirst(t *testing.T) {
+	assert.Equal(t,
+		"ALTER TABLE `team` DROP FOREIGN KEY `team_captain`;\n"+
+			"DROP TABLE IF EXISTS `player`;\n"+
+			"DROP TABLE IF EXISTS `team`;\n",
+		generateDrop(t, cyclicVersion(), db.MYSQLDBType))
+
+	assert.Equal(t,
+		"ALTER TABLE \"team\" DROP CONSTRAINT IF EXISTS \"team_captain\";\n"+
+			"DROP TABLE IF EXISTS \"player\";\n"+
+			"DROP TABLE IF EXISTS \"team\";\n",
+		generateDrop(t, cyclicVersion(), db.PGDBType))
+
+	assert.Equal(t,
+		"PRAGMA foreign_keys = OFF;\n"+
+			"DROP TABLE IF EXISTS \"player\";\n"+
+			"DROP TABLE IF EXISTS \"team\";\n"+
+			"PRAGMA foreign_keys = ON;\n",
+		generateDrop(t, cyclicVersion(), db.SQLiteDBType))
+}
+
+// Tables are told apart by their schema too: the item order references is not
+// the item in billing, which comes after it, so nothing closes a cycle.
+func TestDropTellsSameNamedTablesApartBySchema(t *testing.T) {
+	pv := &nemgen.ProjectVersion{
+		Entities: []*nemgen.Entity{
+			migrationTable("shop_item", "item", []*nemgen.Field{
+				migrationKey("shop_item.id"),
+			}),
+			migrationTable("order", "order", []*nemgen.Field{
+				migrationKey("order.id"),
+				migrationField("order.item_id", "item_id", nemgen.FieldType_FIELD_TYPE_UUID, false),
+			}),
+			migrationTable("billing_item", "item", []*nemgen.Field{
+				migrationKey("billing_item.id"),
+				migrationField("billing_item.order_id", "order_id", nemgen.FieldType_FIELD_TYPE_UUID, false),
+			}),
+		},
+		Relationships: []*nemgen.Relationship{
+			migrationRelationship("order_item", "order_item", "order", "order.item_id", "shop_item", "shop_item.id"),
+			migrationRelationship("billing_item_order", "billing_item_order", "billing_item", "billing_item.order_id", "order", "order.id"),
+		},
+	}
+	results, err := GenerateSQLResults(context.Background(), GenerateRequest{
+		ProjectVersion: pv,
+		Configvalues: &ConfigValues{
+			DBType:   db.PGDBType,
+			Entities: []string{"shop_item", "order", "billing_item"},
+			Actions:  []Action{DropAction},
+			SchemaOptions: SchemaOptions{
+				Schemas: map[string]string{"shop_item": "shop", "order": "shop", "billing_item": "billing"},
+			},
+		},
+	})
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Equal(t,
+		"DROP TABLE IF EXISTS \"billing\".\"item\";\n"+
+			"DROP TABLE IF EXISTS \"shop\".\"order\";\n"+
+			"DROP TABLE IF EXISTS \"shop\".\"item\";\n",
+		results[0].Data)
+}
@@ -0,0 +1,214 @@
+package tosql
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+
+	nemgen "github.com/nuzur/nem/idl/gen"
+	"github.com/nuzur/sql-gen/db"
+)
+
+// EnumMode is how the column of an enum field is declared.
+type EnumMode string
+
+const (
+	// EnumModeInteger stores the numeric value of the selected option, or a
+	// JSON array for a multi-select, and leaves the database free to store
+	// anything. It is what an unset mode means.
+	EnumModeInteger EnumMode = "integer"
+	// EnumModeNative uses the engine's own enum type: a named
+	// CREATE TYPE ... AS ENUM on postgres, ENUM(...) and SET(...) on mysql. The
+	// column stores the option's identifier. sqlite has no enum type, so there
+	// the column is TEXT restricted to the identifiers by a CHECK.
+	EnumModeNative EnumMode = "native"
+	// EnumModeCheck keeps the integer column and adds a CHECK restricting it to
+	// the enum's numeric values.
+	EnumModeCheck EnumMode = "check"
+)
+
+// SchemaEnum is a postgres enum type, created before the tables that use it.
+type SchemaEnum struct {
+	DBType db.DBType
+	Uuid   string
+	Schema string
+	Name   string
+	Values []string
+}
+
+// QualifiedName is the quoted name of the type, in its schema if it has one.
+func (e SchemaEnum) QualifiedName() string {
+	return qualifiedName(e.DBType, e.Schema, e.Name)
+}
+
+// ValuesList is the type's labels as a list of literals, in order.
+func (e SchemaEnum) ValuesList() string {
+	return quoteEnumLiterals(e.Values, e.DBType)
+}
+
+// applyEnumMode rewrites the columns of the entities' enum fields for the
+// options' enum mode, and returns the postgres enum types they use in the order they
+// are first used.
+//
+// An enum field is left as mapField rendered it when its enum has no static
+// values to restrict the column to — an unknown enum or one with remote
+// values — and in check mode when it is a multi-select, whose JSON array no
+// portable CHECK can look inside.
+func applyEnumMode(entities []SchemaEntity, projectVersion *nemgen.ProjectVersion, dbType db.DBType, options SchemaOptions) []SchemaEnum {
+	mode := options.EnumMode
+	if mode != EnumModeNative && mode != EnumModeCheck {
+		return nil
+	}
+
+	enums := make(map[string]*nemgen.Enum)
+	for _, e := range projectVersion.GetEnums() {
+		enums[e.GetUuid()] = e
+	}
+
+	res := []SchemaEnum{}
+	used := make(map[string]bool)
+	for i := range entities {
+		entity := &entities[i]
+		for j := range entity.Fields {
+			f := &entity.Fields[j]
+			if f.Field.GetType() != nemgen.FieldType_FIELD_TYPE_ENUM {
+				continue
+			}
+			config := f.Field.GetTypeConfig().GetEnum()
+			enum := enums[config.GetEnumUuid()]
+			if enum == nil || enum.GetRemoteValues() || len(enum.GetStaticValues()) == 0 {
+				continue
+			}
+			identifiers := []string{}
+			numbers := []string{}
+			for _, v := range enum.GetStaticValues() {
+				identifiers = append(identifiers, v.GetIdentifier())
+				numbers = append(numbers, strconv.FormatInt(v.GetNumericValue(), 10))
+			}
+			column := entity.quote(f.Name)
+
+			if mode == EnumModeCheck {
+				if config.GetAllowMultiple() {
+					continue
+				}
+				entity.addCheck(f.Name, fmt.Sprintf("%s IN (%s)", column, strings.Join(numbers, ", ")))
+				if d, ok := enumDefaultValue(f.Field, enum, false); ok {
+					f.Default = "DEFAULT " + d
+				}
+				continue
+			}
+
+			labels := make(map[string]string)
+			for _, v := range enum.GetStaticValues() {
+				labels[strconv.FormatInt(v.GetNumericValue(), 10)] = v.GetIdentifier()
+				labels[v.GetIdentifier()] = v.GetIdentifier()
+			}
+			f.EnumLabels = labels
+
+			switch dbType {
+			case db.MYSQLDBType:
+				kind := "ENUM"
+				if config.GetAllowMultiple() {
+					kind = "SET"
+				}
+				f.Type = fmt.Sprintf("%s(%s)", kind, quoteEnumLiterals(identifiers, dbType))
+			case db.PGDBType:
+				schemaEnum := SchemaEnum{
+					DBType: dbType,
+					Uuid:   enum.GetUuid(),
+					Schema: options.Schemas[enum.GetUuid()],
+					Name:   enum.GetIdentifier(),
+					Values: identifiers,
+				}
+				f.Type = schemaEnum.QualifiedName()
+				if config.GetAllowMultiple() {
+					f.Type += "[]"
+				}
+				if !used[enum.GetUuid()] {
+					used[enum.GetUuid()] = true
+					res = append(res, schemaEnum)
+				}
+			case db.SQLiteDBType:
+				if config.GetAllowMultiple() {
+					// left the JSON array of numbers
+					f.EnumLabels = nil
+					continue
+				}
+				f.Type = "TEXT"
+				entity.addCheck(f.Name, fmt.Sprintf("%s IN (%s)", column, quoteEnumLiterals(identifiers, dbType)))
+			}
+			if d, ok := enumDefaultValue(f.Field, enum, true); ok {
+				f.Default = fmt.Sprintf("DEFAULT '%s'", escapeLiteral(d, dbType))
+			}
+		}
+	}
+	return res
+}
+
+// enumDefaultValue resolves a literal default on an enum field to the option
+// it names — given as either its identifier or its numeric value, since which
+// one a model stores depends on where it came from — and returns the option's
+// identifier or number. ok=false leaves the default mapField rendered.
+func enumDefaultValue(f *nemgen.Field, enum *nemgen.Enum, identifier bool) (string, bool) {
+	value := f.GetDefaultValue()
+	if value == "" || f.GetDefaultValueIsExpression() {
+		return "", false
+	}
+	for _, v := range enum.GetStaticValues() {
+		if value == v.GetIdentifier() || value == strconv.FormatInt(v.GetNumericValue(), 10) {
+			if identifier {
+				return v.GetIdentifier(), true
+			}
+			return strconv.FormatInt(v.GetNumericValue(), 10), true
+		}
+	}
+	if identifier {
+		// a multi-select default lists several options, as 'a,b' on mysql and
+		// '{a,b}' on postgres; it is passed through as written
+		return value, true
+	}
+	return "", false
+}
+
+func quoteEnumLiterals(values []string, dbType db.DBType) string {
+	quoted := []string{}
+	for _, v := range values {
+		quoted = append(quoted, fmt.Sprintf("'%s'", escapeLiteral(v, dbType)))
+	}
+	return strings.Join(quoted, ", ")
+}
+
+// enumLabelValue is the value a native enum column takes for an option given
+// as its numeric value or its label: the label, and for a multi-select the
+// labels of a JSON array of options, as mysql's SET ('a,b') or postgres'
+// array ('{"a","b"}') spells them.
+func enumLabelValue(f SchemaField, value string, dbType db.DBType) (string, error) {
+	if !f.Field.GetTypeConfig().GetEnum().GetAllowMultiple() {
+		label, ok := f.EnumLabels[value]
+		if !ok {
+			return "", fmt.Errorf("not an option of the enum")
+		}
+		return label, nil
+	}
+
+	var options []any
+	if err := json.Unmarshal([]byte(value), &options); err != nil {
+		return "", fmt.Errorf("not a json array of options")
+	}
+	labels := []string{}
+	for _, o := range options {
+		label, ok := f.EnumLabels[strings.Trim(fmt.Sprint(o), `"`)]
+		if !ok {
+			return "", fmt.Errorf("%v is not an option of the enum", o)
+		}
+		if dbType == db.PGDBType {
+			label = fmt.Sprintf(`"%s"`, strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(label))
+		}
+		labels = append(labels, label)
+	}
+	if dbType == db.PGDBType {
+		return fmt.Sprintf("{%s}", strings.Join(labels, ",")), nil
+	}
+	return strings.Join(labels, ","), nil
+}
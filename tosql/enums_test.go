@@ -0,0 +1,302 @@
+package tosql
+
+import (
+	"context"
+	"os"
+	"testing"
+
+	"github.com/gofrs/uuid"
+	nemgen "github.com/nuzur/nem/idl/gen"
+	"github.com/nuzur/sql-gen/db"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/protobuf/proto"
+)
+
+var (
+	nativeEnums  = SchemaOptions{EnumMode: EnumModeNative}
+	checkedEnums = SchemaOptions{EnumMode: EnumModeCheck}
+)
+
+func enumField(uuid string, identifier string, enumUuid string, allowMultiple bool) *nemgen.Field {
+	f := migrationField(uuid, identifier, nemgen.FieldType_FIELD_TYPE_ENUM, false)
+	f.TypeConfig.Enum = &nemgen.FieldTypeEnumConfig{EnumUuid: enumUuid, AllowMultiple: allowMultiple}
+	return f
+}
+
+// enumVersion has a post whose state is a single-select enum, defaulted by
+// numeric value, and whose labels are a multi-select one.
+func enumVersion() *nemgen.ProjectVersion {
+	state := enumField("post.state", "state", "post_state", false)
+	state.Required = true
+	state.DefaultValue = "1"
+	return &nemgen.ProjectVersion{
+		Entities: []*nemgen.Entity{
+			migrationTable("post", "post", []*nemgen.Field{
+				migrationKey("post.id"),
+				state,
+				enumField("post.labels", "labels", "label", true),
+			}),
+		},
+		Enums: []*nemgen.Enum{
+			{
+				Uuid:       "post_state",
+				Identifier: "post_state",
+				StaticValues: []*nemgen.EnumValue{
+					{Identifier: "draft", NumericValue: 1},
+					{Identifier: "published", NumericValue: 2},
+				},
+			},
+			{
+				Uuid:       "label",
+				Identifier: "label",
+				StaticValues: []*nemgen.EnumValue{
+					{Identifier: "news", NumericValue: 1},
+					{Identifier: "it's", NumericValue: 2},
+				},
+			},
+		},
+	}
+}
+
+func generateWithOptions(t *testing.T, pv *nemgen.ProjectVersion, dbType db.DBType, options SchemaOptions, action Action) string {
+	t.Helper()
+
+	entities := []string{}
+	for _, e := range pv.Entities {
+		entities = append(entities, e.Uuid)
+	}
+	res, err := GenerateSQL(context.Background(), GenerateRequest{
+		ExecutionUUID:  uuid.Must(uuid.NewV4()).String(),
+		ProjectVersion: pv,
+		Configvalues: &ConfigValues{
+			DBType:        dbType,
+			Entities:      entities,
+			Actions:       []Action{action},
+			SchemaOptions: options,
+		},
+	})
+	if !assert.NoError(t, err) {
+		return ""
+	}
+	t.Cleanup(func() {
+		os.RemoveAll(res.WorkingDir)
+		os.RemoveAll(res.ZipFile)
+	})
+	return res.Results[0].Data
+}
+
+func TestIntegerEnumsAreUnconstrained(t *testing.T) {
+	for _, mode := range []EnumMode{"", EnumModeInteger} {
+		assert.Equal(t, "CREATE TABLE IF NOT EXISTS `post` (\n"+
+			"    `id` CHAR(36) NOT NULL,\n"+
+			"    `state` INT NOT NULL DEFAULT 1,\n"+
+			"    `labels` JSON,\n"+
+			"    PRIMARY KEY (`id`)\n"+
+			") ENGINE = InnoDB;\n\n",
+			generateWithOptions(t, enumVersion(), db.MYSQLDBType, SchemaOptions{EnumMode: mode}, CreateAction))
+	}
+}
+
+func TestNativeEnums(t *testing.T) {
+	assert.Equal(t, "CREATE TABLE IF NOT EXISTS `post` (\n"+
+		"    `id` CHAR(36) NOT NULL,\n"+
+		"    `state` ENUM('draft', 'published') NOT NULL DEFAULT 'draft',\n"+
+		"    `labels` SET('news', 'it\\'s'),\n"+
+		"    PRIMARY KEY (`id`)\n"+
+		") ENGINE = InnoDB;\n\n",
+		generateWithOptions(t, enumVersion(), db.MYSQLDBType, nativeEnums, CreateAction))
+
+	assert.Equal(t, "DO $$ BEGIN\n"+
+		"    CREATE TYPE \"post_state\" AS ENUM ('draft', 'published');\n"+
+		"EXCEPTION\n"+
+		"    WHEN duplicate_object THEN NULL;\n"+
+		"END $$;\n\n"+
+		"DO $$ BEGIN\n"+
+		"    CREATE TYPE \"label\" AS ENUM ('news', 'it''s');\n"+
+		"EXCEPTION\n"+
+		"    WHEN duplicate_object THEN NULL;\n"+
+		"END $$;\n\n"+
+		"CREATE TABLE IF NOT EXISTS \"post\" (\n"+
+		"    \"id\" UUID NOT NULL,\n"+
+		"    \"state\" \"post_state\" NOT NULL DEFAULT 'draft',\n"+
+		"    \"labels\" \"label\"[],\n"+
+		"    PRIMARY KEY (\"id\")\n"+
+		");\n\n",
+		generateWithOptions(t, enumVersion(), db.PGDBType, nativeEnums, CreateAction))
+
+	// no enum type: the identifiers are checked instead, and a multi-select
+	// stays JSON
+	assert.Equal(t, "CREATE TABLE IF NOT EXISTS \"post\" (\n"+
+		"    \"id\" CHAR(36) NOT NULL,\n"+
+		"    \"state\" TEXT NOT NULL DEFAULT 'draft',\n"+
+		"    \"labels\" JSON,\n"+
+		"    PRIMARY KEY (\"id\"),\n"+
+		"    CONSTRAINT \"post_state_check\" CHECK (\"state\" IN ('draft', 'published'))\n"+
+		");\n\n",
+		generateWithOptions(t, enumVersion(), db.SQLiteDBType, nativeEnums, CreateAction))
+}
+
+func TestCheckedEnums(t *testing.T) {
+	pv := enumVersion()
+	// the identifier is as good a default as the number
+	pv.Entities[0].Fields[1].DefaultValue = "published"
+
+	assert.Equal(t, "CREATE TABLE IF NOT EXISTS `post` (\n"+
+		"    `id` CHAR(36) NOT NULL,\n"+
+		"    `state` INT NOT NULL DEFAULT 2,\n"+
+		"    `labels` JSON,\n"+
+		"    PRIMARY KEY (`id`),\n"+
+		"    CONSTRAINT `post_state_check` CHECK (`state` IN (1, 2))\n"+
+		") ENGINE = InnoDB;\n\n",
+		generateWithOptions(t, pv, db.MYSQLDBType, checkedEnums, CreateAction))
+
+	assert.Equal(t, "CREATE TABLE IF NOT EXISTS \"post\" (\n"+
+		"    \"id\" UUID NOT NULL,\n"+
+		"    \"state\" INTEGER NOT NULL DEFAULT 2,\n"+
+		"    \"labels\" JSON,\n"+
+		"    PRIMARY KEY (\"id\"),\n"+
+		"    CONSTRAINT \"post_state_check\" CHECK (\"state\" IN (1, 2))\n"+
+		");\n\n",
+		generateWithOptions(t, pv, db.PGDBType, checkedEnums, CreateAction))
+}
+
+// An enum whose values live elsewhere gives the column nothing to be
+// restricted to.
+// A native enum column takes the option's label, whatever the option is given
+// as; a checked one keeps the number.
+func TestEnumStatementValues(t *testing.T) {
+	pv := enumVersion()
+	values := map[string]string{"post.id": "a", "post.state": "1", "post.labels": `[2, "news"]`}
+	insert, err := GenerateInsertForEntityWithValues(context.Background(), GenerateInsertForEntityWithValuesParams{
+		Entity:         pv.Entities[0],
+		ProjectVersion: pv,
+		DBType:         db.PGDBType,
+		SchemaOptions:  nativeEnums,
+		Values:         values,
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"a", "draft", `{"it's","news"}`}, insert.Params)
+
+	rows, err := GenerateBulkInsertForEntity(context.Background(), GenerateBulkInsertForEntityParams{
+		Entity:         pv.Entities[0],
+		ProjectVersion: pv,
+		DBType:         db.MYSQLDBType,
+		SchemaOptions:  nativeEnums,
+		Rows:           []map[string]string{values},
+	})
+	assert.NoError(t, err)
+	assert.Contains(t, rows[0].SQL, `('a','draft','it\'s,news')`)
+
+	update, err := GenerateUpdateForEntityWithValues(context.Background(), GenerateUpdateForEntityWithValuesParams{
+		Entity:         pv.Entities[0],
+		ProjectVersion: pv,
+		DBType:         db.SQLiteDBType,
+		SchemaOptions:  checkedEnums,
+		Values:         map[string]string{"post.state": "2"},
+		Keys:           map[string]string{"post.id": "a"},
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"2", "a"}, update.Params)
+}
+
+func TestEnumsWithRemoteValuesStayIntegers(t *testing.T) {
+	pv := enumVersion()
+	pv.Enums[0].RemoteValues = true
+	assert.Contains(t, generateWithOptions(t, pv, db.MYSQLDBType, nativeEnums, CreateAction), "`state` INT NOT NULL DEFAULT 1,")
+	assert.NotContains(t, generateWithOptions(t, pv, db.MYSQLDBType, checkedEnums, CreateAction), "CHECK")
+}
+
+func TestNativeEnumTypesAreDroppedAfterTheirTables(t *testing.T) {
+	assert.Equal(t, "DROP TABLE IF EXISTS \"post\";\n"+
+		"DROP TYPE IF EXISTS \"post_state\";\n"+
+		"DROP TYPE IF EXISTS \"label\";\n",
+		generateWithOptions(t, enumVersion(), db.PGDBType, nativeEnums, DropAction))
+}
+
+func TestMigrationAddsEnumLabelsInPlace(t *testing.T) {
+	from := enumVersion()
+	to := proto.Clone(from).(*nemgen.ProjectVersion)
+	to.Enums[0].StaticValues = []*nemgen.EnumValue{
+		{Identifier: "draft", NumericValue: 1},
+		{Identifier: "review", NumericValue: 3},
+		{Identifier: "published", NumericValue: 2},
+		{Identifier: "archived", NumericValue: 4},
+	}
+
+	m, err := GenerateMigrationWithOptions(from, to, db.PGDBType, nativeEnums, nativeEnums)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{
+		`ALTER TYPE "post_state" ADD VALUE 'review' BEFORE 'published';`,
+		`ALTER TYPE "post_state" ADD VALUE 'archived';`,
+	}, m.Statements)
+
+	// labels cannot be taken away again, so going back recreates the type
+	assert.Equal(t, []string{
+		`ALTER TYPE "post_state" RENAME TO "post_state__migration";`,
+		`CREATE TYPE "post_state" AS ENUM ('draft', 'published');`,
+		`ALTER TABLE "post" ALTER COLUMN "state" DROP DEFAULT, ALTER COLUMN "state" TYPE "post_state" USING "state"::text::"post_state", ALTER COLUMN "state" SET DEFAULT 'draft';`,
+		`DROP TYPE "post_state__migration";`,
+	}, m.Rollback)
+
+	m, err = GenerateMigrationWithOptions(from, to, db.MYSQLDBType, nativeEnums, nativeEnums)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{
+		"ALTER TABLE `post` MODIFY COLUMN `state` ENUM('draft', 'review', 'published', 'archived') NOT NULL DEFAULT 'draft';",
+	}, m.Statements)
+}
+
+// Postgres reads a backslash as itself, so a quote in a label is doubled.
+func TestPostgresEnumLabelsDoubleQuotes(t *testing.T) {
+	from := enumVersion()
+	from.Enums[0].StaticValues[0].Identifier = "won't"
+	created := generateWithOptions(t, from, db.PGDBType, nativeEnums, CreateAction)
+	assert.Contains(t, created, `CREATE TYPE "post_state" AS ENUM ('won''t', 'published');`)
+	assert.Contains(t, created, `"state" "post_state" NOT NULL DEFAULT 'won''t',`)
+
+	to := proto.Clone(from).(*nemgen.ProjectVersion)
+	to.Enums[0].StaticValues = append(to.Enums[0].StaticValues, &nemgen.EnumValue{Identifier: "can't", NumericValue: 3})
+	m, err := GenerateMigrationWithOptions(from, to, db.PGDBType, nativeEnums, nativeEnums)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{`ALTER TYPE "post_state" ADD VALUE 'can''t';`}, m.Statements)
+}
+
+func TestMigrationRenamesEnumTypes(t *testing.T) {
+	from := enumVersion()
+	to := proto.Clone(from).(*nemgen.ProjectVersion)
+	to.Enums[1].Identifier = "post_label"
+
+	m, err := GenerateMigrationWithOptions(from, to, db.PGDBType, nativeEnums, nativeEnums)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{`ALTER TYPE "label" RENAME TO "post_label";`}, m.Statements)
+}
+
+func TestMigrationCreatesAndDropsEnumTypes(t *testing.T) {
+	m, err := GenerateMigrationWithOptions(nil, enumVersion(), db.PGDBType, nativeEnums, nativeEnums)
+	assert.NoError(t, err)
+	if assert.Len(t, m.Statements, 3) {
+		assert.Equal(t, `CREATE TYPE "post_state" AS ENUM ('draft', 'published');`, m.Statements[0])
+		assert.Equal(t, `CREATE TYPE "label" AS ENUM ('news', 'it''s');`, m.Statements[1])
+		assert.Contains(t, m.Statements[2], `CREATE TABLE IF NOT EXISTS "post"`)
+	}
+	assert.Equal(t, []string{`DROP TABLE "post";`, `DROP TYPE "post_state";`, `DROP TYPE "label";`}, m.Rollback)
+}
+
+func TestMigrationReplacesEnumChecks(t *testing.T) {
+	from := enumVersion()
+	to := proto.Clone(from).(*nemgen.ProjectVersion)
+	to.Enums[0].StaticValues = append(to.Enums[0].StaticValues, &nemgen.EnumValue{Identifier: "archived", NumericValue: 3})
+
+	m, err := GenerateMigrationWithOptions(from, to, db.MYSQLDBType, checkedEnums, checkedEnums)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{
+		"ALTER TABLE `post` DROP CHECK `post_state_check`;",
+		"ALTER TABLE `post` ADD CONSTRAINT `post_state_check` CHECK (`state` IN (1, 2, 3));",
+	}, m.Statements)
+
+	m, err = GenerateMigrationWithOptions(from, to, db.PGDBType, checkedEnums, checkedEnums)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{
+		`ALTER TABLE "post" DROP CONSTRAINT "post_state_check";`,
+		`ALTER TABLE "post" ADD CONSTRAINT "post_state_check" CHECK ("state" IN (1, 2, 3));`,
+	}, m.Statements)
+}
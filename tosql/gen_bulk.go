@@ -0,0 +1,392 @@
+package tosql
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"log"
+	"strings"
+	"text/template"
+
+	nemgen "github.com/nuzur/nem/idl/gen"
+	"github.com/nuzur/sql-gen/db"
+)
+
+// The most parameters a statement binds: postgres and mysql number them in
+// 16 bits, and sqlite's SQLITE_MAX_VARIABLE_NUMBER defaults to 32766.
+const (
+	maxParamsPG     = 65535
+	maxParamsMySQL  = 65535
+	maxParamsSQLite = 32766
+)
+
+// DefaultMySQLMaxStatementBytes is mysql 8's default max_allowed_packet, which
+// a mysql bulk insert is kept under when MaxStatementBytes is not set.
+const DefaultMySQLMaxStatementBytes = 64 << 20
+
+type GenerateBulkInsertForEntityParams struct {
+	Entity         *nemgen.Entity
+	ProjectVersion *nemgen.ProjectVersion
+	DBType         db.DBType
+	ForGolang      bool
+	SchemaOptions
+	Rows   []map[string]string // field uuid / value, one map per row
+	Upsert Upsert
+	// ConflictIndex is the uuid or identifier of the unique index an upsert
+	// conflicts on, the primary key if empty. mysql has no conflict target —
+	// any unique key conflicting updates the row — so there it only decides
+	// which columns are not overwritten.
+	ConflictIndex string
+	// MaxParams caps the parameters of a statement, the dialect's limit if
+	// zero.
+	MaxParams int
+	// MaxStatementBytes caps the length of a statement's display SQL, which
+	// is what a client interpolating the parameters sends. Zero is no cap but
+	// on mysql, where it is DefaultMySQLMaxStatementBytes; set it to the
+	// server's max_allowed_packet.
+	MaxStatementBytes int
+}
+
+// GenerateBulkInsertForEntity builds multi-row INSERTs of the rows, as few as
+// the limits on parameters and statement length allow, in the order of the
+// rows.
+//
+// A row is written the way GenerateInsertForEntityWithValues writes it: a
+// column the database fills by itself is left out when the row has no value
+// for it, so rows that differ in which of those they supply cannot share a
+// VALUES list and go in separate statements. Every other column a row has no
+// value for is NULL.
+//
+// A postgres upsert cannot update the same row twice, so rows with the same
+// conflict key go in separate statements, which apply them in order as mysql
+// and sqlite do within one.
+func GenerateBulkInsertForEntity(ctx context.Context, params GenerateBulkInsertForEntityParams) ([]*GenerateStatementResult, error) {
+	res, _, err := generateBulkInsertForEntity(ctx, params)
+	return res, err
+}
+
+// GenerateBulkInsertForEntityWithTypedValues is GenerateBulkInsertForEntity
+// with typed parameters; see GenerateInsertForEntityWithTypedValues.
+func GenerateBulkInsertForEntityWithTypedValues(ctx context.Context, params GenerateBulkInsertForEntityParams) ([]*GenerateTypedStatementResult, error) {
+	res, bound, err := generateBulkInsertForEntity(ctx, params)
+	if err != nil {
+		return nil, err
+	}
+	typed := []*GenerateTypedStatementResult{}
+	for n := range res {
+		statement, err := bound[n].typedResult(res[n], params.DBType)
+		if err != nil {
+			return nil, err
+		}
+		typed = append(typed, statement)
+	}
+	return typed, nil
+}
+
+// bulkCell is a row's value for a column, nil for NULL.
+type bulkCell struct {
+	field SchemaField
+	value *string
+}
+
+func generateBulkInsertForEntity(ctx context.Context, params GenerateBulkInsertForEntityParams) ([]*GenerateStatementResult, []boundParams, error) {
+	entityTemplate, err := mapStatementEntity(params.Entity, params.ProjectVersion, params.DBType, params.ForGolang, params.SchemaOptions)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if len(params.Rows) == 0 {
+		return nil, nil, fmt.Errorf("no rows provided for entity %q", entityTemplate.Name)
+	}
+	var target []string
+	var where string
+	if params.Upsert != UpsertNone {
+		if target, where, err = conflictTarget(params.Entity, entityTemplate, params.ConflictIndex); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	fileName := fmt.Sprintf("%s_%s", "bulk_insert_data", params.DBType)
+	tmplBytes, err := templates.ReadFile(fmt.Sprintf("templates/%s.tmpl", fileName))
+	if err != nil {
+		return nil, nil, err
+	}
+	tpl, err := template.New("template").Parse(string(tmplBytes))
+	if err != nil {
+		return nil, nil, fmt.Errorf("error creating template: %s %w", fileName, err)
+	}
+
+	maxParams := params.MaxParams
+	if maxParams <= 0 {
+		switch params.DBType {
+		case db.MYSQLDBType:
+			maxParams = maxParamsMySQL
+		case db.PGDBType:
+			maxParams = maxParamsPG
+		case db.SQLiteDBType:
+			maxParams = maxParamsSQLite
+		}
+	}
+	maxBytes := params.MaxStatementBytes
+	if maxBytes <= 0 && params.DBType == db.MYSQLDBType {
+		maxBytes = DefaultMySQLMaxStatementBytes
+	}
+
+	results := []*GenerateStatementResult{}
+	bounds := []boundParams{}
+	var columns []SchemaField
+	var chunk [][]bulkCell
+	chunkParams, chunkBytes := 0, 0
+	// chunkKeys are the conflict keys of the chunk's rows, on a postgres upsert
+	chunkKeys := map[string]bool{}
+	flush := func() error {
+		if len(chunk) == 0 {
+			return nil
+		}
+		res, bound, err := entityTemplate.renderBulkInsert(tpl, columns, chunk, params.Upsert, target, where)
+		if err != nil {
+			return err
+		}
+		results = append(results, res)
+		bounds = append(bounds, bound)
+		chunk = nil
+		return nil
+	}
+
+	for n, row := range params.Rows {
+		fields := []SchemaField{}
+		cells := []bulkCell{}
+		rowParams := 0
+		for _, f := range entityTemplate.InsertFields() {
+			value, ok := row[f.Field.Uuid]
+			if !ok && dbFilledOnInsert(f.Field) {
+				continue
+			}
+			fields = append(fields, f)
+			if !ok || (blankMeansNull(f.Field) && value == "") {
+				cells = append(cells, bulkCell{field: f})
+				continue
+			}
+			cells = append(cells, bulkCell{field: f, value: &value})
+			rowParams++
+		}
+		if len(fields) == 0 {
+			return nil, nil, fmt.Errorf("no insertable values provided for row %d of entity %q", n, entityTemplate.Name)
+		}
+		if rowParams > maxParams {
+			return nil, nil, fmt.Errorf("row %d of entity %q binds %d parameters, more than the %d a statement can", n, entityTemplate.Name, rowParams, maxParams)
+		}
+		// ",\n(...)" is what the row adds to the display sql
+		rowBytes := len(bulkRowValues(params.DBType, cells)) + 2
+		rowKey := ""
+		if params.Upsert != UpsertNone && params.DBType == db.PGDBType {
+			rowKey = conflictKey(params.DBType, cells, target)
+		}
+
+		if len(chunk) > 0 && (!sameColumns(columns, fields) ||
+			chunkParams+rowParams > maxParams ||
+			(maxBytes > 0 && chunkBytes+rowBytes > maxBytes) ||
+			(rowKey != "" && chunkKeys[rowKey])) {
+			if err := flush(); err != nil {
+				return nil, nil, err
+			}
+		}
+		if len(chunk) == 0 {
+			columns = fields
+			chunkParams = 0
+			chunkKeys = map[string]bool{}
+			// the statement with no rows at all is the rest of it
+			empty, _, err := entityTemplate.renderBulkInsert(tpl, columns, nil, params.Upsert, target, where)
+			if err != nil {
+				return nil, nil, err
+			}
+			chunkBytes = len(empty.SQL)
+			if maxBytes > 0 && chunkBytes+rowBytes > maxBytes {
+				return nil, nil, fmt.Errorf("row %d of entity %q takes a statement of %d bytes, more than the %d allowed", n, entityTemplate.Name, chunkBytes+rowBytes, maxBytes)
+			}
+		}
+		chunk = append(chunk, cells)
+		chunkParams += rowParams
+		chunkBytes += rowBytes
+		if rowKey != "" {
+			chunkKeys[rowKey] = true
+		}
+	}
+	if err := flush(); err != nil {
+		return nil, nil, err
+	}
+	return results, bounds, nil
+}
+
+// bulkRowValues is the row as the display sql lists it.
+func bulkRowValues(dbType db.DBType, cells []bulkCell) string {
+	values := []string{}
+	for _, c := range cells {
+		if c.value == nil {
+			values = append(values, "NULL")
+			continue
+		}
+		value := coerceParamValue(c.field, *c.value, dbType)
+		values = append(values, fmt.Sprintf("'%s'", escapeLiteral(value, dbType)))
+	}
+	return fmt.Sprintf("(%s)", strings.Join(values, ","))
+}
+
+// conflictKey is the row's values for the conflict target, empty when one of
+// them is NULL, which conflicts with nothing.
+func conflictKey(dbType db.DBType, cells []bulkCell, target []string) string {
+	values := []string{}
+	for _, column := range target {
+		for _, c := range cells {
+			if c.field.Name != column {
+				continue
+			}
+			if c.value == nil {
+				return ""
+			}
+			// compared as the database does: 'A' and 'a' are the same uuid
+			value, err := typedParamValue(c.field, *c.value, dbType)
+			if err != nil {
+				value = *c.value
+			}
+			values = append(values, fmt.Sprint(value))
+		}
+	}
+	if len(values) < len(target) {
+		return ""
+	}
+	return strings.Join(values, "\x00")
+}
+
+func (e SchemaEntity) renderBulkInsert(tpl *template.Template, columns []SchemaField, rows [][]bulkCell, upsert Upsert, target []string, where string) (*GenerateStatementResult, boundParams, error) {
+	quoted := []string{}
+	for _, f := range columns {
+		quoted = append(quoted, e.quote(f.Name))
+	}
+
+	displayRows := []string{}
+	placeholderRows := []string{}
+	bound := boundParams{}
+	for _, cells := range rows {
+		displayRows = append(displayRows, bulkRowValues(e.DBType, cells))
+		placeholders := []string{}
+		for _, c := range cells {
+			if c.value == nil {
+				placeholders = append(placeholders, "NULL")
+				continue
+			}
+			bound = append(bound, boundParam{field: c.field, value: *c.value})
+			if e.DBType == db.PGDBType {
+				placeholders = append(placeholders, fmt.Sprintf("$%d", len(bound)))
+			} else {
+				placeholders = append(placeholders, "?")
+			}
+		}
+		placeholderRows = append(placeholderRows, fmt.Sprintf("(%s)", strings.Join(placeholders, ",")))
+	}
+	conflict := e.upsertClause(upsert, target, where, columns)
+
+	type bulkInsertTemplateData struct {
+		Entity   SchemaEntity
+		Columns  string
+		Rows     string
+		Conflict string
+	}
+
+	// display sql
+	var body bytes.Buffer
+	if err := tpl.Execute(&body, bulkInsertTemplateData{
+		Entity:   e,
+		Columns:  strings.Join(quoted, ","),
+		Rows:     strings.Join(displayRows, ",\n"),
+		Conflict: conflict,
+	}); err != nil {
+		log.Println("error executing template - ", err)
+		return nil, nil, err
+	}
+	displaySQL := body.String()
+
+	// parametrized sql
+	body.Reset()
+	if err := tpl.Execute(&body, bulkInsertTemplateData{
+		Entity:   e,
+		Columns:  strings.Join(quoted, ","),
+		Rows:     strings.Join(placeholderRows, ",\n"),
+		Conflict: conflict,
+	}); err != nil {
+		log.Println("error executing template - ", err)
+		return nil, nil, err
+	}
+
+	return &GenerateStatementResult{
+		SQL:             displaySQL,
+		ParametrizedSQL: body.String(),
+		Params:          bound.strings(e.DBType),
+	}, bound, nil
+}
+
+// conflictTarget is the columns of the unique index named, or of the primary
+// key, and the predicate of the index if it is a partial one.
+func conflictTarget(entity *nemgen.Entity, entityTemplate SchemaEntity, index string) ([]string, string, error) {
+	names := map[string]string{}
+	for _, f := range entityTemplate.Fields {
+		names[f.Field.Uuid] = f.Name
+	}
+
+	target := []string{}
+	if index == "" {
+		for _, f := range EntityPrimaryKeys(entity) {
+			if name, ok := names[f.Uuid]; ok {
+				target = append(target, name)
+			}
+		}
+		if len(target) == 0 {
+			return nil, "", fmt.Errorf("entity %q has no primary key to upsert on", entityTemplate.Name)
+		}
+		return target, "", nil
+	}
+
+	for _, i := range entity.GetTypeConfig().GetStandalone().GetIndexes() {
+		if i.Uuid != index && i.Identifier != index {
+			continue
+		}
+		if i.Status != nemgen.IndexStatus_INDEX_STATUS_ACTIVE ||
+			(i.Type != nemgen.IndexType_INDEX_TYPE_UNIQUE && i.Type != nemgen.IndexType_INDEX_TYPE_PRIMARY) {
+			return nil, "", fmt.Errorf("index %q of entity %q is not an active unique index", index, entityTemplate.Name)
+		}
+		for _, fi := range i.Fields {
+			if name, ok := names[fi.FieldUuid]; ok {
+				target = append(target, name)
+			}
+		}
+		if len(target) == 0 {
+			return nil, "", fmt.Errorf("index %q of entity %q has no columns to upsert on", index, entityTemplate.Name)
+		}
+		// a partial index conflicts with its predicate, and one with
+		// expressions cannot be named by its columns
+		where := ""
+		for _, si := range entityTemplate.Indexes {
+			if si.Index.GetUuid() != i.Uuid {
+				continue
+			}
+			if len(si.Expressions) > 0 && entityTemplate.DBType != db.MYSQLDBType {
+				return nil, "", fmt.Errorf("index %q of entity %q has expressions, which an upsert cannot conflict on", index, entityTemplate.Name)
+			}
+			where = si.Where
+		}
+		return target, where, nil
+	}
+	return nil, "", fmt.Errorf("entity %q has no index %q", entityTemplate.Name, index)
+}
+
+func sameColumns(a []SchemaField, b []SchemaField) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for n := range a {
+		if a[n].Name != b[n].Name {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,268 @@
+package tosql
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/nuzur/sql-gen/db"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func postRows(n int) []map[string]string {
+	rows := []map[string]string{}
+	for i := 1; i <= n; i++ {
+		rows = append(rows, map[string]string{
+			postFieldUUID:  fmt.Sprintf("9b2c1c2e-0000-4000-8000-%012d", i),
+			postFieldTitle: fmt.Sprintf("post %d", i),
+		})
+	}
+	return rows
+}
+
+func TestGenerateBulkInsertPGUpsert(t *testing.T) {
+	pv := loadTestProjectVersion(t)
+	res, err := GenerateBulkInsertForEntity(context.Background(), GenerateBulkInsertForEntityParams{
+		Entity:         testEntity(t, pv, testPostEntityUUID),
+		ProjectVersion: pv,
+		DBType:         db.PGDBType,
+		Rows:           postRows(2),
+		Upsert:         UpsertUpdate,
+	})
+	require.NoError(t, err)
+	require.Len(t, res, 1)
+
+	statement := res[0]
+	assert.Contains(t, statement.SQL, "'9b2c1c2e-0000-4000-8000-000000000001'")
+	assert.Contains(t, statement.ParametrizedSQL, "VALUES\n(")
+	assert.Contains(t, statement.ParametrizedSQL, `ON CONFLICT ("uuid") DO UPDATE SET`)
+	assert.Contains(t, statement.ParametrizedSQL, `"title" = EXCLUDED."title"`)
+	assert.NotContains(t, statement.ParametrizedSQL, `"uuid" = EXCLUDED."uuid"`, "the key is what the rows are matched on")
+	assert.Len(t, statement.Params, 4)
+	assertPGParamsContiguous(t, statement.ParametrizedSQL, len(statement.Params))
+}
+
+func TestGenerateBulkInsertChunksByParams(t *testing.T) {
+	pv := loadTestProjectVersion(t)
+	res, err := GenerateBulkInsertForEntity(context.Background(), GenerateBulkInsertForEntityParams{
+		Entity:         testEntity(t, pv, testPostEntityUUID),
+		ProjectVersion: pv,
+		DBType:         db.PGDBType,
+		Rows:           postRows(5),
+		MaxParams:      4,
+	})
+	require.NoError(t, err)
+	require.Len(t, res, 3)
+
+	// each statement numbers its own placeholders from $1
+	for _, statement := range res {
+		assertPGParamsContiguous(t, statement.ParametrizedSQL, len(statement.Params))
+	}
+	assert.Equal(t, "9b2c1c2e-0000-4000-8000-000000000005", res[2].Params[0])
+	assert.NotContains(t, res[0].SQL, "ON CONFLICT")
+}
+
+func TestGenerateBulkInsertMySQL(t *testing.T) {
+	pv := loadTestProjectVersion(t)
+	params := GenerateBulkInsertForEntityParams{
+		Entity:         testEntity(t, pv, testPostEntityUUID),
+		ProjectVersion: pv,
+		DBType:         db.MYSQLDBType,
+		Rows:           postRows(4),
+		Upsert:         UpsertNothing,
+	}
+	res, err := GenerateBulkInsertForEntity(context.Background(), params)
+	require.NoError(t, err)
+	require.Len(t, res, 1)
+	assert.True(t, strings.HasSuffix(res[0].ParametrizedSQL, "ON DUPLICATE KEY UPDATE\n`uuid` = `uuid`;"), res[0].ParametrizedSQL)
+	assert.Equal(t, len(res[0].Params), strings.Count(res[0].ParametrizedSQL, "?"))
+
+	// the rows are read through their alias, not the deprecated VALUES()
+	updated, err := GenerateBulkInsertForEntity(context.Background(), GenerateBulkInsertForEntityParams{
+		Entity:         params.Entity,
+		ProjectVersion: pv,
+		DBType:         db.MYSQLDBType,
+		Rows:           postRows(2),
+		Upsert:         UpsertUpdate,
+	})
+	require.NoError(t, err)
+	require.Len(t, updated, 1)
+	assert.Contains(t, updated[0].ParametrizedSQL, ") AS new\nON DUPLICATE KEY UPDATE\n`version` = new.`version`, ")
+	assert.NotContains(t, updated[0].ParametrizedSQL, "VALUES(")
+
+	// a statement a little longer than two rows' worth holds two of them
+	one, err := GenerateBulkInsertForEntity(context.Background(), GenerateBulkInsertForEntityParams{
+		Entity:         params.Entity,
+		ProjectVersion: pv,
+		DBType:         db.MYSQLDBType,
+		Rows:           postRows(2),
+		Upsert:         UpsertNothing,
+	})
+	require.NoError(t, err)
+	params.MaxStatementBytes = len(one[0].SQL) + 10
+	res, err = GenerateBulkInsertForEntity(context.Background(), params)
+	require.NoError(t, err)
+	require.Len(t, res, 2)
+	for _, statement := range res {
+		assert.LessOrEqual(t, len(statement.SQL), params.MaxStatementBytes)
+	}
+
+	params.MaxStatementBytes = 20
+	_, err = GenerateBulkInsertForEntity(context.Background(), params)
+	assert.ErrorContains(t, err, "more than the 20 allowed")
+}
+
+// The rows that leave out a column the database fills cannot share a VALUES
+// list with the rows that supply it.
+func TestGenerateBulkInsertSplitsOnGeneratedColumns(t *testing.T) {
+	pv := loadTestProjectVersion(t)
+	entity := testEntity(t, pv, testUserEntityUUID)
+	setGenerated(t, entity, userFieldCreatedAt)
+
+	row := func(version string, createdAt string) map[string]string {
+		r := map[string]string{
+			userFieldUUID:    "9b2c1c2e-0000-4000-8000-000000000002",
+			userFieldVersion: version,
+			userFieldEmail:   "user@nuzur.dev",
+		}
+		if createdAt != "" {
+			r[userFieldCreatedAt] = createdAt
+		}
+		return r
+	}
+	res, err := GenerateBulkInsertForEntity(context.Background(), GenerateBulkInsertForEntityParams{
+		Entity:         entity,
+		ProjectVersion: pv,
+		DBType:         db.SQLiteDBType,
+		Rows: []map[string]string{
+			row("1", ""),
+			row("2", ""),
+			row("3", "2026-07-28T00:00:00Z"),
+			row("4", ""),
+		},
+		Upsert:        UpsertUpdate,
+		ConflictIndex: "unique_email",
+	})
+	require.NoError(t, err)
+	require.Len(t, res, 3)
+	assert.NotContains(t, res[0].ParametrizedSQL, `"created_at"`)
+	assert.Contains(t, res[1].ParametrizedSQL, `"created_at" = EXCLUDED."created_at"`)
+	assert.NotContains(t, res[2].ParametrizedSQL, `"created_at"`)
+	assert.Equal(t, 2, strings.Count(res[0].ParametrizedSQL, "(?,?,?"))
+
+	for _, statement := range res {
+		assert.Contains(t, statement.ParametrizedSQL, `ON CONFLICT ("email") DO UPDATE SET`)
+		assert.NotContains(t, statement.ParametrizedSQL, `"version" = EXCLUDED`, "the primary key is never overwritten")
+	}
+}
+
+// Postgres refuses to update a row twice in one upsert, so a key that repeats
+// starts a new statement.
+func TestGenerateBulkInsertSplitsOnRepeatedKeys(t *testing.T) {
+	pv := loadTestProjectVersion(t)
+	rows := postRows(3)
+	rows = append(rows, map[string]string{
+		postFieldUUID:  "9B2C1C2E-0000-4000-8000-000000000002",
+		postFieldTitle: "post 2 again",
+	}, postRows(1)[0])
+	params := GenerateBulkInsertForEntityParams{
+		Entity:         testEntity(t, pv, testPostEntityUUID),
+		ProjectVersion: pv,
+		DBType:         db.PGDBType,
+		Rows:           rows,
+		Upsert:         UpsertUpdate,
+	}
+	res, err := GenerateBulkInsertForEntity(context.Background(), params)
+	require.NoError(t, err)
+	require.Len(t, res, 2)
+	assert.Len(t, res[0].Params, 6)
+	assert.Equal(t, []string{
+		"9B2C1C2E-0000-4000-8000-000000000002", "post 2 again",
+		"9b2c1c2e-0000-4000-8000-000000000001", "post 1",
+	}, res[1].Params)
+
+	// without an upsert, or on an engine that applies the rows in order, one
+	// statement takes them all
+	params.Upsert = UpsertNone
+	res, err = GenerateBulkInsertForEntity(context.Background(), params)
+	require.NoError(t, err)
+	assert.Len(t, res, 1)
+	params.Upsert = UpsertUpdate
+	params.DBType = db.SQLiteDBType
+	res, err = GenerateBulkInsertForEntity(context.Background(), params)
+	require.NoError(t, err)
+	assert.Len(t, res, 1)
+}
+
+func TestGenerateBulkInsertTypedValues(t *testing.T) {
+	pv := loadTestProjectVersion(t)
+	res, err := GenerateBulkInsertForEntityWithTypedValues(context.Background(), GenerateBulkInsertForEntityParams{
+		Entity:         testEntity(t, pv, testUserEntityUUID),
+		ProjectVersion: pv,
+		DBType:         db.PGDBType,
+		Rows: []map[string]string{
+			{userFieldUUID: "9b2c1c2e-0000-4000-8000-000000000002", userFieldVersion: "1"},
+			{userFieldUUID: "9b2c1c2e-0000-4000-8000-000000000002", userFieldVersion: "2"},
+		},
+	})
+	require.NoError(t, err)
+	require.Len(t, res, 1)
+	assert.Equal(t, []any{
+		"9b2c1c2e-0000-4000-8000-000000000002", int64(1),
+		"9b2c1c2e-0000-4000-8000-000000000002", int64(2),
+	}, res[0].Params)
+}
+
+func TestGenerateBulkInsertErrors(t *testing.T) {
+	pv := loadTestProjectVersion(t)
+	params := GenerateBulkInsertForEntityParams{
+		Entity:         testEntity(t, pv, testPostEntityUUID),
+		ProjectVersion: pv,
+		DBType:         db.PGDBType,
+	}
+	_, err := GenerateBulkInsertForEntity(context.Background(), params)
+	assert.ErrorContains(t, err, "no rows provided")
+
+	params.Rows = postRows(1)
+	params.Upsert = UpsertUpdate
+	params.ConflictIndex = "nope"
+	_, err = GenerateBulkInsertForEntity(context.Background(), params)
+	assert.ErrorContains(t, err, `has no index "nope"`)
+
+	// a plain index is nothing to conflict on
+	params.ConflictIndex = "nuevo_indice"
+	_, err = GenerateBulkInsertForEntity(context.Background(), params)
+	assert.ErrorContains(t, err, "is not an active unique index")
+}
+
+func TestGenerateBulkInsertOnPartialIndex(t *testing.T) {
+	pv := loadTestProjectVersion(t)
+	entity := testEntity(t, pv, testUserEntityUUID)
+	var email string
+	for _, i := range entity.TypeConfig.Standalone.Indexes {
+		if i.Identifier == "unique_email" {
+			email = i.Uuid
+		}
+	}
+	params := GenerateBulkInsertForEntityParams{
+		Entity:         entity,
+		ProjectVersion: pv,
+		DBType:         db.PGDBType,
+		Rows: []map[string]string{
+			{userFieldUUID: "9b2c1c2e-0000-4000-8000-000000000002", userFieldVersion: "1", userFieldEmail: "user@nuzur.dev"},
+		},
+		SchemaOptions: SchemaOptions{Indexes: map[string]IndexOptions{email: {Where: "status = 1"}}},
+		Upsert:        UpsertUpdate,
+		ConflictIndex: "unique_email",
+	}
+	res, err := GenerateBulkInsertForEntity(context.Background(), params)
+	require.NoError(t, err)
+	require.Len(t, res, 1)
+	assert.Contains(t, res[0].ParametrizedSQL, `ON CONFLICT ("email") WHERE status = 1 DO UPDATE SET`)
+
+	params.SchemaOptions.Indexes[email] = IndexOptions{Expressions: []IndexExpression{{Expression: "lower(email)"}}}
+	_, err = GenerateBulkInsertForEntity(context.Background(), params)
+	assert.ErrorContains(t, err, "has expressions")
+}
@@ -18,7 +18,27 @@ type GenerateInsertForEntityWithValuesParams struct {
 	ProjectVersion *nemgen.ProjectVersion
 	DBType         db.DBType
 	ForGolang      bool
-	Values         map[string]string // field uuid / value
+	// SchemaOptions are the options the schema was generated with, so the
+	// statement addresses the tables and columns as they were created.
+	SchemaOptions
+	Values map[string]string // field uuid / value
+}
+
+// mapStatementEntity maps the entity a statement is over as GenerateSQL does
+// for the options.
+func mapStatementEntity(entity *nemgen.Entity, projectVersion *nemgen.ProjectVersion, dbType db.DBType, forGolang bool, options SchemaOptions) (SchemaEntity, error) {
+	entityTemplate, err := mapEntityToSchemaEntity(entity, projectVersion, dbType, forGolang, options.Indexes)
+	if err != nil {
+		return SchemaEntity{}, err
+	}
+	entityTemplate.placeInSchemas(entity.GetUuid(), options.Schemas)
+	entityTemplate.computeColumns(options.Computed)
+	if err := entityTemplate.softDelete(entity, options.SoftDelete[entity.GetUuid()]); err != nil {
+		return SchemaEntity{}, err
+	}
+	entities := []SchemaEntity{entityTemplate}
+	applyEnumMode(entities, projectVersion, dbType, options)
+	return entities[0], nil
 }
 
 type GenerateStatementResult struct {
@@ -28,9 +48,14 @@ type GenerateStatementResult struct {
 }
 
 func GenerateInsertForEntityWithValues(ctx context.Context, params GenerateInsertForEntityWithValuesParams) (*GenerateStatementResult, error) {
-	entityTemplate, err := MapEntityToSchemaEntity(params.Entity, params.ProjectVersion, params.DBType, params.ForGolang)
+	res, _, err := generateInsertForEntity(ctx, params)
+	return res, err
+}
+
+func generateInsertForEntity(ctx context.Context, params GenerateInsertForEntityWithValuesParams) (*GenerateStatementResult, boundParams, error) {
+	entityTemplate, err := mapStatementEntity(params.Entity, params.ProjectVersion, params.DBType, params.ForGolang, params.SchemaOptions)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 
 	// Build the column list from what the caller actually supplied rather than
@@ -54,9 +79,9 @@ func GenerateInsertForEntityWithValues(ctx context.Context, params GenerateInser
 	columns := []string{}
 	displayValues := []string{}
 	placeholders := []string{}
-	paramsValues := []string{}
+	bound := boundParams{}
 	paramIndex := 0
-	for _, f := range entityTemplate.Fields {
+	for _, f := range entityTemplate.InsertFields() {
 		value, ok := params.Values[f.Field.Uuid]
 		if !ok && dbFilledOnInsert(f.Field) {
 			continue
@@ -65,7 +90,7 @@ func GenerateInsertForEntityWithValues(ctx context.Context, params GenerateInser
 		switch params.DBType {
 		case db.MYSQLDBType:
 			columns = append(columns, fmt.Sprintf("`%s`", f.Name))
-		case db.PGDBType:
+		case db.PGDBType, db.SQLiteDBType:
 			columns = append(columns, fmt.Sprintf(`"%s"`, f.Name))
 		}
 
@@ -75,34 +100,35 @@ func GenerateInsertForEntityWithValues(ctx context.Context, params GenerateInser
 			continue
 		}
 
-		value = coerceParamValue(f.Field, value, params.DBType)
-		displayValues = append(displayValues, fmt.Sprintf("'%s'", EscapeValue(value)))
+		rawValue := value
+		value = coerceParamValue(f, value, params.DBType)
+		displayValues = append(displayValues, fmt.Sprintf("'%s'", escapeLiteral(value, params.DBType)))
 		paramIndex++
 		switch params.DBType {
-		case db.MYSQLDBType:
+		case db.MYSQLDBType, db.SQLiteDBType:
 			placeholders = append(placeholders, "?")
 		case db.PGDBType:
 			placeholders = append(placeholders, fmt.Sprintf("$%d", paramIndex))
 		}
-		paramsValues = append(paramsValues, value)
+		bound = append(bound, boundParam{field: f, value: rawValue})
 	}
 
 	// An INSERT naming no columns is invalid on postgres. Upstream validation
 	// makes this unreachable — non-auto-increment keys are always required — so
 	// this is a diagnostic rather than a path anyone should hit.
 	if len(columns) == 0 {
-		return nil, fmt.Errorf("no insertable values provided for entity %q", entityTemplate.Name)
+		return nil, nil, fmt.Errorf("no insertable values provided for entity %q", entityTemplate.Name)
 	}
 
 	fileName := fmt.Sprintf("%s_%s", "insert_data", params.DBType)
 	tmplBytes, err := templates.ReadFile(fmt.Sprintf("templates/%s.tmpl", fileName))
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 
 	tpl, err := template.New("template").Parse(string(tmplBytes))
 	if err != nil {
-		return nil, fmt.Errorf("error creating template: %s %w", fileName, err)
+		return nil, nil, fmt.Errorf("error creating template: %s %w", fileName, err)
 	}
 
 	// display sql
@@ -117,7 +143,7 @@ func GenerateInsertForEntityWithValues(ctx context.Context, params GenerateInser
 		Values:  strings.Join(displayValues, ","),
 	}); err != nil {
 		log.Println("error executing template - ", err)
-		return nil, err
+		return nil, nil, err
 	}
 	displaySQL := body.String()
 
@@ -133,15 +159,15 @@ func GenerateInsertForEntityWithValues(ctx context.Context, params GenerateInser
 		Values:  strings.Join(placeholders, ","),
 	}); err != nil {
 		log.Println("error executing template - ", err)
-		return nil, err
+		return nil, nil, err
 	}
 	parametrizedSQL := body.String()
 
 	return &GenerateStatementResult{
 		SQL:             displaySQL,
 		ParametrizedSQL: parametrizedSQL,
-		Params:          paramsValues,
-	}, nil
+		Params:          bound.strings(params.DBType),
+	}, bound, nil
 }
 
 type GenerateUpdateForEntityWithValuesParams struct {
@@ -149,14 +175,20 @@ type GenerateUpdateForEntityWithValuesParams struct {
 	ProjectVersion *nemgen.ProjectVersion
 	DBType         db.DBType
 	ForGolang      bool
-	Values         map[string]string // field uuid / value
-	Keys           map[string]string // field uuid / value
+	SchemaOptions
+	Values map[string]string // field uuid / value
+	Keys   map[string]string // field uuid / value
 }
 
 func GenerateUpdateForEntityWithValues(ctx context.Context, params GenerateUpdateForEntityWithValuesParams) (*GenerateStatementResult, error) {
-	entityTemplate, err := MapEntityToSchemaEntity(params.Entity, params.ProjectVersion, params.DBType, params.ForGolang)
+	res, _, err := generateUpdateForEntity(ctx, params)
+	return res, err
+}
+
+func generateUpdateForEntity(ctx context.Context, params GenerateUpdateForEntityWithValuesParams) (*GenerateStatementResult, boundParams, error) {
+	entityTemplate, err := mapStatementEntity(params.Entity, params.ProjectVersion, params.DBType, params.ForGolang, params.SchemaOptions)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 
 	finalKeys := make(map[string]string)
@@ -165,8 +197,8 @@ func GenerateUpdateForEntityWithValues(ctx context.Context, params GenerateUpdat
 			switch params.DBType {
 			case db.MYSQLDBType:
 				finalKeys[fmt.Sprintf("`%s`", f.Name)] = fmt.Sprintf("'%s'", EscapeValue(value))
-			case db.PGDBType:
-				finalKeys[fmt.Sprintf(`"%s"`, f.Name)] = fmt.Sprintf("'%s'", EscapeValue(value))
+			case db.PGDBType, db.SQLiteDBType:
+				finalKeys[fmt.Sprintf(`"%s"`, f.Name)] = fmt.Sprintf("'%s'", escapeLiteral(value, params.DBType))
 			}
 		}
 	}
@@ -174,12 +206,12 @@ func GenerateUpdateForEntityWithValues(ctx context.Context, params GenerateUpdat
 	fileName := fmt.Sprintf("%s_%s", "update_data", params.DBType)
 	tmplBytes, err := templates.ReadFile(fmt.Sprintf("templates/%s.tmpl", fileName))
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 
 	tpl, err := template.New("template").Parse(string(tmplBytes))
 	if err != nil {
-		return nil, fmt.Errorf("error creating template: %s %w", fileName, err)
+		return nil, nil, fmt.Errorf("error creating template: %s %w", fileName, err)
 	}
 
 	// display sql
@@ -194,7 +226,7 @@ func GenerateUpdateForEntityWithValues(ctx context.Context, params GenerateUpdat
 		WhereClause:  entityTemplate.PrimaryKeysWhereClauseWithValues(finalKeys),
 	}); err != nil {
 		log.Println("error executing template - ", err)
-		return nil, err
+		return nil, nil, err
 	}
 	displaySQL := body.String()
 
@@ -215,27 +247,27 @@ func GenerateUpdateForEntityWithValues(ctx context.Context, params GenerateUpdat
 		WhereClause:  entityTemplate.PrimaryKeysWhereClauseParamWithOffset(true, setParamCount),
 	}); err != nil {
 		log.Println("error executing template - ", err)
-		return nil, err
+		return nil, nil, err
 	}
 	parametrizedSQL := body.String()
 
-	paramValues := []string{}
+	bound := boundParams{}
 	for _, f := range entityTemplate.Fields {
-		if !f.Field.Key {
+		if !f.Field.Key && f.Computed == "" {
 			if value, ok := params.Values[f.Field.Uuid]; ok {
 				// Blank values on non-character columns are emitted as NULL literals
 				// in the parametrized SQL, so they must not be added as bound params.
 				if blankMeansNull(f.Field) && value == "" {
 					continue
 				}
-				paramValues = append(paramValues, coerceParamValue(f.Field, value, params.DBType))
+				bound = append(bound, boundParam{field: f, value: value})
 			}
 		}
 	}
 	for _, f := range entityTemplate.Fields {
 		if f.Field.Key {
 			if value, ok := params.Keys[f.Field.Uuid]; ok {
-				paramValues = append(paramValues, coerceParamValue(f.Field, value, params.DBType))
+				bound = append(bound, boundParam{field: f, value: value})
 			}
 		}
 	}
@@ -243,8 +275,8 @@ func GenerateUpdateForEntityWithValues(ctx context.Context, params GenerateUpdat
 	return &GenerateStatementResult{
 		SQL:             displaySQL,
 		ParametrizedSQL: parametrizedSQL,
-		Params:          paramValues,
-	}, nil
+		Params:          bound.strings(params.DBType),
+	}, bound, nil
 }
 
 type GenerateDeleteForEntityWithValuesParams struct {
@@ -252,13 +284,19 @@ type GenerateDeleteForEntityWithValuesParams struct {
 	ProjectVersion *nemgen.ProjectVersion
 	DBType         db.DBType
 	ForGolang      bool
-	Keys           map[string]string // field uuid / value
+	SchemaOptions
+	Keys map[string]string // field uuid / value
 }
 
 func GenerateDeleteForEntityWithValues(ctx context.Context, params GenerateDeleteForEntityWithValuesParams) (*GenerateStatementResult, error) {
-	entityTemplate, err := MapEntityToSchemaEntity(params.Entity, params.ProjectVersion, params.DBType, params.ForGolang)
+	res, _, err := generateDeleteForEntity(ctx, params)
+	return res, err
+}
+
+func generateDeleteForEntity(ctx context.Context, params GenerateDeleteForEntityWithValuesParams) (*GenerateStatementResult, boundParams, error) {
+	entityTemplate, err := mapStatementEntity(params.Entity, params.ProjectVersion, params.DBType, params.ForGolang, params.SchemaOptions)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 
 	finalKeys := make(map[string]string)
@@ -267,8 +305,8 @@ func GenerateDeleteForEntityWithValues(ctx context.Context, params GenerateDelet
 			switch params.DBType {
 			case db.MYSQLDBType:
 				finalKeys[fmt.Sprintf("`%s`", f.Name)] = fmt.Sprintf("'%s'", EscapeValue(value))
-			case db.PGDBType:
-				finalKeys[fmt.Sprintf(`"%s"`, f.Name)] = fmt.Sprintf("'%s'", EscapeValue(value))
+			case db.PGDBType, db.SQLiteDBType:
+				finalKeys[fmt.Sprintf(`"%s"`, f.Name)] = fmt.Sprintf("'%s'", escapeLiteral(value, params.DBType))
 			}
 		}
 	}
@@ -276,12 +314,12 @@ func GenerateDeleteForEntityWithValues(ctx context.Context, params GenerateDelet
 	fileName := fmt.Sprintf("%s_%s", "delete_data", params.DBType)
 	tmplBytes, err := templates.ReadFile(fmt.Sprintf("templates/%s.tmpl", fileName))
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 
 	tpl, err := template.New("template").Parse(string(tmplBytes))
 	if err != nil {
-		return nil, fmt.Errorf("error creating template: %s %w", fileName, err)
+		return nil, nil, fmt.Errorf("error creating template: %s %w", fileName, err)
 	}
 
 	// display sql
@@ -294,7 +332,7 @@ func GenerateDeleteForEntityWithValues(ctx context.Context, params GenerateDelet
 		WhereClause: entityTemplate.PrimaryKeysWhereClauseWithValues(finalKeys),
 	}); err != nil {
 		log.Println("error executing template - ", err)
-		return nil, err
+		return nil, nil, err
 	}
 	displaySQL := body.String()
 
@@ -308,23 +346,23 @@ func GenerateDeleteForEntityWithValues(ctx context.Context, params GenerateDelet
 		WhereClause: entityTemplate.PrimaryKeysWhereClauseParam(true, false),
 	}); err != nil {
 		log.Println("error executing template - ", err)
-		return nil, err
+		return nil, nil, err
 	}
 	parametrizedSQL := body.String()
 
-	paramValues := []string{}
+	bound := boundParams{}
 	for _, f := range entityTemplate.Fields {
 		if f.Field.Key {
 			if value, ok := params.Keys[f.Field.Uuid]; ok {
-				paramValues = append(paramValues, coerceParamValue(f.Field, value, params.DBType))
+				bound = append(bound, boundParam{field: f, value: value})
 			}
 		}
 	}
 	return &GenerateStatementResult{
 		SQL:             displaySQL,
 		ParametrizedSQL: parametrizedSQL,
-		Params:          paramValues,
-	}, nil
+		Params:          bound.strings(params.DBType),
+	}, bound, nil
 }
 
 // dbFilledOnInsert reports whether the database supplies this column's value when
@@ -348,11 +386,21 @@ func dbFilledOnInsert(f *nemgen.Field) bool {
 // non-canonical. Postgres is largely tolerant and gets left alone here.
 // Only the cases we've actually seen blow up in practice are covered;
 // everything else passes through unchanged.
-func coerceParamValue(field *nemgen.Field, value string, dbType db.DBType) string {
+//
+// Enum options come as their numeric value, which is what an integer enum
+// column stores; a native enum column stores the option's label instead.
+func coerceParamValue(f SchemaField, value string, dbType db.DBType) string {
+	field := f.Field
 	if field == nil {
 		return value
 	}
 	switch field.GetType() {
+	case nemgen.FieldType_FIELD_TYPE_ENUM:
+		if f.EnumLabels != nil {
+			if label, err := enumLabelValue(f, value, dbType); err == nil {
+				return label
+			}
+		}
 	case nemgen.FieldType_FIELD_TYPE_BOOLEAN:
 		// Both mysql (TINYINT(1)) and pg (BOOLEAN) accept "0"/"1"; mysql in
 		// strict mode rejects "true"/"false" string literals. Normalize.
@@ -375,22 +423,25 @@ func coerceParamValue(field *nemgen.Field, value string, dbType db.DBType) strin
 			}
 		}
 	case nemgen.FieldType_FIELD_TYPE_DATE:
-		if dbType == db.MYSQLDBType {
+		if dbType == db.MYSQLDBType || dbType == db.SQLiteDBType {
 			if t, ok := parseISOTime(value); ok {
 				return t.UTC().Format("2006-01-02")
 			}
 		}
 	case nemgen.FieldType_FIELD_TYPE_DATETIME:
-		if dbType == db.MYSQLDBType {
+		if dbType == db.MYSQLDBType || dbType == db.SQLiteDBType {
 			if t, ok := parseISOTime(value); ok {
 				// mysql DATETIME wants `YYYY-MM-DD HH:MM:SS[.ffffff]`. The
 				// upstream UI sends RFC3339 with `T` and a `Z` suffix, which
 				// strict-mode mysql refuses (error 1292). Reformat in UTC.
+				// sqlite accepts either, but stores the text as given and
+				// compares it as text, so it gets the same spelling its own
+				// CURRENT_TIMESTAMP default produces.
 				return t.UTC().Format("2006-01-02 15:04:05.999999")
 			}
 		}
 	case nemgen.FieldType_FIELD_TYPE_TIME:
-		if dbType == db.MYSQLDBType {
+		if dbType == db.MYSQLDBType || dbType == db.SQLiteDBType {
 			if t, ok := parseISOTime(value); ok {
 				return t.UTC().Format("15:04:05.999999")
 			}
@@ -420,6 +471,17 @@ func parseISOTime(value string) (time.Time, bool) {
 	return time.Time{}, false
 }
 
+// escapeLiteral escapes a value for the body of a single-quoted literal in the
+// given dialect. sqlite, and postgres with standard_conforming_strings on as it
+// is by default, treat a backslash as an ordinary character, so EscapeValue's
+// \' leaves the quote unescaped there; they only understand a doubled quote.
+func escapeLiteral(value string, dbType db.DBType) string {
+	if dbType == db.SQLiteDBType || dbType == db.PGDBType {
+		return strings.ReplaceAll(value, "'", "''")
+	}
+	return EscapeValue(value)
+}
+
 func EscapeValue(sql string) string {
 	dest := make([]byte, 0, 2*len(sql))
 	var escape byte
@@ -462,7 +524,8 @@ type GenerateSelectForEntityWithValuesParams struct {
 	ProjectVersion *nemgen.ProjectVersion
 	DBType         db.DBType
 	ForGolang      bool
-	Keys           map[string]string // field uuid / value — must cover every primary key
+	SchemaOptions
+	Keys map[string]string // field uuid / value — must cover every primary key
 	// Columns are the field uuids to project, in addition to the primary keys.
 	// Empty projects the primary keys alone, which is enough to answer "does this
 	// row exist".
@@ -483,9 +546,14 @@ type GenerateSelectForEntityWithValuesParams struct {
 // Returns an error when a primary key has no value in Keys: a partial key would
 // silently widen the statement to a range scan.
 func GenerateSelectForEntityWithValues(ctx context.Context, params GenerateSelectForEntityWithValuesParams) (*GenerateStatementResult, error) {
-	entityTemplate, err := MapEntityToSchemaEntity(params.Entity, params.ProjectVersion, params.DBType, params.ForGolang)
+	res, _, err := generateSelectForEntity(ctx, params)
+	return res, err
+}
+
+func generateSelectForEntity(ctx context.Context, params GenerateSelectForEntityWithValuesParams) (*GenerateStatementResult, boundParams, error) {
+	entityTemplate, err := mapStatementEntity(params.Entity, params.ProjectVersion, params.DBType, params.ForGolang, params.SchemaOptions)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 
 	wanted := map[string]bool{}
@@ -502,13 +570,13 @@ func GenerateSelectForEntityWithValues(ctx context.Context, params GenerateSelec
 			keyCount++
 			value, ok := params.Keys[f.Field.Uuid]
 			if !ok {
-				return nil, fmt.Errorf("missing primary key value for field %q on entity %q", f.Name, entityTemplate.Name)
+				return nil, nil, fmt.Errorf("missing primary key value for field %q on entity %q", f.Name, entityTemplate.Name)
 			}
 			switch params.DBType {
 			case db.MYSQLDBType:
 				finalKeys[fmt.Sprintf("`%s`", f.Name)] = fmt.Sprintf("'%s'", EscapeValue(value))
-			case db.PGDBType:
-				finalKeys[fmt.Sprintf(`"%s"`, f.Name)] = fmt.Sprintf("'%s'", EscapeValue(value))
+			case db.PGDBType, db.SQLiteDBType:
+				finalKeys[fmt.Sprintf(`"%s"`, f.Name)] = fmt.Sprintf("'%s'", escapeLiteral(value, params.DBType))
 			}
 		}
 		if !f.Field.Key && !wanted[f.Field.Uuid] {
@@ -521,23 +589,23 @@ func GenerateSelectForEntityWithValues(ctx context.Context, params GenerateSelec
 		switch params.DBType {
 		case db.MYSQLDBType:
 			columns = append(columns, fmt.Sprintf("`%s`", f.Name))
-		case db.PGDBType:
+		case db.PGDBType, db.SQLiteDBType:
 			columns = append(columns, fmt.Sprintf(`"%s"`, f.Name))
 		}
 	}
 	if keyCount == 0 {
-		return nil, fmt.Errorf("entity %q has no primary key to select by", entityTemplate.Name)
+		return nil, nil, fmt.Errorf("entity %q has no primary key to select by", entityTemplate.Name)
 	}
 
 	fileName := fmt.Sprintf("%s_%s", "select_data", params.DBType)
 	tmplBytes, err := templates.ReadFile(fmt.Sprintf("templates/%s.tmpl", fileName))
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 
 	tpl, err := template.New("template").Parse(string(tmplBytes))
 	if err != nil {
-		return nil, fmt.Errorf("error creating template: %s %w", fileName, err)
+		return nil, nil, fmt.Errorf("error creating template: %s %w", fileName, err)
 	}
 
 	type selectTemplateData struct {
@@ -554,7 +622,7 @@ func GenerateSelectForEntityWithValues(ctx context.Context, params GenerateSelec
 		WhereClause: entityTemplate.PrimaryKeysWhereClauseWithValues(finalKeys),
 	}); err != nil {
 		log.Println("error executing template - ", err)
-		return nil, err
+		return nil, nil, err
 	}
 	displaySQL := body.String()
 
@@ -567,15 +635,15 @@ func GenerateSelectForEntityWithValues(ctx context.Context, params GenerateSelec
 		WhereClause: entityTemplate.PrimaryKeysWhereClauseParamWithOffset(true, 0),
 	}); err != nil {
 		log.Println("error executing template - ", err)
-		return nil, err
+		return nil, nil, err
 	}
 	parametrizedSQL := body.String()
 
-	paramValues := []string{}
+	bound := boundParams{}
 	for _, f := range entityTemplate.Fields {
 		if f.Field.Key {
 			if value, ok := params.Keys[f.Field.Uuid]; ok {
-				paramValues = append(paramValues, coerceParamValue(f.Field, value, params.DBType))
+				bound = append(bound, boundParam{field: f, value: value})
 			}
 		}
 	}
@@ -583,6 +651,6 @@ func GenerateSelectForEntityWithValues(ctx context.Context, params GenerateSelec
 	return &GenerateStatementResult{
 		SQL:             displaySQL,
 		ParametrizedSQL: parametrizedSQL,
-		Params:          paramValues,
-	}, nil
+		Params:          bound.strings(params.DBType),
+	}, bound, nil
 }
@@ -7,10 +7,10 @@ import (
 	"fmt"
 	"path"
 	"slices"
+	"sort"
 	"sync"
 	"text/template"
 
-	"github.com/nuzur/filetools"
 	nemgen "github.com/nuzur/nem/idl/gen"
 	"github.com/nuzur/sql-gen/db"
 	"golang.org/x/sync/errgroup"
@@ -19,18 +19,34 @@ import (
 //go:embed templates/**
 var templates embed.FS
 
+// templateFuncs are the helpers every schema template can call.
+var templateFuncs = template.FuncMap{
+	"inc": func(i int) int {
+		return i + 1
+	},
+}
+
 type GenerateRequest struct {
 	ExecutionUUID  string
 	ProjectVersion *nemgen.ProjectVersion
 	Configvalues   *ConfigValues
 	ForGolang      bool
+	// Sink receives GenerateSQL's files. Without one they are written to
+	// <ExecutionsDir>/<ExecutionUUID>/ and zipped to
+	// <ExecutionsDir>/<ExecutionUUID>.zip.
+	Sink Sink
+	// ExecutionsDir is where GenerateSQL writes without a Sink, "executions"
+	// in the working directory if empty.
+	ExecutionsDir string
 }
 
 type GenerateResponse struct {
 	ExecutionUUID string
-	WorkingDir    string
-	ZipFile       string
-	Results       []ActionResult
+	// WorkingDir and ZipFile are where a DirSink wrote the files and their
+	// zip, empty for any other sink.
+	WorkingDir string
+	ZipFile    string
+	Results    []ActionResult
 }
 
 type ActionResult struct {
@@ -38,7 +54,42 @@ type ActionResult struct {
 	Data   string
 }
 
+// GenerateSQL generates the request's actions and writes them to its sink.
 func GenerateSQL(ctx context.Context, req GenerateRequest) (*GenerateResponse, error) {
+	results, err := GenerateSQLResults(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	sink := req.Sink
+	if sink == nil {
+		executionsDir := req.ExecutionsDir
+		if executionsDir == "" {
+			executionsDir = defaultExecutionsDir
+		}
+		sink = &DirSink{Dir: path.Join(executionsDir, req.ExecutionUUID), Zip: true}
+	}
+	if err := writeResults(ctx, sink, results); err != nil {
+		return nil, err
+	}
+
+	res := &GenerateResponse{
+		ExecutionUUID: req.ExecutionUUID,
+		Results:       results,
+	}
+	if dir, ok := sink.(*DirSink); ok {
+		res.WorkingDir = dir.Dir
+		if dir.Zip {
+			res.ZipFile = fmt.Sprintf("%s.zip", path.Clean(dir.Dir))
+		}
+	}
+	return res, nil
+}
+
+// GenerateSQLResults generates the request's actions in memory, without
+// touching the filesystem, one result per action in the order the request
+// lists them. The request's Sink and ExecutionsDir play no part.
+func GenerateSQLResults(ctx context.Context, req GenerateRequest) ([]ActionResult, error) {
 	configvalues := req.Configvalues
 	if len(configvalues.Actions) == 0 {
 		return nil, errors.New("invalid request")
@@ -62,24 +113,38 @@ func GenerateSQL(ctx context.Context, req GenerateRequest) (*GenerateResponse, e
 				continue
 			}
 
-			entityTemplate, err := MapEntityToSchemaEntity(e, projectVersion, configvalues.DBType, req.ForGolang)
+			entityTemplate, err := mapEntityToSchemaEntity(e, projectVersion, configvalues.DBType, req.ForGolang, configvalues.Indexes)
 			if err != nil {
 				return nil, err
 			}
+			entityTemplate.addChecks(configvalues.Checks[e.Uuid])
+			entityTemplate.placeInSchemas(e.Uuid, configvalues.Schemas)
+			entityTemplate.computeColumns(configvalues.Computed)
+			if err := entityTemplate.softDelete(e, configvalues.SoftDelete[e.Uuid]); err != nil {
+				return nil, err
+			}
+			entityTemplate.AutoIncrementStart = configvalues.AutoIncrement[e.Uuid]
 			entities = append(entities, entityTemplate)
 		}
 	}
 
-	if configvalues.DBType == db.PGDBType {
-		// Postgres index names must be unique per schema (MySQL scopes them per
-		// table, so it needs no disambiguation).
+	if configvalues.DBType == db.PGDBType || configvalues.DBType == db.SQLiteDBType {
+		// Postgres index names must be unique per schema and sqlite's per
+		// database (MySQL scopes them per table, so it needs no disambiguation).
 		deduplicateIndexNames(entities)
 	}
 
 	deduplicateConstraintNames(entities)
 
+	enums := applyEnumMode(entities, projectVersion, configvalues.DBType, configvalues.SchemaOptions)
+
+	views := mapViews(configvalues.Views, configvalues.DBType)
+
 	tpl := SchemaTemplate{
 		Entities: entities,
+		Enums:    enums,
+		Schemas:  usedSchemas(entities, enums, views),
+		Views:    views,
 	}
 	results := []ActionResult{}
 
@@ -95,7 +160,6 @@ func GenerateSQL(ctx context.Context, req GenerateRequest) (*GenerateResponse, e
 		eg.Go(func() error {
 			return GenerateFile(ctx, &GenerateFileRequest{
 				mu:            resultsMu,
-				ExecutionUUID: req.ExecutionUUID,
 				Configvalues:  configvalues,
 				Data:          tpl,
 				ActionResults: &results,
@@ -108,27 +172,22 @@ func GenerateSQL(ctx context.Context, req GenerateRequest) (*GenerateResponse, e
 		return nil, err
 	}
 
-	err = filetools.GenerateZip(ctx, filetools.ZipRequest{
-		OutputPath: "executions",
-		Identifier: req.ExecutionUUID,
+	// the actions finish in any order
+	sort.SliceStable(results, func(a, b int) bool {
+		return slices.Index(configvalues.Actions, results[a].Action) < slices.Index(configvalues.Actions, results[b].Action)
 	})
-	if err != nil {
-		return nil, err
-	}
 
-	return &GenerateResponse{
-		ExecutionUUID: req.ExecutionUUID,
-		WorkingDir:    path.Join("executions", req.ExecutionUUID),
-		ZipFile:       path.Join("executions", fmt.Sprintf("%s.zip", req.ExecutionUUID)),
-		Results:       results,
-	}, nil
+	return results, nil
 }
 
 type GenerateFileRequest struct {
 	// mu guards the append to ActionResults. It is a pointer so concurrent
 	// callers writing to one results slice can share it; nil means the caller
 	// is not sharing (a single, sequential GenerateFile call).
-	mu            *sync.Mutex
+	mu *sync.Mutex
+	// Deprecated: GenerateSQL's sink writes the files. When set, GenerateFile
+	// still writes its file to executions/<ExecutionUUID>/ as it did before
+	// there were sinks.
 	ExecutionUUID string
 	Configvalues  *ConfigValues
 	Data          SchemaTemplate
@@ -197,25 +256,18 @@ func deduplicateConstraintNames(entities []SchemaEntity) {
 	}
 }
 
+// GenerateFile renders one action's file in memory and appends it to the
+// request's results, writing it out too for a request with an ExecutionUUID.
 func GenerateFile(ctx context.Context, req *GenerateFileRequest) error {
-	fileName := fmt.Sprintf("%s_%s", string(req.Action), req.Configvalues.DBType)
-	tmplBytes, err := templates.ReadFile(fmt.Sprintf("templates/%s.tmpl", fileName))
+	data, err := renderSchemaTemplate(req.Action, req.Configvalues.DBType, req.Data)
 	if err != nil {
 		return err
 	}
-	data, err := filetools.GenerateFile(ctx, filetools.FileRequest{
-		OutputPath:      path.Join("executions", req.ExecutionUUID, fmt.Sprintf("%s.sql", string(req.Action))),
-		TemplateBytes:   tmplBytes,
-		Data:            req.Data,
-		DisableGoFormat: true,
-		Funcs: template.FuncMap{
-			"inc": func(i int) int {
-				return i + 1
-			},
-		},
-	})
-	if err != nil {
-		return err
+	if req.ExecutionUUID != "" {
+		sink := &DirSink{Dir: path.Join(defaultExecutionsDir, req.ExecutionUUID)}
+		if err := sink.WriteFile(ctx, fmt.Sprintf("%s.sql", req.Action), []byte(data)); err != nil {
+			return err
+		}
 	}
 	if req.mu != nil {
 		req.mu.Lock()
@@ -223,7 +275,7 @@ func GenerateFile(ctx context.Context, req *GenerateFileRequest) error {
 	}
 	*req.ActionResults = append(*req.ActionResults, ActionResult{
 		Action: req.Action,
-		Data:   string(data),
+		Data:   data,
 	})
 
 	return nil
@@ -33,10 +33,12 @@ func TestGenMysql(t *testing.T) {
 				DeleteAction,
 				InsertAction,
 				UpdateAction,
+				UpsertAction,
 				DeleteAction,
 				SelectSimpleAction,
 				SelectForIndexedSimpleAction,
 				SelectForIndexedCombinedAction,
+				DropAction,
 			},
 		},
 		ProjectVersion: projectVersion,
@@ -60,10 +62,14 @@ func TestGenMysql(t *testing.T) {
 			assertGolden(t, "./testdata/inserts_mysql.sql", db.Data)
 		case UpdateAction:
 			assertGolden(t, "./testdata/updates_mysql.sql", db.Data)
+		case UpsertAction:
+			assertGolden(t, "./testdata/upserts_mysql.sql", db.Data)
 		case DeleteAction:
 			assertGolden(t, "./testdata/deletes_mysql.sql", db.Data)
 		case CreateAction:
 			assertGolden(t, "./testdata/creates_mysql.sql", db.Data)
+		case DropAction:
+			assertGolden(t, "./testdata/drops_mysql.sql", db.Data)
 		case SelectSimpleAction:
 			assertGolden(t, "./testdata/selects_simple_mysql.sql", db.Data)
 		case SelectForIndexedSimpleAction:
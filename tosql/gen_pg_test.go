@@ -33,10 +33,12 @@ func TestGenPG(t *testing.T) {
 				DeleteAction,
 				InsertAction,
 				UpdateAction,
+				UpsertAction,
 				DeleteAction,
 				SelectSimpleAction,
 				SelectForIndexedSimpleAction,
 				SelectForIndexedCombinedAction,
+				DropAction,
 			},
 		},
 		ProjectVersion: projectVersion,
@@ -61,10 +63,14 @@ func TestGenPG(t *testing.T) {
 			assertGolden(t, "./testdata/inserts_pg.sql", db.Data)
 		case UpdateAction:
 			assertGolden(t, "./testdata/updates_pg.sql", db.Data)
+		case UpsertAction:
+			assertGolden(t, "./testdata/upserts_pg.sql", db.Data)
 		case DeleteAction:
 			assertGolden(t, "./testdata/deletes_pg.sql", db.Data)
 		case CreateAction:
 			assertGolden(t, "./testdata/creates_pg.sql", db.Data)
+		case DropAction:
+			assertGolden(t, "./testdata/drops_pg.sql", db.Data)
 		case SelectSimpleAction:
 			assertGolden(t, "./testdata/selects_simple_pg.sql", db.Data)
 		case SelectForIndexedSimpleAction:
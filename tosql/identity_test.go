@@ -0,0 +1,61 @@
+package tosql
+
+import (
+	"testing"
+
+	nemgen "github.com/nuzur/nem/idl/gen"
+	"github.com/nuzur/sql-gen/db"
+	"github.com/stretchr/testify/assert"
+)
+
+func identityVersion(autoIncrement bool) *nemgen.ProjectVersion {
+	id := migrationField("counter.id", "id", nemgen.FieldType_FIELD_TYPE_INTEGER, true)
+	id.TypeConfig.Integer = &nemgen.FieldTypeIntegerConfig{Size: nemgen.FieldTypeIntegerConfigSize_FIELD_TYPE_INTEGER_CONFIG_SIZE_SIXTY_FOUR_BITS}
+	id.Key = true
+	id.KeyAutoIncrement = autoIncrement
+	return &nemgen.ProjectVersion{Entities: []*nemgen.Entity{
+		migrationTable("counter", "counter", []*nemgen.Field{id, migrationVarchar("counter.name", "name", 50, true)}),
+	}}
+}
+
+func TestAutoIncrementKeysAreIdentityColumns(t *testing.T) {
+	assert.Contains(t, generateWithOptions(t, identityVersion(true), db.PGDBType, SchemaOptions{}, CreateAction),
+		"\"id\" BIGINT NOT NULL GENERATED BY DEFAULT AS IDENTITY,\n")
+	assert.Contains(t, generateWithOptions(t, identityVersion(false), db.PGDBType, SchemaOptions{}, CreateAction),
+		"\"id\" BIGINT NOT NULL,\n")
+
+	// a uuid key has no sequence to draw from
+	pv := identityVersion(true)
+	pv.Entities[0].Fields[0] = migrationKey("counter.id")
+	pv.Entities[0].Fields[0].KeyAutoIncrement = true
+	assert.Contains(t, generateWithOptions(t, pv, db.PGDBType, SchemaOptions{}, CreateAction),
+		"\"id\" UUID NOT NULL,\n")
+}
+
+func TestMigrationAddsAndDropsIdentity(t *testing.T) {
+	m, err := GenerateMigration(identityVersion(false), identityVersion(true), db.PGDBType)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{
+		`ALTER TABLE "counter" ALTER COLUMN "id" ADD GENERATED BY DEFAULT AS IDENTITY;`,
+	}, m.Statements)
+	assert.Equal(t, []string{
+		`ALTER TABLE "counter" ALTER COLUMN "id" DROP IDENTITY IF EXISTS;`,
+	}, m.Rollback)
+}
+
+func TestAutoIncrementKeysOnMysql(t *testing.T) {
+	options := SchemaOptions{AutoIncrement: map[string]int64{"counter": 1000}}
+	assert.Contains(t, generateWithOptions(t, identityVersion(true), db.MYSQLDBType, options, CreateAction),
+		"`id` BIGINT NOT NULL AUTO_INCREMENT,\n")
+	assert.Contains(t, generateWithOptions(t, identityVersion(true), db.MYSQLDBType, options, CreateAction),
+		") ENGINE = InnoDB AUTO_INCREMENT = 1000;\n")
+	assert.Contains(t, generateWithOptions(t, identityVersion(true), db.MYSQLDBType, SchemaOptions{}, CreateAction),
+		") ENGINE = InnoDB;\n")
+
+	// the counter of a table that exists is its rows', not the schema's
+	m, err := GenerateMigrationWithOptions(identityVersion(false), identityVersion(true), db.MYSQLDBType, SchemaOptions{}, options)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{
+		"ALTER TABLE `counter` MODIFY COLUMN `id` BIGINT NOT NULL AUTO_INCREMENT;",
+	}, m.Statements)
+}
@@ -0,0 +1,142 @@
+package tosql
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/nuzur/sql-gen/db"
+)
+
+// IndexOptions are what an index has beyond the fields nem lists for it.
+type IndexOptions struct {
+	// Where makes it a partial index, over the rows the condition holds for:
+	// the condition in the engine's own SQL, without the WHERE keyword. mysql
+	// has no partial indexes and indexes every row.
+	Where string `json:"where,omitempty"`
+	// Expressions are the members of the index that are expressions over the
+	// columns rather than a column, such as lower(email). On mysql they need
+	// 8.0.13 or later.
+	Expressions []IndexExpression `json:"expressions,omitempty"`
+	// Method is the index's access method. Postgres takes any it has, such as
+	// gin, gist, brin or hash, and builds a btree without one. mysql takes
+	// spatial; InnoDB builds a btree for hash and reports it as one, so hash is
+	// left out there. sqlite has no access methods.
+	Method string `json:"method,omitempty"`
+	// OpClasses are the postgres operator classes of the index's fields, by
+	// field uuid, such as jsonb_path_ops or gin_trgm_ops. A field without one
+	// takes its type's default. The other engines have none.
+	OpClasses map[string]string `json:"op_classes,omitempty"`
+}
+
+// IndexExpression is an expression member of an index.
+type IndexExpression struct {
+	// Expression is the member in the engine's own SQL, without the
+	// parentheses around it.
+	Expression string `json:"expression"`
+	// Priority places the expression among the index's fields, as their own
+	// priority does. A field comes first where the two are equal.
+	Priority   int64 `json:"priority"`
+	Descending bool  `json:"descending,omitempty"`
+	// OpClass is the expression's postgres operator class, as OpClasses is a
+	// field's.
+	OpClass string `json:"op_class,omitempty"`
+}
+
+// InTable reports whether the index is declared in its table's CREATE TABLE
+// rather than created after it. Postgres and sqlite only take a primary key
+// and a plain UNIQUE there: a unique index with a WHERE, an expression, an
+// access method or an operator class in it is no constraint, and is created as
+// an index.
+func (i SchemaIndex) InTable() bool {
+	if i.DBType == db.MYSQLDBType {
+		return true
+	}
+	return i.Type == "primary" || i.Type == "unique" && i.Where == "" && len(i.Expressions) == 0 &&
+		i.Method == "" && len(i.OpClasses) == 0
+}
+
+// UsingClause is the postgres index's access method, with the space before
+// it, or empty for a btree.
+func (i SchemaIndex) UsingClause() string {
+	if i.DBType != db.PGDBType || i.Method == "" {
+		return ""
+	}
+	return " USING " + i.Method
+}
+
+// indexMethod is the access method to render for the engine, lowercased, and
+// empty for the default btree or a method the engine does not take.
+func indexMethod(method string, dbType db.DBType) string {
+	method = strings.ToLower(method)
+	switch {
+	case method == "btree":
+		return ""
+	case dbType == db.MYSQLDBType && method != "spatial":
+		return ""
+	case dbType == db.SQLiteDBType:
+		return ""
+	}
+	return method
+}
+
+// opClass is the operator class after a member of a postgres index, with the
+// space before it, or empty.
+func (i SchemaIndex) opClass(opClass string) string {
+	if i.DBType != db.PGDBType || opClass == "" {
+		return ""
+	}
+	return " " + opClass
+}
+
+// WhereClause is the partial index's WHERE, with the space before it, or empty
+// for an index over every row.
+func (i SchemaIndex) WhereClause() string {
+	if i.Where == "" {
+		return ""
+	}
+	return " WHERE " + i.Where
+}
+
+// indexMember is one rendered member of an index, at the priority it sorts by.
+type indexMember struct {
+	priority int64
+	sql      string
+}
+
+// withExpressions merges the index's expressions into its rendered fields, in
+// priority order. Each expression is wrapped in parentheses, which is also
+// how mysql 8 takes a functional key part.
+func (i SchemaIndex) withExpressions(fields []indexMember) []string {
+	members := fields
+	for _, e := range i.Expressions {
+		member := fmt.Sprintf("(%s)%s", e.Expression, i.opClass(e.OpClass))
+		if e.Descending {
+			member += " DESC"
+		}
+		members = append(members, indexMember{priority: e.Priority, sql: member})
+	}
+	sort.SliceStable(members, func(a, b int) bool {
+		return members[a].priority < members[b].priority
+	})
+	res := []string{}
+	for _, m := range members {
+		res = append(res, m.sql)
+	}
+	return res
+}
+
+// indexOptionsSignature is what the index's options add to its DDL.
+func indexOptionsSignature(i SchemaIndex) string {
+	parts := []string{i.Where, i.Method}
+	for _, e := range i.Expressions {
+		parts = append(parts, fmt.Sprintf("%d %t %s %s", e.Priority, e.Descending, e.Expression, e.OpClass))
+	}
+	opClasses := []string{}
+	for fieldUuid, opClass := range i.OpClasses {
+		opClasses = append(opClasses, fieldUuid+" "+opClass)
+	}
+	sort.Strings(opClasses)
+	parts = append(parts, opClasses...)
+	return strings.Join(parts, "|")
+}
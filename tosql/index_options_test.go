@@ -0,0 +1,144 @@
+package tosql
+
+import (
+	"testing"
+
+	nemgen "github.com/nuzur/nem/idl/gen"
+	"github.com/nuzur/sql-gen/db"
+	"github.com/stretchr/testify/assert"
+)
+
+// indexedVersion is an account with a soft-delete-aware unique email and an
+// index over its lowercased name.
+func indexedVersion() *nemgen.ProjectVersion {
+	return &nemgen.ProjectVersion{Entities: []*nemgen.Entity{
+		migrationTable("account", "account", []*nemgen.Field{
+			migrationKey("account.id"),
+			migrationVarchar("account.email", "email", 255, true),
+			migrationVarchar("account.name", "name", 100, true),
+			migrationField("account.deleted_at", "deleted_at", nemgen.FieldType_FIELD_TYPE_DATE, false),
+		},
+			migrationIndex("account.email_idx", "account_email_live", nemgen.IndexType_INDEX_TYPE_UNIQUE, "account.email"),
+			migrationIndex("account.name_idx", "account_name_lower", nemgen.IndexType_INDEX_TYPE_INDEX),
+		),
+	}}
+}
+
+var indexOptions = SchemaOptions{Indexes: map[string]IndexOptions{
+	"account.email_idx": {Where: "deleted_at IS NULL"},
+	"account.name_idx":  {Expressions: []IndexExpression{{Expression: "lower(name)"}}},
+}}
+
+func TestPartialAndExpressionIndexes(t *testing.T) {
+	assert.Equal(t, "CREATE TABLE IF NOT EXISTS \"account\" (\n"+
+		"    \"id\" UUID NOT NULL,\n"+
+		"    \"email\" VARCHAR(255) NOT NULL,\n"+
+		"    \"name\" VARCHAR(100) NOT NULL,\n"+
+		"    \"deleted_at\" DATE,\n"+
+		"    PRIMARY KEY (\"id\")\n"+
+		");\n"+
+		"CREATE INDEX \"account_name_lower\" ON \"account\" ((lower(name)));\n"+
+		"CREATE UNIQUE INDEX \"account_email_live\" ON \"account\" (\"email\") WHERE deleted_at IS NULL;\n\n",
+		generateWithOptions(t, indexedVersion(), db.PGDBType, indexOptions, CreateAction))
+
+	assert.Contains(t, generateWithOptions(t, indexedVersion(), db.SQLiteDBType, indexOptions, CreateAction),
+		"CREATE UNIQUE INDEX \"account_email_live\" ON \"account\" (\"email\") WHERE deleted_at IS NULL;\n")
+
+	// mysql indexes every row, and takes the expression as a functional key part
+	mysql := generateWithOptions(t, indexedVersion(), db.MYSQLDBType, indexOptions, CreateAction)
+	assert.Contains(t, mysql, "    INDEX `account_name_lower` ((lower(name))),\n")
+	assert.Contains(t, mysql, "    UNIQUE INDEX `account_email_live` (`email`)\n")
+
+	// without its options, the name index has nothing to index
+	assert.NotContains(t, generateWithOptions(t, indexedVersion(), db.PGDBType, SchemaOptions{}, CreateAction), "account_name_lower")
+}
+
+func TestExpressionsTakeTheirPlaceAmongTheFields(t *testing.T) {
+	pv := indexedVersion()
+	pv.Entities[0].TypeConfig.Standalone.Indexes[1] = migrationIndex("account.name_idx", "account_name_lower",
+		nemgen.IndexType_INDEX_TYPE_INDEX, "account.deleted_at", "account.id")
+	pv.Entities[0].TypeConfig.Standalone.Indexes[1].Fields[1].Priority = 2
+	options := SchemaOptions{Indexes: map[string]IndexOptions{
+		"account.name_idx": {Expressions: []IndexExpression{{Expression: "lower(name)", Priority: 1, Descending: true}}},
+	}}
+	assert.Contains(t, generateWithOptions(t, pv, db.PGDBType, options, CreateAction),
+		"CREATE INDEX \"account_name_lower\" ON \"account\" (\"deleted_at\", (lower(name)) DESC, \"id\");\n")
+}
+
+func TestMigrationOfPartialIndexes(t *testing.T) {
+	// a plain unique constraint becomes a partial unique index
+	m, err := GenerateMigrationWithOptions(indexedVersion(), indexedVersion(), db.PGDBType, SchemaOptions{}, indexOptions)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{
+		`ALTER TABLE "account" DROP CONSTRAINT "account_email_key";`,
+		`CREATE INDEX "account_name_lower" ON "account" ((lower(name)));`,
+		`CREATE UNIQUE INDEX "account_email_live" ON "account" ("email") WHERE deleted_at IS NULL;`,
+	}, m.Statements)
+	assert.Equal(t, []string{
+		`DROP INDEX "account_name_lower";`,
+		`DROP INDEX "account_email_live";`,
+		`ALTER TABLE "account" ADD UNIQUE ("email");`,
+	}, m.Rollback)
+
+	// sqlite creates and drops a partial unique index without a rebuild
+	m, err = GenerateMigrationWithOptions(indexedVersion(), indexedVersion(), db.SQLiteDBType, SchemaOptions{Indexes: map[string]IndexOptions{
+		"account.email_idx": {Where: "deleted_at IS NULL"},
+	}}, indexOptions)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{
+		`CREATE INDEX "account_name_lower" ON "account" ((lower(name)));`,
+	}, m.Statements)
+
+	m, err = GenerateMigrationWithOptions(indexedVersion(), indexedVersion(), db.PGDBType, indexOptions, indexOptions)
+	assert.NoError(t, err)
+	assert.Empty(t, m.Statements)
+}
+
+func TestIndexAccessMethods(t *testing.T) {
+	pv := indexedVersion()
+	options := SchemaOptions{Indexes: map[string]IndexOptions{
+		"account.email_idx": {Method: "gin", OpClasses: map[string]string{"account.email": "gin_trgm_ops"}},
+		"account.name_idx":  {Method: "BRIN", Expressions: []IndexExpression{{Expression: "lower(name)", OpClass: "text_minmax_ops"}}},
+	}}
+	pg := generateWithOptions(t, pv, db.PGDBType, options, CreateAction)
+	assert.Contains(t, pg, "CREATE INDEX \"account_name_lower\" ON \"account\" USING brin ((lower(name)) text_minmax_ops);\n")
+	// an operator class takes a unique index out of the table, as an index
+	assert.Contains(t, pg, "CREATE UNIQUE INDEX \"account_email_live\" ON \"account\" USING gin (\"email\" gin_trgm_ops);\n")
+
+	// btree is what postgres builds anyway
+	options.Indexes["account.name_idx"] = IndexOptions{Method: "btree", Expressions: []IndexExpression{{Expression: "lower(name)"}}}
+	assert.Contains(t, generateWithOptions(t, pv, db.PGDBType, options, CreateAction),
+		"CREATE INDEX \"account_name_lower\" ON \"account\" ((lower(name)));\n")
+
+	// mysql takes spatial and ignores operator classes; sqlite takes neither
+	options = SchemaOptions{Indexes: map[string]IndexOptions{
+		"account.email_idx": {Method: "hash", OpClasses: map[string]string{"account.email": "gin_trgm_ops"}},
+		"account.name_idx":  {Method: "spatial", Expressions: []IndexExpression{{Expression: "lower(name)"}}},
+	}}
+	mysql := generateWithOptions(t, pv, db.MYSQLDBType, options, CreateAction)
+	assert.Contains(t, mysql, "    SPATIAL INDEX `account_name_lower` ((lower(name))),\n")
+	assert.Contains(t, mysql, "    UNIQUE INDEX `account_email_live` (`email`)\n")
+	sqlite := generateWithOptions(t, pv, db.SQLiteDBType, options, CreateAction)
+	assert.Contains(t, sqlite, "    UNIQUE (\"email\")\n")
+	assert.Contains(t, sqlite, "CREATE INDEX \"account_name_lower\" ON \"account\" ((lower(name)));\n")
+}
+
+func TestMigrationOfIndexAccessMethods(t *testing.T) {
+	from := SchemaOptions{Indexes: map[string]IndexOptions{
+		"account.name_idx": {Expressions: []IndexExpression{{Expression: "lower(name)"}}},
+	}}
+	to := SchemaOptions{Indexes: map[string]IndexOptions{
+		"account.name_idx": {Method: "hash", Expressions: []IndexExpression{{Expression: "lower(name)"}}},
+	}}
+	m, err := GenerateMigrationWithOptions(indexedVersion(), indexedVersion(), db.PGDBType, from, to)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{
+		`DROP INDEX "account_name_lower";`,
+		`CREATE INDEX "account_name_lower" ON "account" USING hash ((lower(name)));`,
+	}, m.Statements)
+
+	// mysql has no hash index to change to
+	m, err = GenerateMigrationWithOptions(indexedVersion(), indexedVersion(), db.MYSQLDBType, from, to)
+	assert.NoError(t, err)
+	assert.Empty(t, m.Statements)
+}
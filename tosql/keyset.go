@@ -0,0 +1,104 @@
+package tosql
+
+import (
+	"fmt"
+	"strings"
+
+	nemgen "github.com/nuzur/nem/idl/gen"
+	"github.com/nuzur/sql-gen/db"
+)
+
+// SchemaSelectKeyset is a select paged by keyset instead of by OFFSET: each
+// page starts after the sort column and primary key of the last row of the
+// one before, so reading a page costs the same however deep it is. The first
+// page is the select's OrderedBy query with an offset of 0.
+//
+// A row whose sort column is NULL compares as neither before nor after a
+// cursor, so no keyset page returns it.
+type SchemaSelectKeyset struct {
+	DBType db.DBType
+	// Suffix follows the select's name in the query's: the query of select
+	// UserByEmail paged on created_at ascending is
+	// FetchUserByEmailKeysetByCreatedAtASC.
+	Suffix string
+	// Columns are the sort column and then the primary key's, which break the
+	// ties between rows of the same sort value.
+	Columns    []SchemaField
+	Descending bool
+}
+
+// Clause is what follows the select's WHERE: the comparison with the cursor,
+// the ORDER BY and the LIMIT. offset is the number of placeholders before it.
+func (k SchemaSelectKeyset) Clause(forGolang bool, offset int) string {
+	direction, comparison := "ASC", ">"
+	if k.Descending {
+		direction, comparison = "DESC", "<"
+	}
+	placeholder := func() string {
+		if k.DBType == db.PGDBType && forGolang {
+			offset++
+			return fmt.Sprintf("$%d", offset)
+		}
+		return "?"
+	}
+
+	columns := []string{}
+	cursor := []string{}
+	order := []string{}
+	for _, c := range k.Columns {
+		quoted := qualifiedName(k.DBType, "", c.Name)
+		columns = append(columns, quoted)
+		cursor = append(cursor, placeholder())
+		order = append(order, fmt.Sprintf("%s %s", quoted, direction))
+	}
+	return fmt.Sprintf("AND (%s) %s (%s)\nORDER BY %s\nLIMIT %s;",
+		strings.Join(columns, ", "), comparison, strings.Join(cursor, ", "),
+		strings.Join(order, ", "), placeholder())
+}
+
+// keysetPaginate gives every select sorted on a time field its keyset pages,
+// ascending and descending, over the time field and the primary key. The
+// queries are named like the selects, so a name a select already has is not
+// taken; they are not selects themselves and never take a select's name, as
+// the selects have to be what go-code-gen resolves — see usableIndexMember.
+func keysetPaginate(selects []SchemaSelectStatement, primaryKeys []*nemgen.Field, dbType db.DBType, seenNames map[string]bool) {
+	keys := []SchemaField{}
+	for _, pk := range primaryKeys {
+		if f := mapField(pk, dbType); f != nil {
+			keys = append(keys, *f)
+		}
+	}
+	if len(keys) == 0 {
+		return
+	}
+
+	for i, s := range selects {
+		if !s.SortSupported {
+			continue
+		}
+		for _, timeField := range s.TimeFields {
+			columns := []SchemaField{timeField}
+			for _, k := range keys {
+				if k.Name != timeField.Name {
+					columns = append(columns, k)
+				}
+			}
+			for _, descending := range []bool{false, true} {
+				suffix := fmt.Sprintf("KeysetBy%sASC", timeField.NameTitle)
+				if descending {
+					suffix = fmt.Sprintf("KeysetBy%sDESC", timeField.NameTitle)
+				}
+				if seenNames[s.Name+suffix] {
+					continue
+				}
+				seenNames[s.Name+suffix] = true
+				selects[i].Keysets = append(selects[i].Keysets, SchemaSelectKeyset{
+					DBType:     dbType,
+					Suffix:     suffix,
+					Columns:    columns,
+					Descending: descending,
+				})
+			}
+		}
+	}
+}
@@ -0,0 +1,87 @@
+package tosql
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/nuzur/sql-gen/db"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestKeysetForGolangNumbersPlaceholders(t *testing.T) {
+	pv := loadTestProjectVersion(t)
+	results, err := GenerateSQLResults(context.Background(), GenerateRequest{
+		ProjectVersion: pv,
+		ForGolang:      true,
+		Configvalues: &ConfigValues{
+			DBType:   db.PGDBType,
+			Entities: []string{testUserEntityUUID},
+			Actions:  []Action{SelectForIndexedSimpleAction},
+		},
+	})
+	require.NoError(t, err)
+	require.Len(t, results, 1)
+
+	found := 0
+	for _, query := range strings.Split(results[0].Data, "-- name: ") {
+		if !strings.HasPrefix(query, "FetchUserByEmailKeysetBy") {
+			continue
+		}
+		found++
+		assertPGParamsContiguous(t, query, 5)
+	}
+	assert.Equal(t, 2, found)
+	assert.Contains(t, results[0].Data, `"email" = $1  AND ("updated_at", "uuid", "version") < ($2, $3, $4)
+ORDER BY "updated_at" DESC, "uuid" DESC, "version" DESC
+LIMIT $5;`)
+}
+
+// A keyset page takes no name a select has, and the selects are what they
+// were without keyset pages.
+func TestResolveSelectStatementsKeysets(t *testing.T) {
+	pv := loadTestProjectVersion(t)
+	entity := testEntity(t, pv, testUserEntityUUID)
+
+	suffixes := map[string][]string{}
+	for _, s := range ResolveSelectStatements(entity, db.MYSQLDBType) {
+		for _, k := range s.Keysets {
+			suffixes[s.Name] = append(suffixes[s.Name], k.Suffix)
+		}
+		if s.IsPrimary {
+			assert.Empty(t, s.Keysets, "the primary select is not paged")
+		}
+	}
+	assert.Equal(t, []string{"KeysetByUpdatedAtASC", "KeysetByUpdatedAtDESC"}, suffixes["UserByEmail"])
+
+	selects := ResolveSelectStatements(entity, db.MYSQLDBType)
+	for i := range selects {
+		selects[i].Keysets = nil
+	}
+	keysetPaginate(selects, EntityPrimaryKeys(entity), db.MYSQLDBType, map[string]bool{
+		"UserByEmailKeysetByUpdatedAtASC": true,
+	})
+	for _, s := range selects {
+		if s.Name == "UserByEmail" {
+			require.Len(t, s.Keysets, 1)
+			assert.True(t, s.Keysets[0].Descending)
+		}
+	}
+}
+
+func TestKeysetClause(t *testing.T) {
+	k := SchemaSelectKeyset{
+		DBType:  db.SQLiteDBType,
+		Columns: []SchemaField{{Name: "created_at"}, {Name: "id"}},
+	}
+	assert.Equal(t, `AND ("created_at", "id") > (?, ?)
+ORDER BY "created_at" ASC, "id" ASC
+LIMIT ?;`, k.Clause(true, 1))
+
+	k.DBType = db.PGDBType
+	k.Descending = true
+	assert.Equal(t, `AND ("created_at", "id") < ($2, $3)
+ORDER BY "created_at" DESC, "id" DESC
+LIMIT $4;`, k.Clause(true, 1))
+}
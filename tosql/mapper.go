@@ -11,14 +11,22 @@ import (
 )
 
 func MapEntityToSchemaEntity(e *nemgen.Entity, projectVersion *nemgen.ProjectVersion, dbType db.DBType, forGolang bool) (SchemaEntity, error) {
-	fields, indexes, constraints := MapEntityToTypes(e, projectVersion, dbType)
+	return mapEntityToSchemaEntity(e, projectVersion, dbType, forGolang, nil)
+}
+
+// mapEntityToSchemaEntity is MapEntityToSchemaEntity with the options of the
+// entity's indexes, by index uuid (see SchemaOptions.Indexes). They are mapped
+// along with the indexes since they decide which ones the table declares.
+func mapEntityToSchemaEntity(e *nemgen.Entity, projectVersion *nemgen.ProjectVersion, dbType db.DBType, forGolang bool, indexOptions map[string]IndexOptions) (SchemaEntity, error) {
+	fields, indexes, constraints := mapEntityToTypes(e, projectVersion, dbType, indexOptions)
 	selects := ResolveSelectStatements(e, dbType)
+	fitUpsertsToIndexes(selects, indexes)
 	primaryKeys := EntityPrimaryKeys(e)
 	primaryKeysIdentifiers := []string{}
 	for _, pk := range primaryKeys {
 		if dbType == db.MYSQLDBType {
 			primaryKeysIdentifiers = append(primaryKeysIdentifiers, fmt.Sprintf("`%s`", pk.Identifier))
-		} else if dbType == db.PGDBType {
+		} else if dbType == db.PGDBType || dbType == db.SQLiteDBType {
 			primaryKeysIdentifiers = append(primaryKeysIdentifiers, fmt.Sprintf("\"%s\"", pk.Identifier))
 		}
 	}
@@ -32,10 +40,15 @@ func MapEntityToSchemaEntity(e *nemgen.Entity, projectVersion *nemgen.ProjectVer
 		Indexes:          indexes,
 		Constraints:      constraints,
 		SelectStatements: selects,
+		Comment:          e.Description,
 	}, nil
 }
 
 func MapEntityToTypes(e *nemgen.Entity, projectVersion *nemgen.ProjectVersion, dbType db.DBType) ([]SchemaField, []SchemaIndex, []SchemaConstraint) {
+	return mapEntityToTypes(e, projectVersion, dbType, nil)
+}
+
+func mapEntityToTypes(e *nemgen.Entity, projectVersion *nemgen.ProjectVersion, dbType db.DBType, indexOptions map[string]IndexOptions) ([]SchemaField, []SchemaIndex, []SchemaConstraint) {
 	fields := []SchemaField{}
 	indexes := []SchemaIndex{}
 	constraints := []SchemaConstraint{}
@@ -63,20 +76,21 @@ func MapEntityToTypes(e *nemgen.Entity, projectVersion *nemgen.ProjectVersion, d
 
 	if e.TypeConfig != nil && e.TypeConfig.Standalone != nil {
 		// map indexes
-		indexes = mapIndexes(e, dbType, fieldIdentifers, fieldTypes)
+		indexes = mapIndexes(e, dbType, fieldIdentifers, fieldTypes, indexOptions)
 
 		// map relationships to constraints
 		constraints = mapRelationships(e, projectVersion, dbType)
 	}
 
-	// for postgres only primary and unique indexes should have a comma
-	// as they are the only ones that are in the create statement
-	if dbType == db.PGDBType {
+	// for postgres and sqlite only the primary key and plain unique indexes
+	// should have a comma as they are the only ones that are in the create
+	// statement
+	if dbType == db.PGDBType || dbType == db.SQLiteDBType {
 		if len(indexes) > 0 {
 			// filtered index keys
 			filteredIndexKeys := []int{}
 			for i := range len(indexes) {
-				if indexes[i].Type == "primary" || indexes[i].Type == "unique" {
+				if indexes[i].InTable() {
 					filteredIndexKeys = append(filteredIndexKeys, i)
 				}
 			}
@@ -117,6 +131,8 @@ func mapField(f *nemgen.Field, dbType db.DBType) *SchemaField {
 		fieldType = FieldTypeToMYSQL(f)
 	} else if dbType == db.PGDBType {
 		fieldType = FieldTypeToPG(f)
+	} else if dbType == db.SQLiteDBType {
+		fieldType = FieldTypeToSQLite(f)
 	}
 
 	notNull := ""
@@ -145,6 +161,12 @@ func mapField(f *nemgen.Field, dbType db.DBType) *SchemaField {
 	// nothing changes for a model written before per-field defaults existed.
 	ft.Default = defaultClause(f, dbType)
 	ft.OnUpdate = onUpdateClause(f, dbType)
+	ft.Identity = identityClause(f, dbType)
+	ft.Comment = commentClause(f, dbType)
+	if ft.Identity != "" {
+		// neither engine takes a default on a column that counts on its own
+		ft.Default = ""
+	}
 	return &ft
 }
 
@@ -175,7 +197,7 @@ func mapFieldsToSelectFields(fields []*nemgen.Field, dbType db.DBType) []SchemaS
 
 }
 
-func mapIndexes(e *nemgen.Entity, dbType db.DBType, fieldIdentifers map[string]string, fieldTypes map[string]string) []SchemaIndex {
+func mapIndexes(e *nemgen.Entity, dbType db.DBType, fieldIdentifers map[string]string, fieldTypes map[string]string, indexOptions map[string]IndexOptions) []SchemaIndex {
 	indexes := []SchemaIndex{}
 	for _, i := range e.TypeConfig.Standalone.Indexes {
 		if i.Status == nemgen.IndexStatus_INDEX_STATUS_ACTIVE {
@@ -189,9 +211,17 @@ func mapIndexes(e *nemgen.Entity, dbType db.DBType, fieldIdentifers map[string]s
 				}
 			}
 
-			if len(fieldNames) == 0 {
+			options := indexOptions[i.Uuid]
+			if len(fieldNames) == 0 && len(options.Expressions) == 0 {
 				continue
 			}
+			if dbType == db.MYSQLDBType {
+				options.Where = ""
+			}
+			if dbType != db.PGDBType {
+				options.OpClasses = nil
+			}
+			method := indexMethod(options.Method, dbType)
 
 			indexTypePrefix := ""
 			if i.Type == nemgen.IndexType_INDEX_TYPE_UNIQUE {
@@ -200,6 +230,9 @@ func mapIndexes(e *nemgen.Entity, dbType db.DBType, fieldIdentifers map[string]s
 			if i.Type == nemgen.IndexType_INDEX_TYPE_FULLTEXT {
 				indexTypePrefix = "FULLTEXT "
 			}
+			if i.Type == nemgen.IndexType_INDEX_TYPE_INDEX && method == "spatial" {
+				indexTypePrefix = "SPATIAL "
+			}
 
 			indexType := ""
 			indexTypeSort := 0
@@ -220,14 +253,18 @@ func mapIndexes(e *nemgen.Entity, dbType db.DBType, fieldIdentifers map[string]s
 			}
 
 			indexes = append(indexes, SchemaIndex{
-				DBType:     dbType,
-				Name:       i.Identifier,
-				Index:      i,
-				FieldNames: fieldNames,
-				FieldTypes: indexFieldTypes,
-				Type:       indexType,
-				TypeSort:   indexTypeSort,
-				TypePrefix: indexTypePrefix,
+				DBType:      dbType,
+				Name:        i.Identifier,
+				Index:       i,
+				FieldNames:  fieldNames,
+				FieldTypes:  indexFieldTypes,
+				Type:        indexType,
+				TypeSort:    indexTypeSort,
+				TypePrefix:  indexTypePrefix,
+				Where:       options.Where,
+				Expressions: options.Expressions,
+				Method:      method,
+				OpClasses:   options.OpClasses,
 			})
 
 			// Stable: indexes of the same kind keep the order the schema lists
@@ -0,0 +1,1239 @@
+package tosql
+
+import (
+	"bytes"
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+	"text/template"
+
+	nemgen "github.com/nuzur/nem/idl/gen"
+	"github.com/nuzur/sql-gen/db"
+	"google.golang.org/protobuf/proto"
+)
+
+// migrationSuffix names the table a sqlite rebuild copies rows into before
+// it takes the original's place, and the postgres enum type a recreated one
+// takes over from.
+const migrationSuffix = "__migration"
+
+// Migration is the DDL that takes a database from one project version's schema
+// to another's.
+type Migration struct {
+	DBType db.DBType
+	// Statements are in the order they have to run. Each one is complete and
+	// terminated; a new table's entry also carries its CREATE INDEX statements,
+	// the same way create.sql lists them.
+	Statements []string
+	// Rollback undoes Statements: it is the migration from `to` back to
+	// `from`. It restores the schema, not data — a column or table the
+	// migration dropped comes back empty.
+	Rollback []string
+}
+
+// SQL is the migration as a single script.
+func (m *Migration) SQL() string {
+	if m == nil {
+		return ""
+	}
+	return joinStatements(m.Statements)
+}
+
+// RollbackSQL is the rollback as a single script.
+func (m *Migration) RollbackSQL() string {
+	if m == nil {
+		return ""
+	}
+	return joinStatements(m.Rollback)
+}
+
+func joinStatements(statements []string) string {
+	if len(statements) == 0 {
+		return ""
+	}
+	return strings.Join(statements, "\n") + "\n"
+}
+
+// GenerateMigration diffs two project versions and returns the DDL that turns a
+// database created from `from` into one created from `to`.
+//
+// Entities, fields, indexes and relationships are matched by uuid, never by
+// name: a renamed column is the same column and keeps its data, while a dropped
+// column and a new one that happens to take its name are not. Both sides go
+// through the same normalization GenerateSQL applies (unique-field desugaring,
+// topological order, index and constraint name deduplication), so every name the
+// migration refers to is the name create.sql gave the object. Neither project
+// version is modified.
+//
+// A nil `from` is an empty database, so the migration creates every table; a
+// nil `to` drops them all.
+//
+// The statements run in an order each step can rely on:
+//
+//  0. views that go away or change are dropped before anything else, along
+//     with every view over something that changes (see diffViews);
+//  1. foreign keys that go away or change are dropped first, so nothing below
+//     trips over a constraint that is about to disappear anyway;
+//  2. then the indexes and primary keys that go away or change;
+//  3. then the dropped tables, dependents first — after the foreign keys
+//     that tie them into a cycle, if any (see cyclicConstraints);
+//  4. then the schemas that are new on postgres, table renames and moves to
+//     another schema, and the enum types columns are about to use;
+//  5. then column renames, additions, modifications and drops, and comment
+//     changes, table by table, followed by the enum types that have to be
+//     recreated or dropped;
+//  6. then the new tables, in dependency order;
+//  7. then the indexes and primary keys that are new or changed;
+//  8. then the foreign keys that are new or changed, once every table and
+//     column they point at exists;
+//  9. and last the views that are new or were dropped in step 0.
+//
+// A foreign key whose own columns change, or whose referenced table gets a new
+// primary key, is treated as changed: mysql refuses to modify a column a
+// foreign key uses, and postgres to drop a primary key one depends on.
+//
+// No engine can turn a column into a generated one, or change how it is
+// generated, in place: such a column is dropped and added again, along with
+// the indexes and foreign keys over it. Postgres alone can make a generated
+// column a plain one, and keeps its values doing so.
+//
+// sqlite's ALTER TABLE can only rename tables and columns, so any other change
+// to an existing sqlite table rebuilds it: the new definition is created under
+// a temporary name, the rows are copied across, and it replaces the original.
+// Foreign key enforcement is turned off around that, as sqlite's own
+// documentation for the procedure requires.
+//
+// Postgres names an inline PRIMARY KEY or UNIQUE itself (<table>_pkey,
+// <table>_<columns>_key) and does not rename it along with the table or its
+// columns; the migration drops those constraints under the names postgres gave
+// them when the `from` table was created.
+//
+// GenerateMigration renders both sides with the default SchemaOptions; see
+// GenerateMigrationWithOptions.
+func GenerateMigration(from, to *nemgen.ProjectVersion, dbType db.DBType) (*Migration, error) {
+	return GenerateMigrationWithOptions(from, to, dbType, SchemaOptions{}, SchemaOptions{})
+}
+
+// GenerateMigrationWithOptions is GenerateMigration with each side rendered
+// with its own options, the ones its create.sql was generated with. Checks are
+// matched by name; one whose expression changes is dropped and added again.
+//
+// With native enums on postgres the enum types are diffed too. A new type is
+// created before the columns that use it and a dropped one goes once nothing
+// uses it any more. Options added to a type are added in place; a type that
+// loses or reorders options cannot be altered, so it is renamed out of the
+// way, created again, and every column using it is converted across by label.
+func GenerateMigrationWithOptions(from, to *nemgen.ProjectVersion, dbType db.DBType, fromOptions, toOptions SchemaOptions) (*Migration, error) {
+	fromSchema, err := mapMigrationSchema(from, dbType, fromOptions)
+	if err != nil {
+		return nil, err
+	}
+	toSchema, err := mapMigrationSchema(to, dbType, toOptions)
+	if err != nil {
+		return nil, err
+	}
+
+	statements, err := (&migrationBuilder{dbType: dbType, from: fromSchema, to: toSchema}).build()
+	if err != nil {
+		return nil, err
+	}
+	rollback, err := (&migrationBuilder{dbType: dbType, from: toSchema, to: fromSchema}).build()
+	if err != nil {
+		return nil, err
+	}
+	return &Migration{
+		DBType:     dbType,
+		Statements: statements,
+		Rollback:   rollback,
+	}, nil
+}
+
+// migrationEntity is a mapped entity together with the uuid it is matched on.
+type migrationEntity struct {
+	Uuid   string
+	Entity SchemaEntity
+}
+
+type migrationSchema struct {
+	// entities are in dependency order: an entity comes after every entity
+	// its foreign keys reference.
+	entities []migrationEntity
+	byUuid   map[string]migrationEntity
+	enums    []SchemaEnum
+	// views are in dependency order, as mapViews leaves them.
+	views []SchemaView
+}
+
+func (s *migrationSchema) schemaEntities() []SchemaEntity {
+	res := []SchemaEntity{}
+	for _, e := range s.entities {
+		res = append(res, e.Entity)
+	}
+	return res
+}
+
+func mapMigrationSchema(pv *nemgen.ProjectVersion, dbType db.DBType, options SchemaOptions) (*migrationSchema, error) {
+	res := &migrationSchema{
+		byUuid: make(map[string]migrationEntity),
+	}
+	if pv == nil {
+		return res, nil
+	}
+
+	// EnsureUniqueFieldIndexes and SortStandaloneEntities both work in place.
+	pv = proto.Clone(pv).(*nemgen.ProjectVersion)
+	EnsureUniqueFieldIndexes(pv)
+	SortStandaloneEntities(pv)
+
+	uuids := []string{}
+	entities := []SchemaEntity{}
+	for _, e := range pv.Entities {
+		// Only a standalone entity is a table, and a disabled one is a table
+		// that should no longer exist.
+		if e.Identifier == "" ||
+			e.Type != nemgen.EntityType_ENTITY_TYPE_STANDALONE ||
+			e.Status == nemgen.EntityStatus_ENTITY_STATUS_DISABLED {
+			continue
+		}
+		entity, err := mapEntityToSchemaEntity(e, pv, dbType, false, options.Indexes)
+		if err != nil {
+			return nil, err
+		}
+		entity.addChecks(options.Checks[e.Uuid])
+		entity.placeInSchemas(e.Uuid, options.Schemas)
+		entity.computeColumns(options.Computed)
+		entity.AutoIncrementStart = options.AutoIncrement[e.Uuid]
+		uuids = append(uuids, e.Uuid)
+		entities = append(entities, entity)
+	}
+
+	if dbType == db.PGDBType || dbType == db.SQLiteDBType {
+		deduplicateIndexNames(entities)
+	}
+	deduplicateConstraintNames(entities)
+	res.enums = applyEnumMode(entities, pv, dbType, options)
+	res.views = mapViews(options.Views, dbType)
+
+	for i := range entities {
+		me := migrationEntity{Uuid: uuids[i], Entity: entities[i]}
+		res.entities = append(res.entities, me)
+		res.byUuid[me.Uuid] = me
+	}
+	return res, nil
+}
+
+type migrationBuilder struct {
+	dbType db.DBType
+	from   *migrationSchema
+	to     *migrationSchema
+
+	// one list per step, concatenated at the end
+	dropViews       []string
+	dropConstraints []string
+	dropIndexes     []string
+	dropTables      []string
+	createSchemas   []string
+	renameTables    []string
+	createTypes     []string
+	alterColumns    []string
+	recreateTypes   []string
+	dropTypes       []string
+	createTables    []string
+	createIndexes   []string
+	addConstraints  []string
+	createViews     []string
+
+	// foreignKeysOff is set once a sqlite step needs foreign key enforcement
+	// off: a table rebuild, or dropping tables whose foreign keys form a cycle
+	foreignKeysOff bool
+
+	// renamedTypes maps the quoted name of a renamed postgres enum type to its
+	// new one, so the columns using it do not look retyped
+	renamedTypes map[string]string
+}
+
+// tableDiff is everything that changes on a table that exists on both sides.
+type tableDiff struct {
+	from SchemaEntity
+	to   SchemaEntity
+
+	renamedFields  [][2]SchemaField
+	addedFields    []SchemaField
+	modifiedFields [][2]SchemaField
+	droppedFields  []SchemaField
+	// recomputedFields are the columns that become, stop being or change how
+	// they are generated columns, which no engine can do in place: they are
+	// dropped and added again.
+	recomputedFields [][2]SchemaField
+
+	primaryKeyChanged bool
+	droppedIndexes    []SchemaIndex
+	addedIndexes      []SchemaIndex
+
+	droppedConstraints []SchemaConstraint
+	addedConstraints   []SchemaConstraint
+
+	droppedChecks []SchemaCheck
+	addedChecks   []SchemaCheck
+
+	// commentChanged is set when the table's comment changes.
+	commentChanged bool
+	// commentedFields are the postgres columns whose comment changes. A mysql
+	// comment is part of the column definition, and changing it modifies the
+	// column.
+	commentedFields []SchemaField
+}
+
+// changed reports whether anything about the table changes.
+func (d *tableDiff) changed() bool {
+	return d.from.QualifiedName() != d.to.QualifiedName() ||
+		len(d.renamedFields) > 0 || len(d.addedFields) > 0 || len(d.modifiedFields) > 0 || len(d.droppedFields) > 0 ||
+		len(d.recomputedFields) > 0 || d.primaryKeyChanged || len(d.droppedIndexes) > 0 || len(d.addedIndexes) > 0 ||
+		len(d.droppedConstraints) > 0 || len(d.addedConstraints) > 0 ||
+		len(d.droppedChecks) > 0 || len(d.addedChecks) > 0 ||
+		d.commentChanged || len(d.commentedFields) > 0
+}
+
+// needsSQLiteRebuild reports whether the change is beyond what sqlite's ALTER
+// TABLE can express. Secondary indexes are separate objects there and can
+// always be dropped and created on their own; plain unique ones are inline and
+// cannot.
+func (d *tableDiff) needsSQLiteRebuild() bool {
+	if len(d.addedFields) > 0 || len(d.modifiedFields) > 0 || len(d.droppedFields) > 0 || len(d.recomputedFields) > 0 ||
+		d.primaryKeyChanged || len(d.droppedConstraints) > 0 || len(d.addedConstraints) > 0 ||
+		len(d.droppedChecks) > 0 || len(d.addedChecks) > 0 {
+		return true
+	}
+	for _, i := range append(slices.Clone(d.droppedIndexes), d.addedIndexes...) {
+		if i.Type == "unique" && i.InTable() {
+			return true
+		}
+	}
+	return false
+}
+
+func (m *migrationBuilder) build() ([]string, error) {
+	m.diffTypes()
+
+	diffs := []*tableDiff{}
+	for _, te := range m.to.entities {
+		if fe, ok := m.from.byUuid[te.Uuid]; ok {
+			diffs = append(diffs, m.diffTable(fe.Entity, te.Entity))
+		}
+	}
+	m.markDependentConstraints(diffs)
+
+	dropped := []SchemaEntity{}
+	for _, fe := range m.from.entities {
+		if _, ok := m.to.byUuid[fe.Uuid]; !ok {
+			dropped = append(dropped, fe.Entity)
+		}
+	}
+	for _, c := range cyclicConstraints(dropped) {
+		switch m.dbType {
+		case db.MYSQLDBType:
+			m.dropConstraints = append(m.dropConstraints, fmt.Sprintf("ALTER TABLE %s DROP FOREIGN KEY %s;", c.QualifiedTableName(), m.quote(c.Constraint.Name)))
+		case db.PGDBType:
+			m.dropConstraints = append(m.dropConstraints, fmt.Sprintf("ALTER TABLE %s DROP CONSTRAINT %s;", c.QualifiedTableName(), m.quote(c.Constraint.Name)))
+		case db.SQLiteDBType:
+			m.foreignKeysOff = true
+		}
+	}
+	for _, e := range (SchemaTemplate{Entities: dropped}).DropOrder() {
+		m.dropTables = append(m.dropTables, fmt.Sprintf("DROP TABLE %s;", e.QualifiedName()))
+	}
+
+	m.createNewSchemas()
+	for _, d := range diffs {
+		// a table moves to its new schema under its old name, and is renamed
+		// there
+		table := d.from.QualifiedName()
+		if d.from.Schema != d.to.Schema {
+			m.renameTables = append(m.renameTables, fmt.Sprintf("ALTER TABLE %s SET SCHEMA %s;", table, m.quote(schemaOrPublic(d.to.Schema))))
+			table = qualifiedName(m.dbType, d.to.Schema, d.from.Name)
+		}
+		if d.from.Name != d.to.Name {
+			m.renameTables = append(m.renameTables, fmt.Sprintf("ALTER TABLE %s RENAME TO %s;", table, m.quote(d.to.Name)))
+		}
+		if m.dbType == db.SQLiteDBType && d.needsSQLiteRebuild() {
+			if err := m.rebuildSQLiteTable(d); err != nil {
+				return nil, err
+			}
+			continue
+		}
+		m.alterTable(d)
+	}
+
+	newEntities := []SchemaEntity{}
+	for _, te := range m.to.entities {
+		if _, ok := m.from.byUuid[te.Uuid]; !ok {
+			newEntities = append(newEntities, te.Entity)
+		}
+	}
+	for _, e := range newEntities {
+		create, err := renderCreateTables([]SchemaEntity{e}, m.dbType)
+		if err != nil {
+			return nil, err
+		}
+		m.createTables = append(m.createTables, create)
+	}
+	m.recreateChangedTypes(diffs)
+	if err := m.diffViews(diffs, dropped); err != nil {
+		return nil, err
+	}
+
+	statements := slices.Clone(m.dropViews)
+	if m.foreignKeysOff {
+		statements = append(statements, "PRAGMA foreign_keys = OFF;")
+	}
+	for _, step := range [][]string{
+		m.dropConstraints,
+		m.dropIndexes,
+		m.dropTables,
+		m.createSchemas,
+		m.renameTables,
+		m.createTypes,
+		m.alterColumns,
+		m.recreateTypes,
+		m.dropTypes,
+		m.createTables,
+		m.createIndexes,
+		m.addConstraints,
+		m.createViews,
+	} {
+		statements = append(statements, step...)
+	}
+	if m.foreignKeysOff {
+		statements = append(statements, "PRAGMA foreign_keys = ON;")
+	}
+
+	return statements, nil
+}
+
+func (m *migrationBuilder) diffTable(from, to SchemaEntity) *tableDiff {
+	d := &tableDiff{from: from, to: to}
+
+	fromFields := make(map[string]SchemaField)
+	for _, f := range from.Fields {
+		fromFields[f.Field.Uuid] = f
+	}
+	toFields := make(map[string]bool)
+	for _, f := range to.Fields {
+		toFields[f.Field.Uuid] = true
+		ff, ok := fromFields[f.Field.Uuid]
+		if !ok {
+			d.addedFields = append(d.addedFields, f)
+			continue
+		}
+		// postgres can turn a generated column into a plain one, keeping its
+		// values, which pgAlterColumnActions does
+		if ff.Computed != f.Computed && (m.dbType != db.PGDBType || f.Computed != "") {
+			d.recomputedFields = append(d.recomputedFields, [2]SchemaField{ff, f})
+			continue
+		}
+		if ff.Name != f.Name {
+			d.renamedFields = append(d.renamedFields, [2]SchemaField{ff, f})
+		}
+		if m.retypedName(ff.Type) != f.Type || ff.Postfix() != f.Postfix() {
+			d.modifiedFields = append(d.modifiedFields, [2]SchemaField{ff, f})
+		}
+		if m.dbType == db.PGDBType && ff.Field.GetDescription() != f.Field.GetDescription() {
+			d.commentedFields = append(d.commentedFields, f)
+		}
+	}
+	// sqlite has no comments
+	d.commentChanged = m.dbType != db.SQLiteDBType && from.Comment != to.Comment
+	for _, f := range from.Fields {
+		if !toFields[f.Field.Uuid] {
+			d.droppedFields = append(d.droppedFields, f)
+		}
+	}
+
+	d.primaryKeyChanged = primaryKeySignature(from) != primaryKeySignature(to)
+
+	fromIndexKeys := indexKeys(from)
+	toIndexKeys := indexKeys(to)
+	fromIndexes := make(map[string]SchemaIndex)
+	for n, i := range from.Indexes {
+		fromIndexes[fromIndexKeys[n]] = i
+	}
+	toIndexes := make(map[string]SchemaIndex)
+	for n, i := range to.Indexes {
+		toIndexes[toIndexKeys[n]] = i
+	}
+	// an index goes with the column it is over when that is dropped and added
+	// again
+	recomputed := make(map[string]bool)
+	for _, f := range d.recomputedFields {
+		recomputed[f[0].Field.Uuid] = true
+	}
+	indexRecomputed := func(i SchemaIndex) bool {
+		return slices.ContainsFunc(i.Index.GetFields(), func(f *nemgen.IndexField) bool {
+			return recomputed[f.GetFieldUuid()]
+		})
+	}
+	for n, i := range from.Indexes {
+		if i.Type == "primary" {
+			continue
+		}
+		ti, ok := toIndexes[fromIndexKeys[n]]
+		if !ok || indexSignature(i) != indexSignature(ti) || indexRecomputed(i) {
+			d.droppedIndexes = append(d.droppedIndexes, i)
+		}
+	}
+	for n, i := range to.Indexes {
+		if i.Type == "primary" {
+			continue
+		}
+		fi, ok := fromIndexes[toIndexKeys[n]]
+		if !ok || indexSignature(i) != indexSignature(fi) || indexRecomputed(i) {
+			d.addedIndexes = append(d.addedIndexes, i)
+		}
+	}
+
+	fromConstraints := make(map[string]SchemaConstraint)
+	for _, c := range from.Constraints {
+		fromConstraints[c.Relationship.GetUuid()] = c
+	}
+	toConstraints := make(map[string]SchemaConstraint)
+	for _, c := range to.Constraints {
+		toConstraints[c.Relationship.GetUuid()] = c
+	}
+	for _, c := range from.Constraints {
+		tc, ok := toConstraints[c.Relationship.GetUuid()]
+		if !ok || constraintSignature(c) != constraintSignature(tc) {
+			d.droppedConstraints = append(d.droppedConstraints, c)
+		}
+	}
+	for _, c := range to.Constraints {
+		fc, ok := fromConstraints[c.Relationship.GetUuid()]
+		if !ok || constraintSignature(c) != constraintSignature(fc) {
+			d.addedConstraints = append(d.addedConstraints, c)
+		}
+	}
+
+	// A check is matched on its name and expression together: any change to
+	// either is a different constraint.
+	fromChecks := make(map[SchemaCheck]bool)
+	for _, c := range from.Checks {
+		fromChecks[c] = true
+	}
+	toChecks := make(map[SchemaCheck]bool)
+	for _, c := range to.Checks {
+		toChecks[c] = true
+	}
+	for _, c := range from.Checks {
+		if !toChecks[c] {
+			d.droppedChecks = append(d.droppedChecks, c)
+		}
+	}
+	for _, c := range to.Checks {
+		if !fromChecks[c] {
+			d.addedChecks = append(d.addedChecks, c)
+		}
+	}
+
+	return d
+}
+
+// markDependentConstraints re-creates the foreign keys that are unchanged
+// themselves but sit on something that is: one of their own columns, or the
+// primary key of the table they reference. On mysql and postgres that also
+// takes a referenced column that is modified, and an index backing either end
+// that is dropped, neither of which the engines let go while the foreign key
+// holds on to it. sqlite checks none of these, and would rebuild the table
+// for nothing.
+func (m *migrationBuilder) markDependentConstraints(diffs []*tableDiff) {
+	primaryKeyChanged := make(map[string]bool)
+	modifiedByTable := make(map[string]map[string]bool)
+	droppedByTable := make(map[string][]SchemaIndex)
+	for _, d := range diffs {
+		table := d.from.QualifiedName()
+		if d.primaryKeyChanged {
+			primaryKeyChanged[table] = true
+		}
+		modified := make(map[string]bool)
+		for _, f := range d.modifiedFields {
+			modified[f[0].Field.Uuid] = true
+		}
+		for _, f := range d.recomputedFields {
+			modified[f[0].Field.Uuid] = true
+		}
+		modifiedByTable[table] = modified
+		droppedByTable[table] = d.droppedIndexes
+	}
+	referencedToo := m.dbType != db.SQLiteDBType
+
+	for _, d := range diffs {
+		table := d.from.QualifiedName()
+		for _, c := range d.from.Constraints {
+			if slices.ContainsFunc(d.droppedConstraints, func(dc SchemaConstraint) bool {
+				return dc.Relationship.GetUuid() == c.Relationship.GetUuid()
+			}) {
+				continue
+			}
+			referenced := c.QualifiedTableName()
+			fromUuids := c.Relationship.GetFrom().GetTypeConfig().GetEntity().GetFieldUuids()
+			toUuids := c.Relationship.GetTo().GetTypeConfig().GetEntity().GetFieldUuids()
+			affected := primaryKeyChanged[referenced]
+			for _, fu := range fromUuids {
+				affected = affected || modifiedByTable[table][fu]
+			}
+			if referencedToo {
+				for _, fu := range toUuids {
+					affected = affected || modifiedByTable[referenced][fu]
+				}
+				affected = affected ||
+					slices.ContainsFunc(droppedByTable[table], func(i SchemaIndex) bool { return indexBacks(i, fromUuids) }) ||
+					slices.ContainsFunc(droppedByTable[referenced], func(i SchemaIndex) bool { return indexBacks(i, toUuids) })
+			}
+			if !affected {
+				continue
+			}
+			d.droppedConstraints = append(d.droppedConstraints, c)
+			for _, tc := range d.to.Constraints {
+				if tc.Relationship.GetUuid() == c.Relationship.GetUuid() {
+					d.addedConstraints = append(d.addedConstraints, tc)
+				}
+			}
+		}
+	}
+}
+
+// indexBacks reports whether the index can be the one a foreign key over the
+// columns uses: its leading columns are them.
+func indexBacks(i SchemaIndex, fieldUuids []string) bool {
+	fields := slices.Clone(i.Index.GetFields())
+	if len(fieldUuids) == 0 || len(fields) < len(fieldUuids) {
+		return false
+	}
+	sort.SliceStable(fields, func(a, b int) bool {
+		return fields[a].Priority < fields[b].Priority
+	})
+	for _, f := range fields[:len(fieldUuids)] {
+		if !slices.Contains(fieldUuids, f.FieldUuid) {
+			return false
+		}
+	}
+	return true
+}
+
+// alterTable emits the ALTER statements for one table on mysql and postgres,
+// and the index and column renames on sqlite when nothing there needs a rebuild.
+func (m *migrationBuilder) alterTable(d *tableDiff) {
+	fromTable := d.from.QualifiedName()
+	table := d.to.QualifiedName()
+
+	for _, c := range d.droppedConstraints {
+		switch m.dbType {
+		case db.MYSQLDBType:
+			m.dropConstraints = append(m.dropConstraints, fmt.Sprintf("ALTER TABLE %s DROP FOREIGN KEY %s;", fromTable, m.quote(c.Name)))
+		case db.PGDBType:
+			m.dropConstraints = append(m.dropConstraints, fmt.Sprintf("ALTER TABLE %s DROP CONSTRAINT %s;", fromTable, m.quote(c.Name)))
+		}
+	}
+	for _, c := range d.droppedChecks {
+		switch m.dbType {
+		case db.MYSQLDBType:
+			m.dropConstraints = append(m.dropConstraints, fmt.Sprintf("ALTER TABLE %s DROP CHECK %s;", fromTable, m.quote(c.Name)))
+		case db.PGDBType:
+			m.dropConstraints = append(m.dropConstraints, fmt.Sprintf("ALTER TABLE %s DROP CONSTRAINT %s;", fromTable, m.quote(c.Name)))
+		}
+	}
+
+	if d.primaryKeyChanged && hasPrimaryKey(d.from) {
+		switch m.dbType {
+		case db.MYSQLDBType:
+			m.dropIndexes = append(m.dropIndexes, fmt.Sprintf("ALTER TABLE %s DROP PRIMARY KEY;", fromTable))
+		case db.PGDBType:
+			m.dropIndexes = append(m.dropIndexes, fmt.Sprintf("ALTER TABLE %s DROP CONSTRAINT %s;", fromTable, m.quote(d.from.Name+"_pkey")))
+		}
+	}
+	for _, i := range d.droppedIndexes {
+		switch m.dbType {
+		case db.MYSQLDBType:
+			m.dropIndexes = append(m.dropIndexes, fmt.Sprintf("DROP INDEX %s ON %s;", m.quote(i.Name), fromTable))
+		case db.PGDBType:
+			if i.Type == "unique" && i.InTable() {
+				m.dropIndexes = append(m.dropIndexes, fmt.Sprintf("ALTER TABLE %s DROP CONSTRAINT %s;", fromTable, m.quote(pgUniqueConstraintName(d.from.Name, i))))
+			} else {
+				// an index is in the schema of its table
+				m.dropIndexes = append(m.dropIndexes, fmt.Sprintf("DROP INDEX %s;", qualifiedName(m.dbType, d.from.Schema, i.Name)))
+			}
+		case db.SQLiteDBType:
+			m.dropIndexes = append(m.dropIndexes, fmt.Sprintf("DROP INDEX %s;", m.quote(i.Name)))
+		}
+	}
+
+	for _, f := range d.renamedFields {
+		m.alterColumns = append(m.alterColumns, fmt.Sprintf("ALTER TABLE %s RENAME COLUMN %s TO %s;", table, m.quote(f[0].Name), m.quote(f[1].Name)))
+	}
+	for _, f := range d.addedFields {
+		m.alterColumns = append(m.alterColumns, fmt.Sprintf("ALTER TABLE %s ADD COLUMN %s%s;", table, m.columnDefinition(f), m.columnPosition(d.to, f)))
+	}
+	for _, f := range d.modifiedFields {
+		switch m.dbType {
+		case db.MYSQLDBType:
+			m.alterColumns = append(m.alterColumns, fmt.Sprintf("ALTER TABLE %s MODIFY COLUMN %s;", table, m.columnDefinition(f[1])))
+		case db.PGDBType:
+			m.alterColumns = append(m.alterColumns, fmt.Sprintf("ALTER TABLE %s %s;", table, strings.Join(pgAlterColumnActions(f[0], f[1]), ", ")))
+		}
+	}
+	for _, f := range d.recomputedFields {
+		m.alterColumns = append(m.alterColumns,
+			fmt.Sprintf("ALTER TABLE %s DROP COLUMN %s;", table, m.quote(f[0].Name)),
+			fmt.Sprintf("ALTER TABLE %s ADD COLUMN %s%s;", table, m.columnDefinition(f[1]), m.columnPosition(d.to, f[1])))
+	}
+	for _, f := range d.droppedFields {
+		m.alterColumns = append(m.alterColumns, fmt.Sprintf("ALTER TABLE %s DROP COLUMN %s;", table, m.quote(f.Name)))
+	}
+	m.alterComments(d)
+
+	if d.primaryKeyChanged && hasPrimaryKey(d.to) {
+		m.createIndexes = append(m.createIndexes, fmt.Sprintf("ALTER TABLE %s ADD PRIMARY KEY (%s);", table, d.to.PrimaryKeysIdentifiers()))
+	}
+	for _, i := range d.addedIndexes {
+		m.createIndexes = append(m.createIndexes, m.createIndex(d.to, i))
+	}
+
+	for _, c := range d.addedConstraints {
+		m.addConstraints = append(m.addConstraints, fmt.Sprintf("ALTER TABLE %s ADD %s;", table, m.constraintDefinition(c)))
+	}
+	for _, c := range d.addedChecks {
+		m.addConstraints = append(m.addConstraints, fmt.Sprintf("ALTER TABLE %s ADD CONSTRAINT %s CHECK (%s);", table, m.quote(c.Name), c.Expression))
+	}
+}
+
+// alterComments sets the comments that change, and on postgres the ones on
+// the columns that are added or added again.
+func (m *migrationBuilder) alterComments(d *tableDiff) {
+	switch m.dbType {
+	case db.MYSQLDBType:
+		if d.commentChanged {
+			m.alterColumns = append(m.alterColumns, fmt.Sprintf("ALTER TABLE %s COMMENT = %s;", d.to.QualifiedName(), commentLiteral(d.to.Comment, m.dbType)))
+		}
+	case db.PGDBType:
+		if d.commentChanged {
+			m.alterColumns = append(m.alterColumns, d.to.pgTableComment())
+		}
+		fields := slices.Clone(d.commentedFields)
+		for _, f := range d.addedFields {
+			if f.Field.GetDescription() != "" {
+				fields = append(fields, f)
+			}
+		}
+		for _, f := range d.recomputedFields {
+			if f[1].Field.GetDescription() != "" {
+				fields = append(fields, f[1])
+			}
+		}
+		for _, f := range fields {
+			m.alterColumns = append(m.alterColumns, d.to.pgColumnComment(f))
+		}
+	}
+}
+
+// diffViews drops the views that go away or change, and creates the ones that
+// are new or changed. Views have no uuid and are matched by name. A view also
+// counts as changed when it selects from something that does — a changed or
+// dropped table, or another changed view — since postgres will not alter or
+// drop what a view depends on, and a sqlite or mysql view would go on reading
+// the old columns. It is dropped before anything else runs and created again
+// once everything else has.
+func (m *migrationBuilder) diffViews(diffs []*tableDiff, droppedTables []SchemaEntity) error {
+	// the names a view is affected by if it selects from them
+	affecting := []string{}
+	for _, d := range diffs {
+		if d.changed() {
+			affecting = append(affecting, d.from.Name)
+		}
+	}
+	for _, e := range droppedTables {
+		affecting = append(affecting, e.Name)
+	}
+
+	toViews := make(map[string]SchemaView)
+	for _, v := range m.to.views {
+		toViews[v.QualifiedName()] = v
+	}
+	changed := make(map[string]bool)
+	// from.views is in dependency order, so whatever a view depends on has
+	// been looked at by the time it is
+	for _, v := range m.from.views {
+		tv, ok := toViews[v.QualifiedName()]
+		if !ok || tv != v || slices.ContainsFunc(affecting, func(name string) bool {
+			return referencesName(v.Definition, name)
+		}) {
+			changed[v.QualifiedName()] = true
+			affecting = append(affecting, v.Name)
+		}
+	}
+
+	for _, v := range slices.Backward(m.from.views) {
+		if changed[v.QualifiedName()] {
+			m.dropViews = append(m.dropViews, m.dropView(v))
+		}
+	}
+	fromViews := make(map[string]bool)
+	for _, v := range m.from.views {
+		fromViews[v.QualifiedName()] = true
+	}
+	for _, v := range m.to.views {
+		if !fromViews[v.QualifiedName()] || changed[v.QualifiedName()] {
+			create, err := renderSchemaTemplate(CreateAction, m.dbType, SchemaTemplate{Views: []SchemaView{v}})
+			if err != nil {
+				return err
+			}
+			m.createViews = append(m.createViews, strings.TrimSpace(create))
+		}
+	}
+	return nil
+}
+
+func (m *migrationBuilder) dropView(v SchemaView) string {
+	if v.Materialized {
+		return fmt.Sprintf("DROP MATERIALIZED VIEW %s;", v.QualifiedName())
+	}
+	return fmt.Sprintf("DROP VIEW %s;", v.QualifiedName())
+}
+
+// diffTypes matches the postgres enum types of both sides by uuid, and
+// creates, renames, extends and drops them. A type whose labels cannot just be
+// added to is left to recreateChangedTypes.
+func (m *migrationBuilder) diffTypes() {
+	m.renamedTypes = make(map[string]string)
+	if m.dbType != db.PGDBType {
+		return
+	}
+
+	fromTypes := make(map[string]SchemaEnum)
+	for _, e := range m.from.enums {
+		fromTypes[e.Uuid] = e
+	}
+	toTypes := make(map[string]bool)
+	for _, e := range m.to.enums {
+		toTypes[e.Uuid] = true
+	}
+
+	for _, e := range m.to.enums {
+		fe, ok := fromTypes[e.Uuid]
+		if !ok {
+			m.createTypes = append(m.createTypes, m.createType(e))
+			continue
+		}
+		name := fe.QualifiedName()
+		if fe.Schema != e.Schema {
+			m.createTypes = append(m.createTypes, fmt.Sprintf("ALTER TYPE %s SET SCHEMA %s;", name, m.quote(schemaOrPublic(e.Schema))))
+			name = qualifiedName(m.dbType, e.Schema, fe.Name)
+		}
+		if fe.Name != e.Name {
+			m.createTypes = append(m.createTypes, fmt.Sprintf("ALTER TYPE %s RENAME TO %s;", name, m.quote(e.Name)))
+		}
+		if fe.QualifiedName() != e.QualifiedName() {
+			m.renamedTypes[fe.QualifiedName()] = e.QualifiedName()
+		}
+		for _, v := range addedEnumValues(fe.Values, e.Values) {
+			m.createTypes = append(m.createTypes, fmt.Sprintf("ALTER TYPE %s ADD VALUE %s;", e.QualifiedName(), v))
+		}
+	}
+	for _, e := range m.from.enums {
+		if !toTypes[e.Uuid] {
+			m.dropTypes = append(m.dropTypes, fmt.Sprintf("DROP TYPE %s;", e.QualifiedName()))
+		}
+	}
+}
+
+// recreateChangedTypes replaces the postgres enum types that lost or reordered
+// labels, which ALTER TYPE cannot do. It runs once every column is in its
+// final shape, converting each one that uses the type through its label.
+func (m *migrationBuilder) recreateChangedTypes(diffs []*tableDiff) {
+	fromTypes := make(map[string]SchemaEnum)
+	for _, e := range m.from.enums {
+		fromTypes[e.Uuid] = e
+	}
+	for _, e := range m.to.enums {
+		fe, ok := fromTypes[e.Uuid]
+		if !ok || addedEnumValues(fe.Values, e.Values) != nil {
+			continue
+		}
+		name := e.QualifiedName()
+		previous := qualifiedName(m.dbType, e.Schema, e.Name+migrationSuffix)
+		m.recreateTypes = append(m.recreateTypes,
+			fmt.Sprintf("ALTER TYPE %s RENAME TO %s;", name, m.quote(e.Name+migrationSuffix)),
+			m.createType(e),
+		)
+		for _, d := range diffs {
+			for _, f := range d.to.Fields {
+				using := ""
+				switch f.Type {
+				case name:
+					using = "text"
+				case name + "[]":
+					using = "text[]"
+				default:
+					continue
+				}
+				column := fmt.Sprintf("ALTER COLUMN %s", m.quote(f.Name))
+				actions := []string{fmt.Sprintf("%s TYPE %s USING %s::%s::%s", column, f.Type, m.quote(f.Name), using, f.Type)}
+				if f.Default != "" {
+					// the old default is a value of the old type
+					actions = append([]string{column + " DROP DEFAULT"}, actions...)
+					actions = append(actions, fmt.Sprintf("%s SET %s", column, f.Default))
+				}
+				m.recreateTypes = append(m.recreateTypes, fmt.Sprintf("ALTER TABLE %s %s;", d.to.QualifiedName(), strings.Join(actions, ", ")))
+			}
+		}
+		m.recreateTypes = append(m.recreateTypes, fmt.Sprintf("DROP TYPE %s;", previous))
+	}
+}
+
+func (m *migrationBuilder) createType(e SchemaEnum) string {
+	return fmt.Sprintf("CREATE TYPE %s AS ENUM (%s);", e.QualifiedName(), e.ValuesList())
+}
+
+// retypedName is a column type as it reads after the enum type renames.
+func (m *migrationBuilder) retypedName(columnType string) string {
+	name, array := strings.CutSuffix(columnType, "[]")
+	renamed, ok := m.renamedTypes[name]
+	if !ok {
+		return columnType
+	}
+	if array {
+		return renamed + "[]"
+	}
+	return renamed
+}
+
+// addedEnumValues is how ALTER TYPE ... ADD VALUE turns the labels `from`
+// into `to`, each entry a label and, unless it goes last, its position. It
+// returns nil when `to` is not `from` with labels added, which ADD VALUE
+// alone cannot achieve.
+func addedEnumValues(from, to []string) []string {
+	res := []string{}
+	next := 0
+	for n, v := range to {
+		if next < len(from) && from[next] == v {
+			next++
+			continue
+		}
+		if slices.Contains(from, v) {
+			return nil
+		}
+		added := fmt.Sprintf("'%s'", escapeLiteral(v, db.PGDBType))
+		// the next label that was already there, if any, is where this one goes
+		for _, later := range to[n+1:] {
+			if slices.Contains(from, later) {
+				added += fmt.Sprintf(" BEFORE '%s'", escapeLiteral(later, db.PGDBType))
+				break
+			}
+		}
+		res = append(res, added)
+	}
+	if next < len(from) {
+		return nil
+	}
+	return res
+}
+
+// rebuildSQLiteTable replaces a sqlite table with its new definition, carrying
+// over every column that exists on both sides. It runs after the table rename,
+// so it reads from the table's new name.
+func (m *migrationBuilder) rebuildSQLiteTable(d *tableDiff) error {
+	m.foreignKeysOff = true
+
+	temporary := d.to
+	temporary.Name = d.to.Name + migrationSuffix
+	// only the inline primary key and uniques: the secondary indexes are created
+	// once the table has its real name, or they would collide with the
+	// original's until it is dropped
+	temporary.Indexes = []SchemaIndex{}
+	for _, i := range d.to.Indexes {
+		if i.InTable() {
+			temporary.Indexes = append(temporary.Indexes, i)
+		}
+	}
+	create, err := renderCreateTables([]SchemaEntity{temporary}, m.dbType)
+	if err != nil {
+		return err
+	}
+	m.alterColumns = append(m.alterColumns, create)
+
+	fromFields := make(map[string]SchemaField)
+	for _, f := range d.from.Fields {
+		fromFields[f.Field.Uuid] = f
+	}
+	columns := []string{}
+	values := []string{}
+	for _, f := range d.to.Fields {
+		// a generated column cannot be written, and works its values out again
+		if ff, ok := fromFields[f.Field.Uuid]; ok && f.Computed == "" {
+			columns = append(columns, m.quote(f.Name))
+			values = append(values, m.quote(ff.Name))
+		}
+	}
+	if len(columns) > 0 {
+		m.alterColumns = append(m.alterColumns, fmt.Sprintf("INSERT INTO %s (%s) SELECT %s FROM %s;",
+			m.quote(temporary.Name), strings.Join(columns, ", "), strings.Join(values, ", "), m.quote(d.to.Name)))
+	}
+	m.alterColumns = append(m.alterColumns,
+		fmt.Sprintf("DROP TABLE %s;", m.quote(d.to.Name)),
+		fmt.Sprintf("ALTER TABLE %s RENAME TO %s;", m.quote(temporary.Name), m.quote(d.to.Name)),
+	)
+	for _, i := range d.to.Indexes {
+		if !i.InTable() {
+			m.alterColumns = append(m.alterColumns, m.createIndex(d.to, i))
+		}
+	}
+	return nil
+}
+
+func (m *migrationBuilder) createIndex(e SchemaEntity, i SchemaIndex) string {
+	table := e.QualifiedName()
+	switch m.dbType {
+	case db.MYSQLDBType:
+		return fmt.Sprintf("CREATE %sINDEX %s ON %s %s;", i.TypePrefix, m.quote(i.Name), table, i.FieldNamesIdentifiers())
+	case db.PGDBType:
+		if i.Type == "unique" && i.InTable() {
+			// unnamed, as in create.sql, so postgres names it the same way
+			return fmt.Sprintf("ALTER TABLE %s ADD UNIQUE %s;", table, i.FieldNamesIdentifiers())
+		}
+		if fullText := i.FullTextExpression(); fullText != "" {
+			return fmt.Sprintf("CREATE INDEX %s ON %s USING gin (%s)%s;", m.quote(i.Name), table, fullText, i.WhereClause())
+		}
+	}
+	unique := ""
+	if i.Type == "unique" {
+		unique = "UNIQUE "
+	}
+	return fmt.Sprintf("CREATE %sINDEX %s ON %s%s %s%s;", unique, m.quote(i.Name), table, i.UsingClause(), i.FieldNamesIdentifiers(), i.WhereClause())
+}
+
+func (m *migrationBuilder) constraintDefinition(c SchemaConstraint) string {
+	definition := fmt.Sprintf("CONSTRAINT %s FOREIGN KEY (%s) REFERENCES %s (%s)",
+		m.quote(c.Name), c.ForeignKeyFields(), c.QualifiedTableName(), c.ReferenceFields())
+	// ReferentialActions is laid out for the multi-line CREATE TABLE block
+	if actions := strings.Join(strings.Fields(c.ReferentialActions()), " "); actions != "" {
+		definition += " " + actions
+	}
+	return definition
+}
+
+func (m *migrationBuilder) columnDefinition(f SchemaField) string {
+	if postfix := f.Postfix(); postfix != "" {
+		return fmt.Sprintf("%s %s %s", m.quote(f.Name), f.Type, postfix)
+	}
+	return fmt.Sprintf("%s %s", m.quote(f.Name), f.Type)
+}
+
+// columnPosition keeps a column added on mysql where create.sql would have put
+// it; mysql otherwise appends it, and the table would no longer match one
+// created from scratch. Postgres can only append.
+func (m *migrationBuilder) columnPosition(e SchemaEntity, f SchemaField) string {
+	if m.dbType != db.MYSQLDBType {
+		return ""
+	}
+	for n, ef := range e.Fields {
+		if ef.Field.Uuid != f.Field.Uuid {
+			continue
+		}
+		if n == 0 {
+			return " FIRST"
+		}
+		return " AFTER " + m.quote(e.Fields[n-1].Name)
+	}
+	return ""
+}
+
+// createNewSchemas creates the postgres schemas the `to` side puts something
+// in and the `from` side does not. A schema nothing uses any more is left in
+// place, as it may hold objects that were never part of the project.
+func (m *migrationBuilder) createNewSchemas() {
+	existing := usedSchemas(m.from.schemaEntities(), m.from.enums, m.from.views)
+	for _, schema := range usedSchemas(m.to.schemaEntities(), m.to.enums, m.to.views) {
+		if !slices.Contains(existing, schema) {
+			m.createSchemas = append(m.createSchemas, fmt.Sprintf("CREATE SCHEMA IF NOT EXISTS %s;", m.quote(schema)))
+		}
+	}
+}
+
+// schemaOrPublic is the schema to move a table or type into: an unqualified
+// one goes to public, postgres' default schema.
+func schemaOrPublic(schema string) string {
+	if schema == "" {
+		return "public"
+	}
+	return schema
+}
+
+func (m *migrationBuilder) quote(identifier string) string {
+	if m.dbType == db.MYSQLDBType {
+		return fmt.Sprintf("`%s`", identifier)
+	}
+	return fmt.Sprintf(`"%s"`, identifier)
+}
+
+// pgAlterColumnActions is the postgres spelling of MODIFY COLUMN: one action per
+// aspect that changed. A default is dropped before a type change and set again
+// after it, since the old default may not cast to the new type.
+func pgAlterColumnActions(from, to SchemaField) []string {
+	column := fmt.Sprintf(`ALTER COLUMN "%s"`, to.Name)
+	typeChanged := from.Type != to.Type
+	defaultChanged := from.Default != to.Default
+
+	actions := []string{}
+	if from.Computed != "" && to.Computed == "" {
+		actions = append(actions, column+" DROP EXPRESSION")
+	}
+	defaultDropped := false
+	if typeChanged && from.Default != "" {
+		actions = append(actions, column+" DROP DEFAULT")
+		defaultDropped = true
+	}
+	if typeChanged {
+		actions = append(actions, fmt.Sprintf(`%s TYPE %s USING "%s"::%s`, column, to.Type, to.Name, to.Type))
+	}
+	if from.Null != to.Null {
+		if to.Null != "" {
+			actions = append(actions, column+" SET NOT NULL")
+		} else {
+			actions = append(actions, column+" DROP NOT NULL")
+		}
+	}
+	if to.Default != "" && (defaultChanged || defaultDropped) {
+		actions = append(actions, fmt.Sprintf("%s SET %s", column, to.Default))
+	} else if to.Default == "" && defaultChanged && !defaultDropped {
+		actions = append(actions, column+" DROP DEFAULT")
+	}
+	// an identity goes once the default that held its place has, and comes
+	// after it too
+	if from.Identity != to.Identity {
+		if to.Identity != "" {
+			actions = append(actions, fmt.Sprintf("%s ADD %s", column, to.Identity))
+		} else {
+			actions = append(actions, column+" DROP IDENTITY IF EXISTS")
+		}
+	}
+	return actions
+}
+
+// pgUniqueConstraintName is the name postgres gives an unnamed UNIQUE (...)
+// constraint, which is how create.sql declares one.
+func pgUniqueConstraintName(table string, i SchemaIndex) string {
+	fields := slices.Clone(i.Index.Fields)
+	sort.SliceStable(fields, func(a, b int) bool {
+		return fields[a].Priority < fields[b].Priority
+	})
+	parts := []string{table}
+	for _, f := range fields {
+		if name, ok := i.FieldNames[f.FieldUuid]; ok {
+			parts = append(parts, name)
+		}
+	}
+	return strings.Join(parts, "_") + "_key"
+}
+
+func hasPrimaryKey(e SchemaEntity) bool {
+	return slices.ContainsFunc(e.Indexes, func(i SchemaIndex) bool {
+		return i.Type == "primary"
+	}) && len(e.PrimaryKeys) > 0
+}
+
+// primaryKeySignature identifies the primary key create.sql renders for the
+// entity, which is its key fields in field order whatever its index says.
+func primaryKeySignature(e SchemaEntity) string {
+	if !hasPrimaryKey(e) {
+		return ""
+	}
+	uuids := []string{}
+	for _, f := range e.Fields {
+		if e.IsPrimaryKey(f.Field.Identifier) {
+			uuids = append(uuids, f.Field.Uuid)
+		}
+	}
+	return strings.Join(uuids, ",")
+}
+
+// indexKeys lists, in index order, what each of the entity's indexes is
+// matched on across the two versions: its uuid, unless that is missing or — as
+// legacy models allow — shared with another index of the same entity, in which
+// case only its name can tell them apart.
+func indexKeys(e SchemaEntity) []string {
+	count := make(map[string]int)
+	for _, i := range e.Indexes {
+		count[i.Index.GetUuid()]++
+	}
+	keys := []string{}
+	for _, i := range e.Indexes {
+		if uuid := i.Index.GetUuid(); uuid != "" && count[uuid] == 1 {
+			keys = append(keys, uuid)
+		} else {
+			keys = append(keys, "name:"+i.Name)
+		}
+	}
+	return keys
+}
+
+// indexSignature is everything about an index that its DDL depends on. Fields
+// are referenced by uuid, so renaming an indexed column does not make the index
+// look different: both engines carry the index over to the new name.
+func indexSignature(i SchemaIndex) string {
+	fields := slices.Clone(i.Index.Fields)
+	sort.SliceStable(fields, func(a, b int) bool {
+		return fields[a].Priority < fields[b].Priority
+	})
+	parts := []string{i.Type}
+	// postgres and sqlite declare a unique constraint without a name
+	if i.Type != "unique" || !i.InTable() || i.DBType == db.MYSQLDBType {
+		parts = append(parts, i.Name)
+	}
+	for _, f := range fields {
+		if _, ok := i.FieldNames[f.FieldUuid]; !ok {
+			continue
+		}
+		parts = append(parts, fmt.Sprintf("%s %s %d", f.FieldUuid, f.Order, i.prefixLength(f)))
+	}
+	parts = append(parts, indexOptionsSignature(i))
+	return strings.Join(parts, "|")
+}
+
+func constraintSignature(c SchemaConstraint) string {
+	from := c.Relationship.GetFrom().GetTypeConfig().GetEntity()
+	to := c.Relationship.GetTo().GetTypeConfig().GetEntity()
+	return strings.Join([]string{
+		c.Name,
+		strings.Join(from.GetFieldUuids(), ","),
+		to.GetEntityUuid(),
+		strings.Join(to.GetFieldUuids(), ","),
+		c.Relationship.GetOnDelete().String(),
+		c.Relationship.GetOnUpdate().String(),
+	}, "|")
+}
+
+// renderCreateTables renders the create template for the given entities
+// without writing anything to disk.
+func renderCreateTables(entities []SchemaEntity, dbType db.DBType) (string, error) {
+	res, err := renderSchemaTemplate(CreateAction, dbType, SchemaTemplate{Entities: entities})
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(res), nil
+}
+
+// renderSchemaTemplate renders an action's template in memory.
+func renderSchemaTemplate(action Action, dbType db.DBType, data SchemaTemplate) (string, error) {
+	fileName := fmt.Sprintf("%s_%s", action, dbType)
+	tmplBytes, err := templates.ReadFile(fmt.Sprintf("templates/%s.tmpl", fileName))
+	if err != nil {
+		return "", err
+	}
+	tpl, err := template.New("template").Funcs(templateFuncs).Parse(string(tmplBytes))
+	if err != nil {
+		return "", fmt.Errorf("error creating template: %s %w", fileName, err)
+	}
+	var body bytes.Buffer
+	if err := tpl.Execute(&body, data); err != nil {
+		return "", err
+	}
+	return body.String(), nil
+}
@@ -0,0 +1,309 @@
+package tosql
+
+import (
+	"testing"
+
+	nemgen "github.com/nuzur/nem/idl/gen"
+	"github.com/nuzur/sql-gen/db"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/protobuf/proto"
+)
+
+func migrationField(uuid string, identifier string, fieldType nemgen.FieldType, required bool) *nemgen.Field {
+	return &nemgen.Field{
+		Uuid:       uuid,
+		Identifier: identifier,
+		Type:       fieldType,
+		TypeConfig: &nemgen.FieldTypeConfig{},
+		Required:   required,
+		Status:     nemgen.FieldStatus_FIELD_STATUS_ACTIVE,
+	}
+}
+
+func migrationKey(uuid string) *nemgen.Field {
+	f := migrationField(uuid, "id", nemgen.FieldType_FIELD_TYPE_UUID, true)
+	f.Key = true
+	return f
+}
+
+func migrationVarchar(uuid string, identifier string, size int64, required bool) *nemgen.Field {
+	f := migrationField(uuid, identifier, nemgen.FieldType_FIELD_TYPE_VARCHAR, required)
+	f.TypeConfig.Varchar = &nemgen.FieldTypeVarcharConfig{MaxSize: size}
+	return f
+}
+
+func migrationTable(uuid string, identifier string, fields []*nemgen.Field, indexes ...*nemgen.Index) *nemgen.Entity {
+	return &nemgen.Entity{
+		Uuid:       uuid,
+		Identifier: identifier,
+		Type:       nemgen.EntityType_ENTITY_TYPE_STANDALONE,
+		Status:     nemgen.EntityStatus_ENTITY_STATUS_ACTIVE,
+		Fields:     fields,
+		TypeConfig: &nemgen.EntityTypeConfig{
+			Standalone: &nemgen.EntityTypeStandaloneConfig{Indexes: indexes},
+		},
+	}
+}
+
+func migrationIndex(uuid string, identifier string, indexType nemgen.IndexType, fieldUuids ...string) *nemgen.Index {
+	fields := []*nemgen.IndexField{}
+	for n, fu := range fieldUuids {
+		fields = append(fields, &nemgen.IndexField{FieldUuid: fu, Priority: int64(n), Order: nemgen.IndexFieldOrder_INDEX_FIELD_ORDER_ASC})
+	}
+	return &nemgen.Index{
+		Uuid:       uuid,
+		Identifier: identifier,
+		Type:       indexType,
+		Status:     nemgen.IndexStatus_INDEX_STATUS_ACTIVE,
+		Fields:     fields,
+	}
+}
+
+func migrationRelationship(uuid string, identifier string, from string, fromField string, to string, toField string) *nemgen.Relationship {
+	node := func(entity string, field string) *nemgen.RelationshipNode {
+		return &nemgen.RelationshipNode{
+			Type: nemgen.RelationshipNodeType_RELATIONSHIP_NODE_TYPE_ENTITY,
+			TypeConfig: &nemgen.RelationshipNodeTypeConfig{
+				Entity: &nemgen.RelationshipNodeTypeEntityConfig{EntityUuid: entity, FieldUuids: []string{field}},
+			},
+		}
+	}
+	return &nemgen.Relationship{
+		Uuid:          uuid,
+		Identifier:    identifier,
+		From:          node(from, fromField),
+		To:            node(to, toField),
+		UseForeignKey: true,
+		Status:        nemgen.RelationshipStatus_RELATIONSHIP_STATUS_ACTIVE,
+	}
+}
+
+// migrationBaseVersion is an author/book schema with a table (shelf) that
+// nothing references.
+func migrationBaseVersion() *nemgen.ProjectVersion {
+	author := migrationTable("author", "author", []*nemgen.Field{
+		migrationKey("author.id"),
+		migrationVarchar("author.name", "name", 100, true),
+		migrationVarchar("author.email", "email", 255, false),
+	}, migrationIndex("author.email_idx", "idx_author_email", nemgen.IndexType_INDEX_TYPE_INDEX, "author.email"))
+
+	createdAt := migrationField("book.created_at", "created_at", nemgen.FieldType_FIELD_TYPE_DATETIME, true)
+	book := migrationTable("book", "book", []*nemgen.Field{
+		migrationKey("book.id"),
+		migrationField("book.author_id", "author_id", nemgen.FieldType_FIELD_TYPE_UUID, true),
+		migrationVarchar("book.title", "title", 255, true),
+		createdAt,
+	},
+		migrationIndex("book.title_idx", "book_title", nemgen.IndexType_INDEX_TYPE_UNIQUE, "book.title"),
+		migrationIndex("book.created_idx", "idx_book_created", nemgen.IndexType_INDEX_TYPE_INDEX, "book.created_at"),
+	)
+
+	shelf := migrationTable("shelf", "shelf", []*nemgen.Field{
+		migrationKey("shelf.id"),
+		migrationVarchar("shelf.label", "label", 50, false),
+	})
+
+	return &nemgen.ProjectVersion{
+		Entities: []*nemgen.Entity{book, author, shelf},
+		Relationships: []*nemgen.Relationship{
+			migrationRelationship("book_author", "book_author", "book", "book.author_id", "author", "author.id"),
+		},
+	}
+}
+
+// migrationEvolvedVersion changes every kind of object the differ knows about.
+func migrationEvolvedVersion() *nemgen.ProjectVersion {
+	pv := proto.Clone(migrationBaseVersion()).(*nemgen.ProjectVersion)
+	book, author, shelf := pv.Entities[0], pv.Entities[1], pv.Entities[2]
+
+	// renamed table, renamed and widened column, dropped column (and with it
+	// the only index over it)
+	author.Identifier = "writer"
+	author.Fields[1].Identifier = "full_name"
+	author.Fields[1].TypeConfig.Varchar.MaxSize = 200
+	author.Fields[2].Status = nemgen.FieldStatus_FIELD_STATUS_INACTIVE
+
+	// new unique column, a secondary index that goes away, and a foreign key
+	// whose referential action changes
+	isbn := migrationField("book.isbn", "isbn", nemgen.FieldType_FIELD_TYPE_CHAR, false)
+	isbn.TypeConfig.Char = &nemgen.FieldTypeCharConfig{MaxSize: 13}
+	isbn.Unique = true
+	book.Fields = append(book.Fields, isbn)
+	book.TypeConfig.Standalone.Indexes = book.TypeConfig.Standalone.Indexes[:1]
+	pv.Relationships[0].OnDelete = nemgen.RelationshipReferentialAction_RELATIONSHIP_REFERENTIAL_ACTION_CASCADE
+
+	// a dropped table and a new one that references an existing table
+	shelf.Status = nemgen.EntityStatus_ENTITY_STATUS_DISABLED
+	review := migrationTable("review", "review", []*nemgen.Field{
+		migrationKey("review.id"),
+		migrationField("review.book_id", "book_id", nemgen.FieldType_FIELD_TYPE_UUID, true),
+		migrationField("review.body", "body", nemgen.FieldType_FIELD_TYPE_TEXT, false),
+	})
+	pv.Entities = append(pv.Entities, review)
+	pv.Relationships = append(pv.Relationships,
+		migrationRelationship("review_book", "review_book", "review", "review.book_id", "book", "book.id"))
+
+	return pv
+}
+
+func TestGenerateMigration(t *testing.T) {
+	for _, tc := range []struct {
+		dbType db.DBType
+		golden string
+	}{
+		{db.MYSQLDBType, "./testdata/migration_mysql.sql"},
+		{db.PGDBType, "./testdata/migration_pg.sql"},
+		{db.SQLiteDBType, "./testdata/migration_sqlite.sql"},
+	} {
+		t.Run(string(tc.dbType), func(t *testing.T) {
+			m, err := GenerateMigration(migrationBaseVersion(), migrationEvolvedVersion(), tc.dbType)
+			assert.NoError(t, err)
+			assertGolden(t, tc.golden, m.SQL())
+		})
+	}
+}
+
+// Diffing a schema against itself must propose nothing, or every plan would
+// churn the database.
+func TestGenerateMigrationOfAnUnchangedSchemaIsEmpty(t *testing.T) {
+	for _, dbType := range []db.DBType{db.MYSQLDBType, db.PGDBType, db.SQLiteDBType} {
+		for name, pv := range map[string]*nemgen.ProjectVersion{
+			"base":    migrationBaseVersion(),
+			"evolved": migrationEvolvedVersion(),
+		} {
+			m, err := GenerateMigration(pv, pv, dbType)
+			assert.NoError(t, err)
+			assert.Empty(t, m.Statements, "%s %s", dbType, name)
+		}
+	}
+}
+
+// A column rename must not look like a drop and an add: that would lose the
+// column's data.
+func TestGenerateMigrationRenamesKeepData(t *testing.T) {
+	from := migrationBaseVersion()
+	to := proto.Clone(from).(*nemgen.ProjectVersion)
+	to.Entities[1].Fields[1].Identifier = "full_name"
+
+	m, err := GenerateMigration(from, to, db.PGDBType)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{`ALTER TABLE "author" RENAME COLUMN "name" TO "full_name";`}, m.Statements)
+
+	m, err = GenerateMigration(from, to, db.SQLiteDBType)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{`ALTER TABLE "author" RENAME COLUMN "name" TO "full_name";`}, m.Statements)
+}
+
+func TestGenerateMigrationFromNothingCreatesEveryTable(t *testing.T) {
+	m, err := GenerateMigration(nil, migrationBaseVersion(), db.MYSQLDBType)
+	assert.NoError(t, err)
+	if assert.Len(t, m.Statements, 3) {
+		// referenced tables first
+		assert.Contains(t, m.Statements[0], "CREATE TABLE IF NOT EXISTS `author`")
+		assert.Contains(t, m.Statements[2], "CREATE TABLE IF NOT EXISTS `book`")
+	}
+
+	m, err = GenerateMigration(migrationBaseVersion(), nil, db.MYSQLDBType)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"DROP TABLE `book`;", "DROP TABLE `shelf`;", "DROP TABLE `author`;"}, m.Statements)
+}
+
+// The rollback of a migration is the migration back: applying both in turn
+// leaves the database where it started.
+func TestGenerateMigrationRollbackIsTheInverseDiff(t *testing.T) {
+	for _, dbType := range []db.DBType{db.MYSQLDBType, db.PGDBType, db.SQLiteDBType} {
+		forward, err := GenerateMigration(migrationBaseVersion(), migrationEvolvedVersion(), dbType)
+		assert.NoError(t, err)
+		backward, err := GenerateMigration(migrationEvolvedVersion(), migrationBaseVersion(), dbType)
+		assert.NoError(t, err)
+
+		assert.NotEmpty(t, forward.Rollback, dbType)
+		assert.Equal(t, backward.Statements, forward.Rollback, dbType)
+		assert.Equal(t, forward.Statements, backward.Rollback, dbType)
+	}
+
+	m, err := GenerateMigration(migrationBaseVersion(), migrationBaseVersion(), db.PGDBType)
+	assert.NoError(t, err)
+	assert.Empty(t, m.Rollback)
+}
+
+func TestGenerateMigrationDoesNotModifyItsInputs(t *testing.T) {
+	from := migrationBaseVersion()
+	to := migrationEvolvedVersion()
+	_, err := GenerateMigration(from, to, db.PGDBType)
+	assert.NoError(t, err)
+	assert.True(t, proto.Equal(from, migrationBaseVersion()), "from was modified")
+	assert.True(t, proto.Equal(to, migrationEvolvedVersion()), "to was modified")
+}
+
+// Changing the type of a column a foreign key uses has to take the foreign key
+// down and put it back, or mysql refuses the MODIFY.
+func TestGenerateMigrationRecreatesForeignKeysOverModifiedColumns(t *testing.T) {
+	from := migrationBaseVersion()
+	to := proto.Clone(from).(*nemgen.ProjectVersion)
+	authorID := to.Entities[0].Fields[1]
+	authorID.Type = nemgen.FieldType_FIELD_TYPE_VARCHAR
+	authorID.TypeConfig.Varchar = &nemgen.FieldTypeVarcharConfig{MaxSize: 64}
+
+	m, err := GenerateMigration(from, to, db.MYSQLDBType)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{
+		"ALTER TABLE `book` DROP FOREIGN KEY `book_author`;",
+		"ALTER TABLE `book` MODIFY COLUMN `author_id` VARCHAR(64) NOT NULL;",
+		"ALTER TABLE `book` ADD CONSTRAINT `book_author` FOREIGN KEY (`author_id`) REFERENCES `author` (`id`);",
+	}, m.Statements)
+}
+
+// A foreign key also holds on to the column it references, and to the indexes
+// at either end: changing or dropping those goes through dropping it first.
+func TestGenerateMigrationRecreatesForeignKeysOverReferencedColumns(t *testing.T) {
+	from := migrationBaseVersion()
+	author, book := from.Entities[1], from.Entities[0]
+	author.TypeConfig.Standalone.Indexes[0].Type = nemgen.IndexType_INDEX_TYPE_UNIQUE
+	book.Fields = append(book.Fields, migrationVarchar("book.author_email", "author_email", 255, false))
+	from.Relationships = append(from.Relationships,
+		migrationRelationship("book_author_email", "book_author_email", "book", "book.author_email", "author", "author.email"))
+
+	to := proto.Clone(from).(*nemgen.ProjectVersion)
+	to.Entities[1].Fields[2].TypeConfig.Varchar.MaxSize = 320
+
+	m, err := GenerateMigration(from, to, db.MYSQLDBType)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{
+		"ALTER TABLE `book` DROP FOREIGN KEY `book_author_email`;",
+		"ALTER TABLE `author` MODIFY COLUMN `email` VARCHAR(320);",
+		"ALTER TABLE `book` ADD CONSTRAINT `book_author_email` FOREIGN KEY (`author_email`) REFERENCES `author` (`email`);",
+	}, m.Statements)
+
+	// sqlite does not check the referenced column, and keeps the table
+	m, err = GenerateMigration(from, to, db.SQLiteDBType)
+	assert.NoError(t, err)
+	for _, statement := range m.Statements {
+		assert.NotContains(t, statement, `"book"`)
+	}
+}
+
+func TestGenerateMigrationRecreatesForeignKeysOverDroppedIndexes(t *testing.T) {
+	from := migrationBaseVersion()
+	book := from.Entities[0]
+	book.TypeConfig.Standalone.Indexes = append(book.TypeConfig.Standalone.Indexes,
+		migrationIndex("book.author_idx", "idx_book_author", nemgen.IndexType_INDEX_TYPE_INDEX, "book.author_id", "book.title"))
+
+	to := proto.Clone(from).(*nemgen.ProjectVersion)
+	to.Entities[0].TypeConfig.Standalone.Indexes = to.Entities[0].TypeConfig.Standalone.Indexes[:2]
+
+	m, err := GenerateMigration(from, to, db.MYSQLDBType)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{
+		"ALTER TABLE `book` DROP FOREIGN KEY `book_author`;",
+		"DROP INDEX `idx_book_author` ON `book`;",
+		"ALTER TABLE `book` ADD CONSTRAINT `book_author` FOREIGN KEY (`author_id`) REFERENCES `author` (`id`);",
+	}, m.Statements)
+
+	// an index whose leading column is not the foreign key's backs nothing
+	to = proto.Clone(from).(*nemgen.ProjectVersion)
+	to.Entities[0].TypeConfig.Standalone.Indexes = to.Entities[0].TypeConfig.Standalone.Indexes[1:]
+	m, err = GenerateMigration(from, to, db.PGDBType)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{`ALTER TABLE "book" DROP CONSTRAINT "book_title_key";`}, m.Statements)
+}
@@ -0,0 +1,68 @@
+package tosql
+
+import (
+	"fmt"
+	"slices"
+
+	"github.com/nuzur/sql-gen/db"
+)
+
+// qualifiedName quotes a table or type name for the engine, prefixed with its
+// schema when it has one.
+func qualifiedName(dbType db.DBType, schema string, name string) string {
+	if dbType == db.MYSQLDBType {
+		return fmt.Sprintf("`%s`", name)
+	}
+	if schema == "" {
+		return fmt.Sprintf(`"%s"`, name)
+	}
+	return fmt.Sprintf(`"%s"."%s"`, schema, name)
+}
+
+// QualifiedName is the quoted name of the entity's table, in its schema if it
+// has one: "billing"."invoice".
+func (e SchemaEntity) QualifiedName() string {
+	return qualifiedName(e.DBType, e.Schema, e.Name)
+}
+
+// QualifiedTableName is the quoted name of the referenced table, in its schema
+// if it has one.
+func (sc SchemaConstraint) QualifiedTableName() string {
+	return qualifiedName(sc.DBType, sc.TableSchema, sc.TableName)
+}
+
+// placeInSchemas puts the entity's table, and the tables its foreign keys
+// reference, in the schemas the options list for them. Only postgres has
+// schemas within a database to put them in.
+func (e *SchemaEntity) placeInSchemas(entityUuid string, schemas map[string]string) {
+	if e.DBType != db.PGDBType {
+		return
+	}
+	e.Schema = schemas[entityUuid]
+	for i := range e.Constraints {
+		c := &e.Constraints[i]
+		c.TableSchema = schemas[c.Relationship.GetTo().GetTypeConfig().GetEntity().GetEntityUuid()]
+	}
+}
+
+// usedSchemas are the schemas the tables, types and views are in, in the
+// order they are first used.
+func usedSchemas(entities []SchemaEntity, enums []SchemaEnum, views []SchemaView) []string {
+	res := []string{}
+	for _, e := range enums {
+		if e.Schema != "" && !slices.Contains(res, e.Schema) {
+			res = append(res, e.Schema)
+		}
+	}
+	for _, e := range entities {
+		if e.Schema != "" && !slices.Contains(res, e.Schema) {
+			res = append(res, e.Schema)
+		}
+	}
+	for _, v := range views {
+		if v.Schema != "" && !slices.Contains(res, v.Schema) {
+			res = append(res, v.Schema)
+		}
+	}
+	return res
+}
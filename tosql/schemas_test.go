@@ -0,0 +1,108 @@
+package tosql
+
+import (
+	"testing"
+
+	nemgen "github.com/nuzur/nem/idl/gen"
+	"github.com/nuzur/sql-gen/db"
+	"github.com/stretchr/testify/assert"
+)
+
+// schemaVersion has an invoice in one schema referencing a customer in
+// another.
+func schemaVersion() *nemgen.ProjectVersion {
+	return &nemgen.ProjectVersion{
+		Entities: []*nemgen.Entity{
+			migrationTable("invoice", "invoice", []*nemgen.Field{
+				migrationKey("invoice.id"),
+				migrationField("invoice.customer_id", "customer_id", nemgen.FieldType_FIELD_TYPE_UUID, true),
+			}, migrationIndex("invoice.customer_idx", "invoice_customer", nemgen.IndexType_INDEX_TYPE_INDEX, "invoice.customer_id")),
+			migrationTable("customer", "customer", []*nemgen.Field{
+				migrationKey("customer.id"),
+			}),
+		},
+		Relationships: []*nemgen.Relationship{
+			migrationRelationship("invoice_customer", "invoice_customer_fk", "invoice", "invoice.customer_id", "customer", "customer.id"),
+		},
+	}
+}
+
+var schemaOptions = SchemaOptions{Schemas: map[string]string{
+	"invoice":  "billing",
+	"customer": "crm",
+}}
+
+func TestTablesAreQualifiedByTheirSchema(t *testing.T) {
+	assert.Equal(t, "CREATE SCHEMA IF NOT EXISTS \"crm\";\n\n"+
+		"CREATE SCHEMA IF NOT EXISTS \"billing\";\n\n"+
+		"CREATE TABLE IF NOT EXISTS \"crm\".\"customer\" (\n"+
+		"    \"id\" UUID NOT NULL,\n"+
+		"    PRIMARY KEY (\"id\")\n"+
+		");\n\n"+
+		"CREATE TABLE IF NOT EXISTS \"billing\".\"invoice\" (\n"+
+		"    \"id\" UUID NOT NULL,\n"+
+		"    \"customer_id\" UUID NOT NULL,\n"+
+		"    PRIMARY KEY (\"id\"),\n"+
+		"    CONSTRAINT \"invoice_customer_fk\"\n"+
+		"        FOREIGN KEY (\"customer_id\")\n"+
+		"        REFERENCES \"crm\".\"customer\" (\"id\")\n"+
+		");\n"+
+		"CREATE INDEX \"invoice_customer\" ON \"billing\".\"invoice\" (\"customer_id\");\n\n",
+		generateWithOptions(t, schemaVersion(), db.PGDBType, schemaOptions, CreateAction))
+
+	assert.Equal(t, "DROP TABLE IF EXISTS \"billing\".\"invoice\";\n"+
+		"DROP TABLE IF EXISTS \"crm\".\"customer\";\n",
+		generateWithOptions(t, schemaVersion(), db.PGDBType, schemaOptions, DropAction))
+
+	assert.Contains(t, generateWithOptions(t, schemaVersion(), db.PGDBType, schemaOptions, InsertAction),
+		"INSERT INTO \"billing\".\"invoice\"")
+	assert.Contains(t, generateWithOptions(t, schemaVersion(), db.PGDBType, schemaOptions, SelectSimpleAction),
+		"FROM \"crm\".\"customer\";")
+}
+
+// Schemas are a postgres notion; mysql and sqlite render the tables as ever.
+func TestSchemasAreIgnoredOffPostgres(t *testing.T) {
+	for _, dbType := range []db.DBType{db.MYSQLDBType, db.SQLiteDBType} {
+		assert.Equal(t,
+			generateWithOptions(t, schemaVersion(), dbType, SchemaOptions{}, CreateAction),
+			generateWithOptions(t, schemaVersion(), dbType, schemaOptions, CreateAction))
+	}
+}
+
+func TestEnumTypesAreQualifiedByTheirSchema(t *testing.T) {
+	options := nativeEnums
+	options.Schemas = map[string]string{"post": "blog", "post_state": "blog"}
+
+	create := generateWithOptions(t, enumVersion(), db.PGDBType, options, CreateAction)
+	assert.Contains(t, create, "CREATE TYPE \"blog\".\"post_state\" AS ENUM ('draft', 'published');")
+	assert.Contains(t, create, "CREATE TYPE \"label\" AS ENUM")
+	assert.Contains(t, create, "    \"state\" \"blog\".\"post_state\" NOT NULL DEFAULT 'draft',\n")
+	assert.Contains(t, generateWithOptions(t, enumVersion(), db.PGDBType, options, DropAction),
+		"DROP TYPE IF EXISTS \"blog\".\"post_state\";")
+}
+
+func TestMigrationMovesTablesBetweenSchemas(t *testing.T) {
+	to := SchemaOptions{Schemas: map[string]string{
+		"invoice":  "finance",
+		"customer": "crm",
+	}}
+
+	m, err := GenerateMigrationWithOptions(schemaVersion(), schemaVersion(), db.PGDBType, schemaOptions, to)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{
+		`CREATE SCHEMA IF NOT EXISTS "finance";`,
+		`ALTER TABLE "billing"."invoice" SET SCHEMA "finance";`,
+	}, m.Statements)
+	assert.Equal(t, []string{
+		`CREATE SCHEMA IF NOT EXISTS "billing";`,
+		`ALTER TABLE "finance"."invoice" SET SCHEMA "billing";`,
+	}, m.Rollback)
+
+	// an unqualified table is in the default schema
+	m, err = GenerateMigrationWithOptions(schemaVersion(), schemaVersion(), db.PGDBType, SchemaOptions{}, schemaOptions)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{
+		`ALTER TABLE "crm"."customer" SET SCHEMA "public";`,
+		`ALTER TABLE "billing"."invoice" SET SCHEMA "public";`,
+	}, m.Rollback)
+}
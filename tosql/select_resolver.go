@@ -11,6 +11,12 @@ import (
 )
 
 func ResolveSelectStatements(e *nemgen.Entity, dbType db.DBType) []SchemaSelectStatement {
+	return resolveSelectStatements(e, dbType, "")
+}
+
+// resolveSelectStatements is ResolveSelectStatements for an entity soft deleted
+// through the column softDeleteColumn, empty when it is not; see softDelete.
+func resolveSelectStatements(e *nemgen.Entity, dbType db.DBType, softDeleteColumn string) []SchemaSelectStatement {
 	selects := []SchemaSelectStatement{}
 	if e.Type != nemgen.EntityType_ENTITY_TYPE_STANDALONE {
 		return selects
@@ -50,6 +56,9 @@ func ResolveSelectStatements(e *nemgen.Entity, dbType db.DBType) []SchemaSelectS
 
 	// if there are not indexes return
 	if e.TypeConfig == nil || e.TypeConfig.Standalone == nil || len(e.TypeConfig.Standalone.Indexes) == 0 {
+		if softDeleteColumn != "" {
+			selects = includeDeleted(selects, qualifiedName(dbType, "", softDeleteColumn), seenNames)
+		}
 		return selects
 	}
 
@@ -155,6 +164,9 @@ func ResolveSelectStatements(e *nemgen.Entity, dbType db.DBType) []SchemaSelectS
 		name := fmt.Sprintf("%sBy", ToCamelCase(e.Identifier))
 		fields := map[string]SchemaSelectStatementField{}
 		first := true
+		// partial is whether a member of the indexes was left out of the
+		// WHERE clause, which then matches more than the indexes do
+		partial := false
 
 		// for each combination of indexes
 		for _, indexUUID := range combination {
@@ -166,12 +178,14 @@ func ResolveSelectStatements(e *nemgen.Entity, dbType db.DBType) []SchemaSelectS
 				if !exists {
 					field := fieldMap[indexField.FieldUuid]
 					if !usableIndexMember(field) {
+						partial = true
 						continue
 					}
 					if field.Type == nemgen.FieldType_FIELD_TYPE_DATETIME || field.Type == nemgen.FieldType_FIELD_TYPE_DATE {
 						// datetime/date fields inside composite indexes are excluded from
 						// the WHERE clause, matching go-code-gen's module select resolver
 						// (which names its fetch methods without them)
+						partial = true
 						continue
 					}
 					mappedField := mapField(field, dbType)
@@ -232,12 +246,27 @@ func ResolveSelectStatements(e *nemgen.Entity, dbType db.DBType) []SchemaSelectS
 			TimeFields:       timeFields,
 			SortSupported:    sortSupported,
 			CombinedIndexes:  combined,
+			Unique:           !combined && !partial && upsertable(indexMap[combination[0]]),
+			index:            combination[0],
 		})
 	}
 
+	if softDeleteColumn != "" {
+		selects = includeDeleted(selects, qualifiedName(dbType, "", softDeleteColumn), seenNames)
+	}
+
+	// after every select has its name, so a keyset page never takes one
+	keysetPaginate(selects, primaryKeys, dbType, seenNames)
+
 	return selects
 }
 
+// upsertable is whether an upsert can conflict on the index: it has to be
+// unique, and to be in the schema, which an inactive index is not.
+func upsertable(i *nemgen.Index) bool {
+	return i.Type == nemgen.IndexType_INDEX_TYPE_UNIQUE && i.Status == nemgen.IndexStatus_INDEX_STATUS_ACTIVE
+}
+
 // maxPowerSetIndexes is the largest index count for which ResolveSelectStatements
 // computes the full power set of index combinations. 2^8 = 256 subsets is a
 // safe upper bound on the work/memory per entity; above it we degrade to one
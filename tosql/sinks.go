@@ -0,0 +1,113 @@
+package tosql
+
+import (
+	"archive/zip"
+	"context"
+	"fmt"
+	"io"
+	"path/filepath"
+
+	"github.com/nuzur/filetools"
+)
+
+// defaultExecutionsDir is where GenerateSQL writes when the request names
+// neither a sink nor a directory, relative to the working directory.
+const defaultExecutionsDir = "executions"
+
+// Sink receives the files GenerateSQL generates, one per action and named
+// after it: create.sql, insert.sql and so on. GenerateSQL writes them one at a
+// time, in the order of the request's actions, and then closes a sink that is
+// a ContextCloser or an io.Closer.
+type Sink interface {
+	WriteFile(ctx context.Context, name string, data []byte) error
+}
+
+// ContextCloser is a sink whose closing does work the request's context
+// should be able to cancel.
+type ContextCloser interface {
+	CloseContext(ctx context.Context) error
+}
+
+// DirSink writes the files into a directory, which it creates if need be.
+// With Zip set, closing it zips the directory into <Dir>.zip beside it.
+type DirSink struct {
+	Dir string
+	Zip bool
+}
+
+func (s *DirSink) WriteFile(ctx context.Context, name string, data []byte) error {
+	return filetools.Write(filepath.Join(s.Dir, name), data)
+}
+
+func (s *DirSink) Close() error {
+	return s.CloseContext(context.Background())
+}
+
+func (s *DirSink) CloseContext(ctx context.Context) error {
+	if !s.Zip {
+		return nil
+	}
+	dir := filepath.Clean(s.Dir)
+	return filetools.GenerateZip(ctx, filetools.ZipRequest{
+		OutputPath: filepath.Dir(dir),
+		Identifier: filepath.Base(dir),
+	})
+}
+
+// ZipSink writes the files into a zip archive written to an io.Writer, such as
+// an HTTP response. Closing it finishes the archive, and leaves the writer
+// open.
+type ZipSink struct {
+	zw *zip.Writer
+}
+
+func NewZipSink(w io.Writer) *ZipSink {
+	return &ZipSink{zw: zip.NewWriter(w)}
+}
+
+func (s *ZipSink) WriteFile(ctx context.Context, name string, data []byte) error {
+	f, err := s.zw.Create(name)
+	if err != nil {
+		return err
+	}
+	_, err = f.Write(data)
+	return err
+}
+
+func (s *ZipSink) Close() error {
+	return s.zw.Close()
+}
+
+// WriterSink writes the files' contents to an io.Writer one after the other,
+// which for a single action is just its SQL.
+type WriterSink struct {
+	W io.Writer
+}
+
+func (s *WriterSink) WriteFile(ctx context.Context, name string, data []byte) error {
+	_, err := s.W.Write(data)
+	return err
+}
+
+// writeResults writes each result to the sink as its action's file, and closes
+// the sink if it is a ContextCloser or an io.Closer, whether or not the writes
+// succeeded.
+func writeResults(ctx context.Context, sink Sink, results []ActionResult) error {
+	var err error
+	for _, r := range results {
+		if err = sink.WriteFile(ctx, fmt.Sprintf("%s.sql", r.Action), []byte(r.Data)); err != nil {
+			break
+		}
+	}
+	var closeErr error
+	switch closer := sink.(type) {
+	case ContextCloser:
+		closeErr = closer.CloseContext(ctx)
+	case io.Closer:
+		closeErr = closer.Close()
+	}
+	if err == nil {
+		err = closeErr
+	}
+	return err
+}
@@ -0,0 +1,136 @@
+package tosql
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/nuzur/sql-gen/db"
+	"github.com/stretchr/testify/assert"
+)
+
+func sinkRequest(sink Sink) GenerateRequest {
+	return GenerateRequest{
+		ExecutionUUID:  "execution",
+		ProjectVersion: checkVersion(),
+		Configvalues: &ConfigValues{
+			DBType:   db.SQLiteDBType,
+			Entities: []string{"lot"},
+			Actions:  []Action{DropAction, CreateAction},
+		},
+		Sink: sink,
+	}
+}
+
+func TestGenerateSQLResultsStayInMemory(t *testing.T) {
+	dir := t.TempDir()
+	t.Chdir(dir)
+
+	results, err := GenerateSQLResults(context.Background(), sinkRequest(nil))
+	assert.NoError(t, err)
+	// in the order of the request's actions
+	assert.Equal(t, []Action{DropAction, CreateAction}, []Action{results[0].Action, results[1].Action})
+	assert.Contains(t, results[1].Data, `CREATE TABLE IF NOT EXISTS "lot"`)
+
+	entries, err := os.ReadDir(dir)
+	assert.NoError(t, err)
+	assert.Empty(t, entries)
+}
+
+func TestGenerateSQLWritesToTheExecutionsDir(t *testing.T) {
+	req := sinkRequest(nil)
+	req.ExecutionsDir = t.TempDir()
+	res, err := GenerateSQL(context.Background(), req)
+	assert.NoError(t, err)
+	assert.Equal(t, filepath.Join(req.ExecutionsDir, "execution"), res.WorkingDir)
+	assert.Equal(t, filepath.Join(req.ExecutionsDir, "execution.zip"), res.ZipFile)
+
+	create, err := os.ReadFile(filepath.Join(res.WorkingDir, "create.sql"))
+	assert.NoError(t, err)
+	assert.Equal(t, res.Results[1].Data, string(create))
+	assert.FileExists(t, res.ZipFile)
+}
+
+func TestGenerateSQLWritesToTheSink(t *testing.T) {
+	var archive bytes.Buffer
+	zipSink := NewZipSink(&archive)
+	res, err := GenerateSQL(context.Background(), sinkRequest(zipSink))
+	assert.NoError(t, err)
+	assert.Empty(t, res.WorkingDir)
+	assert.Empty(t, res.ZipFile)
+
+	zr, err := zip.NewReader(bytes.NewReader(archive.Bytes()), int64(archive.Len()))
+	assert.NoError(t, err)
+	if assert.Len(t, zr.File, 2) {
+		assert.Equal(t, "drop.sql", zr.File[0].Name)
+		assert.Equal(t, "create.sql", zr.File[1].Name)
+		f, err := zr.File[1].Open()
+		assert.NoError(t, err)
+		create, err := io.ReadAll(f)
+		assert.NoError(t, err)
+		assert.Equal(t, res.Results[1].Data, string(create))
+	}
+
+	var out bytes.Buffer
+	res, err = GenerateSQL(context.Background(), sinkRequest(&WriterSink{W: &out}))
+	assert.NoError(t, err)
+	assert.Equal(t, res.Results[0].Data+res.Results[1].Data, out.String())
+
+	dir := filepath.Join(t.TempDir(), "out")
+	res, err = GenerateSQL(context.Background(), sinkRequest(&DirSink{Dir: dir}))
+	assert.NoError(t, err)
+	assert.Equal(t, dir, res.WorkingDir)
+	assert.Empty(t, res.ZipFile)
+	assert.FileExists(t, filepath.Join(dir, "drop.sql"))
+	assert.NoFileExists(t, dir+".zip")
+}
+
+// closingSink records the context it is closed with.
+type closingSink struct {
+	WriterSink
+	ctx context.Context
+}
+
+func (s *closingSink) CloseContext(ctx context.Context) error {
+	s.ctx = ctx
+	return nil
+}
+
+func (s *closingSink) Close() error {
+	return s.CloseContext(context.Background())
+}
+
+type sinkKey struct{}
+
+func TestGenerateSQLClosesTheSinkWithItsContext(t *testing.T) {
+	sink := &closingSink{WriterSink: WriterSink{W: io.Discard}}
+	ctx := context.WithValue(context.Background(), sinkKey{}, "request")
+	_, err := GenerateSQL(ctx, sinkRequest(sink))
+	assert.NoError(t, err)
+	if assert.NotNil(t, sink.ctx) {
+		assert.Equal(t, "request", sink.ctx.Value(sinkKey{}))
+	}
+}
+
+func TestGenerateFileWritesForAnExecution(t *testing.T) {
+	dir := t.TempDir()
+	t.Chdir(dir)
+
+	results := []ActionResult{}
+	err := GenerateFile(context.Background(), &GenerateFileRequest{
+		ExecutionUUID: "execution",
+		Configvalues:  &ConfigValues{DBType: db.SQLiteDBType},
+		ActionResults: &results,
+		Action:        DropAction,
+	})
+	assert.NoError(t, err)
+	drop, err := os.ReadFile(filepath.Join(dir, defaultExecutionsDir, "execution", "drop.sql"))
+	assert.NoError(t, err)
+	if assert.Len(t, results, 1) {
+		assert.Equal(t, results[0].Data, string(drop))
+	}
+}
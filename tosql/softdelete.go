@@ -0,0 +1,90 @@
+package tosql
+
+import (
+	"fmt"
+	"slices"
+
+	"github.com/iancoleman/strcase"
+	nemgen "github.com/nuzur/nem/idl/gen"
+	"github.com/nuzur/sql-gen/db"
+)
+
+// softDelete makes the entity's rows soft deleted through the nullable
+// datetime field: deleting one sets the column to the current time, and every
+// indexed select and the update leave out the rows it is set on. Each select
+// gets an IncludingDeleted variant that still sees them, right after it.
+//
+// An upsert still conflicts with a soft deleted row, and updates it: the row
+// holds its keys in the unique indexes, so no insert could take them anyway.
+func (e *SchemaEntity) softDelete(entity *nemgen.Entity, fieldUuid string) error {
+	if fieldUuid == "" {
+		return nil
+	}
+	idx := slices.IndexFunc(e.Fields, func(f SchemaField) bool {
+		return f.Field.GetUuid() == fieldUuid
+	})
+	if idx < 0 {
+		return fmt.Errorf("soft delete field %q is not a field of entity %q", fieldUuid, e.Name)
+	}
+	column := e.Fields[idx]
+	switch {
+	case column.Field.Type != nemgen.FieldType_FIELD_TYPE_DATETIME:
+		return fmt.Errorf("soft delete field %q of entity %q is not a datetime", column.Name, e.Name)
+	case column.Field.Required || slices.Contains(e.PrimaryKeys, e.quote(column.Name)):
+		return fmt.Errorf("soft delete field %q of entity %q cannot be null", column.Name, e.Name)
+	case column.Computed != "":
+		return fmt.Errorf("soft delete field %q of entity %q is a generated column", column.Name, e.Name)
+	case column.Field.GetTypeConfig().GetDatetime().GetOnUpdateCurrentTimestamp():
+		// mysql would set it on every update, soft deleting the row
+		return fmt.Errorf("soft delete field %q of entity %q is set on update", column.Name, e.Name)
+	}
+	e.SoftDeleteColumn = column.Name
+	e.SelectStatements = resolveSelectStatements(entity, e.DBType, column.Name)
+	fitUpsertsToIndexes(e.SelectStatements, e.Indexes)
+	return nil
+}
+
+// includeDeleted gives each select the filter leaving soft deleted rows out,
+// and follows it with its IncludingDeleted variant. A variant takes no name
+// another select has, so the names ResolveSelectStatements gives stay what
+// go-code-gen expects.
+func includeDeleted(selects []SchemaSelectStatement, column string, seenNames map[string]bool) []SchemaSelectStatement {
+	res := []SchemaSelectStatement{}
+	for _, s := range selects {
+		s.SoftDeleteFilter = fmt.Sprintf(" AND %s IS NULL", column)
+		res = append(res, s)
+
+		name := s.Name + "IncludingDeleted"
+		if seenNames[name] {
+			continue
+		}
+		seenNames[name] = true
+		including := s
+		including.Name = name
+		including.Identifier = strcase.ToSnake(name)
+		including.SoftDeleteFilter = ""
+		// the filtered select has the upsert already
+		including.Unique = false
+		res = append(res, including)
+	}
+	return res
+}
+
+// SoftDeleteQuoted is the quoted soft delete column.
+func (e SchemaEntity) SoftDeleteQuoted() string {
+	return e.quote(e.SoftDeleteColumn)
+}
+
+// SoftDeleteTimestamp is what a soft delete sets the column to: the current
+// time, on mysql at the column's precision so it is not rounded to the second.
+func (e SchemaEntity) SoftDeleteTimestamp() string {
+	if e.DBType != db.MYSQLDBType {
+		return "CURRENT_TIMESTAMP"
+	}
+	for _, f := range e.Fields {
+		if f.Name == e.SoftDeleteColumn {
+			return currentTimestampMYSQL(f.Field.GetTypeConfig().GetDatetime())
+		}
+	}
+	return "CURRENT_TIMESTAMP"
+}
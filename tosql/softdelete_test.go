@@ -0,0 +1,150 @@
+package tosql
+
+import (
+	"context"
+	"regexp"
+	"testing"
+
+	nemgen "github.com/nuzur/nem/idl/gen"
+	"github.com/nuzur/sql-gen/db"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const userFieldDeletedAt = "6f0b9a0e-3c1d-4e8a-9b57-2d4c8e1f7a30"
+
+// softDeletedVersion is the test project version with a nullable deleted_at
+// on the user, which nothing else sets.
+func softDeletedVersion(t *testing.T) *nemgen.ProjectVersion {
+	t.Helper()
+	pv := loadTestProjectVersion(t)
+	user := testEntity(t, pv, testUserEntityUUID)
+	user.Fields = append(user.Fields, &nemgen.Field{
+		Uuid:       userFieldDeletedAt,
+		Identifier: "deleted_at",
+		Type:       nemgen.FieldType_FIELD_TYPE_DATETIME,
+		TypeConfig: &nemgen.FieldTypeConfig{Datetime: &nemgen.FieldTypeDatetimeConfig{}},
+		Status:     nemgen.FieldStatus_FIELD_STATUS_ACTIVE,
+	})
+	return pv
+}
+
+func generateSoftDeleted(t *testing.T, dbType db.DBType, fieldUuid string, actions ...Action) (string, error) {
+	t.Helper()
+	pv := softDeletedVersion(t)
+	results, err := GenerateSQLResults(context.Background(), GenerateRequest{
+		ProjectVersion: pv,
+		ForGolang:      true,
+		Configvalues: &ConfigValues{
+			DBType:   dbType,
+			Entities: []string{testUserEntityUUID},
+			Actions:  actions,
+			SchemaOptions: SchemaOptions{
+				SoftDelete: map[string]string{testUserEntityUUID: fieldUuid},
+			},
+		},
+	})
+	if err != nil {
+		return "", err
+	}
+	data := ""
+	for _, r := range results {
+		data += r.Data
+	}
+	return data, nil
+}
+
+func TestSoftDeleteDelete(t *testing.T) {
+	data, err := generateSoftDeleted(t, db.PGDBType, userFieldDeletedAt, DeleteAction)
+	require.NoError(t, err)
+	assert.Contains(t, data, `-- name: DeleteUser :execresult
+UPDATE "user"
+SET "deleted_at" = CURRENT_TIMESTAMP
+WHERE
+"uuid" = $1 AND "version" = $2 AND "deleted_at" IS NULL;`)
+	assert.Contains(t, data, `-- name: RestoreUser :execresult
+UPDATE "user"
+SET "deleted_at" = NULL
+WHERE
+"uuid" = $1 AND "version" = $2 AND "deleted_at" IS NOT NULL;`)
+	assert.NotContains(t, data, "DELETE FROM")
+}
+
+func TestSoftDeleteSelects(t *testing.T) {
+	data, err := generateSoftDeleted(t, db.MYSQLDBType, userFieldDeletedAt, SelectSimpleAction, SelectForIndexedSimpleAction)
+	require.NoError(t, err)
+	assert.Contains(t, data, "FROM `user`\nWHERE `deleted_at` IS NULL;")
+	assert.Contains(t, data, "-- name: FetchUserIncludingDeleted :many")
+	assert.Contains(t, data, "`email` = ?  AND `deleted_at` IS NULL\nLIMIT ?, ?;")
+	assert.Contains(t, data, "-- name: FetchUserByEmailIncludingDeleted :many")
+	assert.Contains(t, data, "-- name: FetchUserByUUIDAndVersionIncludingDeletedForUpdate :many")
+}
+
+func TestSoftDeleteUpdate(t *testing.T) {
+	data, err := generateSoftDeleted(t, db.SQLiteDBType, userFieldDeletedAt, UpdateAction)
+	require.NoError(t, err)
+	assert.Contains(t, data, `WHERE
+"uuid" = ? AND "version" = ? AND "deleted_at" IS NULL;`)
+}
+
+// The variants and their keyset pages take no name another query has.
+func TestSoftDeleteNamesAreUnique(t *testing.T) {
+	data, err := generateSoftDeleted(t, db.PGDBType, userFieldDeletedAt, SelectForIndexedCombinedAction)
+	require.NoError(t, err)
+	assert.Contains(t, data, "-- name: FetchUserByEmailIncludingDeletedKeysetByUpdatedAtASC :many")
+	seen := map[string]bool{}
+	for _, m := range regexp.MustCompile(`-- name: (\w+)`).FindAllStringSubmatch(data, -1) {
+		assert.False(t, seen[m[1]], "query %s is named twice", m[1])
+		seen[m[1]] = true
+	}
+
+	selects := includeDeleted([]SchemaSelectStatement{{Name: "UserByEmail"}}, `"deleted_at"`, map[string]bool{
+		"UserByEmailIncludingDeleted": true,
+	})
+	require.Len(t, selects, 1)
+	assert.Equal(t, ` AND "deleted_at" IS NULL`, selects[0].SoftDeleteFilter)
+}
+
+// A single delete statement soft deletes too.
+func TestSoftDeleteDeleteWithValues(t *testing.T) {
+	pv := softDeletedVersion(t)
+	res, err := GenerateDeleteForEntityWithValues(context.Background(), GenerateDeleteForEntityWithValuesParams{
+		Entity:         testEntity(t, pv, testUserEntityUUID),
+		ProjectVersion: pv,
+		DBType:         db.MYSQLDBType,
+		SchemaOptions: SchemaOptions{
+			SoftDelete: map[string]string{testUserEntityUUID: userFieldDeletedAt},
+		},
+		Keys: map[string]string{userFieldUUID: "9b2c1c2e-0000-4000-8000-000000000002", userFieldVersion: "1"},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "UPDATE `user`\nSET `deleted_at` = CURRENT_TIMESTAMP\nWHERE\n`uuid` = ? AND `version` = ? AND `deleted_at` IS NULL;\n", res.ParametrizedSQL)
+	assert.Len(t, res.Params, 2)
+}
+
+func TestSoftDeleteRejectsField(t *testing.T) {
+	_, err := generateSoftDeleted(t, db.SQLiteDBType, userFieldEmail, DeleteAction)
+	assert.ErrorContains(t, err, `soft delete field "email" of entity "user" is not a datetime`)
+	_, err = generateSoftDeleted(t, db.SQLiteDBType, postFieldTitle, DeleteAction)
+	assert.ErrorContains(t, err, "is not a field of entity")
+
+	// mysql would soft delete the row on every update
+	pv := softDeletedVersion(t)
+	for _, f := range testEntity(t, pv, testUserEntityUUID).Fields {
+		if f.Uuid == userFieldDeletedAt {
+			f.TypeConfig.Datetime.OnUpdateCurrentTimestamp = true
+		}
+	}
+	_, err = GenerateSQLResults(context.Background(), GenerateRequest{
+		ProjectVersion: pv,
+		Configvalues: &ConfigValues{
+			DBType:   db.MYSQLDBType,
+			Entities: []string{testUserEntityUUID},
+			Actions:  []Action{DeleteAction},
+			SchemaOptions: SchemaOptions{
+				SoftDelete: map[string]string{testUserEntityUUID: userFieldDeletedAt},
+			},
+		},
+	})
+	assert.ErrorContains(t, err, `soft delete field "deleted_at" of entity "user" is set on update`)
+}
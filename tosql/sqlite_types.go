@@ -0,0 +1,127 @@
+package tosql
+
+import (
+	"fmt"
+
+	nemgen "github.com/nuzur/nem/idl/gen"
+)
+
+// FieldTypeToSQLite maps a field to the type its SQLite column is declared with.
+//
+// SQLite only has storage classes; the declared type picks a column affinity and
+// is otherwise kept verbatim — PRAGMA table_info reports it back exactly as
+// written. So the names below are chosen for two things: the affinity they
+// resolve to (anything containing CHAR/TEXT gets TEXT affinity, INT gets
+// INTEGER, and so on), and being specific enough that introspection can read the
+// field type back out of them. Widths are not enforced by SQLite; they are
+// rendered anyway so the declared type keeps saying what the model meant.
+func FieldTypeToSQLite(f *nemgen.Field) string {
+	switch f.Type {
+
+	case nemgen.FieldType_FIELD_TYPE_UUID: // 1
+		// CHAR(36) rather than UUID: an unknown type name gets NUMERIC affinity,
+		// which would try to coerce every stored value to a number first.
+		return "CHAR(36)"
+	case nemgen.FieldType_FIELD_TYPE_INTEGER: // 2
+		if f.TypeConfig.Integer != nil && f.TypeConfig.Integer.Size != nemgen.FieldTypeIntegerConfigSize_FIELD_TYPE_INTEGER_CONFIG_SIZE_INVALID {
+			switch f.TypeConfig.Integer.Size {
+			// Every integer is stored as up to 64 bits regardless; the name only
+			// keeps the size readable on the way back in. Same SMALLINT floor as
+			// the other engines, for the same reason (see FieldTypeToMYSQL).
+			case nemgen.FieldTypeIntegerConfigSize_FIELD_TYPE_INTEGER_CONFIG_SIZE_ONE_BIT:
+				return "SMALLINT"
+			case nemgen.FieldTypeIntegerConfigSize_FIELD_TYPE_INTEGER_CONFIG_SIZE_EIGHT_BITS:
+				return "SMALLINT"
+			case nemgen.FieldTypeIntegerConfigSize_FIELD_TYPE_INTEGER_CONFIG_SIZE_SIXTEEN_BITS:
+				return "SMALLINT"
+			case nemgen.FieldTypeIntegerConfigSize_FIELD_TYPE_INTEGER_CONFIG_SIZE_TWENTY_FOUR_BITS:
+				return "INTEGER"
+			case nemgen.FieldTypeIntegerConfigSize_FIELD_TYPE_INTEGER_CONFIG_SIZE_THIRTY_TWO_BITS:
+				return "INTEGER"
+			case nemgen.FieldTypeIntegerConfigSize_FIELD_TYPE_INTEGER_CONFIG_SIZE_SIXTY_FOUR_BITS:
+				return "BIGINT"
+			}
+			return "INTEGER"
+		}
+		return "INTEGER"
+	case nemgen.FieldType_FIELD_TYPE_FLOAT: // 3
+		return "REAL"
+	case nemgen.FieldType_FIELD_TYPE_DECIMAL: // 4
+		return decimalType(f.GetTypeConfig().GetDecimal())
+	case nemgen.FieldType_FIELD_TYPE_BOOLEAN: // 5
+		return "BOOLEAN"
+	case nemgen.FieldType_FIELD_TYPE_CHAR: // 6
+		if f.TypeConfig.Char != nil && f.TypeConfig.Char.MaxSize != 0 {
+			return fmt.Sprintf("CHAR(%d)", f.TypeConfig.Char.MaxSize)
+		}
+		return "CHAR(255)" // default
+	case nemgen.FieldType_FIELD_TYPE_VARCHAR: // 7
+		if f.TypeConfig.Varchar != nil && f.TypeConfig.Varchar.MaxSize != 0 {
+			return fmt.Sprintf("VARCHAR(%d)", f.TypeConfig.Varchar.MaxSize)
+		}
+		return "VARCHAR(255)" // default
+	case nemgen.FieldType_FIELD_TYPE_TEXT: // 8
+		return "TEXT"
+	case nemgen.FieldType_FIELD_TYPE_RICHTEXT, // 15
+		nemgen.FieldType_FIELD_TYPE_CODE,     // 16
+		nemgen.FieldType_FIELD_TYPE_MARKDOWN: // 17
+		return "TEXT"
+	case nemgen.FieldType_FIELD_TYPE_ENCRYPTED: // 9
+		if f.TypeConfig.Encrypted != nil && f.TypeConfig.Encrypted.MaxSize != 0 {
+			return fmt.Sprintf("VARCHAR(%d)", f.TypeConfig.Encrypted.MaxSize)
+		}
+		return "VARCHAR(255)" // default
+	case nemgen.FieldType_FIELD_TYPE_EMAIL: // 10
+		return "VARCHAR(512)" // default
+	case nemgen.FieldType_FIELD_TYPE_PHONE: // 11
+		return "VARCHAR(50)" // default
+	case nemgen.FieldType_FIELD_TYPE_URL: // 12
+		return "VARCHAR(2048)" // default
+	case nemgen.FieldType_FIELD_TYPE_LOCATION: // 13
+		return "VARCHAR(2048)" // default
+	case nemgen.FieldType_FIELD_TYPE_COLOR: // 14
+		return "VARCHAR(50)" // default
+	case nemgen.FieldType_FIELD_TYPE_FILE: // 18
+		return handleFileTypeSQLite(f.TypeConfig.File)
+	case nemgen.FieldType_FIELD_TYPE_IMAGE: // 19
+		return handleFileTypeSQLite(f.TypeConfig.Image)
+	case nemgen.FieldType_FIELD_TYPE_AUDIO: // 20
+		return handleFileTypeSQLite(f.TypeConfig.Audio)
+	case nemgen.FieldType_FIELD_TYPE_VIDEO: // 21
+		return handleFileTypeSQLite(f.TypeConfig.Video)
+	case nemgen.FieldType_FIELD_TYPE_ENUM: // 22
+		if f.TypeConfig.Enum.AllowMultiple {
+			return "JSON"
+		}
+		return "INTEGER"
+	case nemgen.FieldType_FIELD_TYPE_JSON, // 23
+		nemgen.FieldType_FIELD_TYPE_ARRAY: // 24
+		return "JSON"
+	case nemgen.FieldType_FIELD_TYPE_DATE: // 25
+		return "DATE"
+	case nemgen.FieldType_FIELD_TYPE_DATETIME: // 26
+		// No precision: SQLite stores a datetime as text and keeps whatever
+		// fractional digits it is given, so DATETIME(n) would state a limit
+		// nothing enforces.
+		return "DATETIME"
+	case nemgen.FieldType_FIELD_TYPE_TIME: // 27
+		return "TIME"
+	case nemgen.FieldType_FIELD_TYPE_SLUG: // 28
+		return "VARCHAR(512)" // default
+	}
+	return ""
+}
+
+// handleFileTypeSQLite is the SQLite half of handleFileTypeMYSQL; see there for
+// why an unset storage_type resolves to object store rather than binary.
+func handleFileTypeSQLite(config *nemgen.FieldTypeFileConfig) string {
+	if config.GetStorageType() == nemgen.FieldTypeFileConfigStorageType_FIELD_TYPE_FILE_CONFIG_STORAGE_TYPE_BINARY {
+		return "BLOB"
+	}
+	if config.GetAllowMultiple() {
+		// a list of object-store references, stored the same way every other
+		// list in the schema is
+		return "JSON"
+	}
+	return "VARCHAR(512)" // default url size
+}
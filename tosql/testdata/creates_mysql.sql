@@ -1,13 +1,13 @@
 CREATE TABLE IF NOT EXISTS `user` (
     `uuid` CHAR(36) NOT NULL,
-    `version` INT NOT NULL,
+    `version` INT NOT NULL COMMENT 'Version',
     `email` VARCHAR(512),
     `password` VARCHAR(255),
-    `status` INT NOT NULL,
-    `created_at` DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
-    `updated_at` DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
-    `created_by` CHAR(36) NOT NULL,
-    `updated_by` CHAR(36) NOT NULL,
+    `status` INT NOT NULL COMMENT 'Status',
+    `created_at` DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP COMMENT 'Created At',
+    `updated_at` DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP COMMENT 'Updated At',
+    `created_by` CHAR(36) NOT NULL COMMENT 'Created By',
+    `updated_by` CHAR(36) NOT NULL COMMENT 'Updated By',
     PRIMARY KEY (`uuid`, `version`),
     INDEX `index_email` (`email`),
     INDEX `index_status` (`status`),
@@ -18,39 +18,39 @@ CREATE TABLE IF NOT EXISTS `user` (
 
 CREATE TABLE IF NOT EXISTS `folder` (
     `uuid` CHAR(36) NOT NULL,
-    `version` INT NOT NULL,
-    `status` INT NOT NULL,
-    `created_at` DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
-    `updated_at` DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
-    `created_by` CHAR(36) NOT NULL,
-    `updated_by` CHAR(36) NOT NULL,
+    `version` INT NOT NULL COMMENT 'Version',
+    `status` INT NOT NULL COMMENT 'Status',
+    `created_at` DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP COMMENT 'Created At',
+    `updated_at` DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP COMMENT 'Updated At',
+    `created_by` CHAR(36) NOT NULL COMMENT 'Created By',
+    `updated_by` CHAR(36) NOT NULL COMMENT 'Updated By',
     PRIMARY KEY (`uuid`)
 ) ENGINE = InnoDB;
 
 CREATE TABLE IF NOT EXISTS `single_key` (
     `uuid` CHAR(36) NOT NULL,
-    `version` INT NOT NULL,
-    `status` INT NOT NULL,
-    `created_at` DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
-    `updated_at` DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
-    `created_by` CHAR(36) NOT NULL,
-    `updated_by` CHAR(36) NOT NULL,
+    `version` INT NOT NULL COMMENT 'Version',
+    `status` INT NOT NULL COMMENT 'Status',
+    `created_at` DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP COMMENT 'Created At',
+    `updated_at` DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP COMMENT 'Updated At',
+    `created_by` CHAR(36) NOT NULL COMMENT 'Created By',
+    `updated_by` CHAR(36) NOT NULL COMMENT 'Updated By',
     PRIMARY KEY (`uuid`),
     INDEX `nuevo_indice` (`version`)
 ) ENGINE = InnoDB;
 
 CREATE TABLE IF NOT EXISTS `post` (
     `uuid` CHAR(36) NOT NULL,
-    `version` INT NOT NULL,
+    `version` INT NOT NULL COMMENT 'Version',
     `title` VARCHAR(255) NOT NULL,
     `slug` VARCHAR(512) NOT NULL,
     `description` VARCHAR(255),
     `content` TEXT,
-    `status` INT NOT NULL,
-    `created_at` DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
-    `updated_at` DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
-    `created_by` CHAR(36) NOT NULL,
-    `updated_by` CHAR(36) NOT NULL,
+    `status` INT NOT NULL COMMENT 'Status',
+    `created_at` DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP COMMENT 'Created At',
+    `updated_at` DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP COMMENT 'Updated At',
+    `created_by` CHAR(36) NOT NULL COMMENT 'Created By',
+    `updated_by` CHAR(36) NOT NULL COMMENT 'Updated By',
     `media` JSON NOT NULL,
     `user_uuid` CHAR(36) NOT NULL,
     PRIMARY KEY (`uuid`),
@@ -63,5 +63,5 @@ CREATE TABLE IF NOT EXISTS `post` (
     CONSTRAINT `post_user`
         FOREIGN KEY (`user_uuid`)
         REFERENCES `user` (`uuid`)
-) ENGINE = InnoDB;
+) ENGINE = InnoDB COMMENT = 'some desc here';
 
@@ -15,6 +15,12 @@ CREATE TABLE IF NOT EXISTS "user" (
 CREATE INDEX "index_email" ON "user" ("email");
 CREATE INDEX "index_status" ON "user" ("status");
 CREATE INDEX "index_updated_at" ON "user" ("updated_at");
+COMMENT ON COLUMN "user"."version" IS 'Version';
+COMMENT ON COLUMN "user"."status" IS 'Status';
+COMMENT ON COLUMN "user"."created_at" IS 'Created At';
+COMMENT ON COLUMN "user"."updated_at" IS 'Updated At';
+COMMENT ON COLUMN "user"."created_by" IS 'Created By';
+COMMENT ON COLUMN "user"."updated_by" IS 'Updated By';
 
 CREATE TABLE IF NOT EXISTS "folder" (
     "uuid" UUID NOT NULL,
@@ -26,6 +32,12 @@ CREATE TABLE IF NOT EXISTS "folder" (
     "updated_by" UUID NOT NULL,
     PRIMARY KEY ("uuid")
 );
+COMMENT ON COLUMN "folder"."version" IS 'Version';
+COMMENT ON COLUMN "folder"."status" IS 'Status';
+COMMENT ON COLUMN "folder"."created_at" IS 'Created At';
+COMMENT ON COLUMN "folder"."updated_at" IS 'Updated At';
+COMMENT ON COLUMN "folder"."created_by" IS 'Created By';
+COMMENT ON COLUMN "folder"."updated_by" IS 'Updated By';
 
 CREATE TABLE IF NOT EXISTS "single_key" (
     "uuid" UUID NOT NULL,
@@ -38,6 +50,12 @@ CREATE TABLE IF NOT EXISTS "single_key" (
     PRIMARY KEY ("uuid")
 );
 CREATE INDEX "nuevo_indice_single_key" ON "single_key" ("version");
+COMMENT ON COLUMN "single_key"."version" IS 'Version';
+COMMENT ON COLUMN "single_key"."status" IS 'Status';
+COMMENT ON COLUMN "single_key"."created_at" IS 'Created At';
+COMMENT ON COLUMN "single_key"."updated_at" IS 'Updated At';
+COMMENT ON COLUMN "single_key"."created_by" IS 'Created By';
+COMMENT ON COLUMN "single_key"."updated_by" IS 'Updated By';
 
 CREATE TABLE IF NOT EXISTS "post" (
     "uuid" UUID NOT NULL,
@@ -64,4 +82,11 @@ CREATE INDEX "nuevo_indice_post" ON "post" ("slug");
 CREATE INDEX "idx_post_user_created" ON "post" ("user_uuid", "created_at");
 CREATE INDEX "idx_post_status_updated" ON "post" ("status", "updated_at");
 CREATE INDEX "idx_post_user_status" ON "post" ("user_uuid", "status");
+COMMENT ON TABLE "post" IS 'some desc here';
+COMMENT ON COLUMN "post"."version" IS 'Version';
+COMMENT ON COLUMN "post"."status" IS 'Status';
+COMMENT ON COLUMN "post"."created_at" IS 'Created At';
+COMMENT ON COLUMN "post"."updated_at" IS 'Updated At';
+COMMENT ON COLUMN "post"."created_by" IS 'Created By';
+COMMENT ON COLUMN "post"."updated_by" IS 'Updated By';
 
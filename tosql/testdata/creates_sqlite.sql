@@ -0,0 +1,67 @@
+CREATE TABLE IF NOT EXISTS "user" (
+    "uuid" CHAR(36) NOT NULL,
+    "version" INTEGER NOT NULL,
+    "email" VARCHAR(512),
+    "password" VARCHAR(255),
+    "status" INTEGER NOT NULL,
+    "created_at" DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    "updated_at" DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    "created_by" CHAR(36) NOT NULL,
+    "updated_by" CHAR(36) NOT NULL,
+    PRIMARY KEY ("uuid", "version"),
+    UNIQUE ("uuid"),
+    UNIQUE ("email")
+);
+CREATE INDEX "index_email" ON "user" ("email");
+CREATE INDEX "index_status" ON "user" ("status");
+CREATE INDEX "index_updated_at" ON "user" ("updated_at");
+
+CREATE TABLE IF NOT EXISTS "folder" (
+    "uuid" CHAR(36) NOT NULL,
+    "version" INTEGER NOT NULL,
+    "status" INTEGER NOT NULL,
+    "created_at" DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    "updated_at" DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    "created_by" CHAR(36) NOT NULL,
+    "updated_by" CHAR(36) NOT NULL,
+    PRIMARY KEY ("uuid")
+);
+
+CREATE TABLE IF NOT EXISTS "single_key" (
+    "uuid" CHAR(36) NOT NULL,
+    "version" INTEGER NOT NULL,
+    "status" INTEGER NOT NULL,
+    "created_at" DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    "updated_at" DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    "created_by" CHAR(36) NOT NULL,
+    "updated_by" CHAR(36) NOT NULL,
+    PRIMARY KEY ("uuid")
+);
+CREATE INDEX "nuevo_indice_single_key" ON "single_key" ("version");
+
+CREATE TABLE IF NOT EXISTS "post" (
+    "uuid" CHAR(36) NOT NULL,
+    "version" INTEGER NOT NULL,
+    "title" VARCHAR(255) NOT NULL,
+    "slug" VARCHAR(512) NOT NULL,
+    "description" VARCHAR(255),
+    "content" TEXT,
+    "status" INTEGER NOT NULL,
+    "created_at" DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    "updated_at" DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    "created_by" CHAR(36) NOT NULL,
+    "updated_by" CHAR(36) NOT NULL,
+    "media" JSON NOT NULL,
+    "user_uuid" CHAR(36) NOT NULL,
+    PRIMARY KEY ("uuid"),
+    UNIQUE ("slug"),
+    UNIQUE ("title"),
+    CONSTRAINT "post_user"
+        FOREIGN KEY ("user_uuid")
+        REFERENCES "user" ("uuid")
+);
+CREATE INDEX "nuevo_indice_post" ON "post" ("slug");
+CREATE INDEX "idx_post_user_created" ON "post" ("user_uuid", "created_at");
+CREATE INDEX "idx_post_status_updated" ON "post" ("status", "updated_at");
+CREATE INDEX "idx_post_user_status" ON "post" ("user_uuid", "status");
+
@@ -0,0 +1,4 @@
+DROP TABLE IF EXISTS "post";
+DROP TABLE IF EXISTS "single_key";
+DROP TABLE IF EXISTS "folder";
+DROP TABLE IF EXISTS "user";
@@ -0,0 +1,20 @@
+ALTER TABLE `book` DROP FOREIGN KEY `book_author`;
+DROP INDEX `idx_author_email` ON `author`;
+DROP INDEX `idx_book_created` ON `book`;
+DROP TABLE `shelf`;
+ALTER TABLE `author` RENAME TO `writer`;
+ALTER TABLE `writer` RENAME COLUMN `name` TO `full_name`;
+ALTER TABLE `writer` MODIFY COLUMN `full_name` VARCHAR(200) NOT NULL;
+ALTER TABLE `writer` DROP COLUMN `email`;
+ALTER TABLE `book` ADD COLUMN `isbn` CHAR(13) AFTER `created_at`;
+CREATE TABLE IF NOT EXISTS `review` (
+    `id` CHAR(36) NOT NULL,
+    `book_id` CHAR(36) NOT NULL,
+    `body` TEXT,
+    PRIMARY KEY (`id`),
+    CONSTRAINT `review_book`
+        FOREIGN KEY (`book_id`)
+        REFERENCES `book` (`id`)
+) ENGINE = InnoDB;
+CREATE UNIQUE INDEX `uq_book_isbn` ON `book` (`isbn`);
+ALTER TABLE `book` ADD CONSTRAINT `book_author` FOREIGN KEY (`author_id`) REFERENCES `writer` (`id`) ON DELETE CASCADE;
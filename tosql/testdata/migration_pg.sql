@@ -0,0 +1,20 @@
+ALTER TABLE "book" DROP CONSTRAINT "book_author";
+DROP INDEX "idx_author_email";
+DROP INDEX "idx_book_created";
+DROP TABLE "shelf";
+ALTER TABLE "author" RENAME TO "writer";
+ALTER TABLE "writer" RENAME COLUMN "name" TO "full_name";
+ALTER TABLE "writer" ALTER COLUMN "full_name" TYPE VARCHAR(200) USING "full_name"::VARCHAR(200);
+ALTER TABLE "writer" DROP COLUMN "email";
+ALTER TABLE "book" ADD COLUMN "isbn" CHAR(13);
+CREATE TABLE IF NOT EXISTS "review" (
+    "id" UUID NOT NULL,
+    "book_id" UUID NOT NULL,
+    "body" TEXT,
+    PRIMARY KEY ("id"),
+    CONSTRAINT "review_book"
+        FOREIGN KEY ("book_id")
+        REFERENCES "book" ("id")
+);
+ALTER TABLE "book" ADD UNIQUE ("isbn");
+ALTER TABLE "book" ADD CONSTRAINT "book_author" FOREIGN KEY ("author_id") REFERENCES "writer" ("id") ON DELETE CASCADE;
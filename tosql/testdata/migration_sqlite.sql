@@ -0,0 +1,38 @@
+PRAGMA foreign_keys = OFF;
+DROP TABLE "shelf";
+ALTER TABLE "author" RENAME TO "writer";
+CREATE TABLE IF NOT EXISTS "writer__migration" (
+    "id" CHAR(36) NOT NULL,
+    "full_name" VARCHAR(200) NOT NULL,
+    PRIMARY KEY ("id")
+);
+INSERT INTO "writer__migration" ("id", "full_name") SELECT "id", "name" FROM "writer";
+DROP TABLE "writer";
+ALTER TABLE "writer__migration" RENAME TO "writer";
+CREATE TABLE IF NOT EXISTS "book__migration" (
+    "id" CHAR(36) NOT NULL,
+    "author_id" CHAR(36) NOT NULL,
+    "title" VARCHAR(255) NOT NULL,
+    "created_at" DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    "isbn" CHAR(13),
+    PRIMARY KEY ("id"),
+    UNIQUE ("title"),
+    UNIQUE ("isbn"),
+    CONSTRAINT "book_author"
+        FOREIGN KEY ("author_id")
+        REFERENCES "writer" ("id")
+        ON DELETE CASCADE
+);
+INSERT INTO "book__migration" ("id", "author_id", "title", "created_at") SELECT "id", "author_id", "title", "created_at" FROM "book";
+DROP TABLE "book";
+ALTER TABLE "book__migration" RENAME TO "book";
+CREATE TABLE IF NOT EXISTS "review" (
+    "id" CHAR(36) NOT NULL,
+    "book_id" CHAR(36) NOT NULL,
+    "body" TEXT,
+    PRIMARY KEY ("id"),
+    CONSTRAINT "review_book"
+        FOREIGN KEY ("book_id")
+        REFERENCES "book" ("id")
+);
+PRAGMA foreign_keys = ON;
@@ -183,6 +183,132 @@ ORDER BY updated_at DESC
 LIMIT ? OFFSET ?;
 
             
+-- name: FetchUserByUUIDKeysetByUpdatedAtASC :many
+SELECT "uuid","version","email","password","status","created_at","updated_at","created_by","updated_by"
+FROM "user"
+WHERE 
+    "uuid" = ?  AND ("updated_at", "uuid", "version") > (?, ?, ?)
+ORDER BY "updated_at" ASC, "uuid" ASC, "version" ASC
+LIMIT ?;
+
+            
+-- name: FetchUserByUUIDKeysetByUpdatedAtDESC :many
+SELECT "uuid","version","email","password","status","created_at","updated_at","created_by","updated_by"
+FROM "user"
+WHERE 
+    "uuid" = ?  AND ("updated_at", "uuid", "version") < (?, ?, ?)
+ORDER BY "updated_at" DESC, "uuid" DESC, "version" DESC
+LIMIT ?;
+
+            
+-- name: FetchUserByEmailKeysetByUpdatedAtASC :many
+SELECT "uuid","version","email","password","status","created_at","updated_at","created_by","updated_by"
+FROM "user"
+WHERE 
+    "email" = ?  AND ("updated_at", "uuid", "version") > (?, ?, ?)
+ORDER BY "updated_at" ASC, "uuid" ASC, "version" ASC
+LIMIT ?;
+
+            
+-- name: FetchUserByEmailKeysetByUpdatedAtDESC :many
+SELECT "uuid","version","email","password","status","created_at","updated_at","created_by","updated_by"
+FROM "user"
+WHERE 
+    "email" = ?  AND ("updated_at", "uuid", "version") < (?, ?, ?)
+ORDER BY "updated_at" DESC, "uuid" DESC, "version" DESC
+LIMIT ?;
+
+            
+-- name: FetchUserByStatusKeysetByUpdatedAtASC :many
+SELECT "uuid","version","email","password","status","created_at","updated_at","created_by","updated_by"
+FROM "user"
+WHERE 
+    "status" = ?  AND ("updated_at", "uuid", "version") > (?, ?, ?)
+ORDER BY "updated_at" ASC, "uuid" ASC, "version" ASC
+LIMIT ?;
+
+            
+-- name: FetchUserByStatusKeysetByUpdatedAtDESC :many
+SELECT "uuid","version","email","password","status","created_at","updated_at","created_by","updated_by"
+FROM "user"
+WHERE 
+    "status" = ?  AND ("updated_at", "uuid", "version") < (?, ?, ?)
+ORDER BY "updated_at" DESC, "uuid" DESC, "version" DESC
+LIMIT ?;
+
+            
+-- name: FetchUserByUUIDAndEmailKeysetByUpdatedAtASC :many
+SELECT "uuid","version","email","password","status","created_at","updated_at","created_by","updated_by"
+FROM "user"
+WHERE 
+    "email" = ? AND "uuid" = ?  AND ("updated_at", "uuid", "version") > (?, ?, ?)
+ORDER BY "updated_at" ASC, "uuid" ASC, "version" ASC
+LIMIT ?;
+
+            
+-- name: FetchUserByUUIDAndEmailKeysetByUpdatedAtDESC :many
+SELECT "uuid","version","email","password","status","created_at","updated_at","created_by","updated_by"
+FROM "user"
+WHERE 
+    "email" = ? AND "uuid" = ?  AND ("updated_at", "uuid", "version") < (?, ?, ?)
+ORDER BY "updated_at" DESC, "uuid" DESC, "version" DESC
+LIMIT ?;
+
+            
+-- name: FetchUserByUUIDAndStatusKeysetByUpdatedAtASC :many
+SELECT "uuid","version","email","password","status","created_at","updated_at","created_by","updated_by"
+FROM "user"
+WHERE 
+    "status" = ? AND "uuid" = ?  AND ("updated_at", "uuid", "version") > (?, ?, ?)
+ORDER BY "updated_at" ASC, "uuid" ASC, "version" ASC
+LIMIT ?;
+
+            
+-- name: FetchUserByUUIDAndStatusKeysetByUpdatedAtDESC :many
+SELECT "uuid","version","email","password","status","created_at","updated_at","created_by","updated_by"
+FROM "user"
+WHERE 
+    "status" = ? AND "uuid" = ?  AND ("updated_at", "uuid", "version") < (?, ?, ?)
+ORDER BY "updated_at" DESC, "uuid" DESC, "version" DESC
+LIMIT ?;
+
+            
+-- name: FetchUserByEmailAndStatusKeysetByUpdatedAtASC :many
+SELECT "uuid","version","email","password","status","created_at","updated_at","created_by","updated_by"
+FROM "user"
+WHERE 
+    "email" = ? AND "status" = ?  AND ("updated_at", "uuid", "version") > (?, ?, ?)
+ORDER BY "updated_at" ASC, "uuid" ASC, "version" ASC
+LIMIT ?;
+
+            
+-- name: FetchUserByEmailAndStatusKeysetByUpdatedAtDESC :many
+SELECT "uuid","version","email","password","status","created_at","updated_at","created_by","updated_by"
+FROM "user"
+WHERE 
+    "email" = ? AND "status" = ?  AND ("updated_at", "uuid", "version") < (?, ?, ?)
+ORDER BY "updated_at" DESC, "uuid" DESC, "version" DESC
+LIMIT ?;
+
+            
+-- name: FetchUserByUUIDAndEmailAndStatusKeysetByUpdatedAtASC :many
+SELECT "uuid","version","email","password","status","created_at","updated_at","created_by","updated_by"
+FROM "user"
+WHERE 
+    "email" = ? AND "status" = ? AND "uuid" = ?  AND ("updated_at", "uuid", "version") > (?, ?, ?)
+ORDER BY "updated_at" ASC, "uuid" ASC, "version" ASC
+LIMIT ?;
+
+            
+-- name: FetchUserByUUIDAndEmailAndStatusKeysetByUpdatedAtDESC :many
+SELECT "uuid","version","email","password","status","created_at","updated_at","created_by","updated_by"
+FROM "user"
+WHERE 
+    "email" = ? AND "status" = ? AND "uuid" = ?  AND ("updated_at", "uuid", "version") < (?, ?, ?)
+ORDER BY "updated_at" DESC, "uuid" DESC, "version" DESC
+LIMIT ?;
+
+            
 
 
 
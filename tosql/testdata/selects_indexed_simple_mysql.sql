@@ -87,6 +87,60 @@ ORDER BY updated_at DESC
 LIMIT ?, ?;
 
             
+-- name: FetchUserByUUIDKeysetByUpdatedAtASC :many
+SELECT `uuid`,`version`,`email`,`password`,`status`,`created_at`,`updated_at`,`created_by`,`updated_by`
+FROM `user`
+WHERE 
+    `uuid` = ?  AND (`updated_at`, `uuid`, `version`) > (?, ?, ?)
+ORDER BY `updated_at` ASC, `uuid` ASC, `version` ASC
+LIMIT ?;
+
+            
+-- name: FetchUserByUUIDKeysetByUpdatedAtDESC :many
+SELECT `uuid`,`version`,`email`,`password`,`status`,`created_at`,`updated_at`,`created_by`,`updated_by`
+FROM `user`
+WHERE 
+    `uuid` = ?  AND (`updated_at`, `uuid`, `version`) < (?, ?, ?)
+ORDER BY `updated_at` DESC, `uuid` DESC, `version` DESC
+LIMIT ?;
+
+            
+-- name: FetchUserByEmailKeysetByUpdatedAtASC :many
+SELECT `uuid`,`version`,`email`,`password`,`status`,`created_at`,`updated_at`,`created_by`,`updated_by`
+FROM `user`
+WHERE 
+    `email` = ?  AND (`updated_at`, `uuid`, `version`) > (?, ?, ?)
+ORDER BY `updated_at` ASC, `uuid` ASC, `version` ASC
+LIMIT ?;
+
+            
+-- name: FetchUserByEmailKeysetByUpdatedAtDESC :many
+SELECT `uuid`,`version`,`email`,`password`,`status`,`created_at`,`updated_at`,`created_by`,`updated_by`
+FROM `user`
+WHERE 
+    `email` = ?  AND (`updated_at`, `uuid`, `version`) < (?, ?, ?)
+ORDER BY `updated_at` DESC, `uuid` DESC, `version` DESC
+LIMIT ?;
+
+            
+-- name: FetchUserByStatusKeysetByUpdatedAtASC :many
+SELECT `uuid`,`version`,`email`,`password`,`status`,`created_at`,`updated_at`,`created_by`,`updated_by`
+FROM `user`
+WHERE 
+    `status` = ?  AND (`updated_at`, `uuid`, `version`) > (?, ?, ?)
+ORDER BY `updated_at` ASC, `uuid` ASC, `version` ASC
+LIMIT ?;
+
+            
+-- name: FetchUserByStatusKeysetByUpdatedAtDESC :many
+SELECT `uuid`,`version`,`email`,`password`,`status`,`created_at`,`updated_at`,`created_by`,`updated_by`
+FROM `user`
+WHERE 
+    `status` = ?  AND (`updated_at`, `uuid`, `version`) < (?, ?, ?)
+ORDER BY `updated_at` DESC, `uuid` DESC, `version` DESC
+LIMIT ?;
+
+            
 
 
 
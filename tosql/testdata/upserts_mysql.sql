@@ -0,0 +1,56 @@
+-- name: UpsertUser :exec
+INSERT INTO `user`
+(`uuid`,`version`,`email`,`password`,`status`,`created_at`,`updated_at`,`created_by`,`updated_by`)
+VALUES
+(?,?,?,?,?,?,?,?,?) AS new
+ON DUPLICATE KEY UPDATE
+`email` = new.`email`, `password` = new.`password`, `status` = new.`status`, `created_at` = new.`created_at`, `updated_at` = new.`updated_at`, `created_by` = new.`created_by`, `updated_by` = new.`updated_by`;
+
+-- name: UpsertUserByUUID :exec
+INSERT INTO `user`
+(`uuid`,`version`,`email`,`password`,`status`,`created_at`,`updated_at`,`created_by`,`updated_by`)
+VALUES
+(?,?,?,?,?,?,?,?,?) AS new
+ON DUPLICATE KEY UPDATE
+`email` = new.`email`, `password` = new.`password`, `status` = new.`status`, `created_at` = new.`created_at`, `updated_at` = new.`updated_at`, `created_by` = new.`created_by`, `updated_by` = new.`updated_by`;
+
+-- name: UpsertUserByEmail :exec
+INSERT INTO `user`
+(`uuid`,`version`,`email`,`password`,`status`,`created_at`,`updated_at`,`created_by`,`updated_by`)
+VALUES
+(?,?,?,?,?,?,?,?,?) AS new
+ON DUPLICATE KEY UPDATE
+`password` = new.`password`, `status` = new.`status`, `created_at` = new.`created_at`, `updated_at` = new.`updated_at`, `created_by` = new.`created_by`, `updated_by` = new.`updated_by`;
+
+-- name: UpsertFolder :exec
+INSERT INTO `folder`
+(`uuid`,`version`,`status`,`created_at`,`updated_at`,`created_by`,`updated_by`)
+VALUES
+(?,?,?,?,?,?,?) AS new
+ON DUPLICATE KEY UPDATE
+`version` = new.`version`, `status` = new.`status`, `created_at` = new.`created_at`, `updated_at` = new.`updated_at`, `created_by` = new.`created_by`, `updated_by` = new.`updated_by`;
+
+-- name: UpsertSingleKey :exec
+INSERT INTO `single_key`
+(`uuid`,`version`,`status`,`created_at`,`updated_at`,`created_by`,`updated_by`)
+VALUES
+(?,?,?,?,?,?,?) AS new
+ON DUPLICATE KEY UPDATE
+`version` = new.`version`, `status` = new.`status`, `created_at` = new.`created_at`, `updated_at` = new.`updated_at`, `created_by` = new.`created_by`, `updated_by` = new.`updated_by`;
+
+-- name: UpsertPost :exec
+INSERT INTO `post`
+(`uuid`,`version`,`title`,`slug`,`description`,`content`,`status`,`created_at`,`updated_at`,`created_by`,`updated_by`,`media`,`user_uuid`)
+VALUES
+(?,?,?,?,?,?,?,?,?,?,?,?,?) AS new
+ON DUPLICATE KEY UPDATE
+`version` = new.`version`, `title` = new.`title`, `slug` = new.`slug`, `description` = new.`description`, `content` = new.`content`, `status` = new.`status`, `created_at` = new.`created_at`, `updated_at` = new.`updated_at`, `created_by` = new.`created_by`, `updated_by` = new.`updated_by`, `media` = new.`media`, `user_uuid` = new.`user_uuid`;
+
+-- name: UpsertPostBySlug :exec
+INSERT INTO `post`
+(`uuid`,`version`,`title`,`slug`,`description`,`content`,`status`,`created_at`,`updated_at`,`created_by`,`updated_by`,`media`,`user_uuid`)
+VALUES
+(?,?,?,?,?,?,?,?,?,?,?,?,?) AS new
+ON DUPLICATE KEY UPDATE
+`version` = new.`version`, `title` = new.`title`, `description` = new.`description`, `content` = new.`content`, `status` = new.`status`, `created_at` = new.`created_at`, `updated_at` = new.`updated_at`, `created_by` = new.`created_by`, `updated_by` = new.`updated_by`, `media` = new.`media`, `user_uuid` = new.`user_uuid`;
+
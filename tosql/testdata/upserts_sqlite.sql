@@ -0,0 +1,56 @@
+-- name: UpsertUser :exec
+INSERT INTO "user"
+("uuid","version","email","password","status","created_at","updated_at","created_by","updated_by")
+VALUES
+(?,?,?,?,?,?,?,?,?)
+ON CONFLICT ("uuid", "version") DO UPDATE SET
+"email" = EXCLUDED."email", "password" = EXCLUDED."password", "status" = EXCLUDED."status", "created_at" = EXCLUDED."created_at", "updated_at" = EXCLUDED."updated_at", "created_by" = EXCLUDED."created_by", "updated_by" = EXCLUDED."updated_by";
+
+-- name: UpsertUserByUUID :exec
+INSERT INTO "user"
+("uuid","version","email","password","status","created_at","updated_at","created_by","updated_by")
+VALUES
+(?,?,?,?,?,?,?,?,?)
+ON CONFLICT ("uuid") DO UPDATE SET
+"email" = EXCLUDED."email", "password" = EXCLUDED."password", "status" = EXCLUDED."status", "created_at" = EXCLUDED."created_at", "updated_at" = EXCLUDED."updated_at", "created_by" = EXCLUDED."created_by", "updated_by" = EXCLUDED."updated_by";
+
+-- name: UpsertUserByEmail :exec
+INSERT INTO "user"
+("uuid","version","email","password","status","created_at","updated_at","created_by","updated_by")
+VALUES
+(?,?,?,?,?,?,?,?,?)
+ON CONFLICT ("email") DO UPDATE SET
+"password" = EXCLUDED."password", "status" = EXCLUDED."status", "created_at" = EXCLUDED."created_at", "updated_at" = EXCLUDED."updated_at", "created_by" = EXCLUDED."created_by", "updated_by" = EXCLUDED."updated_by";
+
+-- name: UpsertFolder :exec
+INSERT INTO "folder"
+("uuid","version","status","created_at","updated_at","created_by","updated_by")
+VALUES
+(?,?,?,?,?,?,?)
+ON CONFLICT ("uuid") DO UPDATE SET
+"version" = EXCLUDED."version", "status" = EXCLUDED."status", "created_at" = EXCLUDED."created_at", "updated_at" = EXCLUDED."updated_at", "created_by" = EXCLUDED."created_by", "updated_by" = EXCLUDED."updated_by";
+
+-- name: UpsertSingleKey :exec
+INSERT INTO "single_key"
+("uuid","version","status","created_at","updated_at","created_by","updated_by")
+VALUES
+(?,?,?,?,?,?,?)
+ON CONFLICT ("uuid") DO UPDATE SET
+"version" = EXCLUDED."version", "status" = EXCLUDED."status", "created_at" = EXCLUDED."created_at", "updated_at" = EXCLUDED."updated_at", "created_by" = EXCLUDED."created_by", "updated_by" = EXCLUDED."updated_by";
+
+-- name: UpsertPost :exec
+INSERT INTO "post"
+("uuid","version","title","slug","description","content","status","created_at","updated_at","created_by","updated_by","media","user_uuid")
+VALUES
+(?,?,?,?,?,?,?,?,?,?,?,?,?)
+ON CONFLICT ("uuid") DO UPDATE SET
+"version" = EXCLUDED."version", "title" = EXCLUDED."title", "slug" = EXCLUDED."slug", "description" = EXCLUDED."description", "content" = EXCLUDED."content", "status" = EXCLUDED."status", "created_at" = EXCLUDED."created_at", "updated_at" = EXCLUDED."updated_at", "created_by" = EXCLUDED."created_by", "updated_by" = EXCLUDED."updated_by", "media" = EXCLUDED."media", "user_uuid" = EXCLUDED."user_uuid";
+
+-- name: UpsertPostBySlug :exec
+INSERT INTO "post"
+("uuid","version","title","slug","description","content","status","created_at","updated_at","created_by","updated_by","media","user_uuid")
+VALUES
+(?,?,?,?,?,?,?,?,?,?,?,?,?)
+ON CONFLICT ("slug") DO UPDATE SET
+"version" = EXCLUDED."version", "title" = EXCLUDED."title", "description" = EXCLUDED."description", "content" = EXCLUDED."content", "status" = EXCLUDED."status", "created_at" = EXCLUDED."created_at", "updated_at" = EXCLUDED."updated_at", "created_by" = EXCLUDED."created_by", "updated_by" = EXCLUDED."updated_by", "media" = EXCLUDED."media", "user_uuid" = EXCLUDED."user_uuid";
+
@@ -0,0 +1,248 @@
+package tosql
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strconv"
+	"time"
+
+	"github.com/gofrs/uuid"
+	nemgen "github.com/nuzur/nem/idl/gen"
+	"github.com/nuzur/sql-gen/db"
+)
+
+// GenerateTypedStatementResult is a GenerateStatementResult whose parameters
+// are the Go values the driver binds as the column's own type, rather than
+// text the database has to cast. See typedParamValue for which value each
+// field type becomes.
+type GenerateTypedStatementResult struct {
+	SQL             string `json:"sql"` // display only
+	ParametrizedSQL string `json:"parametrized_sql"`
+	Params          []any  `json:"params"`
+}
+
+// GenerateInsertForEntityWithTypedValues is GenerateInsertForEntityWithValues
+// with typed parameters. It fails on a value that does not parse as its
+// field's type.
+func GenerateInsertForEntityWithTypedValues(ctx context.Context, params GenerateInsertForEntityWithValuesParams) (*GenerateTypedStatementResult, error) {
+	res, bound, err := generateInsertForEntity(ctx, params)
+	if err != nil {
+		return nil, err
+	}
+	return bound.typedResult(res, params.DBType)
+}
+
+// GenerateUpdateForEntityWithTypedValues is GenerateUpdateForEntityWithValues
+// with typed parameters.
+func GenerateUpdateForEntityWithTypedValues(ctx context.Context, params GenerateUpdateForEntityWithValuesParams) (*GenerateTypedStatementResult, error) {
+	res, bound, err := generateUpdateForEntity(ctx, params)
+	if err != nil {
+		return nil, err
+	}
+	return bound.typedResult(res, params.DBType)
+}
+
+// GenerateDeleteForEntityWithTypedValues is GenerateDeleteForEntityWithValues
+// with typed parameters.
+func GenerateDeleteForEntityWithTypedValues(ctx context.Context, params GenerateDeleteForEntityWithValuesParams) (*GenerateTypedStatementResult, error) {
+	res, bound, err := generateDeleteForEntity(ctx, params)
+	if err != nil {
+		return nil, err
+	}
+	return bound.typedResult(res, params.DBType)
+}
+
+// GenerateSelectForEntityWithTypedValues is GenerateSelectForEntityWithValues
+// with typed parameters.
+func GenerateSelectForEntityWithTypedValues(ctx context.Context, params GenerateSelectForEntityWithValuesParams) (*GenerateTypedStatementResult, error) {
+	res, bound, err := generateSelectForEntity(ctx, params)
+	if err != nil {
+		return nil, err
+	}
+	return bound.typedResult(res, params.DBType)
+}
+
+// boundParam is a value a statement binds, as the caller gave it, with the
+// field it is bound to.
+type boundParam struct {
+	field SchemaField
+	value string
+}
+
+type boundParams []boundParam
+
+// strings are the parameters as GenerateStatementResult carries them.
+func (bp boundParams) strings(dbType db.DBType) []string {
+	res := []string{}
+	for _, p := range bp {
+		res = append(res, coerceParamValue(p.field, p.value, dbType))
+	}
+	return res
+}
+
+func (bp boundParams) typedResult(res *GenerateStatementResult, dbType db.DBType) (*GenerateTypedStatementResult, error) {
+	typed := []any{}
+	for _, p := range bp {
+		value, err := typedParamValue(p.field, p.value, dbType)
+		if err != nil {
+			return nil, fmt.Errorf("invalid value %q for field %q: %w", p.value, p.field.Name, err)
+		}
+		typed = append(typed, value)
+	}
+	return &GenerateTypedStatementResult{
+		SQL:             res.SQL,
+		ParametrizedSQL: res.ParametrizedSQL,
+		Params:          typed,
+	}, nil
+}
+
+// decimalRegexp is a plain decimal number, which is what a DECIMAL column
+// takes without losing precision; the driver gets it as the string.
+var decimalRegexp = regexp.MustCompile(`^[+-]?(\d+(\.\d*)?|\.\d+)$`)
+
+// typedParamValue parses a stringified field value into the Go value a driver
+// binds as the field's column type:
+//
+//   - integers, and single enums stored as their numeric value, are int64
+//   - enums of a native enum column are the option's label, and a
+//     multi-select its labels as the column spells a set of them
+//   - floats are float64
+//   - decimals stay strings, checked to be numbers, so no precision is lost
+//   - booleans are bool
+//   - dates and datetimes are time.Time, but on sqlite, which has no time
+//     type and compares the text it stores, the text coerceParamValue gives
+//   - times of day are the string HH:MM:SS[.ffffff], which every dialect's
+//     TIME takes and a time.Time would carry a date into
+//   - json, arrays, other multiple enums and lists of files are json.RawMessage
+//   - files stored as binary are []byte
+//   - uuids are their canonical string
+//
+// Everything else is text and stays the string it is.
+func typedParamValue(f SchemaField, value string, dbType db.DBType) (any, error) {
+	field := f.Field
+	if field == nil {
+		return value, nil
+	}
+	switch field.GetType() {
+	case nemgen.FieldType_FIELD_TYPE_UUID:
+		id, err := uuid.FromString(value)
+		if err != nil {
+			return nil, fmt.Errorf("not a uuid")
+		}
+		return id.String(), nil
+	case nemgen.FieldType_FIELD_TYPE_INTEGER:
+		if field.GetTypeConfig().GetInteger().GetSize() == nemgen.FieldTypeIntegerConfigSize_FIELD_TYPE_INTEGER_CONFIG_SIZE_ONE_BIT {
+			// the column is numeric on every dialect, but the UI sends a
+			// one-bit integer as a boolean, as coerceParamValue has it
+			if b, err := strconv.ParseBool(value); err == nil {
+				if b {
+					return int64(1), nil
+				}
+				return int64(0), nil
+			}
+		}
+		return parseInt(value)
+	case nemgen.FieldType_FIELD_TYPE_FLOAT:
+		f, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			return nil, fmt.Errorf("not a number")
+		}
+		return f, nil
+	case nemgen.FieldType_FIELD_TYPE_DECIMAL:
+		if !decimalRegexp.MatchString(value) {
+			return nil, fmt.Errorf("not a decimal number")
+		}
+		return value, nil
+	case nemgen.FieldType_FIELD_TYPE_BOOLEAN:
+		b, err := strconv.ParseBool(value)
+		if err != nil {
+			return nil, fmt.Errorf("not a boolean")
+		}
+		return b, nil
+	case nemgen.FieldType_FIELD_TYPE_ENUM:
+		if f.EnumLabels != nil {
+			return enumLabelValue(f, value, dbType)
+		}
+		if field.GetTypeConfig().GetEnum().GetAllowMultiple() {
+			return parseJSON(value)
+		}
+		return parseInt(value)
+	case nemgen.FieldType_FIELD_TYPE_JSON, nemgen.FieldType_FIELD_TYPE_ARRAY:
+		return parseJSON(value)
+	case nemgen.FieldType_FIELD_TYPE_FILE:
+		return fileParamValue(field.GetTypeConfig().GetFile(), value)
+	case nemgen.FieldType_FIELD_TYPE_IMAGE:
+		return fileParamValue(field.GetTypeConfig().GetImage(), value)
+	case nemgen.FieldType_FIELD_TYPE_AUDIO:
+		return fileParamValue(field.GetTypeConfig().GetAudio(), value)
+	case nemgen.FieldType_FIELD_TYPE_VIDEO:
+		return fileParamValue(field.GetTypeConfig().GetVideo(), value)
+	case nemgen.FieldType_FIELD_TYPE_DATE:
+		t, ok := parseISOTime(value)
+		if !ok {
+			var err error
+			if t, err = time.Parse("2006-01-02", value); err != nil {
+				return nil, fmt.Errorf("not a date")
+			}
+		}
+		if dbType == db.SQLiteDBType {
+			return t.UTC().Format("2006-01-02"), nil
+		}
+		return t.UTC().Truncate(24 * time.Hour), nil
+	case nemgen.FieldType_FIELD_TYPE_DATETIME:
+		t, ok := parseISOTime(value)
+		if !ok {
+			var err error
+			if t, err = time.Parse("2006-01-02 15:04:05.999999999", value); err != nil {
+				return nil, fmt.Errorf("not a datetime")
+			}
+		}
+		if dbType == db.SQLiteDBType {
+			return t.UTC().Format("2006-01-02 15:04:05.999999"), nil
+		}
+		return t.UTC(), nil
+	case nemgen.FieldType_FIELD_TYPE_TIME:
+		t, ok := parseISOTime(value)
+		if !ok {
+			var err error
+			if t, err = time.Parse("15:04:05.999999999", value); err != nil {
+				if t, err = time.Parse("15:04", value); err != nil {
+					return nil, fmt.Errorf("not a time")
+				}
+			}
+		} else {
+			t = t.UTC()
+		}
+		return t.Format("15:04:05.999999"), nil
+	}
+	return value, nil
+}
+
+func parseInt(value string) (int64, error) {
+	i, err := strconv.ParseInt(value, 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("not an integer")
+	}
+	return i, nil
+}
+
+func parseJSON(value string) (json.RawMessage, error) {
+	if !json.Valid([]byte(value)) {
+		return nil, fmt.Errorf("not json")
+	}
+	return json.RawMessage(value), nil
+}
+
+// fileParamValue follows handleFileTypePG: a file is its bytes, a list of
+// object store references or a single reference.
+func fileParamValue(config *nemgen.FieldTypeFileConfig, value string) (any, error) {
+	if config.GetStorageType() == nemgen.FieldTypeFileConfigStorageType_FIELD_TYPE_FILE_CONFIG_STORAGE_TYPE_BINARY {
+		return []byte(value), nil
+	}
+	if config.GetAllowMultiple() {
+		return parseJSON(value)
+	}
+	return value, nil
+}
@@ -0,0 +1,176 @@
+package tosql
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	nemgen "github.com/nuzur/nem/idl/gen"
+	"github.com/nuzur/sql-gen/db"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGenerateUpdateTypedParams(t *testing.T) {
+	pv := loadTestProjectVersion(t)
+	params := GenerateUpdateForEntityWithValuesParams{
+		Entity:         testEntity(t, pv, testUserEntityUUID),
+		ProjectVersion: pv,
+		DBType:         db.PGDBType,
+		Values: map[string]string{
+			userFieldEmail:     "user@nuzur.dev",
+			userFieldStatus:    "1",
+			userFieldCreatedAt: "2026-07-28T10:30:00+02:00",
+		},
+		Keys: map[string]string{
+			userFieldUUID:    "9B2C1C2E-0000-4000-8000-000000000002",
+			userFieldVersion: "3",
+		},
+	}
+	res, err := GenerateUpdateForEntityWithTypedValues(context.Background(), params)
+	require.NoError(t, err)
+
+	// same statement as the string variant, only the params differ
+	plain, err := GenerateUpdateForEntityWithValues(context.Background(), params)
+	require.NoError(t, err)
+	assert.Equal(t, plain.ParametrizedSQL, res.ParametrizedSQL)
+	assert.Equal(t, plain.SQL, res.SQL)
+
+	assert.Equal(t, []any{
+		"user@nuzur.dev",
+		int64(1),
+		time.Date(2026, 7, 28, 8, 30, 0, 0, time.UTC),
+		"9b2c1c2e-0000-4000-8000-000000000002",
+		int64(3),
+	}, res.Params)
+	assertPGParamsContiguous(t, res.ParametrizedSQL, len(res.Params))
+}
+
+func TestGenerateInsertTypedParamsJSON(t *testing.T) {
+	pv := loadTestProjectVersion(t)
+	res, err := GenerateInsertForEntityWithTypedValues(context.Background(), GenerateInsertForEntityWithValuesParams{
+		Entity:         testEntity(t, pv, testPostEntityUUID),
+		ProjectVersion: pv,
+		DBType:         db.MYSQLDBType,
+		Values: map[string]string{
+			postFieldUUID:  "9b2c1c2e-0000-4000-8000-000000000001",
+			postFieldTitle: "hello",
+			postFieldMedia: `{"cover": "a.png"}`,
+		},
+	})
+	require.NoError(t, err)
+	assert.Contains(t, res.Params, json.RawMessage(`{"cover": "a.png"}`))
+	assert.Len(t, res.Params, 3)
+}
+
+func TestGenerateTypedParamsRejectUnparsable(t *testing.T) {
+	pv := loadTestProjectVersion(t)
+	_, err := GenerateDeleteForEntityWithTypedValues(context.Background(), GenerateDeleteForEntityWithValuesParams{
+		Entity:         testEntity(t, pv, testUserEntityUUID),
+		ProjectVersion: pv,
+		DBType:         db.PGDBType,
+		Keys: map[string]string{
+			userFieldUUID:    "9b2c1c2e-0000-4000-8000-000000000002",
+			userFieldVersion: "three",
+		},
+	})
+	assert.ErrorContains(t, err, `invalid value "three" for field "version": not an integer`)
+
+	_, err = GenerateSelectForEntityWithTypedValues(context.Background(), GenerateSelectForEntityWithValuesParams{
+		Entity:         testEntity(t, pv, testPostEntityUUID),
+		ProjectVersion: pv,
+		DBType:         db.MYSQLDBType,
+		Keys:           map[string]string{postFieldUUID: "p-1"},
+	})
+	assert.ErrorContains(t, err, "not a uuid")
+
+	_, err = GenerateInsertForEntityWithTypedValues(context.Background(), GenerateInsertForEntityWithValuesParams{
+		Entity:         testEntity(t, pv, testPostEntityUUID),
+		ProjectVersion: pv,
+		DBType:         db.PGDBType,
+		Values: map[string]string{
+			postFieldUUID:  "9b2c1c2e-0000-4000-8000-000000000001",
+			postFieldMedia: `{"cover":`,
+		},
+	})
+	assert.ErrorContains(t, err, "not json")
+}
+
+func TestTypedParamValue(t *testing.T) {
+	field := func(fieldType nemgen.FieldType, config *nemgen.FieldTypeConfig) *nemgen.Field {
+		return &nemgen.Field{Type: fieldType, TypeConfig: config}
+	}
+	binary := &nemgen.FieldTypeConfig{File: &nemgen.FieldTypeFileConfig{
+		StorageType: nemgen.FieldTypeFileConfigStorageType_FIELD_TYPE_FILE_CONFIG_STORAGE_TYPE_BINARY,
+	}}
+	oneBit := &nemgen.FieldTypeConfig{Integer: &nemgen.FieldTypeIntegerConfig{
+		Size: nemgen.FieldTypeIntegerConfigSize_FIELD_TYPE_INTEGER_CONFIG_SIZE_ONE_BIT,
+	}}
+
+	tests := []struct {
+		name   string
+		field  *nemgen.Field
+		dbType db.DBType
+		value  string
+		want   any
+		err    string
+	}{
+		{"boolean", field(nemgen.FieldType_FIELD_TYPE_BOOLEAN, nil), db.MYSQLDBType, "true", true, ""},
+		{"bad boolean", field(nemgen.FieldType_FIELD_TYPE_BOOLEAN, nil), db.MYSQLDBType, "yes", nil, "not a boolean"},
+		{"one bit integer", field(nemgen.FieldType_FIELD_TYPE_INTEGER, oneBit), db.PGDBType, "false", int64(0), ""},
+		{"float", field(nemgen.FieldType_FIELD_TYPE_FLOAT, nil), db.PGDBType, "1.5", 1.5, ""},
+		{"decimal keeps its digits", field(nemgen.FieldType_FIELD_TYPE_DECIMAL, nil), db.PGDBType, "12345678901234567890.123456789", "12345678901234567890.123456789", ""},
+		{"bad decimal", field(nemgen.FieldType_FIELD_TYPE_DECIMAL, nil), db.PGDBType, "1e400", nil, "not a decimal number"},
+		{"date", field(nemgen.FieldType_FIELD_TYPE_DATE, nil), db.PGDBType, "2026-07-28", time.Date(2026, 7, 28, 0, 0, 0, 0, time.UTC), ""},
+		{"datetime from mysql text", field(nemgen.FieldType_FIELD_TYPE_DATETIME, nil), db.MYSQLDBType, "2026-07-28 10:30:00", time.Date(2026, 7, 28, 10, 30, 0, 0, time.UTC), ""},
+		{"datetime on sqlite", field(nemgen.FieldType_FIELD_TYPE_DATETIME, nil), db.SQLiteDBType, "2026-07-28T10:30:00Z", "2026-07-28 10:30:00", ""},
+		{"bad datetime", field(nemgen.FieldType_FIELD_TYPE_DATETIME, nil), db.PGDBType, "yesterday", nil, "not a datetime"},
+		{"time", field(nemgen.FieldType_FIELD_TYPE_TIME, nil), db.PGDBType, "10:30", "10:30:00", ""},
+		{"binary file", field(nemgen.FieldType_FIELD_TYPE_FILE, binary), db.PGDBType, "\x00\x01", []byte{0, 1}, ""},
+		{"text", field(nemgen.FieldType_FIELD_TYPE_TEXT, nil), db.PGDBType, "anything", "anything", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := typedParamValue(SchemaField{Field: tt.field}, tt.value, tt.dbType)
+			if tt.err != "" {
+				assert.ErrorContains(t, err, tt.err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+// Imported schemas declare their enums natively, and those columns take the
+// option's label rather than its number.
+func TestGenerateTypedParamsNativeEnums(t *testing.T) {
+	pv := enumVersion()
+	params := GenerateUpdateForEntityWithValuesParams{
+		Entity:         pv.Entities[0],
+		ProjectVersion: pv,
+		DBType:         db.PGDBType,
+		SchemaOptions:  nativeEnums,
+		Values:         map[string]string{"post.state": "2", "post.labels": "[1]"},
+		Keys:           map[string]string{"post.id": "9b2c1c2e-0000-4000-8000-000000000001"},
+	}
+	res, err := GenerateUpdateForEntityWithTypedValues(context.Background(), params)
+	require.NoError(t, err)
+	assert.Equal(t, []any{"published", `{"news"}`, "9b2c1c2e-0000-4000-8000-000000000001"}, res.Params)
+
+	params.DBType = db.MYSQLDBType
+	res, err = GenerateUpdateForEntityWithTypedValues(context.Background(), params)
+	require.NoError(t, err)
+	assert.Equal(t, []any{"published", "news", "9b2c1c2e-0000-4000-8000-000000000001"}, res.Params)
+
+	params.Values = map[string]string{"post.state": "archived"}
+	_, err = GenerateUpdateForEntityWithTypedValues(context.Background(), params)
+	assert.ErrorContains(t, err, `invalid value "archived" for field "state": not an option of the enum`)
+
+	params.SchemaOptions = SchemaOptions{}
+	params.Values = map[string]string{"post.state": "2"}
+	res, err = GenerateUpdateForEntityWithTypedValues(context.Background(), params)
+	require.NoError(t, err)
+	assert.Equal(t, []any{int64(2), "9b2c1c2e-0000-4000-8000-000000000001"}, res.Params)
+}
@@ -23,19 +23,116 @@ const postgresTextSearchConfig = "simple"
 
 type SchemaTemplate struct {
 	Entities []SchemaEntity
+	// Enums are the postgres enum types the entities' columns use; empty on
+	// the other engines and unless the enum mode is native.
+	Enums []SchemaEnum
+	// Schemas are the postgres schemas the tables and types are in, which
+	// create.sql creates before anything goes in them.
+	Schemas []string
+	// Views are created after every table, in dependency order.
+	Views []SchemaView
+}
+
+// DropOrder is the entities in the order their tables can be dropped in: the
+// reverse of the order they are created in, so every table goes before the
+// tables its foreign keys reference.
+func (t SchemaTemplate) DropOrder() []SchemaEntity {
+	res := slices.Clone(t.Entities)
+	slices.Reverse(res)
+	return res
+}
+
+// CyclicConstraints are the foreign keys that stop DropOrder from working on
+// its own — see cyclicConstraints.
+func (t SchemaTemplate) CyclicConstraints() []SchemaTableConstraint {
+	return cyclicConstraints(t.Entities)
+}
+
+// SchemaTableConstraint is a foreign key together with the table that holds it.
+type SchemaTableConstraint struct {
+	TableName   string
+	TableSchema string
+	Constraint  SchemaConstraint
+}
+
+// QualifiedTableName is the quoted name of the table holding the foreign key.
+func (c SchemaTableConstraint) QualifiedTableName() string {
+	return qualifiedName(c.Constraint.DBType, c.TableSchema, c.TableName)
+}
+
+// cyclicConstraints finds the foreign keys that point at a table created after
+// the one holding them. SortStandaloneEntities only produces those when the
+// foreign keys form a cycle, which no table order can satisfy: whichever table
+// of the cycle is dropped first is still referenced by another. Dropping these
+// constraints first breaks every cycle, after which the reverse of the create
+// order drops the tables cleanly. A table referencing itself is no obstacle to
+// dropping it and is left alone.
+func cyclicConstraints(entities []SchemaEntity) []SchemaTableConstraint {
+	position := make(map[string]int)
+	for i, e := range entities {
+		position[e.QualifiedName()] = i
+	}
+	res := []SchemaTableConstraint{}
+	for i, e := range entities {
+		for _, c := range e.Constraints {
+			if j, ok := position[c.QualifiedTableName()]; ok && j > i {
+				res = append(res, SchemaTableConstraint{
+					TableName:   e.Name,
+					TableSchema: e.Schema,
+					Constraint:  c,
+				})
+			}
+		}
+	}
+	return res
 }
 
 // entity
 type SchemaEntity struct {
-	DBType           db.DBType
-	ForGolang        bool
+	DBType    db.DBType
+	ForGolang bool
+	// Schema is the postgres schema the table is in, empty for the connection's
+	// default one; see SchemaOptions.Schemas.
+	Schema           string
 	Name             string
 	NameTitle        string
 	PrimaryKeys      []string
 	Fields           []SchemaField
 	Indexes          []SchemaIndex
 	Constraints      []SchemaConstraint
+	Checks           []SchemaCheck
 	SelectStatements []SchemaSelectStatement
+	// AutoIncrementStart is the mysql table's AUTO_INCREMENT option, 0 to
+	// leave it out; see SchemaOptions.AutoIncrement.
+	AutoIncrementStart int64
+	// Comment is the entity's description, which is the table's comment.
+	Comment string
+	// SoftDeleteColumn is the column a delete sets instead of removing the
+	// row, empty to delete rows; see SchemaOptions.SoftDelete.
+	SoftDeleteColumn string
+}
+
+// quote quotes an identifier for the entity's engine.
+func (e SchemaEntity) quote(identifier string) string {
+	if e.DBType == db.MYSQLDBType {
+		return fmt.Sprintf("`%s`", identifier)
+	}
+	return fmt.Sprintf(`"%s"`, identifier)
+}
+
+// addCheck adds a CHECK over one of the entity's columns, named the way
+// postgres names an unnamed column CHECK, unless the entity already has a
+// check of that name.
+func (e *SchemaEntity) addCheck(column string, expression string) {
+	name := fmt.Sprintf("%s_%s_check", e.Name, column)
+	if e.hasCheck(name) {
+		return
+	}
+	e.Checks = append(e.Checks, SchemaCheck{
+		DBType:     e.DBType,
+		Name:       name,
+		Expression: expression,
+	})
 }
 
 func (e SchemaEntity) NumOfNonePKFields() int {
@@ -95,7 +192,7 @@ func (e SchemaEntity) PrimaryKeysWhereClauseParamWithOffset(forGolang bool, offs
 	for _, pk := range e.PrimaryKeys {
 		// quotes already added to name
 		switch e.DBType {
-		case db.MYSQLDBType:
+		case db.MYSQLDBType, db.SQLiteDBType:
 			keys = append(keys, fmt.Sprintf("%s = ?", pk))
 		case db.PGDBType:
 			if forGolang {
@@ -131,7 +228,7 @@ func (e SchemaEntity) UpdateFieldsParam(forGolang bool, onlyWithValue bool, valu
 			switch e.DBType {
 			case db.MYSQLDBType:
 				fields = append(fields, fmt.Sprintf("`%s` = NULL", entry.name))
-			case db.PGDBType:
+			case db.PGDBType, db.SQLiteDBType:
 				fields = append(fields, fmt.Sprintf(`"%s" = NULL`, entry.name))
 			}
 			continue
@@ -146,6 +243,8 @@ func (e SchemaEntity) UpdateFieldsParam(forGolang bool, onlyWithValue bool, valu
 			} else {
 				fields = append(fields, fmt.Sprintf(`"%s" = ?`, entry.name))
 			}
+		case db.SQLiteDBType:
+			fields = append(fields, fmt.Sprintf(`"%s" = ?`, entry.name))
 		}
 	}
 	return strings.Join(fields, ", ")
@@ -177,7 +276,7 @@ type updateFieldEntry struct {
 func (e SchemaEntity) updateFieldEntries(onlyWithValue bool, values map[string]string) []updateFieldEntry {
 	entries := []updateFieldEntry{}
 	for _, f := range e.Fields {
-		if f.Field.Key {
+		if f.Field.Key || f.Computed != "" {
 			continue
 		}
 		value, ok := values[f.Field.Uuid]
@@ -200,21 +299,24 @@ func (e SchemaEntity) updateFieldEntries(onlyWithValue bool, values map[string]s
 func (e SchemaEntity) UpdateFieldsWithValues(values map[string]string) string {
 	fields := []string{}
 	for _, f := range e.Fields {
-		if !f.Field.Key {
+		if !f.Field.Key && f.Computed == "" {
 			if value, ok := values[f.Field.Uuid]; ok {
 				if blankMeansNull(f.Field) && value == "" {
 					switch e.DBType {
 					case db.MYSQLDBType:
 						fields = append(fields, fmt.Sprintf("`%s` = NULL", f.Name))
-					case db.PGDBType:
+					case db.PGDBType, db.SQLiteDBType:
 						fields = append(fields, fmt.Sprintf(`"%s" = NULL`, f.Name))
 					}
 				} else {
+					value = coerceParamValue(f, value, e.DBType)
 					switch e.DBType {
 					case db.MYSQLDBType:
 						fields = append(fields, fmt.Sprintf("`%s` = '%s'", f.Name, EscapeValue(value)))
 					case db.PGDBType:
 						fields = append(fields, fmt.Sprintf(`"%s" = '%s'`, f.Name, EscapeValue(value)))
+					case db.SQLiteDBType:
+						fields = append(fields, fmt.Sprintf(`"%s" = '%s'`, f.Name, escapeLiteral(value, e.DBType)))
 					}
 				}
 			}
@@ -235,10 +337,27 @@ type SchemaField struct {
 	// OnUpdate is mysql's ON UPDATE CURRENT_TIMESTAMP clause, empty everywhere
 	// else — postgres has no column-level equivalent (see onUpdateClause).
 	OnUpdate string
+	// Identity is what makes an auto-increment key fill itself in (see
+	// identityClause).
+	Identity string
+	// Computed is the GENERATED ALWAYS AS clause of a computed column, empty
+	// for every other; see SchemaOptions.Computed.
+	Computed string
+	// Comment is mysql's COMMENT clause, empty everywhere else — postgres
+	// comments on a column in a statement of its own (see CommentStatements).
+	Comment string
+	// EnumLabels are the labels a native enum column stores, by the option's
+	// numeric value and by the label itself; nil when the column stores the
+	// numbers. See applyEnumMode.
+	EnumLabels map[string]string
 }
 
 func (f SchemaField) Postfix() string {
 	res := []string{}
+	if f.Computed != "" {
+		// mysql only takes the clause ahead of NOT NULL
+		res = append(res, f.Computed)
+	}
 	if f.Null != "" {
 		res = append(res, f.Null)
 	}
@@ -250,6 +369,12 @@ func (f SchemaField) Postfix() string {
 	if f.OnUpdate != "" {
 		res = append(res, f.OnUpdate)
 	}
+	if f.Identity != "" {
+		res = append(res, f.Identity)
+	}
+	if f.Comment != "" {
+		res = append(res, f.Comment)
+	}
 	return strings.Join(res, " ")
 }
 
@@ -282,6 +407,12 @@ type SchemaIndex struct {
 	Type       string
 	TypeSort   int
 	HasComma   bool
+	// Where, Expressions, Method and OpClasses are the index's options (see
+	// IndexOptions), with what the engine does not take left empty.
+	Where       string
+	Expressions []IndexExpression
+	Method      string
+	OpClasses   map[string]string
 }
 
 // prefixLength resolves the prefix length to render for one index field on
@@ -292,7 +423,7 @@ type SchemaIndex struct {
 // one puts the generated DDL permanently out of step with what the database
 // reports and the diff proposes the index again on every plan.
 func (i SchemaIndex) prefixLength(f *nemgen.IndexField) int64 {
-	if i.DBType != db.MYSQLDBType || i.Type == "fulltext" {
+	if i.DBType != db.MYSQLDBType || i.Type == "fulltext" || i.Method == "spatial" {
 		return 0
 	}
 	if f.Length > 0 {
@@ -310,7 +441,7 @@ func (i SchemaIndex) FieldNamesIdentifiers() string {
 		return fields[i].Priority < fields[j].Priority
 	})
 
-	fieldsStr := []string{}
+	fieldsStr := []indexMember{}
 	for _, f := range fields {
 
 		if i.DBType == db.MYSQLDBType {
@@ -319,12 +450,12 @@ func (i SchemaIndex) FieldNamesIdentifiers() string {
 				orderStr = " DESC"
 			}
 			if length := i.prefixLength(f); length > 0 {
-				fieldsStr = append(fieldsStr, fmt.Sprintf("`%s`(%d)%s", i.FieldNames[f.FieldUuid], length, orderStr))
+				fieldsStr = append(fieldsStr, indexMember{f.Priority, fmt.Sprintf("`%s`(%d)%s", i.FieldNames[f.FieldUuid], length, orderStr)})
 			} else {
 				if orderStr != "" {
-					fieldsStr = append(fieldsStr, fmt.Sprintf("`%s` %s", i.FieldNames[f.FieldUuid], strings.TrimSpace(orderStr)))
+					fieldsStr = append(fieldsStr, indexMember{f.Priority, fmt.Sprintf("`%s` %s", i.FieldNames[f.FieldUuid], strings.TrimSpace(orderStr))})
 				} else {
-					fieldsStr = append(fieldsStr, fmt.Sprintf("`%s`", i.FieldNames[f.FieldUuid]))
+					fieldsStr = append(fieldsStr, indexMember{f.Priority, fmt.Sprintf("`%s`", i.FieldNames[f.FieldUuid])})
 				}
 			}
 		} else if i.DBType == db.PGDBType {
@@ -333,11 +464,19 @@ func (i SchemaIndex) FieldNamesIdentifiers() string {
 			// ("function col(integer) does not exist") or — when the column name
 			// collides with a built-in type such as name/text/date — silently
 			// builds a btree over the constant 10, indexing nothing.
-			fieldsStr = append(fieldsStr, fmt.Sprintf(`"%s"`, i.FieldNames[f.FieldUuid]))
+			fieldsStr = append(fieldsStr, indexMember{f.Priority, fmt.Sprintf(`"%s"%s`, i.FieldNames[f.FieldUuid], i.opClass(i.OpClasses[f.FieldUuid]))})
+		} else if i.DBType == db.SQLiteDBType {
+			// sqlite has no prefix indexes either, but it does keep the sort
+			// order, and reports it back through PRAGMA index_xinfo.
+			if f.Order == nemgen.IndexFieldOrder_INDEX_FIELD_ORDER_DESC {
+				fieldsStr = append(fieldsStr, indexMember{f.Priority, fmt.Sprintf(`"%s" DESC`, i.FieldNames[f.FieldUuid])})
+			} else {
+				fieldsStr = append(fieldsStr, indexMember{f.Priority, fmt.Sprintf(`"%s"`, i.FieldNames[f.FieldUuid])})
+			}
 		}
 	}
 
-	return fmt.Sprintf("(%s)", strings.Join(fieldsStr, ", "))
+	return fmt.Sprintf("(%s)", strings.Join(i.withExpressions(fieldsStr), ", "))
 }
 
 // FullTextExpression renders the indexable expression for a FULLTEXT index on
@@ -400,6 +539,19 @@ type SchemaSelectStatement struct {
 	IsPrimary        bool
 	TimeFields       []SchemaField
 	SortSupported    bool
+	// Unique is whether the select is over exactly the columns of one UNIQUE
+	// index, which an upsert can conflict on.
+	Unique bool
+	// UpsertWhere is the predicate of the partial index the upsert conflicts
+	// on, which the conflict target repeats.
+	UpsertWhere string
+	// index is the uuid of the UNIQUE index of a Unique select.
+	index string
+	// SoftDeleteFilter is what the WHERE adds to leave soft deleted rows out,
+	// empty when the entity has none or the select includes them.
+	SoftDeleteFilter string
+	// Keysets are the select's keyset pages, one per time field and order.
+	Keysets []SchemaSelectKeyset
 }
 
 type SchemaSelectStatementField struct {
@@ -409,14 +561,24 @@ type SchemaSelectStatementField struct {
 }
 
 // contraints
+// SchemaCheck is a CHECK constraint. It is always named, so a migration can
+// drop it on every engine: mysql's generated names depend on creation order.
+type SchemaCheck struct {
+	DBType     db.DBType
+	Name       string
+	Expression string
+}
+
 type SchemaConstraint struct {
 	DBType       db.DBType
 	Name         string
 	Relationship *nemgen.Relationship
 	TableName    string
-	FromFields   []SchemaField
-	ToFields     []SchemaField
-	HasComma     bool
+	// TableSchema is the postgres schema of the referenced table.
+	TableSchema string
+	FromFields  []SchemaField
+	ToFields    []SchemaField
+	HasComma    bool
 }
 
 // ReferentialActions renders the ON DELETE / ON UPDATE clauses of the foreign
@@ -443,7 +605,7 @@ func (sc SchemaConstraint) ForeignKeyFields() string {
 	for _, f := range sc.FromFields {
 		if sc.DBType == db.MYSQLDBType {
 			fields = append(fields, fmt.Sprintf("`%s`", f.Name))
-		} else if sc.DBType == db.PGDBType {
+		} else if sc.DBType == db.PGDBType || sc.DBType == db.SQLiteDBType {
 			fields = append(fields, fmt.Sprintf(`"%s"`, f.Name))
 		}
 	}
@@ -459,7 +621,7 @@ func (sc SchemaConstraint) ReferenceFields() string {
 	for _, f := range sc.ToFields {
 		if sc.DBType == db.MYSQLDBType {
 			fields = append(fields, fmt.Sprintf("`%s`", f.Name))
-		} else if sc.DBType == db.PGDBType {
+		} else if sc.DBType == db.PGDBType || sc.DBType == db.SQLiteDBType {
 			fields = append(fields, fmt.Sprintf(`"%s"`, f.Name))
 		}
 	}
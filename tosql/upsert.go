@@ -0,0 +1,119 @@
+package tosql
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/nuzur/sql-gen/db"
+)
+
+// Upsert is what an insert does with a row that conflicts with one the table
+// already has.
+type Upsert string
+
+const (
+	// UpsertNone fails the statement on a conflicting row.
+	UpsertNone Upsert = ""
+	// UpsertUpdate overwrites the existing row with the columns the statement
+	// writes, bar the primary key and the columns conflicted on.
+	UpsertUpdate Upsert = "update"
+	// UpsertNothing keeps the existing row and skips the new one.
+	UpsertNothing Upsert = "nothing"
+)
+
+// mysqlUpsertAlias is the row alias a mysql upsert reads the new row through.
+const mysqlUpsertAlias = "new"
+
+// fitUpsertsToIndexes fits the upserts of the unique selects to the options of
+// their indexes. A partial index is a conflict target only together with its
+// predicate, and one with expressions is none: the target lists columns. mysql
+// names no target, and a partial index is a whole one there.
+func fitUpsertsToIndexes(selects []SchemaSelectStatement, indexes []SchemaIndex) {
+	for n, s := range selects {
+		if !s.Unique {
+			continue
+		}
+		for _, i := range indexes {
+			if i.Index.GetUuid() != s.index {
+				continue
+			}
+			if len(i.Expressions) > 0 && i.DBType != db.MYSQLDBType {
+				selects[n].Unique = false
+			}
+			selects[n].UpsertWhere = i.Where
+		}
+	}
+}
+
+// UpsertOnPrimaryKey is the conflict clause of the entity's Upsert query,
+// which overwrites the row with the same primary key.
+func (e SchemaEntity) UpsertOnPrimaryKey() string {
+	target := []string{}
+	for _, pk := range e.PrimaryKeys {
+		target = append(target, unquoteIdentifier(pk))
+	}
+	return e.upsertClause(UpsertUpdate, target, "", e.InsertFields())
+}
+
+// UpsertOnIndex is the conflict clause of the Upsert query by a unique
+// select's index. mysql updates whichever unique key conflicts, so there the
+// query differs from the primary key's only in the columns it leaves alone.
+func (e SchemaEntity) UpsertOnIndex(s SchemaSelectStatement) string {
+	target := []string{}
+	for _, f := range s.Fields {
+		target = append(target, f.Field.Name)
+	}
+	return e.upsertClause(UpsertUpdate, target, s.UpsertWhere, e.InsertFields())
+}
+
+// upsertClause is what follows the VALUES of an upsert, conflicting on the
+// target columns and overwriting the other columns written, bar the primary
+// key. where is the predicate of a partial index the target is.
+//
+// On mysql the new row is read through the row alias the clause gives it,
+// which needs MySQL 8.0.19 or later: the VALUES() function it replaces is
+// deprecated since 8.0.20.
+func (e SchemaEntity) upsertClause(upsert Upsert, target []string, where string, columns []SchemaField) string {
+	if upsert == UpsertNone {
+		return ""
+	}
+	quotedTarget := []string{}
+	inTarget := map[string]bool{}
+	for _, name := range target {
+		quotedTarget = append(quotedTarget, e.quote(name))
+		inTarget[name] = true
+	}
+
+	set := []string{}
+	if upsert == UpsertUpdate {
+		for _, f := range columns {
+			if inTarget[f.Name] || f.Field.Key {
+				continue
+			}
+			column := e.quote(f.Name)
+			if e.DBType == db.MYSQLDBType {
+				set = append(set, fmt.Sprintf("%s = %s.%s", column, mysqlUpsertAlias, column))
+			} else {
+				set = append(set, fmt.Sprintf("%s = EXCLUDED.%s", column, column))
+			}
+		}
+	}
+
+	if e.DBType == db.MYSQLDBType {
+		// mysql has no DO NOTHING, and INSERT IGNORE would ignore far more
+		// than the conflict, so nothing is setting a column to itself
+		if len(set) == 0 {
+			set = append(set, fmt.Sprintf("%s = %s", quotedTarget[0], quotedTarget[0]))
+			return fmt.Sprintf("\nON DUPLICATE KEY UPDATE\n%s", strings.Join(set, ", "))
+		}
+		return fmt.Sprintf(" AS %s\nON DUPLICATE KEY UPDATE\n%s", mysqlUpsertAlias, strings.Join(set, ", "))
+	}
+	conflict := fmt.Sprintf("(%s)", strings.Join(quotedTarget, ", "))
+	if where != "" {
+		conflict += fmt.Sprintf(" WHERE %s", where)
+	}
+	if len(set) == 0 {
+		return fmt.Sprintf("\nON CONFLICT %s DO NOTHING", conflict)
+	}
+	return fmt.Sprintf("\nON CONFLICT %s DO UPDATE SET\n%s", conflict, strings.Join(set, ", "))
+}
@@ -0,0 +1,115 @@
+package tosql
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	nemgen "github.com/nuzur/nem/idl/gen"
+	"github.com/nuzur/sql-gen/db"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUpsertForGolangNumbersPlaceholders(t *testing.T) {
+	pv := loadTestProjectVersion(t)
+	results, err := GenerateSQLResults(context.Background(), GenerateRequest{
+		ProjectVersion: pv,
+		ForGolang:      true,
+		Configvalues: &ConfigValues{
+			DBType:   db.PGDBType,
+			Entities: []string{testUserEntityUUID},
+			Actions:  []Action{UpsertAction},
+		},
+	})
+	require.NoError(t, err)
+	require.Len(t, results, 1)
+
+	queries := strings.Split(strings.TrimSpace(results[0].Data), "\n\n")
+	require.Len(t, queries, 3)
+	for _, query := range queries {
+		assert.Contains(t, query, "($1,$2,$3,$4,$5,$6,$7,$8,$9)")
+	}
+	assert.Contains(t, queries[2], "-- name: UpsertUserByEmail :exec")
+	assert.Contains(t, queries[2], `ON CONFLICT ("email") DO UPDATE SET`)
+}
+
+// An upsert can only conflict on what the table really has unique: a select
+// over part of a unique index, or over an index that is not emitted, gets no
+// upsert.
+func TestResolveSelectStatementsUnique(t *testing.T) {
+	pv := loadTestProjectVersion(t)
+	entity := testEntity(t, pv, testUserEntityUUID)
+
+	unique := map[string]bool{}
+	for _, s := range ResolveSelectStatements(entity, db.PGDBType) {
+		unique[s.Name] = s.Unique
+	}
+	assert.Equal(t, map[string]bool{
+		"UserByUUIDAndVersion":        false, // the primary key has its own upsert
+		"UserByUUID":                  true,
+		"UserByEmail":                 true,
+		"UserByStatus":                false,
+		"UserByUUIDAndEmail":          false,
+		"UserByEmailAndStatus":        false,
+		"UserByUUIDAndEmailAndStatus": false,
+		"UserByUUIDAndStatus":         false,
+	}, unique)
+
+	// a unique index over a datetime too is partly left out of the select
+	for _, i := range entity.TypeConfig.Standalone.Indexes {
+		if i.Identifier == "unique_email" {
+			i.Fields = append(i.Fields, &nemgen.IndexField{FieldUuid: userFieldCreatedAt})
+		}
+		if i.Identifier == "unique_uuid" {
+			i.Status = nemgen.IndexStatus_INDEX_STATUS_INVALID
+		}
+	}
+	for _, s := range ResolveSelectStatements(entity, db.PGDBType) {
+		assert.False(t, s.Unique, s.Name)
+	}
+}
+
+// A partial unique index is a conflict target only together with its
+// predicate, and one over an expression is none.
+func TestUpsertOnPartialIndex(t *testing.T) {
+	pv := loadTestProjectVersion(t)
+	entity := testEntity(t, pv, testUserEntityUUID)
+	var email string
+	for _, i := range entity.TypeConfig.Standalone.Indexes {
+		if i.Identifier == "unique_email" {
+			email = i.Uuid
+		}
+	}
+	require.NotEmpty(t, email)
+
+	upserts := func(dbType db.DBType, options IndexOptions) []string {
+		results, err := GenerateSQLResults(context.Background(), GenerateRequest{
+			ProjectVersion: pv,
+			Configvalues: &ConfigValues{
+				DBType:        dbType,
+				Entities:      []string{testUserEntityUUID},
+				Actions:       []Action{UpsertAction},
+				SchemaOptions: SchemaOptions{Indexes: map[string]IndexOptions{email: options}},
+			},
+		})
+		require.NoError(t, err)
+		require.Len(t, results, 1)
+		return strings.Split(strings.TrimSpace(results[0].Data), "\n\n")
+	}
+
+	for _, dbType := range []db.DBType{db.PGDBType, db.SQLiteDBType} {
+		queries := upserts(dbType, IndexOptions{Where: "status = 1"})
+		require.Len(t, queries, 3)
+		assert.Contains(t, queries[2], `ON CONFLICT ("email") WHERE status = 1 DO UPDATE SET`, dbType)
+
+		queries = upserts(dbType, IndexOptions{Expressions: []IndexExpression{{Expression: "lower(email)"}}})
+		require.Len(t, queries, 2, dbType)
+		assert.NotContains(t, strings.Join(queries, "\n"), "UpsertUserByEmail", dbType)
+	}
+
+	// mysql names no target
+	queries := upserts(db.MYSQLDBType, IndexOptions{Where: "status = 1"})
+	require.Len(t, queries, 3)
+	assert.NotContains(t, queries[2], "WHERE")
+}
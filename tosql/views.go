@@ -0,0 +1,111 @@
+package tosql
+
+import (
+	"slices"
+	"strings"
+
+	"github.com/nuzur/sql-gen/db"
+)
+
+// View is a view over the entities' tables. nem has no notion of one, so views
+// travel beside the project version in SchemaOptions.
+type View struct {
+	// Schema is the postgres schema the view is in, empty for the connection's
+	// default one. Other engines ignore it.
+	Schema string `json:"schema,omitempty"`
+	Name   string `json:"name"`
+	// Definition is the view's query in the engine's own SQL: what follows the
+	// AS of a CREATE VIEW, without a terminating semicolon.
+	Definition string `json:"definition"`
+	// Materialized makes it a postgres materialized view. The other engines
+	// have none, and create a plain view instead.
+	Materialized bool `json:"materialized,omitempty"`
+}
+
+// SchemaView is a view as the templates render it.
+type SchemaView struct {
+	DBType       db.DBType
+	Schema       string
+	Name         string
+	Definition   string
+	Materialized bool
+}
+
+// QualifiedName is the quoted name of the view, in its schema if it has one.
+func (v SchemaView) QualifiedName() string {
+	return qualifiedName(v.DBType, v.Schema, v.Name)
+}
+
+// DropViews is the views in the order they can be dropped in, every view
+// before the views it selects from.
+func (t SchemaTemplate) DropViews() []SchemaView {
+	res := slices.Clone(t.Views)
+	slices.Reverse(res)
+	return res
+}
+
+// mapViews maps the views for the engine, each one after the views it selects
+// from so they can be created in order.
+//
+// A view's dependencies are not declared anywhere portable, so they are read
+// off its definition: a view depends on another if it names it. A definition
+// naming a view only in a string or a column alias makes for a needlessly
+// strict order, never a wrong one. Views that depend on nothing among
+// themselves keep the order they are given in.
+func mapViews(views []View, dbType db.DBType) []SchemaView {
+	pending := []SchemaView{}
+	for _, v := range views {
+		sv := SchemaView{
+			DBType:     dbType,
+			Name:       v.Name,
+			Definition: strings.TrimSpace(v.Definition),
+		}
+		if dbType == db.PGDBType {
+			sv.Schema = v.Schema
+			sv.Materialized = v.Materialized
+		}
+		pending = append(pending, sv)
+	}
+
+	res := []SchemaView{}
+	for len(pending) > 0 {
+		next := slices.IndexFunc(pending, func(v SchemaView) bool {
+			return !slices.ContainsFunc(pending, func(other SchemaView) bool {
+				return other.Name != v.Name && referencesName(v.Definition, other.Name)
+			})
+		})
+		if next < 0 {
+			// views that name each other cannot exist; keep the given order
+			next = 0
+		}
+		res = append(res, pending[next])
+		pending = slices.Delete(pending, next, next+1)
+	}
+	return res
+}
+
+// referencesName reports whether the SQL names the identifier as a word of
+// its own, quoted or not, ignoring case.
+func referencesName(sql string, name string) bool {
+	if name == "" {
+		return false
+	}
+	lowerSQL := strings.ToLower(sql)
+	lowerName := strings.ToLower(name)
+	for offset := 0; ; {
+		i := strings.Index(lowerSQL[offset:], lowerName)
+		if i < 0 {
+			return false
+		}
+		start := offset + i
+		end := start + len(lowerName)
+		if (start == 0 || !isIdentifierByte(lowerSQL[start-1])) && (end == len(lowerSQL) || !isIdentifierByte(lowerSQL[end])) {
+			return true
+		}
+		offset = start + 1
+	}
+}
+
+func isIdentifierByte(c byte) bool {
+	return c == '_' || c == '$' || c >= '0' && c <= '9' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z'
+}
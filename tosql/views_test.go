@@ -0,0 +1,107 @@
+package tosql
+
+import (
+	"testing"
+
+	"github.com/nuzur/sql-gen/db"
+	"github.com/stretchr/testify/assert"
+)
+
+// viewOptions has a view over lot, and a materialized view over that view,
+// listed before the view it selects from.
+var viewOptions = SchemaOptions{Views: []View{
+	{Name: "lot_totals", Definition: "SELECT sum(qty) AS qty FROM big_lots", Materialized: true},
+	{Name: "big_lots", Definition: "SELECT id, qty FROM lot WHERE qty > 100"},
+}}
+
+func TestViewsAreCreatedAfterTheTablesTheySelectFrom(t *testing.T) {
+	assert.Equal(t, "CREATE TABLE IF NOT EXISTS \"lot\" (\n"+
+		"    \"id\" UUID NOT NULL,\n"+
+		"    \"qty\" INTEGER NOT NULL,\n"+
+		"    PRIMARY KEY (\"id\")\n"+
+		");\n\n"+
+		"CREATE OR REPLACE VIEW \"big_lots\" AS\n"+
+		"SELECT id, qty FROM lot WHERE qty > 100;\n\n"+
+		"CREATE MATERIALIZED VIEW IF NOT EXISTS \"lot_totals\" AS\n"+
+		"SELECT sum(qty) AS qty FROM big_lots;\n\n",
+		generateWithOptions(t, checkVersion(), db.PGDBType, viewOptions, CreateAction))
+
+	// there are no materialized views off postgres
+	assert.Contains(t, generateWithOptions(t, checkVersion(), db.MYSQLDBType, viewOptions, CreateAction),
+		") ENGINE = InnoDB;\n\n"+
+			"CREATE OR REPLACE VIEW `big_lots` AS\n"+
+			"SELECT id, qty FROM lot WHERE qty > 100;\n\n"+
+			"CREATE OR REPLACE VIEW `lot_totals` AS\n"+
+			"SELECT sum(qty) AS qty FROM big_lots;\n\n")
+	assert.Contains(t, generateWithOptions(t, checkVersion(), db.SQLiteDBType, viewOptions, CreateAction),
+		"CREATE VIEW IF NOT EXISTS \"big_lots\" AS\n")
+}
+
+func TestViewsAreDroppedBeforeTheirTables(t *testing.T) {
+	assert.Equal(t, "DROP MATERIALIZED VIEW IF EXISTS \"lot_totals\";\n"+
+		"DROP VIEW IF EXISTS \"big_lots\";\n"+
+		"DROP TABLE IF EXISTS \"lot\";\n",
+		generateWithOptions(t, checkVersion(), db.PGDBType, viewOptions, DropAction))
+}
+
+func TestMigrationRecreatesChangedViews(t *testing.T) {
+	to := SchemaOptions{Views: []View{
+		{Name: "lot_totals", Definition: "SELECT sum(qty) AS qty FROM big_lots", Materialized: true},
+		{Name: "big_lots", Definition: "SELECT id, qty FROM lot WHERE qty > 500"},
+		{Name: "all_lots", Definition: "SELECT id FROM lot"},
+	}}
+
+	// big_lots changes, and lot_totals has to go with it
+	m, err := GenerateMigrationWithOptions(checkVersion(), checkVersion(), db.PGDBType, viewOptions, to)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{
+		`DROP MATERIALIZED VIEW "lot_totals";`,
+		`DROP VIEW "big_lots";`,
+		"CREATE OR REPLACE VIEW \"big_lots\" AS\nSELECT id, qty FROM lot WHERE qty > 500;",
+		"CREATE MATERIALIZED VIEW IF NOT EXISTS \"lot_totals\" AS\nSELECT sum(qty) AS qty FROM big_lots;",
+		"CREATE OR REPLACE VIEW \"all_lots\" AS\nSELECT id FROM lot;",
+	}, m.Statements)
+	assert.Equal(t, []string{
+		`DROP VIEW "all_lots";`,
+		`DROP MATERIALIZED VIEW "lot_totals";`,
+		`DROP VIEW "big_lots";`,
+		"CREATE OR REPLACE VIEW \"big_lots\" AS\nSELECT id, qty FROM lot WHERE qty > 100;",
+		"CREATE MATERIALIZED VIEW IF NOT EXISTS \"lot_totals\" AS\nSELECT sum(qty) AS qty FROM big_lots;",
+	}, m.Rollback)
+
+	m, err = GenerateMigrationWithOptions(checkVersion(), checkVersion(), db.PGDBType, viewOptions, viewOptions)
+	assert.NoError(t, err)
+	assert.Empty(t, m.Statements)
+}
+
+func TestMigrationRecreatesViewsOverChangedTables(t *testing.T) {
+	to := checkVersion()
+	to.Entities[0].Fields[1].Identifier = "quantity"
+	options := SchemaOptions{Views: []View{{Name: "lots", Definition: "SELECT * FROM lot"}}}
+
+	m, err := GenerateMigrationWithOptions(checkVersion(), to, db.PGDBType, options, options)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{
+		`DROP VIEW "lots";`,
+		`ALTER TABLE "lot" RENAME COLUMN "qty" TO "quantity";`,
+		"CREATE OR REPLACE VIEW \"lots\" AS\nSELECT * FROM lot;",
+	}, m.Statements)
+}
+
+func TestReferencesName(t *testing.T) {
+	cases := []struct {
+		sql  string
+		name string
+		want bool
+	}{
+		{"SELECT * FROM lot", "lot", true},
+		{`SELECT * FROM "Lot" l`, "lot", true},
+		{"SELECT * FROM `db`.`lot`", "lot", true},
+		{"SELECT * FROM lots", "lot", false},
+		{"SELECT lot_id FROM parcel", "lot", false},
+		{"SELECT * FROM parcel JOIN lot ON true", "lot", true},
+	}
+	for _, tc := range cases {
+		assert.Equal(t, tc.want, referencesName(tc.sql, tc.name), tc.sql)
+	}
+}